@@ -17,9 +17,19 @@ const (
 type Option func(order *Order)
 
 type Order struct {
-	column string
-	sort   Sort
-	isRaw  bool
+	column   string
+	sort     Sort
+	isRaw    bool
+	coalesce []string
+	collate  string
+	values   []interface{}
+	distance *distance
+}
+
+// the longitude column and the point the distance of the column, the latitude, and the longitude is to
+type distance struct {
+	lngColumn string
+	lat, lng  float64
 }
 
 func Clause(options ...Option) *Order {
@@ -54,6 +64,29 @@ func (o *Order) IsRaw() bool {
 	return o.isRaw
 }
 
+// GetCoalesce return the columns ordered by their first non null value
+func (o *Order) GetCoalesce() []string {
+	return o.coalesce
+}
+
+// GetCollate return the collation the column is ordered with
+func (o *Order) GetCollate() string {
+	return o.collate
+}
+
+// GetValues return the values the column is ordered by the position in
+func (o *Order) GetValues() []interface{} {
+	return o.values
+}
+
+// GetDistance return the longitude column and the point the column, the latitude, is ordered by the distance to
+func (o *Order) GetDistance() (lngColumn string, lat float64, lng float64, ok bool) {
+	if o.distance == nil {
+		return "", 0, 0, false
+	}
+	return o.distance.lngColumn, o.distance.lat, o.distance.lng, true
+}
+
 func ParseOrder(expressions ...string) []*Order {
 	var orders []*Order
 	for _, expression := range expressions {
@@ -102,3 +135,38 @@ func Raw() Option {
 		order.isRaw = true
 	}
 }
+
+// Coalesce order by the first non null value of the columns, COALESCE(column1, column2, ...)
+func Coalesce(columns ...string) Option {
+	return func(order *Order) {
+		order.coalesce = make([]string, 0, len(columns))
+		for _, column := range columns {
+			order.coalesce = append(order.coalesce, strings.ReplaceAll(column, clauses.ExprSep, clauses.ExprDot))
+		}
+	}
+}
+
+// Collate order by the column with the collation, column COLLATE collation
+func Collate(collation string) Option {
+	return func(order *Order) {
+		order.collate = collation
+	}
+}
+
+// Values order by the position of the value of the column in values, the first value first
+func Values(values ...interface{}) Option {
+	return func(order *Order) {
+		order.values = values
+	}
+}
+
+// Distance order by the distance of the point of the column, the latitude, and lngColumn to the point lat, lng
+func Distance(lngColumn string, lat float64, lng float64) Option {
+	return func(order *Order) {
+		order.distance = &distance{
+			lngColumn: strings.ReplaceAll(lngColumn, clauses.ExprSep, clauses.ExprDot),
+			lat:       lat,
+			lng:       lng,
+		}
+	}
+}
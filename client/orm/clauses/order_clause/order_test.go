@@ -135,3 +135,37 @@ func TestSortString(t *testing.T) {
 		t.Errorf(template, o3.SortString(), ``)
 	}
 }
+
+func TestCoalesce(t *testing.T) {
+	o := Clause(
+		Coalesce(`display_name`, `user__user_name`),
+	)
+
+	coalesce := o.GetCoalesce()
+	if len(coalesce) != 2 || coalesce[0] != `display_name` || coalesce[1] != `user.user_name` {
+		t.Error()
+	}
+}
+
+func TestCollate(t *testing.T) {
+	o := Clause(
+		Column(`user__user_name`),
+		Collate(`utf8mb4_general_ci`),
+	)
+
+	if o.GetColumn() != `user.user_name` || o.GetCollate() != `utf8mb4_general_ci` {
+		t.Error()
+	}
+}
+
+func TestValues(t *testing.T) {
+	o := Clause(
+		Column(`user__id`),
+		Values(3, 1, 2),
+	)
+
+	values := o.GetValues()
+	if o.GetColumn() != `user.id` || len(values) != 3 || values[0] != 3 || values[2] != 2 {
+		t.Error()
+	}
+}
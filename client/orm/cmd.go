@@ -33,6 +33,31 @@ type commander interface {
 
 var commands = make(map[string]commander)
 
+// MigrationOpType is the kind of a destructive operation of syncdb.
+type MigrationOpType int
+
+const (
+	// MigrationDropTable drops a table before creating it again, enabled by force.
+	MigrationDropTable MigrationOpType = iota
+)
+
+// MigrationOp describes a destructive operation of syncdb passed to the migration guard.
+type MigrationOp struct {
+	Type  MigrationOpType
+	Alias string
+	Table string
+	SQL   string
+}
+
+var migrationGuard func(op MigrationOp) error
+
+// SetMigrationGuard set the guard consulted before every destructive operation of syncdb,
+// an error returned by guard vetoes the operation and stops syncdb with that error.
+// guard nil removes the guard.
+func SetMigrationGuard(guard func(op MigrationOp) error) {
+	migrationGuard = guard
+}
+
 // print help.
 func printHelp(errs ...string) {
 	content := `orm command usage:
@@ -115,6 +140,12 @@ func (d *commandSyncDb) Run() error {
 	if d.force && len(drops) > 0 {
 		for i, mi := range defaultModelCache.AllOrdered() {
 			query := drops[i]
+			if migrationGuard != nil {
+				op := MigrationOp{Type: MigrationDropTable, Alias: d.al.Name, Table: mi.Table, SQL: query}
+				if err := migrationGuard(op); err != nil {
+					return err
+				}
+			}
 			if !d.noInfo {
 				fmt.Printf("drop table `%s`\n", mi.Table)
 			}
@@ -173,13 +204,20 @@ func (d *commandSyncDb) Run() error {
 			}
 
 			for _, fi := range fields {
-				query := getColumnAddQuery(d.al, fi)
+				query, err := getColumnAddQuery(d.al, fi)
+				if err != nil {
+					if d.rtOnError {
+						return err
+					}
+					fmt.Printf("    %s\n", err.Error())
+					continue
+				}
 
 				if !d.noInfo {
 					fmt.Printf("add column `%s` for table `%s`\n", fi.FullName, mi.Table)
 				}
 
-				_, err := db.Exec(query)
+				_, err = db.Exec(query)
 				if d.verbose {
 					fmt.Printf("    %s\n", query)
 				}
@@ -289,6 +327,7 @@ func init() {
 // name: Table's alias name (default is "default")
 // force: Run the next sql command even if the current gave an error
 // verbose: Print All information, useful for debugging
+// The rel(fk) columns are indexed when the alias is registered with the IndexForeignKeys option.
 func RunSyncdb(name string, force bool, verbose bool) error {
 	BootStrap()
 
@@ -32,6 +32,10 @@ func getColumnTyp(al *alias, fi *models.FieldInfo) (col string) {
 	T := al.DbBaser.DbTypes()
 	fieldType := fi.FieldType
 	fieldSize := fi.Size
+	if fi.TimeFormat != "" {
+		// the formatted time is stored as a string
+		fieldType = TypeVarCharField
+	}
 
 	defer func() {
 		// handling the placeholder, including %COL%
@@ -42,14 +46,20 @@ checkColumn:
 	switch fieldType {
 	case TypeBooleanField:
 		col = T["bool"]
-	case TypeVarCharField:
-		if al.Driver == DRPostgres && fi.ToText {
+	case TypeVarCharField, TypeCharField:
+		if s := T["set"]; s != "" && len(fi.SetValues) > 0 {
+			members := make([]string, len(fi.SetValues))
+			for i, member := range fi.SetValues {
+				members[i] = "'" + strings.ReplaceAll(member, "'", "''") + "'"
+			}
+			col = fmt.Sprintf(s, strings.Join(members, ", "))
+		} else if fieldType == TypeCharField {
+			col = fmt.Sprintf(T["string-char"], fieldSize)
+		} else if al.Driver == DRPostgres && fi.ToText {
 			col = T["string-text"]
 		} else {
 			col = fmt.Sprintf(T["string"], fieldSize)
 		}
-	case TypeCharField:
-		col = fmt.Sprintf(T["string-char"], fieldSize)
 	case TypeTextField:
 		col = T["string-text"]
 	case TypeTimeField:
@@ -89,6 +99,9 @@ checkColumn:
 		col = T["float64"]
 	case TypeDecimalField:
 		s := T["float64-decimal"]
+		if fi.IsBigRat {
+			s = T["big.Rat-decimal"]
+		}
 		if !strings.Contains(s, "%d") {
 			col = s
 		} else {
@@ -106,6 +119,14 @@ checkColumn:
 			goto checkColumn
 		}
 		col = T["jsonb"]
+	case TypeBinaryField:
+		if fieldSize == 0 {
+			col = T["[]byte"]
+		} else if s := T["[]byte-size"]; strings.Contains(s, "%d") {
+			col = fmt.Sprintf(s, fieldSize)
+		} else {
+			col = s
+		}
 	case RelForeignKey, RelOneToOne:
 		fieldType = fi.RelModelInfo.Fields.Pk.FieldType
 		fieldSize = fi.RelModelInfo.Fields.Pk.Size
@@ -116,9 +137,33 @@ checkColumn:
 }
 
 // create alter sql string.
-func getColumnAddQuery(al *alias, fi *models.FieldInfo) string {
+// a NOT NULL column needs a default to fill the existing rows of the table,
+// the time and text columns have none unless the field has a default(...) tag.
+func getColumnAddQuery(al *alias, fi *models.FieldInfo) (string, error) {
 	Q := al.DbBaser.TableQuote()
 	typ := getColumnTyp(al, fi)
+	def := getColumnDefault(al, fi)
+
+	if !fi.Null {
+		if def == "" && fi.ColDefault {
+			def = fmt.Sprintf(" DEFAULT '%s' ", fi.Initial.String())
+		}
+		if def == "" {
+			return "", fmt.Errorf("<orm.RunSyncdb> NOT NULL column `%s` of table `%s` needs a default(...) to be added to the existing rows", fi.Column, fi.Mi.Table)
+		}
+	}
+
+	// oracle adds without COLUMN and wants the default before the constraints
+	if al.Driver == DROracle {
+		if !fi.Null {
+			def += "NOT NULL"
+		}
+		return fmt.Sprintf("ALTER TABLE %s%s%s ADD %s%s%s %s%s",
+			Q, fi.Mi.Table, Q,
+			Q, fi.Column, Q,
+			typ, strings.TrimRight(def, " "),
+		), nil
+	}
 
 	if !fi.Null {
 		typ += " " + "NOT NULL"
@@ -127,12 +172,27 @@ func getColumnAddQuery(al *alias, fi *models.FieldInfo) string {
 	return fmt.Sprintf("ALTER TABLE %s%s%s ADD COLUMN %s%s%s %s %s",
 		Q, fi.Mi.Table, Q,
 		Q, fi.Column, Q,
-		typ, getColumnDefault(fi),
-	)
+		typ, def,
+	), nil
+}
+
+// create the sql string dropping the column of fi, the reverse of getColumnAddQuery.
+func getColumnDropQuery(al *alias, fi *models.FieldInfo) string {
+	Q := al.DbBaser.TableQuote()
+	return fmt.Sprintf("ALTER TABLE %s%s%s DROP COLUMN %s%s%s", Q, fi.Mi.Table, Q, Q, fi.Column, Q)
+}
+
+// create the sql string dropping the index idx, mysql names the table of the index.
+func getIndexDropQuery(al *alias, idx dbIndex) string {
+	Q := al.DbBaser.TableQuote()
+	if al.Driver == DRMySQL || al.Driver == DRTiDB {
+		return fmt.Sprintf("DROP INDEX %s%s%s ON %s%s%s;", Q, idx.Name, Q, Q, idx.Table, Q)
+	}
+	return fmt.Sprintf("DROP INDEX %s%s%s;", Q, idx.Name, Q)
 }
 
 // Get string value for the attribute "DEFAULT" for the CREATE, ALTER commands
-func getColumnDefault(fi *models.FieldInfo) string {
+func getColumnDefault(al *alias, fi *models.FieldInfo) string {
 	var v, t, d string
 
 	// Skip default attribute if field is in relations
@@ -144,7 +204,7 @@ func getColumnDefault(fi *models.FieldInfo) string {
 
 	// These defaults will be useful if there no config value orm:"default" and NOT NULL is on
 	switch fi.FieldType {
-	case TypeTimeField, TypeDateField, TypeDateTimeField, TypeTextField:
+	case TypeTimeField, TypeDateField, TypeDateTimeField, TypeTextField, TypeBinaryField:
 		return v
 
 	case TypeBitField, TypeSmallIntegerField, TypeIntegerField,
@@ -155,7 +215,7 @@ func getColumnDefault(fi *models.FieldInfo) string {
 		d = "0"
 	case TypeBooleanField:
 		t = " DEFAULT %s "
-		d = "FALSE"
+		d = al.DbBaser.BoolLiteral(false)
 	case TypeJSONField, TypeJsonbField:
 		d = "{}"
 	}
@@ -163,6 +223,8 @@ func getColumnDefault(fi *models.FieldInfo) string {
 	if fi.ColDefault {
 		if !fi.Initial.Exist() {
 			v = fmt.Sprintf(t, "")
+		} else if b, err := fi.Initial.Bool(); err == nil && fi.FieldType == TypeBooleanField {
+			v = fmt.Sprintf(t, al.DbBaser.BoolLiteral(b))
 		} else {
 			v = fmt.Sprintf(t, fi.Initial.String())
 		}
@@ -28,6 +28,120 @@ func Test_getColumnTyp(t *testing.T) {
 			},
 			wantCol: `bigint CHECK("my_col" >= 0)`,
 		},
+		{
+			name: "big.Rat decimal for MySQL",
+			fi: &models.FieldInfo{
+				FieldType: TypeDecimalField,
+				IsBigRat:  true,
+				Digits:    30,
+				Decimals:  10,
+			},
+			al: &alias{
+				DbBaser: newdbBaseMysql(),
+			},
+			wantCol: "numeric(30, 10)",
+		},
+		{
+			name: "big.Rat decimal for sqlite",
+			fi: &models.FieldInfo{
+				FieldType: TypeDecimalField,
+				IsBigRat:  true,
+				Digits:    30,
+				Decimals:  10,
+			},
+			al: &alias{
+				DbBaser: newdbBaseSqlite(),
+			},
+			wantCol: "text",
+		},
+		{
+			name: "sized binary for MySQL",
+			fi: &models.FieldInfo{
+				FieldType: TypeBinaryField,
+				Size:      16,
+			},
+			al: &alias{
+				DbBaser: newdbBaseMysql(),
+			},
+			wantCol: "binary(16)",
+		},
+		{
+			name: "binary for MySQL",
+			fi: &models.FieldInfo{
+				FieldType: TypeBinaryField,
+			},
+			al: &alias{
+				DbBaser: newdbBaseMysql(),
+			},
+			wantCol: "longblob",
+		},
+		{
+			name: "sized binary for PostgreSQL",
+			fi: &models.FieldInfo{
+				FieldType: TypeBinaryField,
+				Size:      16,
+			},
+			al: &alias{
+				DbBaser: newdbBasePostgres(),
+			},
+			wantCol: "bytea",
+		},
+		{
+			name: "sized binary for sqlite",
+			fi: &models.FieldInfo{
+				FieldType: TypeBinaryField,
+				Size:      16,
+			},
+			al: &alias{
+				DbBaser: newdbBaseSqlite(),
+			},
+			wantCol: "blob",
+		},
+		{
+			name: "sized binary for Oracle",
+			fi: &models.FieldInfo{
+				FieldType: TypeBinaryField,
+				Size:      16,
+			},
+			al: &alias{
+				DbBaser: newdbBaseOracle(),
+			},
+			wantCol: "RAW(16)",
+		},
+		{
+			name: "binary for Oracle",
+			fi: &models.FieldInfo{
+				FieldType: TypeBinaryField,
+			},
+			al: &alias{
+				DbBaser: newdbBaseOracle(),
+			},
+			wantCol: "BLOB",
+		},
+		{
+			name: "set for MySQL",
+			fi: &models.FieldInfo{
+				FieldType: TypeVarCharField,
+				Size:      255,
+				SetValues: []string{"read", "write", "it's"},
+			},
+			al: &alias{
+				DbBaser: newdbBaseMysql(),
+			},
+			wantCol: "SET('read', 'write', 'it''s')",
+		},
+		{
+			name: "set for sqlite",
+			fi: &models.FieldInfo{
+				FieldType: TypeVarCharField,
+				Size:      255,
+				SetValues: []string{"read", "write"},
+			},
+			al: &alias{
+				DbBaser: newdbBaseSqlite(),
+			},
+			wantCol: "varchar(255)",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -37,3 +151,128 @@ func Test_getColumnTyp(t *testing.T) {
 		})
 	}
 }
+
+func Test_getColumnDefault(t *testing.T) {
+	testCases := []struct {
+		name string
+		fi   *models.FieldInfo
+		al   *alias
+
+		wantDefault string
+	}{
+		{
+			name: "not null boolean for MySQL",
+			fi: &models.FieldInfo{
+				FieldType: TypeBooleanField,
+			},
+			al: &alias{
+				DbBaser: newdbBaseMysql(),
+			},
+			wantDefault: " DEFAULT FALSE ",
+		},
+		{
+			name: "not null boolean for sqlite",
+			fi: &models.FieldInfo{
+				FieldType: TypeBooleanField,
+			},
+			al: &alias{
+				DbBaser: newdbBaseSqlite(),
+			},
+			wantDefault: " DEFAULT 0 ",
+		},
+		{
+			name: "boolean with default true for Oracle",
+			fi: func() *models.FieldInfo {
+				fi := &models.FieldInfo{
+					FieldType:  TypeBooleanField,
+					ColDefault: true,
+				}
+				fi.Initial.Set("true")
+				return fi
+			}(),
+			al: &alias{
+				DbBaser: newdbBaseOracle(),
+			},
+			wantDefault: " DEFAULT 1 ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantDefault, getColumnDefault(tc.al, tc.fi))
+		})
+	}
+}
+
+func Test_getColumnAddQuery(t *testing.T) {
+	mi := &models.ModelInfo{Table: "user"}
+	withDefault := func(fi *models.FieldInfo, v string) *models.FieldInfo {
+		fi.ColDefault = true
+		fi.Initial.Set(v)
+		return fi
+	}
+
+	testCases := []struct {
+		name string
+		fi   *models.FieldInfo
+		al   *alias
+
+		wantQuery string
+		wantErr   bool
+	}{
+		{
+			name: "not null integer for MySQL",
+			fi:   &models.FieldInfo{Mi: mi, FieldType: TypeIntegerField, Column: "nums"},
+			al:   &alias{Driver: DRMySQL, DbBaser: newdbBaseMysql()},
+
+			wantQuery: "ALTER TABLE `user` ADD COLUMN `nums` integer NOT NULL  DEFAULT 0 ",
+		},
+		{
+			name: "not null varchar with default for postgres",
+			fi:   withDefault(&models.FieldInfo{Mi: mi, FieldType: TypeVarCharField, Column: "lang", Size: 10}, "en"),
+			al:   &alias{Driver: DRPostgres, DbBaser: newdbBasePostgres()},
+
+			wantQuery: `ALTER TABLE "user" ADD COLUMN "lang" varchar(10) NOT NULL  DEFAULT 'en' `,
+		},
+		{
+			name: "not null text with default for sqlite",
+			fi:   withDefault(&models.FieldInfo{Mi: mi, FieldType: TypeTextField, Column: "bio"}, ""),
+			al:   &alias{Driver: DRSqlite, DbBaser: newdbBaseSqlite()},
+
+			wantQuery: "ALTER TABLE `user` ADD COLUMN `bio` text NOT NULL  DEFAULT '' ",
+		},
+		{
+			name: "not null integer for oracle",
+			fi:   &models.FieldInfo{Mi: mi, FieldType: TypeIntegerField, Column: "nums"},
+			al:   &alias{Driver: DROracle, DbBaser: newdbBaseOracle()},
+
+			wantQuery: "ALTER TABLE `user` ADD `nums` INTEGER DEFAULT 0 NOT NULL",
+		},
+		{
+			name: "null datetime for sqlite",
+			fi:   &models.FieldInfo{Mi: mi, FieldType: TypeDateTimeField, Column: "seen", Null: true},
+			al:   &alias{Driver: DRSqlite, DbBaser: newdbBaseSqlite()},
+
+			wantQuery: "ALTER TABLE `user` ADD COLUMN `seen` datetime ",
+		},
+		{
+			name: "not null datetime without default for sqlite",
+			fi:   &models.FieldInfo{Mi: mi, FieldType: TypeDateTimeField, Column: "seen"},
+			al:   &alias{Driver: DRSqlite, DbBaser: newdbBaseSqlite()},
+
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := getColumnAddQuery(tc.al, tc.fi)
+			if tc.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
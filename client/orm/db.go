@@ -19,10 +19,15 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/beego/beego/v2/client/orm/clauses/order_clause"
+
 	"github.com/beego/beego/v2/client/orm/internal/buffers"
 
 	"github.com/beego/beego/v2/client/orm/internal/utils"
@@ -62,11 +67,102 @@ var operators = map[string]bool{
 	// "week_day":    true,
 	"isnull": true,
 	// "search":      true,
+	"gt_all":  true,
+	"gte_all": true,
+	"lt_all":  true,
+	"lte_all": true,
+	"ne_all":  true,
+	"gt_any":  true,
+	"gte_any": true,
+	"lt_any":  true,
+	"lte_any": true,
+	"ne_any":  true,
+	"similar": true,
+	// the json path given exists in the column
+	"json_exists": true,
+	// the json document in the column equals the marshaled arg, regardless of key order and whitespace
+	"json_eq": true,
+	// the column holds a valid json document, or does not when the arg is false
+	"is_json": true,
+	// the column equals the arg regardless of the accents, cafe matches café
+	"unaccent": true,
+	// time comparison, mostly with a DBTime
+	"before": true,
+	"after":  true,
+	// the bitmask column has all or any bits of the mask set
+	"hasall": true,
+	"hasany": true,
+	// case-insensitive LIKE of any of the patterns
+	"ilike_any": true,
+	// the substring first occurs at the position, with a SubstringPos
+	"contains_at": true,
+	// the column sounds like the value, by the soundex codes of both
+	"soundex": true,
+	// the comma-separated values of the column, such as a mysql SET, include the value
+	"includes": true,
+	// the remainder of the column divided by the divisor, with a Modulo
+	"mod": true,
+	// equal when the leading and trailing spaces of both the column and the value are trimmed
+	"trimeq": true,
+}
+
+// quantified operators compare the column with every row of a SubQuery.
+var quantifiedOperators = map[string]bool{
+	"gt_all":  true,
+	"gte_all": true,
+	"lt_all":  true,
+	"lte_all": true,
+	"ne_all":  true,
+	"gt_any":  true,
+	"gte_any": true,
+	"lt_any":  true,
+	"lte_any": true,
+	"ne_any":  true,
+}
+
+// transforms are applied to the column before the operator, such as name__len__gt.
+var transforms = map[string]bool{
+	"len":   true,
+	"size":  true,
+	"lower": true,
+	"upper": true,
+	"trim":  true,
+	"ltrim": true,
+	"rtrim": true,
+	"abs":   true,
+	// the parts of the dates, the week is of ISO-8601
+	"quarter": true,
+	"isoweek": true,
+	// the whole years from the date to the current date
+	"age": true,
+}
+
+// the sql functions allowed by Fn and as transforms, the same in all the drivers.
+var sqlFunctions = map[string]bool{
+	"LOWER": true,
+	"UPPER": true,
+	"TRIM":  true,
+	"LTRIM": true,
+	"RTRIM": true,
+	"ABS":   true,
+}
+
+// the types allowed by FilterCast, each driver maps them to its own type names.
+var castTypes = map[string]bool{
+	"int":      true,
+	"bigint":   true,
+	"decimal":  true,
+	"float":    true,
+	"string":   true,
+	"date":     true,
+	"datetime": true,
 }
 
 // an instance of dbBaser interface/
 type dbBase struct {
 	ins dbBaser
+	// the schema qualifying the tables, set by Ormer.UsingSchema
+	schema string
 }
 
 // check dbBase implements dbBaser interface.
@@ -157,8 +253,25 @@ func (d *dbBase) collectFieldValue(mi *models.ModelInfo, fi *models.FieldInfo, i
 				} else {
 					value = field.String()
 				}
+				if fi.EmptyAsNull && value == "" {
+					value = nil
+				}
+			case TypeBinaryField:
+				value = nil
+				if !field.IsNil() {
+					value = field.Bytes()
+				}
 			case TypeFloatField, TypeDecimalField:
-				if nf, ok := field.Interface().(sql.NullFloat64); ok {
+				if fi.IsBigRat {
+					r, _ := field.Interface().(*big.Rat)
+					if field.Kind() != reflect.Ptr {
+						r = field.Addr().Interface().(*big.Rat)
+					}
+					value = nil
+					if r != nil {
+						value = r.FloatString(fi.Decimals)
+					}
+				} else if nf, ok := field.Interface().(sql.NullFloat64); ok {
 					value = nil
 					if nf.Valid {
 						value = nf.Float64
@@ -179,6 +292,18 @@ func (d *dbBase) collectFieldValue(mi *models.ModelInfo, fi *models.FieldInfo, i
 				}
 			case TypeTimeField, TypeDateField, TypeDateTimeField:
 				value = field.Interface()
+				if nt, ok := value.(sql.NullTime); ok {
+					value = nil
+					if nt.Valid {
+						value = nt.Time
+					}
+				}
+				if pt, ok := value.(*time.Time); ok && fi.TimeFormat != "" {
+					value = nil
+					if pt != nil {
+						value = *pt
+					}
+				}
 				if t, ok := value.(time.Time); ok {
 					d.ins.TimeToDB(&t, tz)
 					if t.IsZero() {
@@ -190,7 +315,12 @@ func (d *dbBase) collectFieldValue(mi *models.ModelInfo, fi *models.FieldInfo, i
 			default:
 				switch {
 				case fi.FieldType&IsPositiveIntegerField > 0:
-					if field.Kind() == reflect.Ptr {
+					if nb, ok := field.Interface().(sql.NullByte); ok {
+						value = nil
+						if nb.Valid {
+							value = uint64(nb.Byte)
+						}
+					} else if field.Kind() == reflect.Ptr {
 						if field.IsNil() {
 							value = nil
 						} else {
@@ -205,6 +335,16 @@ func (d *dbBase) collectFieldValue(mi *models.ModelInfo, fi *models.FieldInfo, i
 						if ni.Valid {
 							value = ni.Int64
 						}
+					} else if ni, ok := field.Interface().(sql.NullInt32); ok {
+						value = nil
+						if ni.Valid {
+							value = int64(ni.Int32)
+						}
+					} else if ni, ok := field.Interface().(sql.NullInt16); ok {
+						value = nil
+						if ni.Valid {
+							value = int64(ni.Int16)
+						}
 					} else if field.Kind() == reflect.Ptr {
 						if field.IsNil() {
 							value = nil
@@ -247,6 +387,8 @@ func (d *dbBase) collectFieldValue(mi *models.ModelInfo, fi *models.FieldInfo, i
 				} else if field.Kind() == reflect.Ptr {
 					v := tnow.In(DefaultTimeLoc)
 					field.Set(reflect.ValueOf(&v))
+				} else if _, ok := field.Interface().(sql.NullTime); ok {
+					field.Set(reflect.ValueOf(sql.NullTime{Time: tnow.In(DefaultTimeLoc), Valid: true}))
 				} else {
 					field.Set(reflect.ValueOf(tnow.In(DefaultTimeLoc)))
 				}
@@ -261,6 +403,9 @@ func (d *dbBase) collectFieldValue(mi *models.ModelInfo, fi *models.FieldInfo, i
 			}
 		}
 	}
+	if t, ok := value.(time.Time); ok && fi.TimeFormat != "" {
+		value = t.Format(fi.TimeFormat)
+	}
 	return value, nil
 }
 
@@ -280,7 +425,7 @@ func (d *dbBase) PrepareInsert(ctx context.Context, q dbQuerier, mi *models.Mode
 	sep := fmt.Sprintf("%s, %s", Q, Q)
 	columns := strings.Join(dbcols, sep)
 
-	query := fmt.Sprintf("INSERT INTO %s%s%s (%s%s%s) VALUES (%s)", Q, mi.Table, Q, Q, columns, Q, qmarks)
+	query := fmt.Sprintf("INSERT INTO %s (%s%s%s) VALUES (%s)", d.TableSQL(mi.Table), Q, columns, Q, qmarks)
 
 	d.ins.ReplaceMarks(&query)
 
@@ -290,6 +435,36 @@ func (d *dbBase) PrepareInsert(ctx context.Context, q dbQuerier, mi *models.Mode
 	return stmt, query, err
 }
 
+// PrepareUpsert create the upsert statement of mi conflicting on the columns conflicts,
+// ErrNotImplement if the driver does not support it.
+func (d *dbBase) PrepareUpsert(ctx context.Context, q dbQuerier, mi *models.ModelInfo, conflicts []string) (stmtQuerier, string, error) {
+	// the columns of InsertStmt, the auto fields are not written
+	names := make([]string, 0, len(mi.Fields.DBcols))
+	for _, fi := range mi.Fields.FieldsDB {
+		if !fi.Auto {
+			names = append(names, fi.Column)
+		}
+	}
+
+	query := d.ins.UpsertSQL(mi, names, conflicts)
+	if query == "" {
+		return nil, "", ErrNotImplement
+	}
+	d.ins.ReplaceMarks(&query)
+
+	stmt, err := q.PrepareContext(ctx, query)
+	return stmt, query, err
+}
+
+// UpsertStmtExec upsert struct with the statement of PrepareUpsert and given struct reflect value.
+func (d *dbBase) UpsertStmtExec(ctx context.Context, stmt stmtQuerier, mi *models.ModelInfo, ind reflect.Value, tz *time.Location) (sql.Result, error) {
+	values, _, err := d.collectValues(mi, ind, mi.Fields.DBcols, true, true, nil, tz)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.ExecContext(ctx, values...)
+}
+
 // InsertStmt insert struct with prepared statement and given struct reflect value.
 func (d *dbBase) InsertStmt(ctx context.Context, stmt stmtQuerier, mi *models.ModelInfo, ind reflect.Value, tz *time.Location) (int64, error) {
 	values, _, err := d.collectValues(mi, ind, mi.Fields.DBcols, true, true, nil, tz)
@@ -336,8 +511,12 @@ func (d *dbBase) Read(ctx context.Context, q dbQuerier, mi *models.ModelInfo, in
 	Q := d.ins.TableQuote()
 
 	sep := fmt.Sprintf("%s, %s", Q, Q)
-	sels := strings.Join(mi.Fields.DBcols, sep)
-	colsNum := len(mi.Fields.DBcols)
+	sels := Q + strings.Join(mi.Fields.DBcols, sep) + Q
+	computed := d.computedCols(mi, nil, "")
+	for _, expr := range computed {
+		sels += ", " + expr
+	}
+	colsNum := len(mi.Fields.DBcols) + len(computed)
 
 	sep = fmt.Sprintf("%s = ? AND %s", Q, Q)
 	wheres := strings.Join(whereCols, sep)
@@ -347,7 +526,7 @@ func (d *dbBase) Read(ctx context.Context, q dbQuerier, mi *models.ModelInfo, in
 		forUpdate = "FOR UPDATE"
 	}
 
-	query := fmt.Sprintf("SELECT %s%s%s FROM %s%s%s WHERE %s%s%s = ? %s", Q, sels, Q, Q, mi.Table, Q, Q, wheres, Q, forUpdate)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s%s%s = ? %s", sels, d.TableSQL(mi.Table), Q, wheres, Q, forUpdate)
 
 	refs := make([]interface{}, colsNum)
 	for i := range refs {
@@ -366,7 +545,8 @@ func (d *dbBase) Read(ctx context.Context, q dbQuerier, mi *models.ModelInfo, in
 	}
 	elm := reflect.New(mi.AddrField.Elem().Type())
 	mind := reflect.Indirect(elm)
-	d.setColsValues(mi, &mind, mi.Fields.DBcols, refs, tz)
+	d.setColsValues(mi, &mind, mi.Fields.DBcols, refs, tz, nil)
+	setComputedValues(mi, mind, refs[len(mi.Fields.DBcols):])
 	ind.Set(mind)
 	return nil
 }
@@ -390,61 +570,163 @@ func (d *dbBase) Insert(ctx context.Context, q dbQuerier, mi *models.ModelInfo,
 	return id, err
 }
 
-// InsertMulti multi-insert sql with given slice struct reflect.Value.
-func (d *dbBase) InsertMulti(ctx context.Context, q dbQuerier, mi *models.ModelInfo, sind reflect.Value, bulk int, tz *time.Location) (int64, error) {
-	var (
-		cnt    int64
-		nums   int
-		values []interface{}
-		names  []string
-	)
+// the rows of a multi-insert binding the same columns.
+type multiInsertRows struct {
+	names []string
+	rows  [][]interface{}
+}
 
-	length, autoFields := sind.Len(), make([]string, 0, 1)
+// Get the values of every model in sind for a multi-insert.
+// the consecutive rows with the same columns are grouped, an auto field is only in the rows setting it,
+// so the database generates it in the others instead of getting NULL.
+func (d *dbBase) collectMultiValues(mi *models.ModelInfo, sind reflect.Value, tz *time.Location) (groups []multiInsertRows, autoFields []string, err error) {
+	length := sind.Len()
+	for i := 0; i < length; i++ {
+		ind := reflect.Indirect(sind.Index(i))
+
+		var names []string
+		values, autos, err := d.collectValues(mi, ind, mi.Fields.DBcols, false, true, &names, tz)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(names) == 0 {
+			return nil, nil, ErrArgs
+		}
+		if n := len(groups); n > 0 && strings.Join(groups[n-1].names, ",") == strings.Join(names, ",") {
+			groups[n-1].rows = append(groups[n-1].rows, values)
+		} else {
+			groups = append(groups, multiInsertRows{names: names, rows: [][]interface{}{values}})
+		}
+		// a model has one auto field at most
+		if len(autos) > 0 {
+			autoFields = autos
+		}
+	}
+	return groups, autoFields, nil
+}
 
-	for i := 1; i <= length; i++ {
+// InsertMulti multi-insert sql with given slice struct reflect.Value.
+func (d *dbBase) InsertMulti(ctx context.Context, q dbQuerier, mi *models.ModelInfo, sind reflect.Value, bulk int, tz *time.Location) (int64, error) {
+	var cnt int64
 
-		ind := reflect.Indirect(sind.Index(i - 1))
+	groups, autoFields, err := d.collectMultiValues(mi, sind, tz)
+	if err != nil {
+		return cnt, err
+	}
 
-		if i == 1 {
-			var (
-				vus []interface{}
-				err error
-			)
-			vus, autoFields, err = d.collectValues(mi, ind, mi.Fields.DBcols, false, true, &names, tz)
-			if err != nil {
-				return cnt, err
+	for _, group := range groups {
+		for start := 0; start < len(group.rows); start += bulk {
+			end := start + bulk
+			if end > len(group.rows) {
+				end = len(group.rows)
 			}
-			values = make([]interface{}, bulk*len(vus))
-			nums += copy(values, vus)
-		} else {
-			vus, _, err := d.collectValues(mi, ind, mi.Fields.DBcols, false, true, nil, tz)
+			values := make([]interface{}, 0, (end-start)*len(group.names))
+			for _, row := range group.rows[start:end] {
+				values = append(values, row...)
+			}
+
+			num, err := d.InsertValue(ctx, q, mi, true, group.names, values)
 			if err != nil {
 				return cnt, err
 			}
+			cnt += num
+		}
+	}
 
-			if len(vus) != len(names) {
-				return cnt, ErrArgs
-			}
+	if len(autoFields) > 0 {
+		err = d.ins.setval(ctx, q, mi, autoFields)
+	}
 
-			nums += copy(values[nums:], vus)
-		}
+	return cnt, err
+}
+
+// InsertMultiReturning multi-insert sql with given slice struct reflect.Value,
+// and scan the inserted rows returned by the database to container.
+// the rows of every bulk are sorted by the auto pk, which is generated in the order of insertion,
+// as the order of RETURNING is not guaranteed.
+func (d *dbBase) InsertMultiReturning(ctx context.Context, q dbQuerier, mi *models.ModelInfo, sind reflect.Value, bulk int, container interface{}, tz *time.Location) error {
+	if !d.ins.SupportReturning() {
+		return ErrNotImplement
+	}
+
+	groups, autoFields, err := d.collectMultiValues(mi, sind, tz)
+	if err != nil {
+		return err
+	}
+
+	Q := d.ins.TableQuote()
+	returning := fmt.Sprintf(" RETURNING %s%s%s", Q, strings.Join(mi.Fields.DBcols, Q+", "+Q), Q)
+
+	slice := reflect.Indirect(reflect.ValueOf(container))
+	isPtr := slice.Type().Elem().Kind() == reflect.Ptr
+
+	for _, group := range groups {
+		for start := 0; start < len(group.rows); start += bulk {
+			end := start + bulk
+			if end > len(group.rows) {
+				end = len(group.rows)
+			}
+			values := make([]interface{}, 0, (end-start)*len(group.names))
+			for _, row := range group.rows[start:end] {
+				values = append(values, row...)
+			}
 
-		if i > 1 && i%bulk == 0 || length == i {
-			num, err := d.InsertValue(ctx, q, mi, true, names, values[:nums])
+			query := d.InsertValueSQL(group.names, values, true, mi) + returning
+			inds, err := d.scanReturning(ctx, q, mi, query, values, tz)
 			if err != nil {
-				return cnt, err
+				return err
+			}
+			if mi.Fields.Pk.Auto {
+				pk := mi.Fields.Pk.FieldIndex
+				sort.SliceStable(inds, func(i, j int) bool {
+					a, b := inds[i].Elem().FieldByIndex(pk), inds[j].Elem().FieldByIndex(pk)
+					if a.CanInt() {
+						return a.Int() < b.Int()
+					}
+					return a.Uint() < b.Uint()
+				})
+			}
+			for _, ind := range inds {
+				if isPtr {
+					slice.Set(reflect.Append(slice, ind))
+				} else {
+					slice.Set(reflect.Append(slice, ind.Elem()))
+				}
 			}
-			cnt += num
-			nums = 0
 		}
 	}
 
-	var err error
 	if len(autoFields) > 0 {
 		err = d.ins.setval(ctx, q, mi, autoFields)
 	}
+	return err
+}
 
-	return cnt, err
+// query the insert sql with RETURNING, return the scanned models.
+func (d *dbBase) scanReturning(ctx context.Context, q dbQuerier, mi *models.ModelInfo, query string, values []interface{}, tz *time.Location) ([]reflect.Value, error) {
+	rs, err := q.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	refs := make([]interface{}, len(mi.Fields.DBcols))
+	for i := range refs {
+		var ref interface{}
+		refs[i] = &ref
+	}
+
+	var inds []reflect.Value
+	for rs.Next() {
+		if err := rs.Scan(refs...); err != nil {
+			return nil, err
+		}
+		elm := reflect.New(mi.AddrField.Elem().Type())
+		mind := reflect.Indirect(elm)
+		d.setColsValues(mi, &mind, mi.Fields.DBcols, refs, tz, nil)
+		inds = append(inds, elm)
+	}
+	return inds, rs.Err()
 }
 
 // InsertValue execute insert sql with given struct and given values.
@@ -482,9 +764,7 @@ func (d *dbBase) InsertValueSQL(names []string, values []interface{}, isMulti bo
 	Q := d.ins.TableQuote()
 
 	_, _ = buf.WriteString("INSERT INTO ")
-	_, _ = buf.WriteString(Q)
-	_, _ = buf.WriteString(mi.Table)
-	_, _ = buf.WriteString(Q)
+	_, _ = buf.WriteString(d.TableSQL(mi.Table))
 
 	_, _ = buf.WriteString(" (")
 	for i, name := range names {
@@ -593,9 +873,7 @@ func (d *dbBase) InsertOrUpdateSQL(names []string, values *[]interface{}, mi *mo
 	defer buffers.Put(buf)
 
 	_, _ = buf.WriteString("INSERT INTO ")
-	_, _ = buf.WriteString(quote)
-	_, _ = buf.WriteString(mi.Table)
-	_, _ = buf.WriteString(quote)
+	_, _ = buf.WriteString(d.TableSQL(mi.Table))
 	_, _ = buf.WriteString(" (")
 
 	for i, name := range names {
@@ -651,7 +929,11 @@ func (d *dbBase) InsertOrUpdateSQL(names []string, values *[]interface{}, mi *mo
 					_, _ = buf.WriteString("=(select ")
 					_, _ = buf.WriteString(valueStr)
 					_, _ = buf.WriteString(" from ")
-					_, _ = buf.WriteString(mi.Table)
+					if d.schema != "" {
+						_, _ = buf.WriteString(d.TableSQL(mi.Table))
+					} else {
+						_, _ = buf.WriteString(mi.Table)
+					}
 					_, _ = buf.WriteString(" where ")
 					_, _ = buf.WriteString(args0)
 					_, _ = buf.WriteString(" = ? )")
@@ -739,9 +1021,7 @@ func (d *dbBase) UpdateSQL(setNames []string, pkName string, mi *models.ModelInf
 	Q := d.ins.TableQuote()
 
 	_, _ = buf.WriteString("UPDATE ")
-	_, _ = buf.WriteString(Q)
-	_, _ = buf.WriteString(mi.Table)
-	_, _ = buf.WriteString(Q)
+	_, _ = buf.WriteString(d.TableSQL(mi.Table))
 	_, _ = buf.WriteString(" SET ")
 
 	for i, name := range setNames {
@@ -815,9 +1095,7 @@ func (d *dbBase) DeleteSQL(whereCols []string, mi *models.ModelInfo) string {
 	Q := d.ins.TableQuote()
 
 	_, _ = buf.WriteString("DELETE FROM ")
-	_, _ = buf.WriteString(Q)
-	_, _ = buf.WriteString(mi.Table)
-	_, _ = buf.WriteString(Q)
+	_, _ = buf.WriteString(d.TableSQL(mi.Table))
 	_, _ = buf.WriteString(" WHERE ")
 
 	for i, col := range whereCols {
@@ -884,9 +1162,7 @@ func (d *dbBase) UpdateBatchSQL(mi *models.ModelInfo, cols []string, values []in
 	defer buffers.Put(buf)
 
 	_, _ = buf.WriteString("UPDATE ")
-	_, _ = buf.WriteString(quote)
-	_, _ = buf.WriteString(mi.Table)
-	_, _ = buf.WriteString(quote)
+	_, _ = buf.WriteString(d.TableSQL(mi.Table))
 
 	if d.ins.SupportUpdateJoin() {
 		_, _ = buf.WriteString(" T0 ")
@@ -912,9 +1188,7 @@ func (d *dbBase) UpdateBatchSQL(mi *models.ModelInfo, cols []string, values []in
 		_, _ = buf.WriteString(mi.Fields.Pk.Column)
 		_, _ = buf.WriteString(quote)
 		_, _ = buf.WriteString(" FROM ")
-		_, _ = buf.WriteString(quote)
-		_, _ = buf.WriteString(mi.Table)
-		_, _ = buf.WriteString(quote)
+		_, _ = buf.WriteString(d.TableSQL(mi.Table))
 		_, _ = buf.WriteString(" T0 ")
 		_, _ = buf.WriteString(specifyIndexes)
 		_, _ = buf.WriteString(join)
@@ -1031,7 +1305,7 @@ func (d *dbBase) DeleteBatch(ctx context.Context, q dbQuerier, qs *querySet, mi
 	join := tables.getJoinSQL()
 
 	cols := fmt.Sprintf("T0.%s%s%s", Q, mi.Fields.Pk.Column, Q)
-	query := fmt.Sprintf("SELECT %s FROM %s%s%s T0 %s%s%s", cols, Q, mi.Table, Q, specifyIndexes, join, where)
+	query := fmt.Sprintf("SELECT %s FROM %s T0 %s%s%s", cols, d.TableSQL(mi.Table), specifyIndexes, join, where)
 
 	d.ins.ReplaceMarks(&query)
 
@@ -1071,7 +1345,7 @@ func (d *dbBase) DeleteBatch(ctx context.Context, q dbQuerier, qs *querySet, mi
 		marks[i] = "?"
 	}
 	sqlIn := fmt.Sprintf("IN (%s)", strings.Join(marks, ", "))
-	query = fmt.Sprintf("DELETE FROM %s%s%s WHERE %s%s%s %s", Q, mi.Table, Q, Q, mi.Fields.Pk.Column, Q, sqlIn)
+	query = fmt.Sprintf("DELETE FROM %s WHERE %s%s%s %s", d.TableSQL(mi.Table), Q, mi.Fields.Pk.Column, Q, sqlIn)
 
 	d.ins.ReplaceMarks(&query)
 	res, err := q.ExecContext(ctx, query, args...)
@@ -1091,6 +1365,77 @@ func (d *dbBase) DeleteBatch(ctx context.Context, q dbQuerier, qs *querySet, mi
 	return 0, err
 }
 
+// DeleteBatchReturning delete the rows matching cond, up to the limit of qs in its order,
+// and scan the deleted rows returned by RETURNING to container.
+// the rows are selected in a sub query locked by SkipLockedSQL, so that concurrent calls delete different rows.
+func (d *dbBase) DeleteBatchReturning(ctx context.Context, q dbQuerier, qs *querySet, mi *models.ModelInfo, cond *Condition, container interface{}, tz *time.Location) (int64, error) {
+	if !d.ins.SupportReturning() {
+		return 0, ErrNotImplement
+	}
+
+	query, args := d.deleteReturningSQL(qs, mi, cond, tz)
+	inds, err := d.scanReturning(ctx, q, mi, query, args, tz)
+	if err != nil {
+		return 0, err
+	}
+
+	slice := reflect.Indirect(reflect.ValueOf(container))
+	isPtr := slice.Type().Elem().Kind() == reflect.Ptr
+	pks := make([]interface{}, 0, len(inds))
+	for _, ind := range inds {
+		pks = append(pks, ind.Elem().FieldByIndex(mi.Fields.Pk.FieldIndex).Interface())
+		if isPtr {
+			slice.Set(reflect.Append(slice, ind))
+		} else {
+			slice.Set(reflect.Append(slice, ind.Elem()))
+		}
+	}
+
+	num := int64(len(inds))
+	if num > 0 {
+		if err := d.deleteRels(ctx, q, mi, pks, tz); err != nil {
+			return num, err
+		}
+	}
+	return num, nil
+}
+
+// generate DELETE of the pks selected by a sub query with the order and limit of qs, RETURNING the columns.
+func (d *dbBase) deleteReturningSQL(qs *querySet, mi *models.ModelInfo, cond *Condition, tz *time.Location) (string, []interface{}) {
+	if cond == nil || cond.IsEmpty() {
+		panic(fmt.Errorf("delete operation cannot execute without condition"))
+	}
+
+	tables := newDbTables(mi, d.ins)
+	tables.skipEnd = true
+	tables.parseRelated(qs.related, qs.relDepth)
+
+	Q := d.ins.TableQuote()
+
+	where, args := tables.getCondSQL(cond, false, tz)
+	orderBy, orderArgs := tables.getOrderSQL(qs.orders, tz)
+	args = append(args, orderArgs...)
+	join := tables.getJoinSQL()
+	specifyIndexes := tables.getIndexSql(mi.Table, qs.useIndex, qs.indexes)
+
+	limit := ""
+	if qs.limit != 0 || qs.offset > 0 {
+		limit = tables.getLimitSQL(mi, qs.offset, qs.limit)
+	}
+	lock := d.ins.SkipLockedSQL("T0")
+	if lock != "" {
+		lock = " " + lock
+	}
+
+	pk := fmt.Sprintf("%s%s%s", Q, mi.Fields.Pk.Column, Q)
+	subQuery := fmt.Sprintf("SELECT T0.%s FROM %s T0 %s%s%s%s%s%s", pk, d.TableSQL(mi.Table), specifyIndexes, join, where, orderBy, limit, lock)
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s) RETURNING %s%s%s",
+		d.TableSQL(mi.Table), pk, subQuery, Q, strings.Join(mi.Fields.DBcols, Q+", "+Q), Q)
+
+	d.ins.ReplaceMarks(&query)
+	return query, args
+}
+
 // ReadBatch read related records.
 func (d *dbBase) ReadBatch(ctx context.Context, q dbQuerier, qs querySet, mi *models.ModelInfo, cond *Condition, container interface{}, tz *time.Location, cols []string) (int64, error) {
 	val := reflect.ValueOf(container)
@@ -1125,41 +1470,19 @@ func (d *dbBase) ReadBatch(ctx context.Context, q dbQuerier, qs querySet, mi *mo
 		RegisterModel(container)
 	}
 
-	var tCols []string
-	if len(cols) > 0 {
-		hasRel := len(qs.related) > 0 || qs.relDepth > 0
-		tCols = make([]string, 0, len(cols))
-		var maps map[string]bool
-		if hasRel {
-			maps = make(map[string]bool)
-		}
-		for _, col := range cols {
-			if fi, ok := mi.Fields.GetByAny(col); ok {
-				tCols = append(tCols, fi.Column)
-				if hasRel {
-					maps[fi.Column] = true
-				}
-			} else {
-				return 0, fmt.Errorf("wrong field/column name `%s`", col)
-			}
-		}
-		if hasRel {
-			for _, fi := range mi.Fields.FieldsDB {
-				if fi.FieldType&IsRelField > 0 {
-					if !maps[fi.Column] {
-						tCols = append(tCols, fi.Column)
-					}
-				}
-			}
-		}
-	} else {
-		tCols = mi.Fields.DBcols
+	tCols, err := d.readBatchCols(qs, mi, cols)
+	if err != nil {
+		return 0, err
 	}
 
 	tables := newDbTables(mi, d.ins)
 	tables.parseRelated(qs.related, qs.relDepth)
 
-	colsNum := len(tCols)
+	var computed []string
+	if !unregister {
+		computed = d.computedCols(mi, cols, "T0.")
+	}
+	colsNum := len(tCols) + len(computed)
 
 	for _, tbl := range tables.tables {
 		if tbl.sel {
@@ -1167,7 +1490,15 @@ func (d *dbBase) ReadBatch(ctx context.Context, q dbQuerier, qs querySet, mi *mo
 		}
 	}
 
-	query, args := d.readBatchSQL(tables, tCols, cond, qs, mi, tz)
+	query, args := d.readBatchSQL(tables, tCols, cond, qs, mi, tz, computed...)
+
+	var warns *scanWarnings
+	if qs.scanWarnings != nil {
+		warns = new(scanWarnings)
+		defer func() {
+			*qs.scanWarnings = warns.list
+		}()
+	}
 
 	rs, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -1188,63 +1519,29 @@ func (d *dbBase) ReadBatch(ctx context.Context, q dbQuerier, qs querySet, mi *mo
 		var ref interface{}
 		refs[i] = &ref
 	}
+	rowRefs, computedRefs := splitComputedRefs(refs, len(tCols), len(computed))
+	size := resultBytes{max: qs.maxResultBytes}
 	var cnt int64
 	for rs.Next() {
 		if one && cnt == 0 || !one {
 			if err := rs.Scan(refs...); err != nil {
 				return 0, err
 			}
+			if err := size.add(refs); err != nil {
+				return 0, err
+			}
 
-			elm := reflect.New(mi.AddrField.Elem().Type())
-			mind := reflect.Indirect(elm)
-
-			cacheV := make(map[string]*reflect.Value)
-			cacheM := make(map[string]*models.ModelInfo)
-			trefs := refs
-
-			d.setColsValues(mi, &mind, tCols, refs[:len(tCols)], tz)
-			trefs = refs[len(tCols):]
-
-			for _, tbl := range tables.tables {
-				// loop selected tables
-				if tbl.sel {
-					last := mind
-					names := ""
-					mmi := mi
-					// loop cascade models
-					for _, name := range tbl.names {
-						names += name
-						if val, ok := cacheV[names]; ok {
-							last = *val
-							mmi = cacheM[names]
-						} else {
-							fi := mmi.Fields.GetByName(name)
-							lastm := mmi
-							mmi = fi.RelModelInfo
-							field := last
-							if last.Kind() != reflect.Invalid {
-								field = reflect.Indirect(last.FieldByIndex(fi.FieldIndex))
-								if field.IsValid() {
-									d.setColsValues(mmi, &field, mmi.Fields.DBcols, trefs[:len(mmi.Fields.DBcols)], tz)
-									for _, fi := range mmi.Fields.FieldsReverse {
-										if fi.InModel && fi.ReverseFieldInfo.Mi == lastm {
-											if fi.ReverseFieldInfo != nil {
-												f := field.FieldByIndex(fi.FieldIndex)
-												if f.Kind() == reflect.Ptr {
-													f.Set(last.Addr())
-												}
-											}
-										}
-									}
-									last = field
-								}
-							}
-							cacheV[names] = &field
-							cacheM[names] = mmi
-						}
-					}
-					trefs = trefs[len(mmi.Fields.DBcols):]
+			if warns != nil {
+				warns.row = int(cnt)
+			}
+			mind := d.setRowValues(mi, tables, tCols, rowRefs, tz, warns)
+			setComputedValues(mi, mind, computedRefs)
+			if qs.mapper != nil {
+				res, err := mapElem(qs.mapper, mind.Addr())
+				if err != nil {
+					return 0, err
 				}
+				mind = res.Elem()
 			}
 
 			if one {
@@ -1289,41 +1586,500 @@ func (d *dbBase) ReadBatch(ctx context.Context, q dbQuerier, qs querySet, mi *mo
 	return cnt, nil
 }
 
-func (d *dbBase) readBatchSQL(tables *dbTables, tCols []string, cond *Condition, qs querySet, mi *models.ModelInfo, tz *time.Location) (string, []interface{}) {
-	cols := d.preProcCols(tCols) // pre process columns
-
-	buf := buffers.Get()
-	defer buffers.Put(buf)
+// get the columns of cols to read, with the rel columns needed by the related models.
+func (d *dbBase) readBatchCols(qs querySet, mi *models.ModelInfo, cols []string) ([]string, error) {
+	if len(cols) == 0 {
+		return mi.Fields.DBcols, nil
+	}
+	hasRel := len(qs.related) > 0 || qs.relDepth > 0
+	tCols := make([]string, 0, len(cols))
+	var maps map[string]bool
+	if hasRel {
+		maps = make(map[string]bool)
+	}
+	for _, col := range cols {
+		if fi, ok := mi.Fields.GetByAny(col); ok {
+			tCols = append(tCols, fi.Column)
+			if hasRel {
+				maps[fi.Column] = true
+			}
+		} else {
+			return nil, fmt.Errorf("wrong field/column name `%s`", col)
+		}
+	}
+	if hasRel {
+		for _, fi := range mi.Fields.FieldsDB {
+			if fi.FieldType&IsRelField > 0 {
+				if !maps[fi.Column] {
+					tCols = append(tCols, fi.Column)
+				}
+			}
+		}
+	}
+	return tCols, nil
+}
 
-	args := d.readSQL(buf, tables, cols, cond, qs, mi, tz)
+// the sql of the computed fields of mi, read only with all the columns when cols is empty.
+// the columns of mi in them are qualified by prefix.
+func (d *dbBase) computedCols(mi *models.ModelInfo, cols []string, prefix string) []string {
+	if len(cols) > 0 || len(mi.ComputedFields) == 0 {
+		return nil
+	}
+	exprs := make([]string, len(mi.ComputedFields))
+	for i, cf := range mi.ComputedFields {
+		exprs[i] = d.computedSQL(mi, cf.Expr, prefix)
+	}
+	return exprs
+}
 
-	query := buf.String()
+// rewrite the expr of a computed field to the sql of the dialect, quoting the columns of mi with prefix,
+// and concatenating the parts of the || at its top level by ConcatSQL.
+func (d *dbBase) computedSQL(mi *models.ModelInfo, expr string, prefix string) string {
+	Q := d.ins.TableQuote()
+	var parts []string
+	var buf strings.Builder
+	depth := 0
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// quoted literals and identifiers are kept as is
+			j := i + 1
+			for j < len(expr) && expr[j] != c {
+				j++
+			}
+			if j < len(expr) {
+				j++
+			}
+			buf.WriteString(expr[i:j])
+			i = j
+		case c == '|' && depth == 0 && i+1 < len(expr) && expr[i+1] == '|':
+			parts = append(parts, strings.TrimSpace(buf.String()))
+			buf.Reset()
+			i += 2
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(expr) && (expr[j] == '_' || expr[j] >= 'a' && expr[j] <= 'z' || expr[j] >= 'A' && expr[j] <= 'Z' || expr[j] >= '0' && expr[j] <= '9') {
+				j++
+			}
+			word := expr[i:j]
+			qualified := i > 0 && expr[i-1] == '.'
+			if fi, ok := mi.Fields.Columns[word]; ok && fi.DBcol && !qualified && (c < '0' || c > '9') {
+				word = prefix + Q + word + Q
+			}
+			buf.WriteString(word)
+			i = j
+		default:
+			if c == '(' {
+				depth++
+			} else if c == ')' {
+				depth--
+			}
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	if len(parts) == 0 {
+		return buf.String()
+	}
+	parts = append(parts, strings.TrimSpace(buf.String()))
+	return d.ins.ConcatSQL(parts)
+}
 
-	d.ins.ReplaceMarks(&query)
+// ReadInto read records into the elements of the slice of the models of mi,
+// reusing them and growing the slice only when there are more rows than its length.
+// the slice is cut to the number of rows.
+func (d *dbBase) ReadInto(ctx context.Context, q dbQuerier, qs querySet, mi *models.ModelInfo, cond *Condition, container interface{}, tz *time.Location, cols []string) (int64, error) {
+	val := reflect.ValueOf(container)
+	ind := reflect.Indirect(val)
+	if val.Kind() != reflect.Ptr || ind.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("<QuerySeter.AllInto> container must be a ptr slice, not `%T`", container)
+	}
+	typ := ind.Type().Elem()
+	isPtr := typ.Kind() == reflect.Ptr
+	if isPtr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || models.GetFullName(typ) != mi.FullName {
+		return 0, fmt.Errorf("<QuerySeter.AllInto> container must be a slice of `%s`, not `%T`", mi.FullName, container)
+	}
 
-	return query, args
-}
+	tCols, err := d.readBatchCols(qs, mi, cols)
+	if err != nil {
+		return 0, err
+	}
 
-func (d *dbBase) preProcCols(cols []string) []string {
-	res := make([]string, len(cols))
+	tables := newDbTables(mi, d.ins)
+	tables.parseRelated(qs.related, qs.relDepth)
 
-	quote := d.ins.TableQuote()
-	for i, col := range cols {
-		res[i] = fmt.Sprintf("T0.%s%s%s", quote, col, quote)
+	computed := d.computedCols(mi, cols, "T0.")
+	colsNum := len(tCols) + len(computed)
+	related := false
+	for _, tbl := range tables.tables {
+		if tbl.sel {
+			colsNum += len(tbl.mi.Fields.DBcols)
+			related = true
+		}
 	}
 
-	return res
-}
+	query, args := d.readBatchSQL(tables, tCols, cond, qs, mi, tz, computed...)
 
-// readSQL generate a select sql string and return args
+	var warns *scanWarnings
+	if qs.scanWarnings != nil {
+		warns = new(scanWarnings)
+		defer func() {
+			*qs.scanWarnings = warns.list
+		}()
+	}
+
+	rs, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rs.Close()
+
+	refs := make([]interface{}, colsNum)
+	for i := range refs {
+		var ref interface{}
+		refs[i] = &ref
+	}
+
+	rowRefs, computedRefs := splitComputedRefs(refs, len(tCols), len(computed))
+
+	slice := ind
+	zero := reflect.Zero(typ)
+	size := resultBytes{max: qs.maxResultBytes}
+	var cnt int
+	for rs.Next() {
+		if err := rs.Scan(refs...); err != nil {
+			return 0, err
+		}
+		if err := size.add(refs); err != nil {
+			return 0, err
+		}
+		if cnt == slice.Len() {
+			if isPtr {
+				slice = reflect.Append(slice, reflect.New(typ))
+			} else {
+				slice = reflect.Append(slice, zero)
+			}
+		}
+		elm := slice.Index(cnt)
+		if isPtr {
+			if elm.IsNil() {
+				elm.Set(reflect.New(typ))
+			}
+			elm = elm.Elem()
+		}
+		if warns != nil {
+			warns.row = cnt
+		}
+		if related {
+			elm.Set(d.setRowValues(mi, tables, tCols, rowRefs, tz, warns))
+		} else {
+			elm.Set(zero)
+			d.setColsValues(mi, &elm, tCols, rowRefs, tz, warns)
+		}
+		setComputedValues(mi, elm, computedRefs)
+		cnt++
+	}
+
+	if err = rs.Err(); err != nil {
+		return 0, err
+	}
+
+	if slice.IsNil() {
+		slice = reflect.MakeSlice(ind.Type(), 0, 0)
+	}
+	ind.Set(slice.Slice(0, cnt))
+	return int64(cnt), nil
+}
+
+// transform the pointer to the scanned model by the Map fn, its result must be a pointer of the same type.
+func mapElem(fn func(interface{}) interface{}, elem reflect.Value) (reflect.Value, error) {
+	res := fn(elem.Interface())
+	val := reflect.ValueOf(res)
+	if !val.IsValid() || val.Type() != elem.Type() || val.IsNil() {
+		return elem, fmt.Errorf("<QuerySeter.Map> the result `%T` is not a `%s`", res, elem.Type())
+	}
+	return val, nil
+}
+
+// set the scanned refs of a row to a new model of mi and its selected related models,
+// return the struct value of the model.
+func (d *dbBase) setRowValues(mi *models.ModelInfo, tables *dbTables, tCols []string, refs []interface{}, tz *time.Location, warns *scanWarnings) reflect.Value {
+	elm := reflect.New(mi.AddrField.Elem().Type())
+	mind := reflect.Indirect(elm)
+
+	cacheV := make(map[string]*reflect.Value)
+	cacheM := make(map[string]*models.ModelInfo)
+	trefs := refs
+
+	d.setColsValues(mi, &mind, tCols, refs[:len(tCols)], tz, warns)
+	trefs = refs[len(tCols):]
+
+	for _, tbl := range tables.tables {
+		// loop selected tables
+		if tbl.sel {
+			last := mind
+			names := ""
+			mmi := mi
+			// loop cascade models
+			for _, name := range tbl.names {
+				names += name
+				if val, ok := cacheV[names]; ok {
+					last = *val
+					mmi = cacheM[names]
+				} else {
+					fi := mmi.Fields.GetByName(name)
+					lastm := mmi
+					mmi = fi.RelModelInfo
+					field := last
+					if last.Kind() != reflect.Invalid {
+						field = reflect.Indirect(last.FieldByIndex(fi.FieldIndex))
+						if field.IsValid() {
+							d.setColsValues(mmi, &field, mmi.Fields.DBcols, trefs[:len(mmi.Fields.DBcols)], tz, warns)
+							for _, fi := range mmi.Fields.FieldsReverse {
+								if fi.InModel && fi.ReverseFieldInfo.Mi == lastm {
+									if fi.ReverseFieldInfo != nil {
+										f := field.FieldByIndex(fi.FieldIndex)
+										if f.Kind() == reflect.Ptr {
+											f.Set(last.Addr())
+										}
+									}
+								}
+							}
+							last = field
+						}
+					}
+					cacheV[names] = &field
+					cacheM[names] = mmi
+				}
+			}
+			trefs = trefs[len(mmi.Fields.DBcols):]
+		}
+	}
+	return mind
+}
+
+// the rows read by StreamBatch, *sql.Rows or the rows of a cursor.
+type streamRows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+	Close() error
+}
+
+// the number of the cursors declared by StreamBatch, naming them uniquely.
+var cursorSeq uint64
+
+// the rows of a cursor fetched size rows at a time.
+type cursorRows struct {
+	ctx     context.Context
+	q       dbQuerier
+	fetch   string
+	close   string
+	size    int
+	rows    *sql.Rows
+	fetched int
+	err     error
+}
+
+func (c *cursorRows) Next() bool {
+	for c.err == nil {
+		if c.rows.Next() {
+			c.fetched++
+			return true
+		}
+		if c.err = c.rows.Err(); c.err != nil || c.fetched < c.size {
+			// fewer rows than size are the last ones
+			return false
+		}
+		c.rows.Close()
+		c.rows, c.err = c.q.QueryContext(c.ctx, c.fetch)
+		c.fetched = 0
+	}
+	return false
+}
+
+func (c *cursorRows) Scan(dest ...interface{}) error {
+	return c.rows.Scan(dest...)
+}
+
+func (c *cursorRows) Err() error {
+	return c.err
+}
+
+func (c *cursorRows) Close() error {
+	if c.rows != nil {
+		c.rows.Close()
+	}
+	_, err := c.q.ExecContext(context.Background(), c.close)
+	return err
+}
+
+// StreamBatch run the query of qs and return its rows with the func scanning the current row to a new model of mi,
+// the caller must close the rows.
+// With the FetchSize of qs in a transaction, the rows are fetched by a cursor of CursorSQL where the driver supports it.
+func (d *dbBase) StreamBatch(ctx context.Context, q dbQuerier, qs querySet, mi *models.ModelInfo, cond *Condition, tz *time.Location) (streamRows, func() (reflect.Value, error), error) {
+	tCols := mi.Fields.DBcols
+	tables := newDbTables(mi, d.ins)
+	tables.parseRelated(qs.related, qs.relDepth)
+
+	colsNum := len(tCols)
+	for _, tbl := range tables.tables {
+		if tbl.sel {
+			colsNum += len(tbl.mi.Fields.DBcols)
+		}
+	}
+
+	query, args := d.readBatchSQL(tables, tCols, cond, qs, mi, tz)
+
+	var rs streamRows
+	var declare, fetch, closeSQL string
+	if _, inTx := q.(txEnder); inTx && qs.fetchSize > 0 {
+		declare, fetch, closeSQL = d.ins.CursorSQL(fmt.Sprintf("orm_cursor_%d", atomic.AddUint64(&cursorSeq, 1)), qs.fetchSize)
+	}
+	if declare != "" {
+		if rc, ok := q.(*dbReadCache); ok {
+			// every fetch of the cursor has the same sql but the next rows
+			q = rc.db
+		}
+		if _, err := q.ExecContext(ctx, declare+" "+query, args...); err != nil {
+			return nil, nil, err
+		}
+		cursor := &cursorRows{ctx: ctx, q: q, fetch: fetch, close: closeSQL, size: qs.fetchSize}
+		if cursor.rows, cursor.err = q.QueryContext(ctx, fetch); cursor.err != nil {
+			cursor.Close()
+			return nil, nil, cursor.err
+		}
+		rs = cursor
+	} else {
+		rows, err := q.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, nil, err
+		}
+		rs = rows
+	}
+
+	refs := make([]interface{}, colsNum)
+	for i := range refs {
+		var ref interface{}
+		refs[i] = &ref
+	}
+	size := resultBytes{max: qs.maxResultBytes}
+	scan := func() (reflect.Value, error) {
+		if err := rs.Scan(refs...); err != nil {
+			return reflect.Value{}, err
+		}
+		if err := size.add(refs); err != nil {
+			return reflect.Value{}, err
+		}
+		return d.setRowValues(mi, tables, tCols, refs, tz, nil), nil
+	}
+	return rs, scan, nil
+}
+
+// Explain run the query of qs prefixed by ExplainSQL and return the plan,
+// the columns of a row are joined by tab and the rows by newline.
+func (d *dbBase) Explain(ctx context.Context, q dbQuerier, qs querySet, mi *models.ModelInfo, cond *Condition, analyze bool, tz *time.Location) (string, error) {
+	explain := d.ins.ExplainSQL(analyze)
+	if explain == "" {
+		return "", ErrNotImplement
+	}
+
+	tables := newDbTables(mi, d.ins)
+	tables.parseRelated(qs.related, qs.relDepth)
+	query, args := d.readBatchSQL(tables, mi.Fields.DBcols, cond, qs, mi, tz)
+
+	rs, err := q.QueryContext(ctx, explain+" "+query, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rs.Close()
+
+	cols, err := rs.Columns()
+	if err != nil {
+		return "", err
+	}
+	vals := make([]interface{}, len(cols))
+	refs := make([]interface{}, len(cols))
+	for i := range vals {
+		refs[i] = &vals[i]
+	}
+
+	var lines []string
+	for rs.Next() {
+		if err := rs.Scan(refs...); err != nil {
+			return "", err
+		}
+		cells := make([]string, len(vals))
+		for i, v := range vals {
+			switch v := v.(type) {
+			case nil:
+				cells[i] = "NULL"
+			case []byte:
+				cells[i] = string(v)
+			default:
+				cells[i] = utils.ToStr(v)
+			}
+		}
+		lines = append(lines, strings.Join(cells, "\t"))
+	}
+	if err := rs.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// the sql exprs are selected after the columns tCols.
+func (d *dbBase) readBatchSQL(tables *dbTables, tCols []string, cond *Condition, qs querySet, mi *models.ModelInfo, tz *time.Location, exprs ...string) (string, []interface{}) {
+	cols := append(d.preProcCols(tCols), exprs...) // pre process columns
+
+	buf := buffers.Get()
+	defer buffers.Put(buf)
+
+	args := d.readSQL(buf, tables, cols, cond, qs, mi, tz)
+
+	query := buf.String()
+
+	d.ins.ReplaceMarks(&query)
+
+	return query, args
+}
+
+func (d *dbBase) preProcCols(cols []string) []string {
+	res := make([]string, len(cols))
+
+	quote := d.ins.TableQuote()
+	for i, col := range cols {
+		res[i] = fmt.Sprintf("T0.%s%s%s", quote, col, quote)
+	}
+
+	return res
+}
+
+// readSQL generate a select sql string and return args
 // ReadBatch and ReadValues methods will reuse this method.
 func (d *dbBase) readSQL(buf buffers.Buffer, tables *dbTables, tCols []string, cond *Condition, qs querySet, mi *models.ModelInfo, tz *time.Location) []interface{} {
 
 	quote := d.ins.TableQuote()
 
 	where, args := tables.getCondSQL(cond, false, tz)
-	groupBy := tables.getGroupSQL(qs.groups)
-	orderBy := tables.getOrderSQL(qs.orders)
+	if len(qs.aggrs) > 0 {
+		qs.aggregate = tables.getAggregationSQL(qs.groups, qs.aggrs)
+	}
+	groupBy := tables.getGroupSQL(qs.groups, qs.buckets, tz)
+	having, havingArgs := tables.getHavingSQL(qs.havings)
+	args = append(args, havingArgs...)
+	orders := qs.orders
+	// the order columns of a default order would have to be selected by DISTINCT
+	if len(orders) == 0 && !qs.noDefault && !qs.distinct && len(qs.groups) == 0 && len(qs.buckets) == 0 && qs.aggregate == "" {
+		orders = order_clause.ParseOrder(mi.DefaultOrderBy...)
+	}
+	orderBy, orderArgs := tables.getOrderSQL(orders, tz)
+	args = append(args, orderArgs...)
 	limit := tables.getLimitSQL(mi, qs.offset, qs.limit)
 	join := tables.getJoinSQL()
 	specifyIndexes := tables.getIndexSql(mi.Table, qs.useIndex, qs.indexes)
@@ -1362,14 +2118,13 @@ func (d *dbBase) readSQL(buf buffers.Buffer, tables *dbTables, tCols []string, c
 	}
 
 	_, _ = buf.WriteString(" FROM ")
-	_, _ = buf.WriteString(quote)
-	_, _ = buf.WriteString(mi.Table)
-	_, _ = buf.WriteString(quote)
+	_, _ = buf.WriteString(d.TableSQL(mi.Table))
 	_, _ = buf.WriteString(" T0 ")
 	_, _ = buf.WriteString(specifyIndexes)
 	_, _ = buf.WriteString(join)
 	_, _ = buf.WriteString(where)
 	_, _ = buf.WriteString(groupBy)
+	_, _ = buf.WriteString(having)
 	_, _ = buf.WriteString(orderBy)
 	_, _ = buf.WriteString(limit)
 
@@ -1401,7 +2156,7 @@ func (d *dbBase) countSQL(qs querySet, mi *models.ModelInfo, cond *Condition, tz
 		_, _ = buf.WriteString("SELECT COUNT(*) FROM (")
 	}
 
-	qs.aggregate = "COUNT(*)"
+	qs.aggregate = d.countSQLExpr(tables, mi, qs.countCol)
 	args := d.readSQL(buf, tables, nil, cond, qs, mi, tz)
 
 	if len(qs.groups) > 0 {
@@ -1415,8 +2170,119 @@ func (d *dbBase) countSQL(qs querySet, mi *models.ModelInfo, cond *Condition, tz
 	return query, args
 }
 
+// generate the COUNT of col, COUNT(*) if col is empty.
+func (d *dbBase) countSQLExpr(tables *dbTables, mi *models.ModelInfo, col string) string {
+	if col == "" {
+		return "COUNT(*)"
+	}
+	index, _, fi, suc := tables.parseExprs(mi, strings.Split(col, ExprSep))
+	if !suc {
+		panic(fmt.Errorf("unknown field/column name `%s`", col))
+	}
+	Q := d.ins.TableQuote()
+	return fmt.Sprintf("COUNT(%s.%s%s%s)", index, Q, fi.Column, Q)
+}
+
+// GroupCount execute count sql grouped by groupCol and return the count of every group.
+// NULL group values are collected under the nil key.
+func (d *dbBase) GroupCount(ctx context.Context, q dbQuerier, qs querySet, mi *models.ModelInfo, cond *Condition, groupCol string, tz *time.Location) (map[interface{}]int64, error) {
+	tables := newDbTables(mi, d.ins)
+	_, _, fi, suc := tables.parseExprs(mi, strings.Split(groupCol, ExprSep))
+	if !suc {
+		return nil, fmt.Errorf("wrong field/column name `%s`", groupCol)
+	}
+
+	query, args := d.groupCountSQL(qs, mi, cond, groupCol, tz)
+
+	rs, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rs.Close()
+
+	res := make(map[interface{}]int64)
+	for rs.Next() {
+		var (
+			ref interface{}
+			cnt int64
+		)
+		if err := rs.Scan(&ref, &cnt); err != nil {
+			return nil, err
+		}
+		key, err := d.convertValueFromDB(fi, ref, tz)
+		if err != nil {
+			return nil, err
+		}
+		res[pkKey(key)] += cnt
+	}
+
+	if err = rs.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (d *dbBase) groupCountSQL(qs querySet, mi *models.ModelInfo, cond *Condition, groupCol string, tz *time.Location) (string, []interface{}) {
+	tables := newDbTables(mi, d.ins)
+	tables.parseRelated(qs.related, qs.relDepth)
+
+	index, _, fi, suc := tables.parseExprs(mi, strings.Split(groupCol, ExprSep))
+	if !suc {
+		panic(fmt.Errorf("unknown field/column name `%s`", groupCol))
+	}
+
+	Q := d.ins.TableQuote()
+
+	buf := buffers.Get()
+	defer buffers.Put(buf)
+
+	qs.aggregate = fmt.Sprintf("%s.%s%s%s, %s", index, Q, fi.Column, Q, d.countSQLExpr(tables, mi, qs.countCol))
+	qs.groups = []string{groupCol}
+	qs.orders = nil
+	args := d.readSQL(buf, tables, nil, cond, qs, mi, tz)
+
+	query := buf.String()
+
+	d.ins.ReplaceMarks(&query)
+
+	return query, args
+}
+
 // GenerateOperatorSQL generate sql with replacing operator string placeholders and replaced values.
 func (d *dbBase) GenerateOperatorSQL(mi *models.ModelInfo, fi *models.FieldInfo, operator string, args []interface{}, tz *time.Location) (string, []interface{}) {
+	if quantifiedOperators[operator] {
+		return d.quantifiedSQL(operator, args, tz)
+	}
+	if operator == "similar" {
+		return d.similarSQL(operator, args)
+	}
+	if operator == "hasall" || operator == "hasany" {
+		return d.bitmaskSQL(fi, operator, args, tz)
+	}
+	if operator == "ilike_any" {
+		return d.likeAnySQL(fi, operator, args, tz)
+	}
+	if operator == "soundex" {
+		return d.soundexSQL(fi, operator, args, tz)
+	}
+	if len(args) == 1 {
+		if sub, ok := args[0].(*SubQuery); ok && operator == "in" {
+			query, params, _ := d.SubQuerySQL(sub, tz)
+			return fmt.Sprintf("IN (%s)", query), params
+		}
+		if t, ok := args[0].(DBTime); ok {
+			return d.dbTimeSQL(operator, t)
+		}
+		if f, ok := args[0].(FnValue); ok {
+			return d.fnValueSQL(fi, operator, f, tz)
+		}
+		if v, ok := args[0].(TypedValue); ok {
+			return d.typedValueSQL(mi, fi, operator, v, tz)
+		}
+	}
+
 	var sql string
 	params := getFlatParams(fi, args, tz)
 
@@ -1448,7 +2314,10 @@ func (d *dbBase) GenerateOperatorSQL(mi *models.ModelInfo, fi *models.FieldInfo,
 				params[0] = "IS NULL"
 			}
 		case "iexact", "contains", "icontains", "startswith", "endswith", "istartswith", "iendswith":
-			param := strings.Replace(utils.ToStr(arg), `%`, `\%`, -1)
+			param := utils.ToStr(arg)
+			if strings.Contains(sql, "LIKE") {
+				param = escapeLike(param)
+			}
 			switch operator {
 			case "iexact":
 			case "contains", "icontains":
@@ -1475,13 +2344,388 @@ func (d *dbBase) GenerateOperatorSQL(mi *models.ModelInfo, fi *models.FieldInfo,
 	return sql, params
 }
 
+// escape the wildcards of LIKE in s by backslash, the escape character of all the drivers.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// generate the quantified comparison with a sub query, such as > ALL (SELECT ...).
+// the marks of the sub query are replaced with the whole query.
+func (d *dbBase) quantifiedSQL(operator string, args []interface{}, tz *time.Location) (string, []interface{}) {
+	sql := d.ins.OperatorSQL(operator)
+	if sql == "" {
+		panic(fmt.Errorf("operator `%s` is not supported by the driver", operator))
+	}
+	if len(args) != 1 {
+		panic(fmt.Errorf("operator `%s` need 1 args not %d", operator, len(args)))
+	}
+	sub, ok := args[0].(*SubQuery)
+	if !ok {
+		panic(fmt.Errorf("operator `%s` need a *SubQuery value not `%T`", operator, args[0]))
+	}
+
+	query, params, _ := d.SubQuerySQL(sub, tz)
+	return fmt.Sprintf(sql, query), params
+}
+
+// SubQuerySQL return the select sql of the column of sub and its field.
+// the marks of the sub query are replaced with the whole query.
+func (d *dbBase) SubQuerySQL(sub *SubQuery, tz *time.Location) (string, []interface{}, *models.FieldInfo) {
+	fi, ok := sub.qs.mi.Fields.GetByAny(sub.col)
+	if !ok {
+		panic(fmt.Errorf("wrong field/column name `%s` for sub query", sub.col))
+	}
+	qs := *sub.qs
+	if qs.limit == 0 {
+		// the default rows limit is not applied to sub queries
+		qs.limit = -1
+	}
+	// neither is the default order, which does not change the rows
+	qs.noDefault = true
+	tables := newDbTables(qs.mi, d.ins)
+	tables.parseRelated(qs.related, qs.relDepth)
+
+	buf := buffers.Get()
+	defer buffers.Put(buf)
+	params := d.readSQL(buf, tables, d.preProcCols([]string{fi.Column}), qs.cond, qs, qs.mi, tz)
+
+	return strings.TrimSpace(buf.String()), params, fi
+}
+
+// generate the comparison of the trigram similarity with the threshold,
+// the column is wrapped by GenerateOperatorLeftCol of the dialect.
+func (d *dbBase) similarSQL(operator string, args []interface{}) (string, []interface{}) {
+	sql := d.ins.OperatorSQL(operator)
+	if sql == "" {
+		panic(fmt.Errorf("operator `%s` is not supported by the driver", operator))
+	}
+	if len(args) != 1 {
+		panic(fmt.Errorf("operator `%s` need 1 args not %d", operator, len(args)))
+	}
+	s, ok := args[0].(Similarity)
+	if !ok {
+		panic(fmt.Errorf("operator `%s` need a Similarity value not `%T`", operator, args[0]))
+	}
+	return sql, []interface{}{s.Text, s.Threshold}
+}
+
+// generate the comparison with the soundex code of the value,
+// the column is wrapped by GenerateOperatorLeftCol of the dialect.
+func (d *dbBase) soundexSQL(fi *models.FieldInfo, operator string, args []interface{}, tz *time.Location) (string, []interface{}) {
+	sql := d.ins.OperatorSQL(operator)
+	if sql == "" {
+		panic(fmt.Errorf("operator `%s` is not supported by the driver", operator))
+	}
+	params := getFlatParams(fi, args, tz)
+	if len(params) != 1 {
+		panic(fmt.Errorf("operator `%s` need 1 args not %d", operator, len(params)))
+	}
+	return sql, params
+}
+
+// generate the LIKE of a pattern, the caller repeats it for every param joined by OR.
+// the patterns are used as is, % and _ in them are wildcards.
+func (d *dbBase) likeAnySQL(fi *models.FieldInfo, operator string, args []interface{}, tz *time.Location) (string, []interface{}) {
+	sql := d.ins.OperatorSQL(operator)
+	if sql == "" {
+		panic(fmt.Errorf("operator `%s` is not supported by the driver", operator))
+	}
+	params := getFlatParams(fi, args, tz)
+	if len(params) == 0 {
+		panic(fmt.Errorf("operator `%s` need at least one args", operator))
+	}
+	return sql, params
+}
+
+// generate the comparison of the column and mask by BitAndSQL with the mask,
+// the mask is also compared for hasall: (col & mask) = mask.
+func (d *dbBase) bitmaskSQL(fi *models.FieldInfo, operator string, args []interface{}, tz *time.Location) (string, []interface{}) {
+	params := getFlatParams(fi, args, tz)
+	if len(params) != 1 {
+		panic(fmt.Errorf("operator `%s` need 1 args not %d", operator, len(params)))
+	}
+	sql := d.ins.OperatorSQL(operator)
+	if operator == "hasall" {
+		return sql, []interface{}{params[0], params[0]}
+	}
+	return sql, params
+}
+
+// generate the comparison with the sql function of f applied to its value.
+func (d *dbBase) fnValueSQL(fi *models.FieldInfo, operator string, f FnValue, tz *time.Location) (string, []interface{}) {
+	switch operator {
+	case "exact", "eq", "ne", "gt", "gte", "lt", "lte":
+	default:
+		panic(fmt.Errorf("operator `%s` cannot compare with Fn", operator))
+	}
+	params := getFlatParams(fi, []interface{}{f.arg}, tz)
+	if len(params) != 1 {
+		panic(fmt.Errorf("operator `%s` need 1 args not %d", operator, len(params)))
+	}
+	sql := strings.Replace(d.ins.OperatorSQL(operator), "?", f.name+"(?)", 1)
+	return sql, params
+}
+
+// generate the comparison with the params of v cast to its type.
+func (d *dbBase) typedValueSQL(mi *models.ModelInfo, fi *models.FieldInfo, operator string, v TypedValue, tz *time.Location) (string, []interface{}) {
+	switch operator {
+	case "exact", "eq", "ne", "gt", "gte", "lt", "lte", "in", "between":
+	default:
+		panic(fmt.Errorf("operator `%s` cannot compare with Typed", operator))
+	}
+	sql, params := d.GenerateOperatorSQL(mi, fi, operator, []interface{}{v.arg}, tz)
+	return strings.ReplaceAll(sql, "?", fmt.Sprintf("CAST(? AS %s)", v.typ)), params
+}
+
+// generate the comparison with the current time of the database plus the intervals of t.
+func (d *dbBase) dbTimeSQL(operator string, t DBTime) (string, []interface{}) {
+	switch operator {
+	case "exact", "eq", "ne", "gt", "gte", "lt", "lte", "before", "after":
+	default:
+		panic(fmt.Errorf("operator `%s` cannot compare with DBTime", operator))
+	}
+	intervals := t.intervals
+	if len(intervals) == 0 {
+		intervals = []dbInterval{{unit: Second}}
+	}
+	var expr string
+	for _, interval := range intervals {
+		if expr = d.ins.IntervalSQL(expr, interval.amount, interval.unit); expr == "" {
+			panic(fmt.Errorf("interval arithmetic is not supported by the driver"))
+		}
+	}
+	return strings.Replace(d.ins.OperatorSQL(operator), "?", expr, 1), nil
+}
+
 // GenerateOperatorLeftCol gernerate sql string with inner function, such as UPPER(text).
 func (d *dbBase) GenerateOperatorLeftCol(*models.FieldInfo, string, *string) {
 	// default not use
 }
 
+// LengthSQL return sql of the character length of column.
+func (d *dbBase) LengthSQL(col string) string {
+	return fmt.Sprintf("LENGTH(%s)", col)
+}
+
+// DatePartSQL return sql of the part of the date column, quarter, isoweek or age, as an integer,
+// isoweek is the week of ISO-8601 starting on monday, the week 1 has the first thursday of the year,
+// age is the whole years from the date to the current date, one less before the anniversary in the current year.
+// empty string means the driver does not support it.
+func (d *dbBase) DatePartSQL(string, string) string {
+	return ""
+}
+
+// TimezoneConvertSQL return sql converting the time column stored in the timezone of the utc offset from,
+// the one of the db alias, to the wall time of the timezone tz, empty string means the driver does not support it.
+func (d *dbBase) TimezoneConvertSQL(string, string, string) string {
+	return ""
+}
+
+// ArrayLengthSQL return sql of the number of elements of the array or json array column,
+// empty string means the driver does not support it.
+func (d *dbBase) ArrayLengthSQL(*models.FieldInfo, string) string {
+	return ""
+}
+
+// ArrayElementSQL return sql of the element at the 0-based index of the array or json array column,
+// empty string means the driver does not support it.
+func (d *dbBase) ArrayElementSQL(*models.FieldInfo, string, int) string {
+	return ""
+}
+
+// BitAndSQL return sql of the bitwise and of column with a mask placeholder.
+func (d *dbBase) BitAndSQL(col string) string {
+	return fmt.Sprintf("(%s & ?)", col)
+}
+
+// RecursiveWithSQL return the keywords starting a recursive common table expression,
+// empty string means the driver does not support it.
+func (d *dbBase) RecursiveWithSQL() string {
+	return "WITH RECURSIVE"
+}
+
+// TrimSQL return sql of the column with the leading and trailing spaces trimmed.
+func (d *dbBase) TrimSQL(col string) string {
+	return fmt.Sprintf("TRIM(%s)", col)
+}
+
+// ModuloSQL return sql of the remainder of column divided by a divisor placeholder.
+func (d *dbBase) ModuloSQL(col string) string {
+	return fmt.Sprintf("(%s %% ?)", col)
+}
+
+// CastSQL return sql casting column to the type of castTypes,
+// empty string means the driver does not support it.
+func (d *dbBase) CastSQL(string, string) string {
+	return ""
+}
+
+// TableSQL return the quoted table, qualified by the schema of UsingSchema if any.
+func (d *dbBase) TableSQL(table string) string {
+	Q := d.ins.TableQuote()
+	if d.schema == "" {
+		return Q + table + Q
+	}
+	return Q + d.schema + Q + "." + Q + table + Q
+}
+
+// SearchPathSQL return the sql making the schema of UsingSchema the default of the unqualified tables in a transaction,
+// empty string means the driver does not support it or there is no schema.
+func (d *dbBase) SearchPathSQL() string {
+	return ""
+}
+
+// ConcatSQL return sql concatenating the exprs by the || operator of standard sql.
+func (d *dbBase) ConcatSQL(exprs []string) string {
+	return fmt.Sprintf("(%s)", strings.Join(exprs, " || "))
+}
+
+// RoundSQL return sql rounding column to decimals.
+func (d *dbBase) RoundSQL(col string, decimals int) string {
+	return fmt.Sprintf("ROUND(%s, %d)", col, decimals)
+}
+
+// TimeBucketSQL return sql truncating column to the unit,
+// empty string means the driver does not support it.
+func (d *dbBase) TimeBucketSQL(string, string) string {
+	return ""
+}
+
+// PercentileSQL return sql of the continuous percentile p of column in a group,
+// empty string means the driver does not support it.
+func (d *dbBase) PercentileSQL(string, float64) string {
+	return ""
+}
+
+// GroupConcatSQL return sql of the values of column in a group joined by sep, ordered by orderBy when not empty,
+// empty string means the driver does not support it.
+func (d *dbBase) GroupConcatSQL(string, string, string) string {
+	return ""
+}
+
+// PositionSQL return sql of the position of the value of column in n values of the args, to order by.
+func (d *dbBase) PositionSQL(col string, n int) string {
+	var buf strings.Builder
+	buf.WriteString("CASE ")
+	buf.WriteString(col)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, " WHEN ? THEN %d", i)
+	}
+	buf.WriteString(" END")
+	return buf.String()
+}
+
+// IntervalSQL return sql of the time expr plus amount of unit, expr is the current time if empty,
+// empty string means the driver does not support it.
+func (d *dbBase) IntervalSQL(string, int, IntervalUnit) string {
+	return ""
+}
+
+// AdvisoryLockSQL return the sql trying to take the advisory lock of a key without waiting,
+// selecting true if taken, and the sql releasing it, both with the key as the only arg.
+// empty strings mean the driver does not support it.
+func (d *dbBase) AdvisoryLockSQL() (string, string) {
+	return "", ""
+}
+
+// UpsertSQL return the sql inserting the columns names of mi, updating the row conflicting on the columns conflicts instead,
+// empty string means the driver does not support it.
+func (d *dbBase) UpsertSQL(*models.ModelInfo, []string, []string) string {
+	return ""
+}
+
+// the columns quoted by Q with prefix, joined by ", ".
+func quoteCols(Q string, cols []string, prefix string) string {
+	quoted := make([]string, len(cols))
+	for i, col := range cols {
+		quoted[i] = prefix + Q + col + Q
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// the sql inserting the columns names of mi.
+func (d *dbBase) upsertInsertSQL(mi *models.ModelInfo, names []string) string {
+	marks := make([]string, len(names))
+	for i := range marks {
+		marks[i] = "?"
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", d.TableSQL(mi.Table), quoteCols(d.ins.TableQuote(), names, ""), strings.Join(marks, ", "))
+}
+
+// the columns of names updated by an upsert, not the conflicts or the columns of auto_now_add fields.
+func upsertUpdateCols(mi *models.ModelInfo, names []string, conflicts []string) []string {
+	cols := make([]string, 0, len(names))
+	for _, name := range names {
+		if fi := mi.Fields.GetByColumn(name); fi != nil && fi.AutoNowAdd {
+			continue
+		}
+		conflict := false
+		for _, c := range conflicts {
+			conflict = conflict || c == name
+		}
+		if !conflict {
+			cols = append(cols, name)
+		}
+	}
+	return cols
+}
+
+// the upsert by the ON CONFLICT of postgresql and sqlite.
+func (d *dbBase) onConflictUpsertSQL(mi *models.ModelInfo, names []string, conflicts []string) string {
+	Q := d.ins.TableQuote()
+	query := fmt.Sprintf("%s ON CONFLICT (%s) DO ", d.upsertInsertSQL(mi, names), quoteCols(Q, conflicts, ""))
+	updates := upsertUpdateCols(mi, names, conflicts)
+	if len(updates) == 0 {
+		return query + "NOTHING"
+	}
+	sets := make([]string, len(updates))
+	for i, col := range updates {
+		sets[i] = fmt.Sprintf("%s%s%s = EXCLUDED.%s%s%s", Q, col, Q, Q, col, Q)
+	}
+	return query + "UPDATE SET " + strings.Join(sets, ", ")
+}
+
+// SubstringIndexSQL return sql of the position of the substring arg in column from the 1-based position pos,
+// 0 if it is missing there, empty string means the driver does not support it.
+func (d *dbBase) SubstringIndexSQL(string, int) string {
+	return ""
+}
+
+// JSONExistsSQL return the predicate of the json path existing in column and its args,
+// empty string means the driver does not support it.
+func (d *dbBase) JSONExistsSQL(string, string) (string, []interface{}) {
+	return "", nil
+}
+
+// JSONValidSQL return the predicate of column holding a valid json document,
+// empty string means the driver does not support it.
+func (d *dbBase) JSONValidSQL(*models.FieldInfo, string) string {
+	return ""
+}
+
+// UnaccentSQL return the predicate of column equal to the ? arg regardless of the accents,
+// empty string means the driver does not support it.
+func (d *dbBase) UnaccentSQL(string) string {
+	return ""
+}
+
+// JSONEqualSQL return the predicate of the json document in column equal to the one in doc and its args,
+// the documents are compared as values, so the order of the keys and the whitespace do not matter.
+// empty string means the driver does not support it.
+func (d *dbBase) JSONEqualSQL(string, string) (string, []interface{}) {
+	return "", nil
+}
+
+// SetIncludesSQL return the predicate of the comma-separated values in column including elem and its args,
+// the column is wrapped by commas and matched by LIKE.
+func (d *dbBase) SetIncludesSQL(col string, elem string) (string, []interface{}) {
+	return fmt.Sprintf("(',' || %s || ',') LIKE ? ESCAPE '\\'", col), []interface{}{"%," + escapeLike(elem) + ",%"}
+}
+
 // Set values to struct column.
-func (d *dbBase) setColsValues(mi *models.ModelInfo, ind *reflect.Value, cols []string, values []interface{}, tz *time.Location) {
+func (d *dbBase) setColsValues(mi *models.ModelInfo, ind *reflect.Value, cols []string, values []interface{}, tz *time.Location, warns *scanWarnings) {
 	for i, column := range cols {
 		val := reflect.Indirect(reflect.ValueOf(values[i])).Interface()
 
@@ -1490,14 +2734,16 @@ func (d *dbBase) setColsValues(mi *models.ModelInfo, ind *reflect.Value, cols []
 		field := ind.FieldByIndex(fi.FieldIndex)
 
 		value, err := d.convertValueFromDB(fi, val, tz)
-		if err != nil {
-			panic(fmt.Errorf("Raw value: `%v` %s", val, err.Error()))
+		if err == nil {
+			_, err = d.setFieldValue(fi, value, field)
 		}
 
-		_, err = d.setFieldValue(fi, value, field)
-
 		if err != nil {
-			panic(fmt.Errorf("Raw value: `%v` %s", val, err.Error()))
+			if warns == nil {
+				panic(fmt.Errorf("Raw value: `%v` %s", val, err.Error()))
+			}
+			field.Set(reflect.Zero(field.Type()))
+			warns.add(column, val, err)
 		}
 	}
 }
@@ -1507,6 +2753,13 @@ func (d *dbBase) convertValueFromDB(fi *models.FieldInfo, val interface{}, tz *t
 	if val == nil {
 		return nil, nil
 	}
+	// computed columns without a field, such as the case expressions of ValuesCase
+	if fi == nil {
+		if b, ok := val.([]byte); ok {
+			return string(b), nil
+		}
+		return val, nil
+	}
 
 	var value interface{}
 	var tErr error
@@ -1550,6 +2803,17 @@ setValue:
 		} else {
 			value = str.String()
 		}
+	case fieldType == TypeBinaryField:
+		switch v := val.(type) {
+		case []byte:
+			// the driver may reuse the bytes after the next scan
+			value = append([]byte{}, v...)
+		case string:
+			value = []byte(v)
+		default:
+			tErr = fmt.Errorf("unsupported binary value `%T`", val)
+			goto end
+		}
 	case fieldType == TypeTimeField || fieldType == TypeDateField || fieldType == TypeDateTimeField:
 		if str == nil {
 			switch t := val.(type) {
@@ -1561,7 +2825,18 @@ setValue:
 				str = &s
 			}
 		}
-		if str != nil {
+		if str != nil && fi.TimeFormat != "" {
+			var t time.Time
+			if s := str.String(); s != "" {
+				var err error
+				if t, err = time.ParseInLocation(fi.TimeFormat, s, tz); err != nil {
+					tErr = err
+					goto end
+				}
+				t = t.In(DefaultTimeLoc)
+			}
+			value = t
+		} else if str != nil {
 			s := str.String()
 			var (
 				t   time.Time
@@ -1633,6 +2908,18 @@ setValue:
 				value = v
 			}
 		}
+	case fieldType == TypeDecimalField && fi.IsBigRat:
+		// parse the raw string from driver, float64 can not hold the exact value
+		if str == nil {
+			s := utils.StrTo(utils.ToStr(val))
+			str = &s
+		}
+		r, ok := new(big.Rat).SetString(str.String())
+		if !ok {
+			tErr = fmt.Errorf("invalid decimal value `%s`", str.String())
+			goto end
+		}
+		value = r
 	case fieldType == TypeFloatField || fieldType == TypeDecimalField:
 		if str == nil {
 			switch v := val.(type) {
@@ -1717,9 +3004,25 @@ setValue:
 				field.SetString(value.(string))
 			}
 		}
-	case fieldType == TypeTimeField || fieldType == TypeDateField || fieldType == TypeDateTimeField:
+	case fieldType == TypeBinaryField:
 		if isNative {
 			if value == nil {
+				field.SetBytes(nil)
+			} else {
+				field.SetBytes(value.([]byte))
+			}
+		}
+	case fieldType == TypeTimeField || fieldType == TypeDateField || fieldType == TypeDateTimeField:
+		if isNative {
+			if nt, ok := field.Interface().(sql.NullTime); ok {
+				if value == nil {
+					nt.Valid = false
+				} else {
+					nt.Time = value.(time.Time)
+					nt.Valid = true
+				}
+				field.Set(reflect.ValueOf(nt))
+			} else if value == nil {
 				value = time.Time{}
 			} else if field.Kind() == reflect.Ptr {
 				if value != nil {
@@ -1783,10 +3086,20 @@ setValue:
 	case fieldType&IsIntegerField > 0:
 		if fieldType&IsPositiveIntegerField > 0 {
 			if isNative {
-				if value == nil {
-					value = uint64(0)
+				if nb, ok := field.Interface().(sql.NullByte); ok {
+					if value == nil {
+						nb.Valid = false
+					} else {
+						nb.Byte = byte(value.(uint64))
+						nb.Valid = true
+					}
+					field.Set(reflect.ValueOf(nb))
+				} else {
+					if value == nil {
+						value = uint64(0)
+					}
+					field.SetUint(value.(uint64))
 				}
-				field.SetUint(value.(uint64))
 			}
 		} else {
 			if isNative {
@@ -1798,6 +3111,22 @@ setValue:
 						ni.Valid = true
 					}
 					field.Set(reflect.ValueOf(ni))
+				} else if ni, ok := field.Interface().(sql.NullInt32); ok {
+					if value == nil {
+						ni.Valid = false
+					} else {
+						ni.Int32 = int32(value.(int64))
+						ni.Valid = true
+					}
+					field.Set(reflect.ValueOf(ni))
+				} else if ni, ok := field.Interface().(sql.NullInt16); ok {
+					if value == nil {
+						ni.Valid = false
+					} else {
+						ni.Int16 = int16(value.(int64))
+						ni.Valid = true
+					}
+					field.Set(reflect.ValueOf(ni))
 				} else {
 					if value == nil {
 						value = int64(0)
@@ -1806,6 +3135,19 @@ setValue:
 				}
 			}
 		}
+	case fieldType == TypeDecimalField && fi.IsBigRat:
+		if field.Kind() == reflect.Ptr {
+			if value != nil {
+				field.Set(reflect.ValueOf(value))
+			}
+		} else {
+			r := field.Addr().Interface().(*big.Rat)
+			if value == nil {
+				r.SetInt64(0)
+			} else {
+				r.Set(value.(*big.Rat))
+			}
+		}
 	case fieldType == TypeFloatField || fieldType == TypeDecimalField:
 		if isNative {
 			if nf, ok := field.Interface().(sql.NullFloat64); ok {
@@ -1892,11 +3234,16 @@ func (d *dbBase) ReadValues(ctx context.Context, q dbQuerier, qs querySet, mi *m
 	tables := newDbTables(mi, d.ins)
 
 	var (
-		cols  []string
-		infos []*models.FieldInfo
+		cols     []string
+		infos    []*models.FieldInfo
+		colsArgs []interface{}
 	)
 
 	hasExprs := len(exprs) > 0
+	// the locations of the time buckets with a timezone by the index of their columns
+	locs := make(map[int]*time.Location)
+	// the indexes of the columns of ValuesBool
+	bools := make(map[int]bool)
 
 	Q := d.ins.TableQuote()
 
@@ -1904,6 +3251,33 @@ func (d *dbBase) ReadValues(ctx context.Context, q dbQuerier, qs querySet, mi *m
 		cols = make([]string, 0, len(exprs))
 		infos = make([]*models.FieldInfo, 0, len(exprs))
 		for _, ex := range exprs {
+			if bucketSQL, fi, loc, ok := tables.getTimeBucketSQL(qs.buckets, ex, tz); ok {
+				if loc != nil {
+					locs[len(cols)] = loc
+				}
+				cols = append(cols, fmt.Sprintf("%s %s%s%s", bucketSQL, Q, ex, Q))
+				infos = append(infos, fi)
+				continue
+			}
+			if tzSQL, ok := tables.getTimezoneSQL(qs.tzColumns, ex, tz); ok {
+				cols = append(cols, fmt.Sprintf("%s %s%s%s", tzSQL, Q, ex, Q))
+				// the wall time of another timezone is not converted like the column
+				infos = append(infos, nil)
+				continue
+			}
+			if caseSQL, params, ok := tables.getCaseSQL(qs.cases, ex, tz); ok {
+				cols = append(cols, fmt.Sprintf("%s %s%s%s", caseSQL, Q, ex, Q))
+				infos = append(infos, nil)
+				colsArgs = append(colsArgs, params...)
+				continue
+			}
+			if boolSQL, params, ok := tables.getBoolSQL(qs.bools, ex, tz); ok {
+				bools[len(cols)] = true
+				cols = append(cols, fmt.Sprintf("%s %s%s%s", boolSQL, Q, ex, Q))
+				infos = append(infos, nil)
+				colsArgs = append(colsArgs, params...)
+				continue
+			}
 			index, name, fi, suc := tables.parseExprs(mi, strings.Split(ex, ExprSep))
 			if !suc {
 				panic(fmt.Errorf("unknown field/column name `%s`", ex))
@@ -1921,6 +3295,8 @@ func (d *dbBase) ReadValues(ctx context.Context, q dbQuerier, qs querySet, mi *m
 	}
 
 	query, args := d.readValuesSQL(tables, cols, qs, mi, cond, tz)
+	// the params of the selected columns come before the ones of the conditions
+	args = append(colsArgs, args...)
 
 	rs, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -1934,6 +3310,16 @@ func (d *dbBase) ReadValues(ctx context.Context, q dbQuerier, qs querySet, mi *m
 
 	defer rs.Close()
 
+	convert := func(i int, val interface{}) (interface{}, error) {
+		if loc, ok := locs[i]; ok {
+			return wallTimeIn(val, loc)
+		}
+		if bools[i] {
+			return flagBool(val)
+		}
+		return d.convertValueFromDB(infos[i], val, tz)
+	}
+
 	var (
 		cnt     int64
 		columns []string
@@ -1955,11 +3341,9 @@ func (d *dbBase) ReadValues(ctx context.Context, q dbQuerier, qs querySet, mi *m
 		case 1:
 			params := make(Params, len(cols))
 			for i, ref := range refs {
-				fi := infos[i]
-
 				val := reflect.Indirect(reflect.ValueOf(ref)).Interface()
 
-				value, err := d.convertValueFromDB(fi, val, tz)
+				value, err := convert(i, val)
 				if err != nil {
 					panic(fmt.Errorf("db value convert failed `%v` %s", val, err.Error()))
 				}
@@ -1970,11 +3354,9 @@ func (d *dbBase) ReadValues(ctx context.Context, q dbQuerier, qs querySet, mi *m
 		case 2:
 			params := make(ParamsList, 0, len(cols))
 			for i, ref := range refs {
-				fi := infos[i]
-
 				val := reflect.Indirect(reflect.ValueOf(ref)).Interface()
 
-				value, err := d.convertValueFromDB(fi, val, tz)
+				value, err := convert(i, val)
 				if err != nil {
 					panic(fmt.Errorf("db value convert failed `%v` %s", val, err.Error()))
 				}
@@ -1984,11 +3366,9 @@ func (d *dbBase) ReadValues(ctx context.Context, q dbQuerier, qs querySet, mi *m
 			lists = append(lists, params)
 		case 3:
 			for i, ref := range refs {
-				fi := infos[i]
-
 				val := reflect.Indirect(reflect.ValueOf(ref)).Interface()
 
-				value, err := d.convertValueFromDB(fi, val, tz)
+				value, err := convert(i, val)
 				if err != nil {
 					panic(fmt.Errorf("db value convert failed `%v` %s", val, err.Error()))
 				}
@@ -2034,6 +3414,11 @@ func (d *dbBase) SupportUpdateJoin() bool {
 	return true
 }
 
+// SupportsInlineCollate flag of COLLATE on a column in comparisons.
+func (d *dbBase) SupportsInlineCollate() bool {
+	return true
+}
+
 func (d *dbBase) MaxLimit() uint64 {
 	return 18446744073709551615
 }
@@ -2043,11 +3428,42 @@ func (d *dbBase) TableQuote() string {
 	return "`"
 }
 
+// BoolLiteral return the sql literal of boolean value v.
+func (d *dbBase) BoolLiteral(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
 // ReplaceMarks replace value placeholder in parametered sql string.
 func (d *dbBase) ReplaceMarks(query *string) {
 	// default use `?` as mark, do nothing
 }
 
+// CursorSQL return the sql declaring the cursor name for the query appended to it,
+// fetching the next size rows from it and closing it, empty strings mean the driver does not support it.
+func (d *dbBase) CursorSQL(string, int) (string, string, string) {
+	return "", "", ""
+}
+
+// ExplainSQL return the keyword prepended to a query to get its plan, executing it when analyze,
+// empty string means the driver does not support it.
+func (d *dbBase) ExplainSQL(bool) string {
+	return ""
+}
+
+// SkipLockedSQL return the lock clause of a select locking the rows of the table alias
+// and skipping the rows locked by other transactions, empty string means the driver does not need or support it.
+func (d *dbBase) SkipLockedSQL(string) string {
+	return ""
+}
+
+// SupportReturning return whether insert sql can return the rows by RETURNING.
+func (d *dbBase) SupportReturning() bool {
+	return false
+}
+
 // flag of RETURNING sql.
 func (d *dbBase) HasReturningID(*models.ModelInfo, *string) bool {
 	return false
@@ -17,8 +17,10 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
@@ -99,6 +101,50 @@ func (ac *_dbCache) get(name string) (al *alias, ok bool) {
 	return
 }
 
+// get all the database aliases.
+func (ac *_dbCache) all() []*alias {
+	ac.mux.RLock()
+	defer ac.mux.RUnlock()
+	als := make([]*alias, 0, len(ac.cache))
+	for _, al := range ac.cache {
+		als = append(als, al)
+	}
+	return als
+}
+
+// drain the aliases then close their pools, see Shutdown.
+func (ac *_dbCache) shutdown(ctx context.Context) error {
+	als := ac.all()
+	for _, al := range als {
+		if al.DB.draining != nil {
+			al.DB.draining.Store(true)
+		}
+	}
+
+	var errs []error
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	// the connections in use are the ones of the running statements, of the rows not closed and of the transactions
+wait:
+	for _, al := range als {
+		for al.DB.DB.Stats().InUse > 0 {
+			select {
+			case <-ctx.Done():
+				errs = append(errs, fmt.Errorf("<orm.Shutdown> in-flight operations are not done: %w", ctx.Err()))
+				break wait
+			case <-ticker.C:
+			}
+		}
+	}
+
+	for _, al := range als {
+		if err := al.DB.DB.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("<orm.Shutdown> close db alias `%s`: %w", al.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // get default alias.
 func (ac *_dbCache) getDefault() (al *alias) {
 	al, _ = ac.get("default")
@@ -110,6 +156,39 @@ type DB struct {
 	DB                  *sql.DB
 	stmtDecorators      *lru.Cache
 	stmtDecoratorsLimit int
+	// set by Shutdown, the new operations are rejected with ErrShutdown
+	draining *atomic.Bool
+	// set by SetMaxConcurrentQueries, nil when the queries are not limited
+	limiter *atomic.Pointer[queryLimiter]
+}
+
+// the semaphore of the queries running at the same time on a db.
+type queryLimiter struct {
+	sem   chan struct{}
+	block bool
+}
+
+// acquire a slot of the limiter, waiting for it until ctx is done when the limiter blocks.
+// the returned func releases the slot.
+func (l *queryLimiter) acquire(ctx context.Context) (func(), error) {
+	select {
+	case l.sem <- struct{}{}:
+		return l.release, nil
+	default:
+	}
+	if !l.block {
+		return nil, ErrQueryThrottled
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return l.release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *queryLimiter) release() {
+	<-l.sem
 }
 
 var (
@@ -117,11 +196,32 @@ var (
 	_ txer      = new(DB)
 )
 
+// the db is draining by Shutdown, the transactions begun before go on.
+func (d *DB) isDraining() bool {
+	return d.draining != nil && d.draining.Load()
+}
+
+// wait for a slot of the queries running at the same time, see SetMaxConcurrentQueries.
+// the returned func releases the slot.
+func (d *DB) acquireQuery(ctx context.Context) (func(), error) {
+	if d.limiter == nil {
+		return func() {}, nil
+	}
+	l := d.limiter.Load()
+	if l == nil {
+		return func() {}, nil
+	}
+	return l.acquire(ctx)
+}
+
 func (d *DB) Begin() (*sql.Tx, error) {
-	return d.DB.Begin()
+	return d.BeginTx(context.Background(), nil)
 }
 
 func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if d.isDraining() {
+		return nil, ErrShutdown
+	}
 	return d.DB.BeginTx(ctx, opts)
 }
 
@@ -157,11 +257,19 @@ func (d *DB) getStmtDecorator(query string) (*stmtDecorator, error) {
 	return sd, nil
 }
 
+// a DB sharing the connections of d, executing queries without the prepared statement cache.
+func (d *DB) withoutStmtCache() *DB {
+	return &DB{RWMutex: d.RWMutex, DB: d.DB, draining: d.draining, limiter: d.limiter}
+}
+
 func (d *DB) Prepare(query string) (*sql.Stmt, error) {
-	return d.DB.Prepare(query)
+	return d.PrepareContext(context.Background(), query)
 }
 
 func (d *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if d.isDraining() {
+		return nil, ErrShutdown
+	}
 	return d.DB.PrepareContext(ctx, query)
 }
 
@@ -170,6 +278,14 @@ func (d *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
 }
 
 func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if d.isDraining() {
+		return nil, ErrShutdown
+	}
+	release, err := d.acquireQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	if d.stmtDecorators == nil {
 		return d.DB.ExecContext(ctx, query, args...)
 	}
@@ -188,6 +304,14 @@ func (d *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
 }
 
 func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if d.isDraining() {
+		return nil, ErrShutdown
+	}
+	release, err := d.acquireQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	if d.stmtDecorators == nil {
 		return d.DB.QueryContext(ctx, query, args...)
 	}
@@ -206,6 +330,15 @@ func (d *DB) QueryRow(query string, args ...interface{}) *sql.Row {
 }
 
 func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if d.isDraining() {
+		// a Row cannot be made with an error, the replay db returns one carrying it
+		return getReplayDB().QueryRowContext(ctx, "", ErrShutdown)
+	}
+	release, err := d.acquireQuery(ctx)
+	if err != nil {
+		return getReplayDB().QueryRowContext(ctx, "", err)
+	}
+	defer release()
 	if d.stmtDecorators == nil {
 		return d.DB.QueryRowContext(ctx, query, args...)
 	}
@@ -282,19 +415,32 @@ func (t *TxDB) QueryRowContext(ctx context.Context, query string, args ...interf
 }
 
 type alias struct {
-	Name            string
-	Driver          DriverType
-	DriverName      string
-	DataSource      string
-	MaxIdleConns    int
-	MaxOpenConns    int
-	ConnMaxLifetime time.Duration
-	ConnMaxIdletime time.Duration
-	StmtCacheSize   int
-	DB              *DB
-	DbBaser         dbBaser
-	TZ              *time.Location
-	Engine          string
+	Name             string
+	Driver           DriverType
+	DriverName       string
+	DataSource       string
+	MaxIdleConns     int
+	MaxOpenConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdletime  time.Duration
+	StmtCacheSize    int
+	RowWarnThreshold int
+	EntityCache      EntityCacher
+	CheckUnique      bool
+	SkipInvalid      bool
+	ConnectAttempts  int
+	ConnectBackoff   time.Duration
+	DB               *DB
+	DbBaser          dbBaser
+	TZ               *time.Location
+	Engine           string
+
+	// the queries running at the same time on the pool, 0 for no limit
+	MaxConcurrentQueries int
+	// syncdb creates an index on the columns of rel(fk)
+	IndexForeignKeys bool
+	// the version of sqlite detected by the registration, empty for the other drivers
+	SqliteVersion string
 }
 
 func detectTZ(al *alias) {
@@ -337,7 +483,13 @@ func detectTZ(al *alias) {
 			al.Engine = "INNODB"
 		}
 
-	case DRSqlite, DROracle:
+	case DRSqlite:
+		al.TZ = time.UTC
+
+		row := al.DB.QueryRow("SELECT sqlite_version()")
+		row.Scan(&al.SqliteVersion)
+
+	case DROracle:
 		al.TZ = time.UTC
 
 	case DRPostgres:
@@ -374,8 +526,10 @@ func addAliasWthDB(aliasName, driverName string, db *sql.DB, params ...DBOption)
 func newAliasWithDb(aliasName, driverName string, db *sql.DB, params ...DBOption) (*alias, error) {
 	al := &alias{}
 	al.DB = &DB{
-		RWMutex: new(sync.RWMutex),
-		DB:      db,
+		RWMutex:  new(sync.RWMutex),
+		DB:       db,
+		draining: new(atomic.Bool),
+		limiter:  new(atomic.Pointer[queryLimiter]),
 	}
 
 	for _, p := range params {
@@ -407,7 +561,7 @@ func newAliasWithDb(aliasName, driverName string, db *sql.DB, params ...DBOption
 		return nil, fmt.Errorf("driver name `%s` have not registered", driverName)
 	}
 
-	err := db.Ping()
+	err := pingWithRetry(db, al.ConnectAttempts, al.ConnectBackoff)
 	if err != nil {
 		return nil, fmt.Errorf("Register db Ping `%s`, %s", aliasName, err.Error())
 	}
@@ -417,6 +571,18 @@ func newAliasWithDb(aliasName, driverName string, db *sql.DB, params ...DBOption
 	return al, nil
 }
 
+// ping db up to attempts times, waiting backoff after the first failure and doubling it after each next one.
+func pingWithRetry(db *sql.DB, attempts int, backoff time.Duration) error {
+	err := db.Ping()
+	for i := 1; i < attempts && err != nil; i++ {
+		DebugLog.Printf("Ping db failed, retry %d/%d in %s: %s\n", i, attempts-1, backoff, err.Error())
+		time.Sleep(backoff)
+		backoff *= 2
+		err = db.Ping()
+	}
+	return err
+}
+
 // SetMaxIdleConns Change the max idle conns for *sql.DB, use specify database alias name
 // Deprecated you should not use this, we will remove it in the future
 func SetMaxIdleConns(aliasName string, maxIdleConns int) {
@@ -443,6 +609,16 @@ func (al *alias) SetMaxOpenConns(maxOpenConns int) {
 	al.DB.DB.SetMaxOpenConns(maxOpenConns)
 }
 
+// limit the queries running at the same time on the pool to n, see SetMaxConcurrentQueries.
+func (al *alias) SetMaxConcurrentQueries(n int, block bool) {
+	al.MaxConcurrentQueries = n
+	if n <= 0 {
+		al.DB.limiter.Store(nil)
+		return
+	}
+	al.DB.limiter.Store(&queryLimiter{sem: make(chan struct{}, n), block: block})
+}
+
 func (al *alias) SetConnMaxLifetime(lifeTime time.Duration) {
 	al.ConnMaxLifetime = lifeTime
 	al.DB.DB.SetConnMaxLifetime(lifeTime)
@@ -491,6 +667,18 @@ end:
 	return err
 }
 
+// Shutdown stops all the db aliases accepting new operations, which get ErrShutdown,
+// waits for the in-flight queries and transactions until ctx is done, and then closes the pools of the aliases.
+// The transactions begun before go on until they are committed or rolled back.
+// The pools are closed even when ctx is done first, the error has ctx.Err() then.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	err := orm.Shutdown(ctx)
+func Shutdown(ctx context.Context) error {
+	return dataBaseCache.shutdown(ctx)
+}
+
 // RegisterDriver Register a database driver use specify driver name, this can be definition the driver is which database type.
 func RegisterDriver(driverName string, typ DriverType) error {
 	if t, ok := drivers[driverName]; !ok {
@@ -503,6 +691,52 @@ func RegisterDriver(driverName string, typ DriverType) error {
 	return nil
 }
 
+// WarmupConnections open and ping n connections of the database alias, then put them back to the pool,
+// so that the first queries do not wait for new connections.
+// The pool keeps at most MaxIdleConns of them, n is limited to MaxOpenConns if it is set.
+func WarmupConnections(aliasName string, n int) error {
+	al, ok := dataBaseCache.get(aliasName)
+	if !ok {
+		return fmt.Errorf("DataBase alias name `%s` not registered", aliasName)
+	}
+	if al.MaxOpenConns > 0 && n > al.MaxOpenConns {
+		n = al.MaxOpenConns
+	}
+	ctx := context.Background()
+	conns := make([]*sql.Conn, 0, n)
+	defer func() {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}()
+	for i := 0; i < n; i++ {
+		conn, err := al.DB.DB.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		conns = append(conns, conn)
+		if err = conn.PingContext(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetMaxConcurrentQueries Limit the statements running at the same time on the pool of the database alias to n,
+// apart from the open connections managed by the driver, so the app throttles itself to protect the database.
+// When the limit is hit, a statement fails with ErrQueryThrottled, or waits for a running one to end if block is true,
+// until its context is done.
+// The slot is held while the statement executes, not while its rows are read; the transactions are not limited.
+// n <= 0 removes the limit.
+func SetMaxConcurrentQueries(aliasName string, n int, block bool) error {
+	if al, ok := dataBaseCache.get(aliasName); ok {
+		al.SetMaxConcurrentQueries(n, block)
+	} else {
+		return fmt.Errorf("DataBase alias name `%s` not registered", aliasName)
+	}
+	return nil
+}
+
 // SetDataBaseTZ Change the database default used timezone
 func SetDataBaseTZ(aliasName string, tz *time.Location) error {
 	if al, ok := dataBaseCache.get(aliasName); ok {
@@ -513,6 +747,59 @@ func SetDataBaseTZ(aliasName string, tz *time.Location) error {
 	return nil
 }
 
+// SetRowWarnThreshold Log a warning when All() returns more than n rows, use specify database alias name.
+// n <= 0 disables the warning.
+func SetRowWarnThreshold(aliasName string, n int) error {
+	if al, ok := dataBaseCache.get(aliasName); ok {
+		al.RowWarnThreshold = n
+	} else {
+		return fmt.Errorf("DataBase alias name `%s` not registered", aliasName)
+	}
+	return nil
+}
+
+// SetEntityCache Set the cache of the models read by pk, use specify database alias name.
+// nil disables the cache.
+func SetEntityCache(aliasName string, cache EntityCacher) error {
+	if al, ok := dataBaseCache.get(aliasName); ok {
+		al.EntityCache = cache
+	} else {
+		return fmt.Errorf("DataBase alias name `%s` not registered", aliasName)
+	}
+	return nil
+}
+
+// SetSkipInvalid Set whether InsertMulti inserts the valid rows of the models when some are invalid, use specify database alias name.
+func SetSkipInvalid(aliasName string, skip bool) error {
+	if al, ok := dataBaseCache.get(aliasName); ok {
+		al.SkipInvalid = skip
+	} else {
+		return fmt.Errorf("DataBase alias name `%s` not registered", aliasName)
+	}
+	return nil
+}
+
+// SetCheckUnique Set whether Insert checks the unique columns of the model before inserting it, use specify database alias name.
+// false disables the checks, which cost a query per unique column.
+func SetCheckUnique(aliasName string, check bool) error {
+	if al, ok := dataBaseCache.get(aliasName); ok {
+		al.CheckUnique = check
+	} else {
+		return fmt.Errorf("DataBase alias name `%s` not registered", aliasName)
+	}
+	return nil
+}
+
+// SetIndexForeignKeys Set whether syncdb creates an index on every rel(fk) column of the models, use specify database alias name.
+func SetIndexForeignKeys(aliasName string, index bool) error {
+	if al, ok := dataBaseCache.get(aliasName); ok {
+		al.IndexForeignKeys = index
+	} else {
+		return fmt.Errorf("DataBase alias name `%s` not registered", aliasName)
+	}
+	return nil
+}
+
 // GetDB Get *sql.DB from registered database by db alias name.
 // Use "default" as alias name if you not Set.
 func GetDB(aliasNames ...string) (*sql.DB, error) {
@@ -610,3 +897,62 @@ func MaxStmtCacheSize(v int) DBOption {
 		al.StmtCacheSize = v
 	}
 }
+
+// MaxConcurrentQueries return a hint about MaxConcurrentQueries, see SetMaxConcurrentQueries.
+func MaxConcurrentQueries(n int, block bool) DBOption {
+	return func(al *alias) {
+		al.SetMaxConcurrentQueries(n, block)
+	}
+}
+
+// RowWarnThreshold return a hint about RowWarnThreshold
+func RowWarnThreshold(v int) DBOption {
+	return func(al *alias) {
+		al.RowWarnThreshold = v
+	}
+}
+
+// EntityCache return a hint about EntityCache
+func EntityCache(cache EntityCacher) DBOption {
+	return func(al *alias) {
+		al.EntityCache = cache
+	}
+}
+
+// SkipInvalid return a hint about SkipInvalid,
+// InsertMulti then inserts the valid rows and returns their number with the MultiError of the invalid ones,
+// instead of inserting none of them.
+func SkipInvalid(skip bool) DBOption {
+	return func(al *alias) {
+		al.SkipInvalid = skip
+	}
+}
+
+// CheckUnique return a hint about CheckUnique,
+// Insert then returns an *ErrDuplicate when a row has the value of a unique column of the model,
+// instead of the error of the unique constraint. The checks are best-effort, the rows inserted at the same time are not seen.
+func CheckUnique(check bool) DBOption {
+	return func(al *alias) {
+		al.CheckUnique = check
+	}
+}
+
+// IndexForeignKeys return a hint about IndexForeignKeys,
+// syncdb then creates the index table_column on every rel(fk) column, which is not indexed by the databases
+// as syncdb declares no FOREIGN KEY constraint, also on mysql whose InnoDB only indexes the constrained columns.
+// The columns which are unique or lead another index of the model are skipped.
+func IndexForeignKeys(index bool) DBOption {
+	return func(al *alias) {
+		al.IndexForeignKeys = index
+	}
+}
+
+// WithConnectRetry return a hint about ConnectAttempts and ConnectBackoff,
+// the db is pinged up to maxAttempts times when registering it, so it may be unavailable for a while at startup.
+// It waits backoff after the first failed ping, and twice as long after each next one.
+func WithConnectRetry(maxAttempts int, backoff time.Duration) DBOption {
+	return func(al *alias) {
+		al.ConnectAttempts = maxAttempts
+		al.ConnectBackoff = backoff
+	}
+}
@@ -15,6 +15,12 @@
 package orm
 
 import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -37,6 +43,43 @@ func TestRegisterDataBase(t *testing.T) {
 	assert.Equal(t, al.ConnMaxIdletime, time.Minute)
 }
 
+func TestRegisterDataBaseRowWarnThreshold(t *testing.T) {
+	aliasName := "TestRegisterDataBase_RowWarnThreshold"
+	err := RegisterDataBase(aliasName, DBARGS.Driver, DBARGS.Source, RowWarnThreshold(100))
+	assert.Nil(t, err)
+
+	al := getDbAlias(aliasName)
+	assert.NotNil(t, al)
+	assert.Equal(t, al.RowWarnThreshold, 100)
+
+	err = SetRowWarnThreshold(aliasName, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, al.RowWarnThreshold, 10)
+
+	err = SetRowWarnThreshold("not-exist", 10)
+	assert.NotNil(t, err)
+}
+
+func TestWarmupConnections(t *testing.T) {
+	aliasName := "TestWarmupConnections"
+	err := RegisterDataBase(aliasName, DBARGS.Driver, DBARGS.Source, MaxIdleConnections(5), MaxOpenConnections(10))
+	assert.Nil(t, err)
+
+	err = WarmupConnections(aliasName, 3)
+	assert.Nil(t, err)
+	stats := getDbAlias(aliasName).DB.DB.Stats()
+	assert.Equal(t, 3, stats.OpenConnections)
+	assert.Equal(t, 3, stats.Idle)
+
+	// the idle connections are reused
+	err = WarmupConnections(aliasName, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, getDbAlias(aliasName).DB.DB.Stats().OpenConnections)
+
+	err = WarmupConnections("not-exist", 1)
+	assert.NotNil(t, err)
+}
+
 func TestRegisterDataBaseMaxStmtCacheSizeNegative1(t *testing.T) {
 	aliasName := "TestRegisterDataBase_MaxStmtCacheSizeNegative1"
 	err := RegisterDataBase(aliasName, DBARGS.Driver, DBARGS.Source, MaxStmtCacheSize(-1))
@@ -86,3 +129,234 @@ func TestDBCache(t *testing.T) {
 	assert.NotNil(t, al)
 	assert.True(t, ok)
 }
+
+// connector failing the first fails connects.
+type flakyConnector struct {
+	driver sqldriver.Driver
+	dsn    string
+	fails  int
+	calls  int
+}
+
+func (c *flakyConnector) Connect(context.Context) (sqldriver.Conn, error) {
+	c.calls++
+	if c.calls <= c.fails {
+		return nil, errors.New("connection refused")
+	}
+	return c.driver.Open(c.dsn)
+}
+
+func (c *flakyConnector) Driver() sqldriver.Driver {
+	return c.driver
+}
+
+func TestWithConnectRetry(t *testing.T) {
+	db, err := sql.Open(DBARGS.Driver, DBARGS.Source)
+	assert.Nil(t, err)
+	dr := db.Driver()
+	db.Close()
+
+	connector := &flakyConnector{driver: dr, dsn: DBARGS.Source, fails: 2}
+	err = AddAliasWthDB("TestWithConnectRetry", DBARGS.Driver, sql.OpenDB(connector),
+		WithConnectRetry(3, time.Millisecond))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, connector.calls)
+	al := getDbAlias("TestWithConnectRetry")
+	assert.Equal(t, 3, al.ConnectAttempts)
+	assert.Equal(t, time.Millisecond, al.ConnectBackoff)
+
+	connector = &flakyConnector{driver: dr, dsn: DBARGS.Source, fails: 3}
+	err = AddAliasWthDB("TestWithConnectRetry_fail", DBARGS.Driver, sql.OpenDB(connector),
+		WithConnectRetry(3, time.Millisecond))
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, connector.calls)
+
+	connector = &flakyConnector{driver: dr, dsn: DBARGS.Source, fails: 1}
+	err = AddAliasWthDB("TestWithConnectRetry_none", DBARGS.Driver, sql.OpenDB(connector))
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, connector.calls)
+}
+
+func TestHealthCheck(t *testing.T) {
+	db, err := sql.Open(DBARGS.Driver, DBARGS.Source)
+	assert.Nil(t, err)
+	dr := db.Driver()
+	db.Close()
+
+	connector := &flakyConnector{driver: dr, dsn: DBARGS.Source}
+	db = sql.OpenDB(connector)
+	err = AddAliasWthDB("TestHealthCheck", DBARGS.Driver, db, MaxOpenConnections(4))
+	assert.Nil(t, err)
+	// every ping opens a connection
+	db.SetMaxIdleConns(0)
+
+	report := new(orm).HealthCheck(context.Background())
+	health := report.Aliases["TestHealthCheck"]
+	assert.True(t, health.Healthy)
+	assert.Empty(t, health.Error)
+	assert.True(t, health.Latency > 0)
+	assert.Equal(t, 4, health.MaxOpenConnections)
+	assert.Equal(t, 0, health.InUse)
+	assert.Equal(t, float64(0), health.Saturation)
+
+	// the db goes down
+	connector.fails = connector.calls + 10
+	report = new(orm).HealthCheck(context.Background())
+	assert.False(t, report.Healthy)
+	health = report.Aliases["TestHealthCheck"]
+	assert.False(t, health.Healthy)
+	assert.Equal(t, "connection refused", health.Error)
+}
+
+func TestShutdown(t *testing.T) {
+	newCache := func(name string) (*_dbCache, Ormer, *alias) {
+		db, err := sql.Open("sqlite3", t.TempDir()+"/shutdown.db")
+		assert.Nil(t, err)
+		al, err := newAliasWithDb(name, "sqlite3", db)
+		assert.Nil(t, err)
+		ac := &_dbCache{cache: make(map[string]*alias)}
+		assert.True(t, ac.add(name, al))
+		return ac, newDBWithAlias(al), al
+	}
+
+	ac, o, al := newCache("shutdown")
+	_, err := o.Raw("CREATE TABLE item (id INTEGER PRIMARY KEY, name TEXT)").Exec()
+	assert.Nil(t, err)
+
+	// the transaction in flight completes while the new operations are rejected
+	txOrm, err := o.Begin()
+	assert.Nil(t, err)
+	_, err = txOrm.Raw("INSERT INTO item (name) VALUES (?)", "in flight").Exec()
+	assert.Nil(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- ac.shutdown(context.Background())
+	}()
+	for !al.DB.isDraining() {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err = o.Raw("INSERT INTO item (name) VALUES (?)", "new").Exec()
+	assert.True(t, errors.Is(err, ErrShutdown))
+	_, err = o.Begin()
+	assert.True(t, errors.Is(err, ErrShutdown))
+	var num int
+	assert.NotNil(t, o.Raw("SELECT COUNT(*) FROM item").QueryRow(&num))
+	err = al.DB.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM item").Scan(&num)
+	assert.True(t, errors.Is(err, ErrShutdown))
+
+	_, err = txOrm.Raw("INSERT INTO item (name) VALUES (?)", "in flight too").Exec()
+	assert.Nil(t, err)
+	assert.Nil(t, txOrm.Raw("SELECT COUNT(*) FROM item").QueryRow(&num))
+	assert.Equal(t, 2, num)
+
+	select {
+	case <-done:
+		t.Fatal("shutdown returned before the transaction completes")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Nil(t, txOrm.Commit())
+	select {
+	case err = <-done:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown is not done after the transaction completes")
+	}
+	assert.NotNil(t, al.DB.DB.Ping())
+
+	// the pool is closed at the deadline
+	ac, o, al = newCache("shutdown-deadline")
+	txOrm, err = o.Begin()
+	assert.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = ac.shutdown(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.NotNil(t, al.DB.DB.Ping())
+	_ = txOrm.Rollback()
+}
+
+func TestSetMaxConcurrentQueries(t *testing.T) {
+	db, err := sql.Open("sqlite3", t.TempDir()+"/throttle.db")
+	assert.Nil(t, err)
+	al, err := newAliasWithDb("throttle", "sqlite3", db, MaxConcurrentQueries(2, false))
+	assert.Nil(t, err)
+	o := newDBWithAlias(al)
+	_, err = o.Raw("CREATE TABLE item (id INTEGER PRIMARY KEY, name TEXT)").Exec()
+	assert.Nil(t, err)
+
+	// the query over the limit is throttled
+	release1, err := al.DB.acquireQuery(context.Background())
+	assert.Nil(t, err)
+	release2, err := al.DB.acquireQuery(context.Background())
+	assert.Nil(t, err)
+	_, err = o.Raw("INSERT INTO item (name) VALUES (?)", "throttled").Exec()
+	assert.True(t, errors.Is(err, ErrQueryThrottled))
+	var num int
+	assert.NotNil(t, o.Raw("SELECT COUNT(*) FROM item").QueryRow(&num))
+	err = al.DB.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM item").Scan(&num)
+	assert.True(t, errors.Is(err, ErrQueryThrottled))
+	release1()
+	_, err = o.Raw("INSERT INTO item (name) VALUES (?)", "run").Exec()
+	assert.Nil(t, err)
+
+	// the query over the limit waits for a slot until its context is done
+	al.SetMaxConcurrentQueries(1, true)
+	release1, err = al.DB.acquireQuery(context.Background())
+	assert.Nil(t, err)
+	done := make(chan error)
+	go func() {
+		_, err := o.Raw("INSERT INTO item (name) VALUES (?)", "blocked").Exec()
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Fatal("the query over the limit did not wait for a slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release1()
+	select {
+	case err = <-done:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the query did not run after the slot is released")
+	}
+	release1, err = al.DB.acquireQuery(context.Background())
+	assert.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = al.DB.ExecContext(ctx, "INSERT INTO item (name) VALUES (?)", "timeout")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	release1()
+	release2()
+
+	// the queries running at the same time are bounded to n
+	al.SetMaxConcurrentQueries(3, true)
+	var running, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := al.DB.acquireQuery(context.Background())
+			assert.Nil(t, err)
+			n := atomic.AddInt32(&running, 1)
+			for p := atomic.LoadInt32(&peak); n > p && !atomic.CompareAndSwapInt32(&peak, p, n); p = atomic.LoadInt32(&peak) {
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			release()
+		}()
+	}
+	wg.Wait()
+	assert.True(t, peak <= 3)
+	assert.True(t, peak > 0)
+
+	// n <= 0 removes the limit
+	al.SetMaxConcurrentQueries(0, false)
+	assert.Equal(t, 0, al.MaxConcurrentQueries)
+	_, err = o.Raw("INSERT INTO item (name) VALUES (?)", "unlimited").Exec()
+	assert.Nil(t, err)
+	assert.NotNil(t, SetMaxConcurrentQueries("not-registered", 1, false))
+}
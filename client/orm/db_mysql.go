@@ -24,12 +24,13 @@ import (
 )
 
 // mysql operators.
+// the escape character of LIKE is the backslash, written '\\' in a mysql string.
 var mysqlOperators = map[string]string{
 	"exact":       "= ?",
-	"iexact":      "LIKE ?",
+	"iexact":      "LIKE ? ESCAPE '\\\\'",
 	"strictexact": "= BINARY ?",
-	"contains":    "LIKE BINARY ?",
-	"icontains":   "LIKE ?",
+	"contains":    "LIKE BINARY ? ESCAPE '\\\\'",
+	"icontains":   "LIKE ? ESCAPE '\\\\'",
 	// "regex":       "REGEXP BINARY ?",
 	// "iregex":      "REGEXP ?",
 	"gt":          "> ?",
@@ -38,10 +39,47 @@ var mysqlOperators = map[string]string{
 	"lte":         "<= ?",
 	"eq":          "= ?",
 	"ne":          "!= ?",
-	"startswith":  "LIKE BINARY ?",
-	"endswith":    "LIKE BINARY ?",
-	"istartswith": "LIKE ?",
-	"iendswith":   "LIKE ?",
+	"startswith":  "LIKE BINARY ? ESCAPE '\\\\'",
+	"endswith":    "LIKE BINARY ? ESCAPE '\\\\'",
+	"istartswith": "LIKE ? ESCAPE '\\\\'",
+	"iendswith":   "LIKE ? ESCAPE '\\\\'",
+	"gt_all":      "> ALL (%s)",
+	"gte_all":     ">= ALL (%s)",
+	"lt_all":      "< ALL (%s)",
+	"lte_all":     "<= ALL (%s)",
+	"ne_all":      "!= ALL (%s)",
+	"gt_any":      "> ANY (%s)",
+	"gte_any":     ">= ANY (%s)",
+	"lt_any":      "< ANY (%s)",
+	"lte_any":     "<= ANY (%s)",
+	"ne_any":      "!= ANY (%s)",
+	"before":      "< ?",
+	"after":       "> ?",
+	"hasall":      "= ?",
+	"hasany":      "<> 0",
+	"ilike_any":   "LIKE ?",
+	"soundex":     "= SOUNDEX(?)",
+	"trimeq":      "= TRIM(?)",
+}
+
+// mysql formats truncating datetime to the time bucket units.
+var mysqlTimeBucketFormats = map[string]string{
+	"minute": "%Y-%m-%d %H:%i:00",
+	"hour":   "%Y-%m-%d %H:00:00",
+	"day":    "%Y-%m-%d 00:00:00",
+	"month":  "%Y-%m-01 00:00:00",
+	"year":   "%Y-01-01 00:00:00",
+}
+
+// mysql CAST types of castTypes.
+var mysqlCastTypes = map[string]string{
+	"int":      "SIGNED",
+	"bigint":   "SIGNED",
+	"decimal":  "DECIMAL(65, 30)",
+	"float":    "DOUBLE",
+	"string":   "CHAR",
+	"date":     "DATE",
+	"datetime": "DATETIME",
 }
 
 // mysql column field types.
@@ -64,7 +102,11 @@ var mysqlTypes = map[string]string{
 	"uint64":              "bigint unsigned",
 	"float64":             "double precision",
 	"float64-decimal":     "numeric(%d, %d)",
+	"big.Rat-decimal":     "numeric(%d, %d)",
 	"time.Time-precision": "datetime(%d)",
+	"[]byte":              "longblob",
+	"[]byte-size":         "binary(%d)",
+	"set":                 "SET(%s)",
 }
 
 // mysql dbBaser implementation.
@@ -79,6 +121,13 @@ func (d *dbBaseMysql) OperatorSQL(operator string) string {
 	return mysqlOperators[operator]
 }
 
+// GenerateOperatorLeftCol mysql compares the soundex code of the column.
+func (d *dbBaseMysql) GenerateOperatorLeftCol(_ *models.FieldInfo, operator string, leftCol *string) {
+	if operator == "soundex" {
+		*leftCol = fmt.Sprintf("SOUNDEX(%s)", *leftCol)
+	}
+}
+
 // DbTypes Get mysql table field types.
 func (d *dbBaseMysql) DbTypes() map[string]string {
 	return mysqlTypes
@@ -95,6 +144,173 @@ func (d *dbBaseMysql) ShowColumnsQuery(table string) string {
 		"WHERE table_schema = DATABASE() AND table_name = '%s'", table)
 }
 
+// PositionSQL mysql orders by the FIELD of column in the values.
+func (d *dbBaseMysql) PositionSQL(col string, n int) string {
+	return fmt.Sprintf("FIELD(%s%s)", col, strings.Repeat(", ?", n))
+}
+
+// LengthSQL mysql LENGTH counts bytes, use CHAR_LENGTH for characters.
+func (d *dbBaseMysql) LengthSQL(col string) string {
+	return fmt.Sprintf("CHAR_LENGTH(%s)", col)
+}
+
+// ArrayLengthSQL mysql counts the elements of a json array by JSON_LENGTH.
+func (d *dbBaseMysql) ArrayLengthSQL(_ *models.FieldInfo, col string) string {
+	return fmt.Sprintf("JSON_LENGTH(%s)", col)
+}
+
+// ArrayElementSQL mysql extracts the element of a json array by its 0-based path,
+// unquoted to compare with the value as text.
+func (d *dbBaseMysql) ArrayElementSQL(_ *models.FieldInfo, col string, index int) string {
+	return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, '$[%d]'))", col, index)
+}
+
+// GroupConcatSQL mysql joins the values by GROUP_CONCAT, whose result is cut at group_concat_max_len.
+func (d *dbBaseMysql) GroupConcatSQL(col string, sep string, orderBy string) string {
+	return mysqlGroupConcatSQL(col, sep, orderBy)
+}
+
+func mysqlGroupConcatSQL(col string, sep string, orderBy string) string {
+	if orderBy != "" {
+		col += " ORDER BY " + orderBy
+	}
+	sep = strings.NewReplacer(`\`, `\\`, "'", "''").Replace(sep)
+	return fmt.Sprintf("GROUP_CONCAT(%s SEPARATOR '%s')", col, sep)
+}
+
+// TimeBucketSQL mysql has no date_trunc, format the column to the start of the unit.
+func (d *dbBaseMysql) TimeBucketSQL(col string, unit string) string {
+	return fmt.Sprintf("DATE_FORMAT(%s, '%s')", col, mysqlTimeBucketFormats[unit])
+}
+
+// CastSQL mysql casts to its own CAST types, such as SIGNED for integers.
+func (d *dbBaseMysql) CastSQL(col string, castType string) string {
+	return mysqlCastSQL(col, castType)
+}
+
+// ConcatSQL mysql concatenates by CONCAT, its || is OR unless PIPES_AS_CONCAT.
+func (d *dbBaseMysql) ConcatSQL(exprs []string) string {
+	return mysqlConcatSQL(exprs)
+}
+
+func mysqlConcatSQL(exprs []string) string {
+	return fmt.Sprintf("CONCAT(%s)", strings.Join(exprs, ", "))
+}
+
+func mysqlCastSQL(col string, castType string) string {
+	if typ, ok := mysqlCastTypes[castType]; ok {
+		return fmt.Sprintf("CAST(%s AS %s)", col, typ)
+	}
+	return ""
+}
+
+// ExplainSQL mysql runs the query for EXPLAIN ANALYZE since 8.0.18.
+func (d *dbBaseMysql) ExplainSQL(analyze bool) string {
+	return mysqlExplainSQL(analyze)
+}
+
+func mysqlExplainSQL(analyze bool) string {
+	if analyze {
+		return "EXPLAIN ANALYZE"
+	}
+	return "EXPLAIN"
+}
+
+// UnaccentSQL mysql compares by the accent insensitive collation of mysql 8.0.
+func (d *dbBaseMysql) UnaccentSQL(col string) string {
+	return fmt.Sprintf("%s COLLATE utf8mb4_0900_ai_ci = ?", col)
+}
+
+// JSONValidSQL mysql checks the document by JSON_VALID, it has no IS JSON.
+func (d *dbBaseMysql) JSONValidSQL(fi *models.FieldInfo, col string) string {
+	return fmt.Sprintf("JSON_VALID(%s) = 1", col)
+}
+
+// JSONExistsSQL mysql checks the json path by JSON_CONTAINS_PATH.
+func (d *dbBaseMysql) JSONExistsSQL(col string, path string) (string, []interface{}) {
+	return fmt.Sprintf("JSON_CONTAINS_PATH(%s, 'one', ?)", col), []interface{}{path}
+}
+
+// SetIncludesSQL mysql finds the value in the SET or comma-separated column by FIND_IN_SET.
+func (d *dbBaseMysql) SetIncludesSQL(col string, elem string) (string, []interface{}) {
+	return fmt.Sprintf("FIND_IN_SET(?, %s) > 0", col), []interface{}{elem}
+}
+
+// JSONEqualSQL mysql compares the json values, the keys of objects are sorted when they are stored.
+func (d *dbBaseMysql) JSONEqualSQL(col string, doc string) (string, []interface{}) {
+	return fmt.Sprintf("%s = CAST(? AS JSON)", col), []interface{}{doc}
+}
+
+// DatePartSQL mysql WEEK mode 3 is the week of ISO-8601.
+func (d *dbBaseMysql) DatePartSQL(part string, col string) string {
+	return mysqlDatePartSQL(part, col)
+}
+
+func mysqlDatePartSQL(part string, col string) string {
+	switch part {
+	case "quarter":
+		return fmt.Sprintf("QUARTER(%s)", col)
+	case "isoweek":
+		return fmt.Sprintf("WEEK(%s, 3)", col)
+	case "age":
+		return fmt.Sprintf("TIMESTAMPDIFF(YEAR, %s, CURDATE())", col)
+	}
+	return ""
+}
+
+// UpsertSQL mysql updates the row of a duplicate key, of any unique index.
+func (d *dbBaseMysql) UpsertSQL(mi *models.ModelInfo, names []string, conflicts []string) string {
+	return mysqlUpsertSQL(&d.dbBase, mi, names, conflicts)
+}
+
+func mysqlUpsertSQL(d *dbBase, mi *models.ModelInfo, names []string, conflicts []string) string {
+	updates := upsertUpdateCols(mi, names, conflicts)
+	if len(updates) == 0 {
+		// keep the row by updating nothing
+		updates = conflicts[:1]
+	}
+	sets := make([]string, len(updates))
+	for i, col := range updates {
+		sets[i] = fmt.Sprintf("`%s` = VALUES(`%s`)", col, col)
+	}
+	return d.upsertInsertSQL(mi, names) + " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+// TimezoneConvertSQL mysql converts by CONVERT_TZ, the named timezones need the timezone tables.
+func (d *dbBaseMysql) TimezoneConvertSQL(col string, from string, tz string) string {
+	return fmt.Sprintf("CONVERT_TZ(%s, '%s', '%s')", col, from, tz)
+}
+
+// SubstringIndexSQL mysql finds the substring from pos by LOCATE.
+func (d *dbBaseMysql) SubstringIndexSQL(col string, pos int) string {
+	return fmt.Sprintf("LOCATE(?, %s, %d)", col, pos)
+}
+
+// IntervalSQL mysql adds INTERVAL to NOW().
+func (d *dbBaseMysql) IntervalSQL(expr string, amount int, unit IntervalUnit) string {
+	return mysqlIntervalSQL(expr, amount, unit)
+}
+
+func mysqlIntervalSQL(expr string, amount int, unit IntervalUnit) string {
+	if expr == "" {
+		expr = "NOW()"
+	}
+	sign := "+"
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s %s INTERVAL %d %s", expr, sign, amount, strings.ToUpper(string(unit)))
+}
+
+// AdvisoryLockSQL mysql takes named locks by GET_LOCK without waiting.
+func (d *dbBaseMysql) AdvisoryLockSQL() (string, string) {
+	return mysqlAdvisoryLockSQL()
+}
+
+func mysqlAdvisoryLockSQL() (string, string) {
+	return "SELECT GET_LOCK(?, 0)", "SELECT RELEASE_LOCK(?)"
+}
+
 // IndexExists execute sql to check index exist.
 func (d *dbBaseMysql) IndexExists(ctx context.Context, db dbQuerier, table string, name string) bool {
 	row := db.QueryRowContext(ctx, "SELECT count(*) FROM information_schema.statistics "+
@@ -152,7 +368,7 @@ func (d *dbBaseMysql) InsertOrUpdate(ctx context.Context, q dbQuerier, mi *model
 	columns := strings.Join(names, sep)
 
 	// conflitValue maybe is an int,can`t use fmt.Sprintf
-	query := fmt.Sprintf("INSERT INTO %s%s%s (%s%s%s) VALUES (%s) %s "+qupdates, Q, mi.Table, Q, Q, columns, Q, qmarks, iouStr)
+	query := fmt.Sprintf("INSERT INTO %s (%s%s%s) VALUES (%s) %s "+qupdates, d.TableSQL(mi.Table), Q, columns, Q, qmarks, iouStr)
 
 	d.ins.ReplaceMarks(&query)
 
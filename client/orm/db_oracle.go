@@ -17,6 +17,7 @@ package orm
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/beego/beego/v2/client/orm/internal/models"
@@ -31,7 +32,50 @@ var oracleOperators = map[string]string{
 	"gte":         ">= ?",
 	"lt":          "< ?",
 	"lte":         "<= ?",
-	"//iendswith": "LIKE ?",
+	"iexact":      "= UPPER(?)",
+	"contains":    "LIKE ? ESCAPE '\\'",
+	"icontains":   "LIKE UPPER(?) ESCAPE '\\'",
+	"startswith":  "LIKE ? ESCAPE '\\'",
+	"endswith":    "LIKE ? ESCAPE '\\'",
+	"istartswith": "LIKE UPPER(?) ESCAPE '\\'",
+	"iendswith":   "LIKE UPPER(?) ESCAPE '\\'",
+	"gt_all":      "> ALL (%s)",
+	"gte_all":     ">= ALL (%s)",
+	"lt_all":      "< ALL (%s)",
+	"lte_all":     "<= ALL (%s)",
+	"ne_all":      "<> ALL (%s)",
+	"gt_any":      "> ANY (%s)",
+	"gte_any":     ">= ANY (%s)",
+	"lt_any":      "< ANY (%s)",
+	"lte_any":     "<= ANY (%s)",
+	"ne_any":      "<> ANY (%s)",
+	"before":      "< ?",
+	"after":       "> ?",
+	"hasall":      "= ?",
+	"hasany":      "<> 0",
+	"ilike_any":   "LIKE UPPER(?)",
+	"soundex":     "= SOUNDEX(?)",
+	"trimeq":      "= TRIM(?)",
+}
+
+// oracle TRUNC formats of the time bucket units.
+var oracleTimeBucketFormats = map[string]string{
+	"minute": "MI",
+	"hour":   "HH24",
+	"day":    "DD",
+	"month":  "MM",
+	"year":   "YYYY",
+}
+
+// oracle CAST types of castTypes.
+var oracleCastTypes = map[string]string{
+	"int":      "NUMBER(10)",
+	"bigint":   "NUMBER(19)",
+	"decimal":  "NUMBER",
+	"float":    "BINARY_DOUBLE",
+	"string":   "VARCHAR2(4000)",
+	"date":     "DATE",
+	"datetime": "TIMESTAMP",
 }
 
 // oracle column field types.
@@ -53,7 +97,10 @@ var oracleTypes = map[string]string{
 	"uint64":              "INTEGER",
 	"float64":             "NUMBER",
 	"float64-decimal":     "NUMBER(%d, %d)",
+	"big.Rat-decimal":     "NUMBER(%d, %d)",
 	"time.Time-precision": "TIMESTAMP(%d)",
+	"[]byte":              "BLOB",
+	"[]byte-size":         "RAW(%d)",
 }
 
 // oracle dbBaser
@@ -70,16 +117,159 @@ func newdbBaseOracle() dbBaser {
 	return b
 }
 
+// ArrayElementSQL oracle takes the scalar at the 0-based path of a json array by JSON_VALUE.
+func (d *dbBaseOracle) ArrayElementSQL(_ *models.FieldInfo, col string, index int) string {
+	return fmt.Sprintf("JSON_VALUE(%s, '$[%d]')", col, index)
+}
+
+// GroupConcatSQL oracle joins the values by the ordered-set aggregate LISTAGG, in no specific order without orderBy.
+func (d *dbBaseOracle) GroupConcatSQL(col string, sep string, orderBy string) string {
+	if orderBy == "" {
+		orderBy = "NULL"
+	}
+	return fmt.Sprintf("LISTAGG(%s, '%s') WITHIN GROUP (ORDER BY %s)", col, strings.ReplaceAll(sep, "'", "''"), orderBy)
+}
+
+// TimeBucketSQL oracle truncates datetime by TRUNC.
+func (d *dbBaseOracle) TimeBucketSQL(col string, unit string) string {
+	return fmt.Sprintf("TRUNC(%s, '%s')", col, oracleTimeBucketFormats[unit])
+}
+
+// PercentileSQL oracle has the ordered-set aggregate PERCENTILE_CONT.
+func (d *dbBaseOracle) PercentileSQL(col string, p float64) string {
+	return fmt.Sprintf("PERCENTILE_CONT(%s) WITHIN GROUP (ORDER BY %s)", strconv.FormatFloat(p, 'f', -1, 64), col)
+}
+
+// CastSQL oracle casts to NUMBER for the numeric types.
+func (d *dbBaseOracle) CastSQL(col string, castType string) string {
+	if typ, ok := oracleCastTypes[castType]; ok {
+		return fmt.Sprintf("CAST(%s AS %s)", col, typ)
+	}
+	return ""
+}
+
+// BitAndSQL oracle has no & operator but BITAND.
+func (d *dbBaseOracle) BitAndSQL(col string) string {
+	return fmt.Sprintf("BITAND(%s, ?)", col)
+}
+
+// RecursiveWithSQL oracle has no RECURSIVE keyword, a WITH with a column list is recursive.
+func (d *dbBaseOracle) RecursiveWithSQL() string {
+	return "WITH"
+}
+
+// ModuloSQL oracle has no % operator but MOD.
+func (d *dbBaseOracle) ModuloSQL(col string) string {
+	return fmt.Sprintf("MOD(%s, ?)", col)
+}
+
+// UnaccentSQL oracle compares by the accent insensitive BINARY_AI collation of oracle 12.2.
+func (d *dbBaseOracle) UnaccentSQL(col string) string {
+	return fmt.Sprintf("%s COLLATE BINARY_AI = ?", col)
+}
+
+// JSONValidSQL oracle checks the document by IS JSON.
+func (d *dbBaseOracle) JSONValidSQL(fi *models.FieldInfo, col string) string {
+	return fmt.Sprintf("%s IS JSON", col)
+}
+
+// JSONExistsSQL oracle JSON_EXISTS needs the json path as a literal.
+func (d *dbBaseOracle) JSONExistsSQL(col string, path string) (string, []interface{}) {
+	return fmt.Sprintf("JSON_EXISTS(%s, '%s')", col, strings.ReplaceAll(path, "'", "''")), nil
+}
+
+// JSONEqualSQL oracle compares the json documents by JSON_EQUAL, since 18c.
+func (d *dbBaseOracle) JSONEqualSQL(col string, doc string) (string, []interface{}) {
+	return fmt.Sprintf("JSON_EQUAL(%s, ?)", col), []interface{}{doc}
+}
+
+// DatePartSQL oracle TO_CHAR IW is the week of ISO-8601.
+func (d *dbBaseOracle) DatePartSQL(part string, col string) string {
+	switch part {
+	case "quarter":
+		return fmt.Sprintf("TO_NUMBER(TO_CHAR(%s, 'Q'))", col)
+	case "isoweek":
+		return fmt.Sprintf("TO_NUMBER(TO_CHAR(%s, 'IW'))", col)
+	case "age":
+		return fmt.Sprintf("TRUNC(MONTHS_BETWEEN(SYSDATE, %s) / 12)", col)
+	}
+	return ""
+}
+
+// UpsertSQL oracle merges the row selected from dual by the conflicts.
+func (d *dbBaseOracle) UpsertSQL(mi *models.ModelInfo, names []string, conflicts []string) string {
+	Q := d.ins.TableQuote()
+	ons := make([]string, len(conflicts))
+	for i, col := range conflicts {
+		ons[i] = fmt.Sprintf("T.%s%s%s = S.%s%s%s", Q, col, Q, Q, col, Q)
+	}
+	query := fmt.Sprintf("MERGE INTO %s T USING (SELECT %s FROM dual) S ON (%s)",
+		d.TableSQL(mi.Table), quoteCols(Q, names, "? "), strings.Join(ons, " AND "))
+	if updates := upsertUpdateCols(mi, names, conflicts); len(updates) > 0 {
+		sets := make([]string, len(updates))
+		for i, col := range updates {
+			sets[i] = fmt.Sprintf("T.%s%s%s = S.%s%s%s", Q, col, Q, Q, col, Q)
+		}
+		query += " WHEN MATCHED THEN UPDATE SET " + strings.Join(sets, ", ")
+	}
+	return query + fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)", quoteCols(Q, names, ""), quoteCols(Q, names, "S."))
+}
+
+// TimezoneConvertSQL oracle converts the timestamp from the utc offset by FROM_TZ and AT TIME ZONE.
+func (d *dbBaseOracle) TimezoneConvertSQL(col string, from string, tz string) string {
+	return fmt.Sprintf("(FROM_TZ(CAST(%s AS TIMESTAMP), '%s') AT TIME ZONE '%s')", col, from, tz)
+}
+
+// SubstringIndexSQL oracle finds the substring by INSTR in the SUBSTR from pos.
+func (d *dbBaseOracle) SubstringIndexSQL(col string, pos int) string {
+	return fmt.Sprintf("INSTR(SUBSTR(%s, %d), ?)", col, pos)
+}
+
+// IntervalSQL oracle adds NUMTODSINTERVAL or NUMTOYMINTERVAL to SYSTIMESTAMP,
+// which have no precision limit like INTERVAL literals.
+func (d *dbBaseOracle) IntervalSQL(expr string, amount int, unit IntervalUnit) string {
+	if expr == "" {
+		expr = "SYSTIMESTAMP"
+	}
+	if unit == Month {
+		return fmt.Sprintf("%s + NUMTOYMINTERVAL(%d, 'MONTH')", expr, amount)
+	}
+	return fmt.Sprintf("%s + NUMTODSINTERVAL(%d, '%s')", expr, amount, strings.ToUpper(string(unit)))
+}
+
 // OperatorSQL Get oracle operator.
 func (d *dbBaseOracle) OperatorSQL(operator string) string {
 	return oracleOperators[operator]
 }
 
+// generate functioned sql for oracle, LIKE is case sensitive so both sides are upper cased.
+func (d *dbBaseOracle) GenerateOperatorLeftCol(fi *models.FieldInfo, operator string, leftCol *string) {
+	switch operator {
+	case "iexact", "icontains", "istartswith", "iendswith", "ilike_any":
+		*leftCol = fmt.Sprintf("UPPER(%s)", *leftCol)
+	case "soundex":
+		*leftCol = fmt.Sprintf("SOUNDEX(%s)", *leftCol)
+	}
+}
+
 // DbTypes Get oracle table field types.
 func (d *dbBaseOracle) DbTypes() map[string]string {
 	return oracleTypes
 }
 
+// BoolLiteral oracle has no boolean literal in sql, use 1 or 0.
+func (d *dbBaseOracle) BoolLiteral(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+// SupportsInlineCollate oracle uses NLS settings instead of COLLATE in comparisons.
+func (d *dbBaseOracle) SupportsInlineCollate() bool {
+	return false
+}
+
 // ShowTablesQuery show All the tables in database
 func (d *dbBaseOracle) ShowTablesQuery() string {
 	return "SELECT TABLE_NAME FROM USER_TABLES"
@@ -145,7 +335,7 @@ func (d *dbBaseOracle) InsertValue(ctx context.Context, q dbQuerier, mi *models.
 		qmarks = strings.Repeat(qmarks+"), (", multi-1) + qmarks
 	}
 
-	query := fmt.Sprintf("INSERT INTO %s%s%s (%s%s%s) VALUES (%s)", Q, mi.Table, Q, Q, columns, Q, qmarks)
+	query := fmt.Sprintf("INSERT INTO %s (%s%s%s) VALUES (%s)", d.TableSQL(mi.Table), Q, columns, Q, qmarks)
 
 	d.ins.ReplaceMarks(&query)
 
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/beego/beego/v2/client/orm/internal/models"
 )
@@ -26,18 +27,47 @@ import (
 var postgresOperators = map[string]string{
 	"exact":       "= ?",
 	"iexact":      "= UPPER(?)",
-	"contains":    "LIKE ?",
-	"icontains":   "LIKE UPPER(?)",
+	"contains":    "LIKE ? ESCAPE '\\'",
+	"icontains":   "LIKE UPPER(?) ESCAPE '\\'",
 	"gt":          "> ?",
 	"gte":         ">= ?",
 	"lt":          "< ?",
 	"lte":         "<= ?",
 	"eq":          "= ?",
 	"ne":          "!= ?",
-	"startswith":  "LIKE ?",
-	"endswith":    "LIKE ?",
-	"istartswith": "LIKE UPPER(?)",
-	"iendswith":   "LIKE UPPER(?)",
+	"startswith":  "LIKE ? ESCAPE '\\'",
+	"endswith":    "LIKE ? ESCAPE '\\'",
+	"istartswith": "LIKE UPPER(?) ESCAPE '\\'",
+	"iendswith":   "LIKE UPPER(?) ESCAPE '\\'",
+	"gt_all":      "> ALL (%s)",
+	"gte_all":     ">= ALL (%s)",
+	"lt_all":      "< ALL (%s)",
+	"lte_all":     "<= ALL (%s)",
+	"ne_all":      "!= ALL (%s)",
+	"gt_any":      "> ANY (%s)",
+	"gte_any":     ">= ANY (%s)",
+	"lt_any":      "< ANY (%s)",
+	"lte_any":     "<= ANY (%s)",
+	"ne_any":      "!= ANY (%s)",
+	"similar":     "> ?", // needs the pg_trgm extension
+	"before":      "< ?",
+	"after":       "> ?",
+	"hasall":      "= ?",
+	"hasany":      "<> 0",
+	"ilike_any":   "LIKE UPPER(?)",
+	"soundex":     "= soundex(?)", // needs the fuzzystrmatch extension
+	"trimeq":      "= TRIM(?)",
+}
+
+// postgresql types of castTypes.
+var postgresCastTypes = map[string]string{
+	"int":      "integer",
+	"bigint":   "bigint",
+	"decimal":  "numeric",
+	"float":    "double precision",
+	"string":   "text",
+	"date":     "date",
+	"datetime": "timestamp",
 }
 
 // postgresql column field types.
@@ -60,9 +90,12 @@ var postgresTypes = map[string]string{
 	"uint64":              `bigint CHECK("%COL%" >= 0)`,
 	"float64":             "double precision",
 	"float64-decimal":     "numeric(%d, %d)",
+	"big.Rat-decimal":     "numeric(%d, %d)",
 	"json":                "json",
 	"jsonb":               "jsonb",
 	"time.Time-precision": "timestamp(%d) with time zone",
+	"[]byte":              "bytea",
+	"[]byte-size":         "bytea",
 }
 
 // postgresql dbBaser.
@@ -82,9 +115,142 @@ func (d *dbBasePostgres) GenerateOperatorLeftCol(fi *models.FieldInfo, operator
 	switch operator {
 	case "contains", "startswith", "endswith":
 		*leftCol = fmt.Sprintf("%s::text", *leftCol)
-	case "iexact", "icontains", "istartswith", "iendswith":
+	case "iexact", "icontains", "istartswith", "iendswith", "ilike_any":
 		*leftCol = fmt.Sprintf("UPPER(%s::text)", *leftCol)
+	case "similar":
+		*leftCol = fmt.Sprintf("similarity(%s, ?)", *leftCol)
+	case "soundex":
+		*leftCol = fmt.Sprintf("soundex(%s)", *leftCol)
+	}
+}
+
+// TrimSQL postgresql trims the column as text, so that non-text columns are compared too.
+func (d *dbBasePostgres) TrimSQL(col string) string {
+	return fmt.Sprintf("TRIM(%s::text)", col)
+}
+
+// ArrayLengthSQL postgresql counts the elements of json columns by jsonb_array_length,
+// and of the first dimension of array columns by array_length, which is NULL for an empty array.
+func (d *dbBasePostgres) ArrayLengthSQL(fi *models.FieldInfo, col string) string {
+	if fi.FieldType == TypeJSONField || fi.FieldType == TypeJsonbField {
+		return fmt.Sprintf("jsonb_array_length(%s::jsonb)", col)
+	}
+	return fmt.Sprintf("COALESCE(array_length(%s, 1), 0)", col)
+}
+
+// ArrayElementSQL postgresql arrays are 1-based, so the index is shifted by one,
+// the elements of json columns are taken as text by the 0-based ->>.
+func (d *dbBasePostgres) ArrayElementSQL(fi *models.FieldInfo, col string, index int) string {
+	if fi.FieldType == TypeJSONField || fi.FieldType == TypeJsonbField {
+		return fmt.Sprintf("(%s::jsonb ->> %d)", col, index)
+	}
+	return fmt.Sprintf("%s[%d]", col, index+1)
+}
+
+// GroupConcatSQL postgresql joins the values by string_agg, which only takes text.
+func (d *dbBasePostgres) GroupConcatSQL(col string, sep string, orderBy string) string {
+	sep = "'" + strings.ReplaceAll(sep, "'", "''") + "'"
+	if orderBy != "" {
+		sep += " ORDER BY " + orderBy
+	}
+	return fmt.Sprintf("string_agg(%s::text, %s)", col, sep)
+}
+
+// TimeBucketSQL postgresql truncates datetime by date_trunc.
+func (d *dbBasePostgres) TimeBucketSQL(col string, unit string) string {
+	return fmt.Sprintf("date_trunc('%s', %s)", unit, col)
+}
+
+// PercentileSQL postgresql has the ordered-set aggregate PERCENTILE_CONT.
+func (d *dbBasePostgres) PercentileSQL(col string, p float64) string {
+	return fmt.Sprintf("PERCENTILE_CONT(%s) WITHIN GROUP (ORDER BY %s)", strconv.FormatFloat(p, 'f', -1, 64), col)
+}
+
+// SearchPathSQL postgresql sets the search_path of the transaction to the schema.
+func (d *dbBasePostgres) SearchPathSQL() string {
+	if d.schema == "" {
+		return ""
+	}
+	return fmt.Sprintf(`SET LOCAL search_path TO "%s"`, d.schema)
+}
+
+// CastSQL postgresql casts by the :: operator.
+func (d *dbBasePostgres) CastSQL(col string, castType string) string {
+	if typ, ok := postgresCastTypes[castType]; ok {
+		return fmt.Sprintf("%s::%s", col, typ)
+	}
+	return ""
+}
+
+// RoundSQL postgresql rounds to decimals only the numeric, not the double precision.
+func (d *dbBasePostgres) RoundSQL(col string, decimals int) string {
+	return fmt.Sprintf("ROUND(%s::numeric, %d)", col, decimals)
+}
+
+// JSONExistsSQL postgresql checks the json path by jsonb_path_exists,
+// the ? operator of jsonb would be taken as a placeholder.
+func (d *dbBasePostgres) JSONExistsSQL(col string, path string) (string, []interface{}) {
+	return fmt.Sprintf("jsonb_path_exists(%s::jsonb, CAST(? AS jsonpath))", col), []interface{}{path}
+}
+
+// UnaccentSQL postgresql folds both sides by unaccent, which needs the unaccent extension.
+func (d *dbBasePostgres) UnaccentSQL(col string) string {
+	return fmt.Sprintf("unaccent(%s) = unaccent(?)", col)
+}
+
+// JSONValidSQL postgresql json and jsonb columns only hold valid documents,
+// the text is checked by IS JSON of postgresql 16+.
+func (d *dbBasePostgres) JSONValidSQL(fi *models.FieldInfo, col string) string {
+	if fi.FieldType == TypeJSONField || fi.FieldType == TypeJsonbField {
+		return fmt.Sprintf("%s IS NOT NULL", col)
+	}
+	return fmt.Sprintf("%s IS JSON", col)
+}
+
+// JSONEqualSQL postgresql compares as jsonb, which drops the whitespace, sorts the keys and keeps the last of the duplicated keys.
+func (d *dbBasePostgres) JSONEqualSQL(col string, doc string) (string, []interface{}) {
+	return fmt.Sprintf("%s::jsonb = CAST(? AS jsonb)", col), []interface{}{doc}
+}
+
+// DatePartSQL postgresql EXTRACT WEEK is the week of ISO-8601, AGE is the interval of the whole years, months and days.
+func (d *dbBasePostgres) DatePartSQL(part string, col string) string {
+	switch part {
+	case "quarter":
+		return fmt.Sprintf("CAST(EXTRACT(QUARTER FROM %s) AS INTEGER)", col)
+	case "isoweek":
+		return fmt.Sprintf("CAST(EXTRACT(WEEK FROM %s) AS INTEGER)", col)
+	case "age":
+		return fmt.Sprintf("CAST(EXTRACT(YEAR FROM AGE(CURRENT_DATE, %s)) AS INTEGER)", col)
+	}
+	return ""
+}
+
+// UpsertSQL postgresql updates the row conflicting by ON CONFLICT, since 9.5.
+func (d *dbBasePostgres) UpsertSQL(mi *models.ModelInfo, names []string, conflicts []string) string {
+	return d.onConflictUpsertSQL(mi, names, conflicts)
+}
+
+// TimezoneConvertSQL postgresql converts by AT TIME ZONE, the time.Time columns are timestamp with time zone,
+// so a single AT TIME ZONE gives the wall time of tz whatever the timezone of the db alias is.
+func (d *dbBasePostgres) TimezoneConvertSQL(col string, _ string, tz string) string {
+	return fmt.Sprintf("(%s AT TIME ZONE '%s')", col, tz)
+}
+
+// SubstringIndexSQL postgresql finds the substring by STRPOS in the SUBSTR from pos.
+func (d *dbBasePostgres) SubstringIndexSQL(col string, pos int) string {
+	return fmt.Sprintf("STRPOS(SUBSTR(%s, %d), ?)", col, pos)
+}
+
+// IntervalSQL postgresql adds INTERVAL to CURRENT_TIMESTAMP.
+func (d *dbBasePostgres) IntervalSQL(expr string, amount int, unit IntervalUnit) string {
+	if expr == "" {
+		expr = "CURRENT_TIMESTAMP"
+	}
+	sign := "+"
+	if amount < 0 {
+		sign, amount = "-", -amount
 	}
+	return fmt.Sprintf("%s %s INTERVAL '%d %s'", expr, sign, amount, unit)
 }
 
 // postgresql unsupports updating joined record.
@@ -92,6 +258,11 @@ func (d *dbBasePostgres) SupportUpdateJoin() bool {
 	return false
 }
 
+// AdvisoryLockSQL postgresql takes session advisory locks of the key hash.
+func (d *dbBasePostgres) AdvisoryLockSQL() (string, string) {
+	return "SELECT pg_try_advisory_lock(hashtext(?))", "SELECT pg_advisory_unlock(hashtext(?))"
+}
+
 func (d *dbBasePostgres) MaxLimit() uint64 {
 	return 0
 }
@@ -142,6 +313,31 @@ func (d *dbBasePostgres) HasReturningID(mi *models.ModelInfo, query *string) boo
 	return true
 }
 
+// CursorSQL postgresql declares a cursor only in a transaction.
+func (d *dbBasePostgres) CursorSQL(name string, size int) (string, string, string) {
+	return fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR", name),
+		fmt.Sprintf("FETCH FORWARD %d FROM %s", size, name),
+		fmt.Sprintf("CLOSE %s", name)
+}
+
+// ExplainSQL postgresql runs the query for EXPLAIN ANALYZE.
+func (d *dbBasePostgres) ExplainSQL(analyze bool) string {
+	if analyze {
+		return "EXPLAIN ANALYZE"
+	}
+	return "EXPLAIN"
+}
+
+// postgresql locks the rows by FOR UPDATE OF the table only, the joined ones may be on the nullable side.
+func (d *dbBasePostgres) SkipLockedSQL(table string) string {
+	return fmt.Sprintf("FOR UPDATE OF %s SKIP LOCKED", table)
+}
+
+// postgresql supports RETURNING.
+func (d *dbBasePostgres) SupportReturning() bool {
+	return true
+}
+
 // sync auto key
 func (d *dbBasePostgres) setval(ctx context.Context, db dbQuerier, mi *models.ModelInfo, autoFields []string) error {
 	if len(autoFields) == 0 {
@@ -149,11 +345,15 @@ func (d *dbBasePostgres) setval(ctx context.Context, db dbQuerier, mi *models.Mo
 	}
 
 	Q := d.ins.TableQuote()
+	table := mi.Table
+	if d.schema != "" {
+		table = d.schema + "." + table
+	}
 	for _, name := range autoFields {
-		query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), (SELECT MAX(%s%s%s) FROM %s%s%s));",
-			mi.Table, name,
+		query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), (SELECT MAX(%s%s%s) FROM %s));",
+			table, name,
 			Q, name, Q,
-			Q, mi.Table, Q)
+			d.TableSQL(mi.Table))
 		if _, err := db.ExecContext(ctx, query); err != nil {
 			return err
 		}
@@ -43,6 +43,33 @@ var sqliteOperators = map[string]string{
 	"endswith":    "LIKE ? ESCAPE '\\'",
 	"istartswith": "LIKE ? ESCAPE '\\'",
 	"iendswith":   "LIKE ? ESCAPE '\\'",
+	"before":      "< ?",
+	"after":       "> ?",
+	"hasall":      "= ?",
+	"hasany":      "<> 0",
+	"ilike_any":   "LIKE ?",
+	"trimeq":      "= TRIM(?)",
+}
+
+// sqlite formats truncating datetime to the time bucket units.
+var sqliteTimeBucketFormats = map[string]string{
+	"minute": "%Y-%m-%d %H:%M:00",
+	"hour":   "%Y-%m-%d %H:00:00",
+	"day":    "%Y-%m-%d 00:00:00",
+	"month":  "%Y-%m-01 00:00:00",
+	"year":   "%Y-01-01 00:00:00",
+}
+
+// sqlite sql casting to the types of castTypes,
+// CAST AS DATE would give a number, the dates are normalized by the date functions.
+var sqliteCastFormats = map[string]string{
+	"int":      "CAST(%s AS INTEGER)",
+	"bigint":   "CAST(%s AS INTEGER)",
+	"decimal":  "CAST(%s AS NUMERIC)",
+	"float":    "CAST(%s AS REAL)",
+	"string":   "CAST(%s AS TEXT)",
+	"date":     "DATE(%s)",
+	"datetime": "DATETIME(%s)",
 }
 
 // sqlite column types.
@@ -66,6 +93,9 @@ var sqliteTypes = map[string]string{
 	"uint64":              "bigint unsigned",
 	"float64":             "real",
 	"float64-decimal":     "decimal",
+	"big.Rat-decimal":     "text", // NUMERIC affinity would turn exact decimals into REAL, the text makes gt, lt and OrderBy lexical
+	"[]byte":              "blob",
+	"[]byte-size":         "blob",
 }
 
 // sqlite dbBaser.
@@ -96,11 +126,109 @@ func (d *dbBaseSqlite) GenerateOperatorLeftCol(fi *models.FieldInfo, operator st
 	}
 }
 
+// ArrayLengthSQL sqlite counts the elements of a json array by json_array_length.
+func (d *dbBaseSqlite) ArrayLengthSQL(_ *models.FieldInfo, col string) string {
+	return fmt.Sprintf("json_array_length(%s)", col)
+}
+
+// ArrayElementSQL sqlite extracts the element of a json array by its 0-based path.
+func (d *dbBaseSqlite) ArrayElementSQL(_ *models.FieldInfo, col string, index int) string {
+	return fmt.Sprintf("json_extract(%s, '$[%d]')", col, index)
+}
+
+// GroupConcatSQL sqlite joins the values by group_concat, ordered by the ORDER BY in it of sqlite 3.44+.
+func (d *dbBaseSqlite) GroupConcatSQL(col string, sep string, orderBy string) string {
+	sep = "'" + strings.ReplaceAll(sep, "'", "''") + "'"
+	if orderBy != "" {
+		sep += " ORDER BY " + orderBy
+	}
+	return fmt.Sprintf("group_concat(%s, %s)", col, sep)
+}
+
+// sqlite stores datetime as text, format it to the start of the unit.
+func (d *dbBaseSqlite) TimeBucketSQL(col string, unit string) string {
+	return fmt.Sprintf("strftime('%s', %s)", sqliteTimeBucketFormats[unit], col)
+}
+
+// CastSQL sqlite casts to the storage classes, and formats dates by the date functions.
+func (d *dbBaseSqlite) CastSQL(col string, castType string) string {
+	if format, ok := sqliteCastFormats[castType]; ok {
+		return fmt.Sprintf(format, col)
+	}
+	return ""
+}
+
+// ExplainSQL sqlite describes the plan by EXPLAIN QUERY PLAN, it cannot analyze.
+func (d *dbBaseSqlite) ExplainSQL(analyze bool) string {
+	if analyze {
+		return ""
+	}
+	return "EXPLAIN QUERY PLAN"
+}
+
+// JSONValidSQL sqlite checks the document by json_valid.
+func (d *dbBaseSqlite) JSONValidSQL(fi *models.FieldInfo, col string) string {
+	return fmt.Sprintf("json_valid(%s) = 1", col)
+}
+
+// JSONExistsSQL sqlite json_type is NULL only if the json path does not exist.
+func (d *dbBaseSqlite) JSONExistsSQL(col string, path string) (string, []interface{}) {
+	return fmt.Sprintf("json_type(%s, ?) IS NOT NULL", col), []interface{}{path}
+}
+
+// DatePartSQL sqlite strftime has no ISO-8601 week before 3.46,
+// the week is counted by the day of the year of the thursday of the week of the date,
+// the age is the difference of the years less one if the month and day of the date are not reached.
+func (d *dbBaseSqlite) DatePartSQL(part string, col string) string {
+	switch part {
+	case "quarter":
+		return fmt.Sprintf("((CAST(strftime('%%m', %s) AS INTEGER) + 2) / 3)", col)
+	case "isoweek":
+		return fmt.Sprintf("((CAST(strftime('%%j', date(%s, '-3 days', 'weekday 4')) AS INTEGER) - 1) / 7 + 1)", col)
+	case "age":
+		return fmt.Sprintf("(CAST(strftime('%%Y', 'now', 'localtime') AS INTEGER) - CAST(strftime('%%Y', %s) AS INTEGER) - "+
+			"(strftime('%%m-%%d', 'now', 'localtime') < strftime('%%m-%%d', %s)))", col, col)
+	}
+	return ""
+}
+
+// UpsertSQL sqlite updates the row conflicting by ON CONFLICT, since 3.24.
+func (d *dbBaseSqlite) UpsertSQL(mi *models.ModelInfo, names []string, conflicts []string) string {
+	return d.onConflictUpsertSQL(mi, names, conflicts)
+}
+
+// SubstringIndexSQL sqlite finds the substring by INSTR in the SUBSTR from pos.
+func (d *dbBaseSqlite) SubstringIndexSQL(col string, pos int) string {
+	return fmt.Sprintf("INSTR(SUBSTR(%s, %d), ?)", col, pos)
+}
+
+// IntervalSQL sqlite adds the interval by the modifier of datetime,
+// the current time is local as the times are stored.
+func (d *dbBaseSqlite) IntervalSQL(expr string, amount int, unit IntervalUnit) string {
+	if expr == "" {
+		expr = "'now', 'localtime'"
+	}
+	return fmt.Sprintf("datetime(%s, '%+d %ss')", expr, amount, unit)
+}
+
+// sqlite stores boolean as integer 1 or 0.
+func (d *dbBaseSqlite) BoolLiteral(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
 // unable updating joined record in sqlite.
 func (d *dbBaseSqlite) SupportUpdateJoin() bool {
 	return false
 }
 
+// RETURNING is supported since sqlite 3.35.
+func (d *dbBaseSqlite) SupportReturning() bool {
+	return true
+}
+
 // max int in sqlite.
 func (d *dbBaseSqlite) MaxLimit() uint64 {
 	return 9223372036854775807
@@ -184,3 +312,12 @@ func newdbBaseSqlite() dbBaser {
 	b.ins = b
 	return b
 }
+
+// check the sqlite version is major.minor or higher, an unknown version is taken as higher.
+func sqliteVersionAtLeast(version string, major, minor int) bool {
+	var ma, mi int
+	if _, err := fmt.Sscanf(version, "%d.%d", &ma, &mi); err != nil {
+		return true
+	}
+	return ma > major || ma == major && mi >= minor
+}
@@ -15,7 +15,11 @@
 package orm
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -208,7 +212,7 @@ func (t *dbTables) getJoinSQL() (join string) {
 			}
 		}
 
-		join += fmt.Sprintf("%s%s%s %s ON %s.%s%s%s = %s.%s%s%s ", Q, table, Q, t2,
+		join += fmt.Sprintf("%s %s ON %s.%s%s%s = %s.%s%s%s ", t.base.TableSQL(table), t2,
 			t2, Q, c2, Q, t1, Q, c1, Q)
 	}
 	return
@@ -357,6 +361,20 @@ func (t *dbTables) getCondSQL(cond *Condition, sub bool, tz *time.Location) (whe
 			}
 			where += w
 			params = append(params, ps...)
+		} else if p.topN != nil {
+			w, ps := t.getTopNSQL(p.topN, tz)
+			where += w
+			params = append(params, ps...)
+		} else if p.window != nil {
+			w, ps := t.getWindowSQL(p.window, tz)
+			where += w
+			params = append(params, ps...)
+		} else if p.hasRelated != nil {
+			where += t.getHasRelatedSQL(p.hasRelated)
+		} else if p.tree != nil {
+			w, ps := t.getDescendantsSQL(p.tree, tz)
+			where += w
+			params = append(params, ps...)
 		} else {
 			exprs := p.exprs
 
@@ -367,6 +385,40 @@ func (t *dbTables) getCondSQL(cond *Condition, sub bool, tz *time.Location) (whe
 				exprs = exprs[:num]
 			}
 
+			transform := ""
+			if num = len(exprs) - 1; num > 0 && transforms[exprs[num]] && !isRelFieldExpr(mi, exprs) {
+				transform = exprs[num]
+				exprs = exprs[:num]
+			}
+
+			// a number after the field is the 0-based index of an element of the array, such as tags__0
+			element := -1
+			if num = len(exprs) - 1; num > 0 {
+				if i, err := strconv.Atoi(exprs[num]); err == nil && i >= 0 {
+					element = i
+					exprs = exprs[:num]
+				}
+			}
+
+			if p.isNot && t.hasManyRel(mi, exprs) {
+				// joining the many side then negating still matches the rows
+				// having another related row, exclude the matched pks instead.
+				w, ps := t.getExcludeManySQL(p, tz)
+				where += w
+				params = append(params, ps...)
+				continue
+			}
+
+			if poly := mi.Polymorphics[exprs[0]]; poly != nil && len(exprs) == 1 && !p.isRaw {
+				if operator != "" && operator != "exact" {
+					panic(fmt.Errorf("polymorphic relation `%s` only supports the exact operator", exprs[0]))
+				}
+				w, ps := t.getPolymorphicSQL(poly, p.args)
+				where += w
+				params = append(params, ps...)
+				continue
+			}
+
 			index, _, fi, suc := t.parseExprs(mi, exprs)
 			if !suc {
 				panic(fmt.Errorf("unknown field/column name `%s`", strings.Join(p.exprs, ExprSep)))
@@ -376,16 +428,175 @@ func (t *dbTables) getCondSQL(cond *Condition, sub bool, tz *time.Location) (whe
 				operator = "exact"
 			}
 
+			// NOT IN a sub query is never true when it selects a NULL,
+			// exclude by NOT EXISTS of the column in the rows of the sub query instead.
+			var sub *SubQuery
+			if p.isNot && !p.isRaw && operator == "in" && len(p.args) == 1 {
+				sub, _ = p.args[0].(*SubQuery)
+			}
+
+			// IN (NULL) never matches, the nils of the values are compared by IS NULL instead
+			inArgs, inNull := p.args, false
+			if operator == "in" && !p.isRaw && sub == nil {
+				inArgs, inNull = splitInNulls(fi, p.args, tz)
+			}
+
 			var operSQL string
 			var args []interface{}
 			if p.isRaw {
 				operSQL = p.sql
-			} else {
-				operSQL, args = t.base.GenerateOperatorSQL(mi, fi, operator, p.args, tz)
+			} else if p.boolean {
+				operSQL = "= " + t.base.BoolLiteral(true)
+			} else if operator != "json_exists" && operator != "json_eq" && operator != "is_json" && operator != "unaccent" && operator != "contains_at" && operator != "includes" && operator != "mod" && !p.approx && sub == nil && (!inNull || len(inArgs) > 0) {
+				operSQL, args = t.base.GenerateOperatorSQL(mi, fi, operator, inArgs, tz)
 			}
 
 			leftCol := fmt.Sprintf("%s.%s%s%s", index, Q, fi.Column, Q)
-			t.base.GenerateOperatorLeftCol(fi, operator, &leftCol)
+			if element >= 0 {
+				if leftCol = t.base.ArrayElementSQL(fi, leftCol, element); leftCol == "" {
+					panic(fmt.Errorf("array element is not supported by the driver"))
+				}
+			}
+			var colParams []interface{}
+			if len(p.concat) > 0 {
+				leftCol, colParams = t.getConcatSQL(leftCol, p.concat, p.concatSep)
+			}
+			if len(p.coalesce) > 0 {
+				leftCol = fmt.Sprintf("COALESCE(%s, ?)", leftCol)
+				colParams = getFlatParams(fi, p.coalesce, tz)
+			}
+			if p.cast != "" {
+				if leftCol = t.base.CastSQL(leftCol, p.cast); leftCol == "" {
+					panic(fmt.Errorf("cast to `%s` is not supported by the driver", p.cast))
+				}
+			}
+			if p.timezone != "" {
+				if leftCol = t.base.TimezoneConvertSQL(leftCol, tzOffset(tz), p.timezone); leftCol == "" {
+					panic(fmt.Errorf("timezone conversion is not supported by the driver"))
+				}
+			}
+			if p.rounded {
+				leftCol = t.base.RoundSQL(leftCol, p.round)
+			}
+			if p.approx {
+				leftCol = fmt.Sprintf("ABS(%s - ?)", leftCol)
+				colParams = append(colParams, p.target)
+			}
+			switch transform {
+			case "len":
+				leftCol = t.base.LengthSQL(leftCol)
+			case "size":
+				if leftCol = t.base.ArrayLengthSQL(fi, leftCol); leftCol == "" {
+					panic(fmt.Errorf("array length is not supported by the driver"))
+				}
+			case "quarter", "isoweek", "age":
+				if leftCol = t.base.DatePartSQL(transform, leftCol); leftCol == "" {
+					panic(fmt.Errorf("date part `%s` is not supported by the driver", transform))
+				}
+			case "lower", "upper", "trim", "ltrim", "rtrim", "abs":
+				leftCol = fmt.Sprintf("%s(%s)", strings.ToUpper(transform), leftCol)
+			default:
+				t.base.GenerateOperatorLeftCol(fi, operator, &leftCol)
+			}
+			if operator == "hasall" || operator == "hasany" {
+				leftCol = t.base.BitAndSQL(leftCol)
+			}
+			if operator == "trimeq" {
+				leftCol = t.base.TrimSQL(leftCol)
+			}
+			if p.collate != "" {
+				if !t.base.SupportsInlineCollate() {
+					panic(fmt.Errorf("COLLATE in comparison is not supported by the driver"))
+				}
+				leftCol = fmt.Sprintf("%s COLLATE %s", leftCol, p.collate)
+			}
+
+			if sub != nil {
+				query, ps, subFi := t.base.SubQuerySQL(sub, tz)
+				where += fmt.Sprintf("EXISTS (SELECT 1 FROM (%s) S WHERE S.%s%s%s = %s) ", query, Q, subFi.Column, Q, leftCol)
+				params = append(params, ps...)
+				params = append(params, colParams...)
+				continue
+			}
+			if operator == "ilike_any" && !p.isRaw {
+				// one LIKE per pattern, the params of leftCol repeat with it
+				likes := make([]string, len(args))
+				for i, arg := range args {
+					likes[i] = fmt.Sprintf("%s %s", leftCol, operSQL)
+					params = append(params, colParams...)
+					params = append(params, arg)
+				}
+				where += fmt.Sprintf("(%s) ", strings.Join(likes, " OR "))
+				continue
+			}
+			if inNull {
+				if len(inArgs) == 0 {
+					where += fmt.Sprintf("%s IS NULL ", leftCol)
+					params = append(params, colParams...)
+					continue
+				}
+				where += fmt.Sprintf("(%s %s OR %s IS NULL) ", leftCol, operSQL, leftCol)
+				params = append(params, colParams...)
+				params = append(params, args...)
+				params = append(params, colParams...)
+				continue
+			}
+			params = append(params, colParams...)
+
+			if operator == "json_exists" && !p.isRaw {
+				w, ps := t.getJSONExistsSQL(leftCol, p.args)
+				where += w + " "
+				params = append(params, ps...)
+				continue
+			}
+			if operator == "is_json" && !p.isRaw {
+				where += t.getJSONValidSQL(fi, leftCol, p.args) + " "
+				continue
+			}
+			if operator == "unaccent" && !p.isRaw {
+				w, ps := t.getUnaccentSQL(leftCol, p.args)
+				where += w + " "
+				params = append(params, ps...)
+				continue
+			}
+			if operator == "json_eq" && !p.isRaw {
+				w, ps := t.getJSONEqualSQL(leftCol, p.args)
+				where += w + " "
+				params = append(params, ps...)
+				continue
+			}
+			if p.approx {
+				// the tolerance is not converted to the type of the column
+				where += fmt.Sprintf("%s <= ? ", leftCol)
+				params = append(params, p.tolerance)
+				continue
+			}
+			if operator == "contains_at" && !p.isRaw {
+				w, ps := t.getContainsAtSQL(leftCol, p.args)
+				where += w + " "
+				params = append(params, ps...)
+				continue
+			}
+			if operator == "mod" && !p.isRaw {
+				w, ps := t.getModuloSQL(leftCol, p.args)
+				where += w + " "
+				params = append(params, ps...)
+				continue
+			}
+			if operator == "includes" && !p.isRaw {
+				w, ps := t.getSetIncludesSQL(leftCol, p.args)
+				where += w + " "
+				params = append(params, ps...)
+				continue
+			}
+
+			if fi.EmptyAsNull && (operator == "exact" || operator == "eq") && len(args) == 1 && args[0] == "" && !p.isRaw {
+				// the empty strings are written as NULL, the rows written before may still have them
+				where += fmt.Sprintf("(%s IS NULL OR %s %s) ", leftCol, leftCol, operSQL)
+				params = append(params, colParams...)
+				params = append(params, args...)
+				continue
+			}
 
 			where += fmt.Sprintf("%s %s ", leftCol, operSQL)
 			params = append(params, args...)
@@ -400,8 +611,299 @@ func (t *dbTables) getCondSQL(cond *Condition, sub bool, tz *time.Location) (whe
 	return
 }
 
+// generate the sql matching the polymorphic relation with the model arg,
+// its type field is the table name of the model and its id field is the pk of the arg.
+func (t *dbTables) getPolymorphicSQL(poly *models.Polymorphic, args []interface{}) (string, []interface{}) {
+	if len(args) != 1 || args[0] == nil {
+		panic(fmt.Errorf("polymorphic relation `%s` needs one model arg", poly.Name))
+	}
+	ind := reflect.Indirect(reflect.ValueOf(args[0]))
+	rmi, ok := defaultModelCache.GetByFullName(models.GetFullName(ind.Type()))
+	if !ok {
+		panic(fmt.Errorf("polymorphic relation `%s` arg `%s` is not a registered model", poly.Name, ind.Type()))
+	}
+	_, pk, exist := getExistPk(rmi, ind)
+	if !exist {
+		panic(fmt.Errorf("polymorphic relation `%s` arg `%s` has no pk value", poly.Name, ind.Type()))
+	}
+	Q := t.base.TableQuote()
+	return fmt.Sprintf("(T0.%s%s%s = ? AND T0.%s%s%s = ?) ", Q, poly.TypeField.Column, Q, Q, poly.IDField.Column, Q),
+		[]interface{}{rmi.Table, pk}
+}
+
+// check whether exprs go through a m2m or reverse many relation from mi.
+func (t *dbTables) hasManyRel(mi *models.ModelInfo, exprs []string) bool {
+	mmi := mi
+	for _, ex := range exprs {
+		fi, ok := mmi.Fields.GetByAny(ex)
+		if !ok {
+			return false
+		}
+		switch {
+		case fi.FieldType == RelManyToMany || fi.FieldType == RelReverseMany:
+			return true
+		case fi.Rel:
+			mmi = fi.RelModelInfo
+		case fi.Reverse:
+			mmi = fi.ReverseFieldInfo.Mi
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// generate the pk IN subquery of the rows matching the condition p,
+// the caller negates it to exclude them.
+func (t *dbTables) getExcludeManySQL(p condValue, tz *time.Location) (string, []interface{}) {
+	Q := t.base.TableQuote()
+	pk := t.mi.Fields.Pk.Column
+
+	p.isNot = false
+	p.isOr = false
+	tables := newDbTables(t.mi, t.base)
+	where, params := tables.getCondSQL(&Condition{params: []condValue{p}}, false, tz)
+	join := tables.getJoinSQL()
+
+	return fmt.Sprintf("T0.%s%s%s IN (SELECT T0.%s%s%s FROM %s T0 %s%s) ",
+		Q, pk, Q, Q, pk, Q, t.base.TableSQL(t.mi.Table), join, where), params
+}
+
+// generate the EXISTS of the rows related to T0 by the relation field fi,
+// in the table of the related model or, for m2m relations, of the through model.
+func (t *dbTables) getHasRelatedSQL(fi *models.FieldInfo) string {
+	Q := t.base.TableQuote()
+	var table, col, leftCol string
+	switch {
+	case fi.FieldType == RelManyToMany || fi.Reverse:
+		// the fk to the model in the through or the related model
+		table, col, leftCol = fi.ReverseFieldInfo.Mi.Table, fi.ReverseFieldInfo.Column, t.mi.Fields.Pk.Column
+	default:
+		table, col, leftCol = fi.RelModelInfo.Table, fi.RelModelInfo.Fields.Pk.Column, fi.Column
+	}
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %s R WHERE R.%s%s%s = T0.%s%s%s) ",
+		t.base.TableSQL(table), Q, col, Q, Q, leftCol, Q)
+}
+
+// generate the predicate of the pk column in the rows of the subtree under the root,
+// collected by a recursive common table expression from the root down the parent column.
+func (t *dbTables) getDescendantsSQL(tree *descendantsTree, tz *time.Location) (string, []interface{}) {
+	with := t.base.RecursiveWithSQL()
+	if with == "" {
+		panic(fmt.Errorf("recursive common table expression is not supported by the driver"))
+	}
+	Q := t.base.TableQuote()
+	pk, parent, table := Q+tree.pk.Column+Q, Q+tree.parent.Column+Q, t.base.TableSQL(t.mi.Table)
+	params := getFlatParams(tree.pk, []interface{}{tree.root}, tz)
+	if len(params) != 1 {
+		panic(fmt.Errorf("descendants need 1 root not %d", len(params)))
+	}
+	return fmt.Sprintf("T0.%s IN (%s %stree%s (%s) AS (SELECT %s FROM %s WHERE %s = ? UNION ALL SELECT C.%s FROM %s C INNER JOIN %stree%s P ON C.%s = P.%s) SELECT %s FROM %stree%s) ",
+		pk, with, Q, Q, pk, pk, table, pk, pk, table, Q, Q, parent, pk, pk, Q, Q), params
+}
+
+// generate the predicate of the pk in the top rows of each group ranked by ROW_NUMBER.
+func (t *dbTables) getTopNSQL(topN *topNPerGroup, tz *time.Location) (string, []interface{}) {
+	Q := t.base.TableQuote()
+	pk := t.mi.Fields.Pk.Column
+
+	tables := newDbTables(t.mi, t.base)
+	partition := make([]string, 0, len(topN.partition))
+	for _, col := range topN.partition {
+		exprs := strings.Split(col, ExprSep)
+		index, _, fi, suc := tables.parseExprs(t.mi, exprs)
+		if !suc {
+			panic(fmt.Errorf("unknown field/column name `%s`", col))
+		}
+		partition = append(partition, fmt.Sprintf("%s.%s%s%s", index, Q, fi.Column, Q))
+	}
+	orderBy, params := tables.getOrderSQL([]*order_clause.Order{topN.order}, tz)
+	where, whereParams := tables.getCondSQL(topN.cond, false, tz)
+	params = append(params, whereParams...)
+	join := tables.getJoinSQL()
+
+	return fmt.Sprintf("T0.%s%s%s IN (SELECT T.%s%s%s FROM (SELECT T0.%s%s%s, ROW_NUMBER() OVER (PARTITION BY %s %s) %srow_num%s FROM %s T0 %s%s) T WHERE T.%srow_num%s <= %d) ",
+		Q, pk, Q, Q, pk, Q, Q, pk, Q, strings.Join(partition, ", "), strings.TrimSpace(orderBy), Q, Q,
+		t.base.TableSQL(t.mi.Table), join, where, Q, Q, topN.n), params
+}
+
+// generate the predicate of the pk in the rows whose window aggregate matches the comparison.
+func (t *dbTables) getWindowSQL(window *windowFilter, tz *time.Location) (string, []interface{}) {
+	Q := t.base.TableQuote()
+	pk := t.mi.Fields.Pk.Column
+	aggr := window.aggr
+
+	tables := newDbTables(t.mi, t.base)
+	column := func(col string) (string, *models.FieldInfo) {
+		index, _, fi, suc := tables.parseExprs(t.mi, strings.Split(col, ExprSep))
+		if !suc {
+			panic(fmt.Errorf("unknown field/column name `%s`", col))
+		}
+		return fmt.Sprintf("%s.%s%s%s", index, Q, fi.Column, Q), fi
+	}
+
+	aggrCol, fi := column(aggr.Column)
+	over := make([]string, 0, 3)
+	if len(aggr.Partition) > 0 {
+		partition := make([]string, 0, len(aggr.Partition))
+		for _, col := range aggr.Partition {
+			partitionCol, _ := column(col)
+			partition = append(partition, partitionCol)
+		}
+		over = append(over, "PARTITION BY "+strings.Join(partition, ", "))
+	}
+	var params []interface{}
+	if aggr.OrderBy != "" {
+		var orderBy string
+		orderBy, params = tables.getOrderSQL(order_clause.ParseOrder(aggr.OrderBy), tz)
+		over = append(over, strings.TrimSpace(orderBy))
+	}
+	if aggr.Preceding > 0 {
+		over = append(over, fmt.Sprintf("ROWS %d PRECEDING", aggr.Preceding))
+	}
+	where, whereParams := tables.getCondSQL(window.cond, false, tz)
+	params = append(params, whereParams...)
+	join := tables.getJoinSQL()
+
+	// COUNT and AVG are not of the type of the column
+	valueFi := fi
+	if aggr.Func == "COUNT" || aggr.Func == "AVG" {
+		valueFi = nil
+	}
+	params = append(params, getFlatParams(valueFi, []interface{}{window.value}, tz)...)
+
+	return fmt.Sprintf("T0.%s%s%s IN (SELECT T.%s%s%s FROM (SELECT T0.%s%s%s, %s(%s) OVER (%s) %swindow_aggr%s FROM %s T0 %s%s) T WHERE T.%swindow_aggr%s %s) ",
+		Q, pk, Q, Q, pk, Q, Q, pk, Q, aggr.Func, aggrCol, strings.Join(over, " "), Q, Q,
+		t.base.TableSQL(t.mi.Table), join, where, Q, Q, t.base.OperatorSQL(window.operator)), params
+}
+
+// generate the predicate of the json path args[0] existing in leftCol.
+func (t *dbTables) getJSONExistsSQL(leftCol string, args []interface{}) (string, []interface{}) {
+	if len(args) != 1 {
+		panic(fmt.Errorf("operator `json_exists` need 1 args not %d", len(args)))
+	}
+	path, ok := args[0].(string)
+	if !ok {
+		panic(fmt.Errorf("operator `json_exists` need a string json path not `%T`", args[0]))
+	}
+	w, params := t.base.JSONExistsSQL(leftCol, path)
+	if w == "" {
+		panic(fmt.Errorf("operator `json_exists` is not supported by the driver"))
+	}
+	return w, params
+}
+
+// generate the predicate of leftCol holding a valid json document, or not when args[0] is false.
+func (t *dbTables) getJSONValidSQL(fi *models.FieldInfo, leftCol string, args []interface{}) string {
+	if len(args) != 1 {
+		panic(fmt.Errorf("operator `is_json` need 1 args not %d", len(args)))
+	}
+	valid, ok := args[0].(bool)
+	if !ok {
+		panic(fmt.Errorf("operator `is_json` need a bool arg not `%T`", args[0]))
+	}
+	w := t.base.JSONValidSQL(fi, leftCol)
+	if w == "" {
+		panic(fmt.Errorf("operator `is_json` is not supported by the driver"))
+	}
+	if !valid {
+		w = fmt.Sprintf("NOT (%s)", w)
+	}
+	return w
+}
+
+// generate the predicate of leftCol equal to the string args[0] regardless of the accents.
+func (t *dbTables) getUnaccentSQL(leftCol string, args []interface{}) (string, []interface{}) {
+	if len(args) != 1 {
+		panic(fmt.Errorf("operator `unaccent` need 1 args not %d", len(args)))
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		panic(fmt.Errorf("operator `unaccent` need a string arg not `%T`", args[0]))
+	}
+	w := t.base.UnaccentSQL(leftCol)
+	if w == "" {
+		panic(fmt.Errorf("operator `unaccent` is not supported by the driver"))
+	}
+	return w, []interface{}{s}
+}
+
+// generate the predicate of the json document in leftCol equal to args[0] marshaled,
+// a json.RawMessage or []byte arg is taken as the json document as is.
+func (t *dbTables) getJSONEqualSQL(leftCol string, args []interface{}) (string, []interface{}) {
+	if len(args) != 1 {
+		panic(fmt.Errorf("operator `json_eq` need 1 args not %d", len(args)))
+	}
+	var doc []byte
+	switch v := args[0].(type) {
+	case json.RawMessage:
+		doc = v
+	case []byte:
+		doc = v
+	default:
+		var err error
+		if doc, err = json.Marshal(v); err != nil {
+			panic(fmt.Errorf("operator `json_eq` cannot marshal the arg: %w", err))
+		}
+	}
+	if !json.Valid(doc) {
+		panic(fmt.Errorf("operator `json_eq` need a valid json document"))
+	}
+	w, params := t.base.JSONEqualSQL(leftCol, string(doc))
+	if w == "" {
+		panic(fmt.Errorf("operator `json_eq` is not supported by the driver"))
+	}
+	return w, params
+}
+
+// generate the comparison of the position of the substring in leftCol with a SubstringPos arg.
+func (t *dbTables) getContainsAtSQL(leftCol string, args []interface{}) (string, []interface{}) {
+	if len(args) != 1 {
+		panic(fmt.Errorf("operator `contains_at` need 1 args not %d", len(args)))
+	}
+	s, ok := args[0].(SubstringPos)
+	if !ok {
+		panic(fmt.Errorf("operator `contains_at` need a SubstringPos value not `%T`", args[0]))
+	}
+	if s.Pos < 1 {
+		panic(fmt.Errorf("operator `contains_at` need a position from 1 not %d", s.Pos))
+	}
+	expr := t.base.SubstringIndexSQL(leftCol, s.Pos)
+	if expr == "" {
+		panic(fmt.Errorf("operator `contains_at` is not supported by the driver"))
+	}
+	return fmt.Sprintf("%s > 0", expr), []interface{}{s.Substr}
+}
+
+// generate the predicate of the remainder of leftCol divided by the Modulo args[0].
+func (t *dbTables) getModuloSQL(leftCol string, args []interface{}) (string, []interface{}) {
+	if len(args) != 1 {
+		panic(fmt.Errorf("operator `mod` need 1 args not %d", len(args)))
+	}
+	m, ok := args[0].(Modulo)
+	if !ok {
+		panic(fmt.Errorf("operator `mod` need a Modulo value not `%T`", args[0]))
+	}
+	return fmt.Sprintf("%s = ?", t.base.ModuloSQL(leftCol)), []interface{}{m.Divisor, m.Remainder}
+}
+
+// generate the predicate of the comma-separated values in leftCol including the string args[0].
+func (t *dbTables) getSetIncludesSQL(leftCol string, args []interface{}) (string, []interface{}) {
+	if len(args) != 1 {
+		panic(fmt.Errorf("operator `includes` need 1 args not %d", len(args)))
+	}
+	elem, ok := args[0].(string)
+	if !ok {
+		panic(fmt.Errorf("operator `includes` need a string value not `%T`", args[0]))
+	}
+	if strings.Contains(elem, ",") {
+		panic(fmt.Errorf("operator `includes` value `%s` cannot have a comma", elem))
+	}
+	return t.base.SetIncludesSQL(leftCol, elem)
+}
+
 // generate group sql.
-func (t *dbTables) getGroupSQL(groups []string) (groupSQL string) {
+func (t *dbTables) getGroupSQL(groups []string, buckets []timeBucket, tz *time.Location) (groupSQL string) {
 	if len(groups) == 0 {
 		return
 	}
@@ -410,6 +912,11 @@ func (t *dbTables) getGroupSQL(groups []string) (groupSQL string) {
 
 	groupSqls := make([]string, 0, len(groups))
 	for _, group := range groups {
+		if bucketSQL, _, _, ok := t.getTimeBucketSQL(buckets, group, tz); ok {
+			groupSqls = append(groupSqls, bucketSQL)
+			continue
+		}
+
 		exprs := strings.Split(group, ExprSep)
 
 		index, _, fi, suc := t.parseExprs(t.mi, exprs)
@@ -424,8 +931,249 @@ func (t *dbTables) getGroupSQL(groups []string) (groupSQL string) {
 	return
 }
 
+// generate the select sql of the group columns and the aggregations.
+func (t *dbTables) getAggregationSQL(groups []string, aggrs []Aggregation) string {
+	Q := t.base.TableQuote()
+	cols := make([]string, 0, len(groups)+len(aggrs))
+	for _, group := range groups {
+		cols = append(cols, t.getAggregationColumn(group))
+	}
+	for _, a := range aggrs {
+		cols = append(cols, fmt.Sprintf("%s %s%s%s", t.getAggregationExpr(a), Q, a.alias, Q))
+	}
+	return strings.Join(cols, ", ")
+}
+
+// generate the sql of the column of an aggregation, * is kept as is.
+func (t *dbTables) getAggregationColumn(column string) string {
+	if column == "*" {
+		return column
+	}
+	index, _, fi, suc := t.parseExprs(t.mi, strings.Split(column, ExprSep))
+	if !suc {
+		panic(fmt.Errorf("unknown field/column name `%s`", column))
+	}
+	Q := t.base.TableQuote()
+	return fmt.Sprintf("%s.%s%s%s", index, Q, fi.Column, Q)
+}
+
+// generate the sql of the aggregation without its alias.
+func (t *dbTables) getAggregationExpr(a Aggregation) string {
+	expr := t.getAggregationColumn(a.column)
+	if a.coalesceZero && a.column != "*" {
+		expr = fmt.Sprintf("COALESCE(%s, 0)", expr)
+	}
+	if a.fn == "PERCENTILE_CONT" {
+		if expr = t.base.PercentileSQL(expr, a.percentile); expr == "" {
+			panic(fmt.Errorf("percentile is not supported by the driver"))
+		}
+		return expr
+	}
+	if a.fn == "GROUP_CONCAT" {
+		var orderBy string
+		if a.orderBy != "" {
+			orderBy, _ = t.getOrderSQL(order_clause.ParseOrder(a.orderBy), nil)
+			orderBy = strings.TrimSpace(strings.TrimPrefix(orderBy, "ORDER BY "))
+		}
+		if expr = t.base.GroupConcatSQL(expr, a.separator, orderBy); expr == "" {
+			panic(fmt.Errorf("group concat is not supported by the driver"))
+		}
+		return expr
+	}
+	return fmt.Sprintf("%s(%s)", a.fn, expr)
+}
+
+// generate the HAVING sql of the conditions joined by AND and return args.
+func (t *dbTables) getHavingSQL(havings []HavingCond) (havingSQL string, params []interface{}) {
+	if len(havings) == 0 {
+		return
+	}
+	conds := make([]string, 0, len(havings))
+	for _, h := range havings {
+		expr := t.getAggregationExpr(h.aggrs[0])
+		if len(h.aggrs) == 2 {
+			// 1.0 * avoids the integer division of postgres and sqlite
+			expr = fmt.Sprintf("1.0 * %s / NULLIF(%s, 0)", expr, t.getAggregationExpr(h.aggrs[1]))
+		}
+		conds = append(conds, fmt.Sprintf("%s %s ?", expr, h.operator))
+		params = append(params, h.value)
+	}
+	havingSQL = fmt.Sprintf("HAVING %s ", strings.Join(conds, " AND "))
+	return
+}
+
+// generate the sql of the time bucket named alias, ok is false if there is none.
+// fi is the bucket column, its value is read as datetime, or as the wall time of loc when the bucket has a timezone.
+func (t *dbTables) getTimeBucketSQL(buckets []timeBucket, alias string, tz *time.Location) (bucketSQL string, fi *models.FieldInfo, loc *time.Location, ok bool) {
+	for _, bucket := range buckets {
+		if bucket.alias != alias {
+			continue
+		}
+		index, _, fi, suc := t.parseExprs(t.mi, strings.Split(bucket.column, ExprSep))
+		if !suc {
+			panic(fmt.Errorf("unknown field/column name `%s`", bucket.column))
+		}
+		if fi.FieldType != TypeDateField && fi.FieldType != TypeDateTimeField {
+			panic(fmt.Errorf("time bucket column `%s` must be a date or datetime field", bucket.column))
+		}
+		Q := t.base.TableQuote()
+		col := fmt.Sprintf("%s.%s%s%s", index, Q, fi.Column, Q)
+		if bucket.tz != "" {
+			// the local day of tz starts at another time than the one of UTC, convert before truncating
+			if col = t.base.TimezoneConvertSQL(col, tzOffset(tz), bucket.tz); col == "" {
+				panic(fmt.Errorf("timezone conversion is not supported by the driver"))
+			}
+		}
+		bucketSQL = t.base.TimeBucketSQL(col, bucket.unit)
+		if bucketSQL == "" {
+			panic(fmt.Errorf("time bucket is not supported by the driver"))
+		}
+		bfi := *fi
+		bfi.FieldType = TypeDateTimeField
+		bfi.TimePrecision = nil
+		return bucketSQL, &bfi, bucket.loc, true
+	}
+	return "", nil, nil, false
+}
+
+// generate the sql of the time column converted to a timezone named alias, ok is false if there is none.
+func (t *dbTables) getTimezoneSQL(cols []tzColumn, alias string, tz *time.Location) (tzSQL string, ok bool) {
+	for _, c := range cols {
+		if c.alias != alias {
+			continue
+		}
+		index, _, fi, suc := t.parseExprs(t.mi, strings.Split(c.column, ExprSep))
+		if !suc {
+			panic(fmt.Errorf("unknown field/column name `%s`", c.column))
+		}
+		if fi.FieldType != TypeDateTimeField {
+			panic(fmt.Errorf("timezone column `%s` must be a datetime field", c.column))
+		}
+		Q := t.base.TableQuote()
+		tzSQL = t.base.TimezoneConvertSQL(fmt.Sprintf("%s.%s%s%s", index, Q, fi.Column, Q), tzOffset(tz), c.tz)
+		if tzSQL == "" {
+			panic(fmt.Errorf("timezone conversion is not supported by the driver"))
+		}
+		return tzSQL, true
+	}
+	return "", false
+}
+
+// generate the sql concatenating the column leftCol with the columns cols by sep, and its params of sep.
+func (t *dbTables) getConcatSQL(leftCol string, cols []string, sep string) (string, []interface{}) {
+	Q := t.base.TableQuote()
+	exprs := make([]string, 0, 2*len(cols)+1)
+	exprs = append(exprs, leftCol)
+	var params []interface{}
+	for _, col := range cols {
+		index, _, fi, suc := t.parseExprs(t.mi, strings.Split(col, ExprSep))
+		if !suc {
+			panic(fmt.Errorf("unknown field/column name `%s`", col))
+		}
+		if sep != "" {
+			exprs = append(exprs, "?")
+			params = append(params, sep)
+		}
+		exprs = append(exprs, fmt.Sprintf("%s.%s%s%s", index, Q, fi.Column, Q))
+	}
+	return t.base.ConcatSQL(exprs), params
+}
+
+// check the last of exprs is a field of the model of the rel field before it, such as profile__age,
+// rather than a transform of the same name.
+func isRelFieldExpr(mi *models.ModelInfo, exprs []string) bool {
+	for _, name := range exprs[:len(exprs)-1] {
+		fi, ok := mi.Fields.GetByAny(name)
+		if !ok || fi.RelModelInfo == nil {
+			return false
+		}
+		mi = fi.RelModelInfo
+	}
+	_, ok := mi.Fields.GetByAny(exprs[len(exprs)-1])
+	return ok
+}
+
+// split the nils from the values of an IN, also the ones in slices,
+// the values are returned as is if there is none.
+func splitInNulls(fi *models.FieldInfo, args []interface{}, tz *time.Location) ([]interface{}, bool) {
+	if len(args) == 1 {
+		switch args[0].(type) {
+		case *SubQuery, DBTime, FnValue, TypedValue:
+			return args, false
+		}
+	}
+	if !hasInNull(args) {
+		return args, false
+	}
+	params := getFlatParams(fi, args, tz)
+	values := make([]interface{}, 0, len(params))
+	for _, param := range params {
+		if param != nil {
+			values = append(values, param)
+		}
+	}
+	return values, true
+}
+
+// check the args or their slices have a nil, the nil model pointers of slices are skipped as by getFlatParams.
+func hasInNull(args []interface{}) bool {
+	for _, arg := range args {
+		if arg == nil {
+			return true
+		}
+		val := reflect.ValueOf(arg)
+		if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+			continue
+		}
+		if _, ok := arg.([]byte); ok {
+			continue
+		}
+		for i := 0; i < val.Len(); i++ {
+			if v := val.Index(i); v.Kind() == reflect.Interface && v.IsNil() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// generate the sql of the case expression named alias and its params, ok is false if there is none.
+func (t *dbTables) getCaseSQL(cases []caseColumn, alias string, tz *time.Location) (caseSQL string, params []interface{}, ok bool) {
+	for _, c := range cases {
+		if c.alias != alias {
+			continue
+		}
+		buf := make([]string, 0, len(c.whens)+2)
+		buf = append(buf, "CASE")
+		for _, when := range c.whens {
+			where, args := t.getCondSQL(when.Cond, true, tz)
+			buf = append(buf, fmt.Sprintf("WHEN %sTHEN ?", where))
+			params = append(params, args...)
+			params = append(params, when.Then)
+		}
+		if c.elseVal != nil {
+			buf = append(buf, "ELSE ?")
+			params = append(params, c.elseVal)
+		}
+		buf = append(buf, "END")
+		return strings.Join(buf, " "), params, true
+	}
+	return "", nil, false
+}
+
+// generate the sql of the condition named alias as 1 or 0 and its params, ok is false if there is none.
+func (t *dbTables) getBoolSQL(bools []boolColumn, alias string, tz *time.Location) (boolSQL string, params []interface{}, ok bool) {
+	for _, b := range bools {
+		if b.alias == alias {
+			where, args := t.getCondSQL(b.cond, true, tz)
+			return fmt.Sprintf("CASE WHEN %sTHEN 1 ELSE 0 END", where), args, true
+		}
+	}
+	return "", nil, false
+}
+
 // generate order sql.
-func (t *dbTables) getOrderSQL(orders []*order_clause.Order) (orderSQL string) {
+func (t *dbTables) getOrderSQL(orders []*order_clause.Order, tz *time.Location) (orderSQL string, args []interface{}) {
 	if len(orders) == 0 {
 		return
 	}
@@ -437,7 +1185,33 @@ func (t *dbTables) getOrderSQL(orders []*order_clause.Order) (orderSQL string) {
 		column := order.GetColumn()
 		clause := strings.Split(column, clauses.ExprDot)
 
-		if order.IsRaw() {
+		if coalesce := order.GetCoalesce(); len(coalesce) > 0 {
+			cols := make([]string, 0, len(coalesce))
+			for _, col := range coalesce {
+				clause := strings.Split(col, clauses.ExprDot)
+				index, _, fi, suc := t.parseExprs(t.mi, clause)
+				if !suc {
+					panic(fmt.Errorf("unknown field/column name `%s`", strings.Join(clause, ExprSep)))
+				}
+				cols = append(cols, fmt.Sprintf("%s.%s%s%s", index, Q, fi.Column, Q))
+			}
+			orderSqls = append(orderSqls, fmt.Sprintf("COALESCE(%s) %s", strings.Join(cols, ", "), order.SortString()))
+		} else if lngColumn, lat, lng, ok := order.GetDistance(); ok {
+			cols := make([]string, 0, 2)
+			for _, clause := range [][]string{clause, strings.Split(lngColumn, clauses.ExprDot)} {
+				index, _, fi, suc := t.parseExprs(t.mi, clause)
+				if !suc {
+					panic(fmt.Errorf("unknown field/column name `%s`", strings.Join(clause, ExprSep)))
+				}
+				cols = append(cols, fmt.Sprintf("%s.%s%s%s", index, Q, fi.Column, Q))
+			}
+			// the squared equirectangular distance, the longitude scaled by the cosine of the latitude of the point,
+			// which sorts like the great circle distance for the nearby points and needs no trigonometric functions.
+			scale := math.Cos(lat * math.Pi / 180)
+			orderSqls = append(orderSqls, fmt.Sprintf("((%s - ?) * (%s - ?) + (%s - ?) * (%s - ?) * ?) %s",
+				cols[0], cols[0], cols[1], cols[1], order.SortString()))
+			args = append(args, lat, lat, lng, lng, scale*scale)
+		} else if order.IsRaw() {
 			if len(clause) == 2 {
 				orderSqls = append(orderSqls, fmt.Sprintf("%s.%s %s", clause[0], clause[1], order.SortString()))
 			} else if len(clause) == 1 {
@@ -451,7 +1225,19 @@ func (t *dbTables) getOrderSQL(orders []*order_clause.Order) (orderSQL string) {
 				panic(fmt.Errorf("unknown field/column name `%s`", strings.Join(clause, ExprSep)))
 			}
 
-			orderSqls = append(orderSqls, fmt.Sprintf("%s.%s%s%s %s", index, Q, fi.Column, Q, order.SortString()))
+			col := fmt.Sprintf("%s.%s%s%s", index, Q, fi.Column, Q)
+			if values := order.GetValues(); len(values) > 0 {
+				params := getFlatParams(fi, values, tz)
+				col = t.base.PositionSQL(col, len(params))
+				args = append(args, params...)
+			}
+			if collate := order.GetCollate(); collate != "" {
+				if !t.base.SupportsInlineCollate() {
+					panic(fmt.Errorf("COLLATE in order is not supported by the driver"))
+				}
+				col = fmt.Sprintf("%s COLLATE %s", col, collate)
+			}
+			orderSqls = append(orderSqls, fmt.Sprintf("%s %s", col, order.SortString()))
 		}
 	}
 
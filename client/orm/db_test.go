@@ -15,6 +15,8 @@
 package orm
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
@@ -1423,16 +1425,3767 @@ func TestDbBase_countSQL(t *testing.T) {
 			wantRes:  `SELECT COUNT(*) FROM (SELECT COUNT(*) FROM "test_tab" T0 INNER JOIN "test_tab1" T1 ON T1."id" = T0."test_tab_1_id" INNER JOIN "test_tab2" T2 ON T2."id" = T1."test_tab_2_id" WHERE T0."name" = $1 OR ( T0."age" > $2 AND T0."score" < $3 ) GROUP BY T0."name", T0."age" ) AS T`,
 			wantArgs: []interface{}{"test_name", int64(18), int64(60)},
 		},
+		{
+			name: "count column with MySQL",
+			db: &dbBase{
+				ins: newdbBaseMysql(),
+			},
+			qs: querySet{
+				mi:       mi,
+				cond:     cond,
+				countCol: "age",
+			},
+			wantRes:  "SELECT COUNT(T0.`age`) FROM `test_tab` T0 WHERE T0.`name` = ? OR ( T0.`age` > ? AND T0.`score` < ? ) ",
+			wantArgs: []interface{}{"test_name", int64(18), int64(60)},
+		},
+		{
+			name: "count related column with PostgreSQL",
+			db: &dbBase{
+				ins: newdbBasePostgres(),
+			},
+			qs: querySet{
+				mi:       mi,
+				cond:     cond,
+				countCol: "TestTab1__name_1",
+			},
+			wantRes:  `SELECT COUNT(T1."name_1") FROM "test_tab" T0 INNER JOIN "test_tab1" T1 ON T1."id" = T0."test_tab_1_id" WHERE T0."name" = $1 OR ( T0."age" > $2 AND T0."score" < $3 ) `,
+			wantArgs: []interface{}{"test_name", int64(18), int64(60)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, args := tc.db.countSQL(tc.qs, mi, cond, tz)
+
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestDbBase_groupCountSQL(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	cond := NewCondition().And("score__gt", 60)
+
+	tz := time.Local
+
+	testCases := []struct {
+		name string
+		db   *dbBase
+
+		qs       querySet
+		groupCol string
+
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name: "group count with MySQL",
+			db: &dbBase{
+				ins: newdbBaseMysql(),
+			},
+			qs: querySet{
+				mi:   mi,
+				cond: cond,
+				orders: []*order_clause.Order{
+					order_clause.Clause(order_clause.Column("score"),
+						order_clause.SortDescending()),
+				},
+			},
+			groupCol: "age",
+			wantRes:  "SELECT T0.`age`, COUNT(*) FROM `test_tab` T0 WHERE T0.`score` > ? GROUP BY T0.`age` ",
+			wantArgs: []interface{}{int64(60)},
+		},
+		{
+			name: "group count with PostgreSQL",
+			db: &dbBase{
+				ins: newdbBasePostgres(),
+			},
+			qs: querySet{
+				mi:   mi,
+				cond: cond,
+			},
+			groupCol: "age",
+			wantRes:  `SELECT T0."age", COUNT(*) FROM "test_tab" T0 WHERE T0."score" > $1 GROUP BY T0."age" `,
+			wantArgs: []interface{}{int64(60)},
+		},
+		{
+			name: "group count on related column with MySQL",
+			db: &dbBase{
+				ins: newdbBaseMysql(),
+			},
+			qs: querySet{
+				mi:   mi,
+				cond: cond,
+			},
+			groupCol: "TestTab1__name_1",
+			wantRes:  "SELECT T1.`name_1`, COUNT(*) FROM `test_tab` T0 INNER JOIN `test_tab1` T1 ON T1.`id` = T0.`test_tab_1_id` WHERE T0.`score` > ? GROUP BY T1.`name_1` ",
+			wantArgs: []interface{}{int64(60)},
+		},
+		{
+			name: "group count column with PostgreSQL",
+			db: &dbBase{
+				ins: newdbBasePostgres(),
+			},
+			qs: querySet{
+				mi:       mi,
+				cond:     cond,
+				countCol: "name",
+			},
+			groupCol: "age",
+			wantRes:  `SELECT T0."age", COUNT(T0."name") FROM "test_tab" T0 WHERE T0."score" > $1 GROUP BY T0."age" `,
+			wantArgs: []interface{}{int64(60)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, args := tc.db.groupCountSQL(tc.qs, mi, cond, tc.groupCol, tz)
+
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestQuerySet_FilterByExample(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	testCases := []struct {
+		name    string
+		example *testTab
+
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "example with non-zero fields",
+			example:  &testTab{Name: "slene", Age: 28},
+			wantRes:  "WHERE T0.`name` = ? AND T0.`age` = ? ",
+			wantArgs: []interface{}{"slene", int64(28)},
+		},
+		{
+			name:     "example with related model",
+			example:  &testTab{Score: 60, TestTab1: &testTab1{ID: 3}},
+			wantRes:  "WHERE T0.`score` = ? AND T0.`test_tab_1_id` = ? ",
+			wantArgs: []interface{}{int64(60), int64(3)},
+		},
+		{
+			name:     "example with related model without pk",
+			example:  &testTab{ID: 1, TestTab1: &testTab1{Name1: "name"}},
+			wantRes:  "WHERE T0.`id` = ? ",
+			wantArgs: []interface{}{int64(1)},
+		},
+		{
+			name:    "example with zero fields",
+			example: &testTab{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			qs := querySet{mi: mi}
+			cond := qs.FilterByExample(tc.example).GetCond()
+
+			tables := newDbTables(mi, newdbBaseMysql())
+			res, args := tables.getCondSQL(cond, false, tz)
+
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+
+	assert.Panics(t, func() {
+		qs := querySet{mi: mi}
+		qs.FilterByExample(&testTab1{ID: 1})
+	})
+}
+
+func TestQuerySet_FilterCoalesce(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	qs := querySet{mi: mi, limit: -1}
+	cond := qs.Filter("age", 18).FilterCoalesce("name", "active", "", "active").
+		FilterCoalesce("TestTab1__Score1", 60, "gte", 50).GetCond()
+
+	tables := newDbTables(mi, newdbBaseMysql())
+	res, args := tables.getCondSQL(cond, false, tz)
+	assert.Equal(t, "WHERE T0.`age` = ? AND COALESCE(T0.`name`, ?) = ? AND COALESCE(T1.`score_1`, ?) >= ? ", res)
+	assert.Equal(t, []interface{}{int64(18), "active", "active", int64(60), int64(50)}, args)
+
+	db := newdbBasePostgres().(*dbBasePostgres)
+	tables = newDbTables(mi, db)
+	res, args = db.readBatchSQL(tables, []string{"id"}, cond, qs, mi, tz)
+	assert.Equal(t, `SELECT T0."id" FROM "test_tab" T0 INNER JOIN "test_tab1" T1 ON T1."id" = T0."test_tab_1_id" WHERE T0."age" = $1 AND COALESCE(T0."name", $2) = $3 AND COALESCE(T1."score_1", $4) >= $5 `, res)
+	assert.Equal(t, []interface{}{int64(18), "active", "active", int64(60), int64(50)}, args)
+
+	assert.Panics(t, func() {
+		qs.FilterCoalesce("name", "", "unknown", "")
+	})
+}
+
+func TestQuerySet_FilterCollate(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	testCases := []struct {
+		name      string
+		db        dbBaser
+		collation string
+		operator  string
+
+		wantRes string
+	}{
+		{
+			name:      "collate with MySQL",
+			db:        newdbBaseMysql(),
+			collation: "utf8mb4_general_ci",
+			wantRes:   "WHERE T0.`name` COLLATE utf8mb4_general_ci = ? ",
+		},
+		{
+			name:      "collate with TiDB",
+			db:        newdbBaseTidb(),
+			collation: "utf8mb4_bin",
+			operator:  "gt",
+			wantRes:   "WHERE T0.`name` COLLATE utf8mb4_bin > ? ",
+		},
+		{
+			name:      "collate with PostgreSQL",
+			db:        newdbBasePostgres(),
+			collation: `"C"`,
+			operator:  "contains",
+			wantRes:   `WHERE T0."name"::text COLLATE "C" LIKE ? ESCAPE '\' `,
+		},
+		{
+			name:      "collate with sqlite",
+			db:        newdbBaseSqlite(),
+			collation: "NOCASE",
+			wantRes:   "WHERE T0.`name` COLLATE NOCASE = ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			qs := querySet{mi: mi}
+			cond := qs.FilterCollate("Name", tc.collation, tc.operator, "slene").GetCond()
+
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, 1, len(args))
+		})
+	}
+
+	assert.Panics(t, func() {
+		cond := querySet{mi: mi}.FilterCollate("Name", "BINARY", "", "slene").GetCond()
+		newDbTables(mi, newdbBaseOracle()).getCondSQL(cond, false, tz)
+	})
+	assert.Panics(t, func() {
+		querySet{mi: mi}.FilterCollate("Name", "utf8mb4_bin; DROP TABLE test_tab", "", "slene")
+	})
+}
+
+func TestQuerySet_Search(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	testCases := []struct {
+		name    string
+		db      dbBaser
+		columns []string
+
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "mysql columns",
+			db:       newdbBaseMysql(),
+			columns:  []string{"Name", "Name", "TestTab1__Name1"},
+			wantRes:  "WHERE ( T0.`name` LIKE ? ESCAPE '\\\\' OR T1.`name_1` LIKE ? ESCAPE '\\\\' ) ",
+			wantArgs: []interface{}{"%sle%", "%sle%"},
+		},
+		{
+			name:     "postgres upper",
+			db:       newdbBasePostgres(),
+			columns:  []string{"Name", "TestTab1__Name1"},
+			wantRes:  `WHERE ( UPPER(T0."name"::text) LIKE UPPER(?) ESCAPE '\' OR UPPER(T1."name_1"::text) LIKE UPPER(?) ESCAPE '\' ) `,
+			wantArgs: []interface{}{"%sle%", "%sle%"},
+		},
+		{
+			name: "no columns",
+			db:   newdbBaseMysql(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			qs := querySet{mi: mi}
+			cond := qs.Search("sle", tc.columns...).GetCond()
+
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestDbBase_TimeBucketSQL(t *testing.T) {
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantHour string
+		wantDay  string
+	}{
+		{
+			name:     "mysql",
+			db:       newdbBaseMysql(),
+			wantHour: "DATE_FORMAT(T0.`created`, '%Y-%m-%d %H:00:00')",
+			wantDay:  "DATE_FORMAT(T0.`created`, '%Y-%m-%d 00:00:00')",
+		},
+		{
+			name:     "tidb",
+			db:       newdbBaseTidb(),
+			wantHour: "DATE_FORMAT(T0.`created`, '%Y-%m-%d %H:00:00')",
+			wantDay:  "DATE_FORMAT(T0.`created`, '%Y-%m-%d 00:00:00')",
+		},
+		{
+			name:     "postgres",
+			db:       newdbBasePostgres(),
+			wantHour: "date_trunc('hour', T0.`created`)",
+			wantDay:  "date_trunc('day', T0.`created`)",
+		},
+		{
+			name:     "sqlite",
+			db:       newdbBaseSqlite(),
+			wantHour: "strftime('%Y-%m-%d %H:00:00', T0.`created`)",
+			wantDay:  "strftime('%Y-%m-%d 00:00:00', T0.`created`)",
+		},
+		{
+			name:     "oracle",
+			db:       newdbBaseOracle(),
+			wantHour: "TRUNC(T0.`created`, 'HH24')",
+			wantDay:  "TRUNC(T0.`created`, 'DD')",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantHour, tc.db.TimeBucketSQL("T0.`created`", "hour"))
+			assert.Equal(t, tc.wantDay, tc.db.TimeBucketSQL("T0.`created`", "day"))
+		})
+	}
+}
+
+func TestDbTables_getTimeBucketSQLWithTZ(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTimeTab))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTimeTab))
+
+	assert.True(t, ok)
+
+	qs := querySet{mi: mi}.TimeBucketTZ("created", "day", "day", "Asia/Tokyo").(*querySet)
+	// the times are stored in the timezone of the db alias
+	tz := time.FixedZone("", 8*3600)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "DATE_FORMAT(CONVERT_TZ(T0.`created`, '+08:00', 'Asia/Tokyo'), '%Y-%m-%d 00:00:00')",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `date_trunc('day', (T0."created" AT TIME ZONE 'Asia/Tokyo'))`,
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "TRUNC((FROM_TZ(CAST(T0.`created` AS TIMESTAMP), '+08:00') AT TIME ZONE 'Asia/Tokyo'), 'DD')",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, fi, loc, ok := tables.getTimeBucketSQL(qs.buckets, "day", tz)
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, TypeDateTimeField, fi.FieldType)
+			assert.Equal(t, "Asia/Tokyo", loc.String())
+			assert.Equal(t, "GROUP BY "+tc.wantRes+" ", tables.getGroupSQL([]string{"day"}, qs.buckets, tz))
+		})
+	}
+
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseSqlite()).getTimeBucketSQL(qs.buckets, "day", tz)
+	})
+	assert.Panics(t, func() {
+		querySet{mi: mi}.TimeBucketTZ("created", "day", "day", "Nowhere/Unknown")
+	})
+
+	// the buckets are labeled in the timezone
+	loc := qs.buckets[0].loc
+	day, err := wallTimeIn("2024-03-02 00:00:00", loc)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, loc), day)
+	day, err = wallTimeIn(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), loc)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, loc), day)
+}
+
+func TestDbBase_GenerateOperatorSQLEscapeLike(t *testing.T) {
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantContains   string
+		wantIStartWith string
+	}{
+		{
+			name:           "mysql",
+			db:             newdbBaseMysql(),
+			wantContains:   `LIKE BINARY ? ESCAPE '\\'`,
+			wantIStartWith: `LIKE ? ESCAPE '\\'`,
+		},
+		{
+			name:           "tidb",
+			db:             newdbBaseTidb(),
+			wantContains:   `LIKE BINARY ? ESCAPE '\\'`,
+			wantIStartWith: `LIKE ? ESCAPE '\\'`,
+		},
+		{
+			name:           "postgres",
+			db:             newdbBasePostgres(),
+			wantContains:   `LIKE ? ESCAPE '\'`,
+			wantIStartWith: `LIKE UPPER(?) ESCAPE '\'`,
+		},
+		{
+			name:           "sqlite",
+			db:             newdbBaseSqlite(),
+			wantContains:   `LIKE ? ESCAPE '\'`,
+			wantIStartWith: `LIKE ? ESCAPE '\'`,
+		},
+		{
+			name:           "oracle",
+			db:             newdbBaseOracle(),
+			wantContains:   `LIKE ? ESCAPE '\'`,
+			wantIStartWith: `LIKE UPPER(?) ESCAPE '\'`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, args := tc.db.GenerateOperatorSQL(nil, nil, "contains", []interface{}{`50%_\`}, time.Local)
+			assert.Equal(t, tc.wantContains, sql)
+			assert.Equal(t, []interface{}{`%50\%\_\\%`}, args)
+
+			sql, args = tc.db.GenerateOperatorSQL(nil, nil, "istartswith", []interface{}{"50%"}, time.Local)
+			assert.Equal(t, tc.wantIStartWith, sql)
+			assert.Equal(t, []interface{}{`50\%%`}, args)
+		})
+	}
+
+	// a LIKE on mysql, not on postgres
+	sql, args := newdbBaseMysql().GenerateOperatorSQL(nil, nil, "iexact", []interface{}{"50%"}, time.Local)
+	assert.Equal(t, `LIKE ? ESCAPE '\\'`, sql)
+	assert.Equal(t, []interface{}{`50\%`}, args)
+	_, args = newdbBasePostgres().GenerateOperatorSQL(nil, nil, "iexact", []interface{}{"50%"}, time.Local)
+	assert.Equal(t, []interface{}{"50%"}, args)
+}
+
+func TestDbBase_GenerateOperatorSQLWithDBTime(t *testing.T) {
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantBefore string
+		wantAfter  string
+	}{
+		{
+			name:       "mysql",
+			db:         newdbBaseMysql(),
+			wantBefore: "< NOW() - INTERVAL 7 DAY",
+			wantAfter:  "> NOW() - INTERVAL 7 DAY + INTERVAL 2 HOUR",
+		},
+		{
+			name:       "tidb",
+			db:         newdbBaseTidb(),
+			wantBefore: "< NOW() - INTERVAL 7 DAY",
+			wantAfter:  "> NOW() - INTERVAL 7 DAY + INTERVAL 2 HOUR",
+		},
+		{
+			name:       "postgres",
+			db:         newdbBasePostgres(),
+			wantBefore: "< CURRENT_TIMESTAMP - INTERVAL '7 day'",
+			wantAfter:  "> CURRENT_TIMESTAMP - INTERVAL '7 day' + INTERVAL '2 hour'",
+		},
+		{
+			name:       "sqlite",
+			db:         newdbBaseSqlite(),
+			wantBefore: "< datetime('now', 'localtime', '-7 days')",
+			wantAfter:  "> datetime(datetime('now', 'localtime', '-7 days'), '+2 hours')",
+		},
+		{
+			name:       "oracle",
+			db:         newdbBaseOracle(),
+			wantBefore: "< SYSTIMESTAMP + NUMTODSINTERVAL(-7, 'DAY')",
+			wantAfter:  "> SYSTIMESTAMP + NUMTODSINTERVAL(-7, 'DAY') + NUMTODSINTERVAL(2, 'HOUR')",
+		},
+	}
+
+	weekAgo := Now().Minus(7, Day)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, args := tc.db.GenerateOperatorSQL(nil, nil, "before", []interface{}{weekAgo}, time.Local)
+			assert.Equal(t, tc.wantBefore, sql)
+			assert.Empty(t, args)
+
+			sql, args = tc.db.GenerateOperatorSQL(nil, nil, "after", []interface{}{weekAgo.Plus(2, Hour)}, time.Local)
+			assert.Equal(t, tc.wantAfter, sql)
+			assert.Empty(t, args)
+		})
+	}
+
+	assert.Panics(t, func() {
+		newdbBaseMysql().GenerateOperatorSQL(nil, nil, "in", []interface{}{weekAgo}, time.Local)
+	})
+	assert.Panics(t, func() {
+		Now().Plus(1, "week")
+	})
+}
+
+func TestDbTables_getCondSQLWithRecent(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTimeTab))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTimeTab))
+
+	assert.True(t, ok)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE T0.`created` >= NOW() - INTERVAL 1 HOUR ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE T0."created" >= CURRENT_TIMESTAMP - INTERVAL '1 hour' `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE T0.`created` >= datetime('now', 'localtime', '-1 hours') ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE T0.`created` >= SYSTIMESTAMP + NUMTODSINTERVAL(-1, 'HOUR') ",
+		},
+	}
+
+	qs := querySet{mi: mi}.FilterRecent("created", time.Hour).(*querySet)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, args := newDbTables(mi, tc.db).getCondSQL(qs.cond, false, time.Local)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Empty(t, args)
+		})
+	}
+
+	assert.Equal(t, Now().Minus(2, Day), recentDBTime(48*time.Hour))
+	assert.Equal(t, Now().Minus(90, Minute), recentDBTime(90*time.Minute))
+	assert.Equal(t, Now().Minus(61, Second), recentDBTime(61*time.Second))
+	assert.Panics(t, func() {
+		recentDBTime(0)
+	})
+	assert.Panics(t, func() {
+		recentDBTime(1500 * time.Millisecond)
+	})
+}
+
+func TestDbTables_getCondSQLWithIncludes(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "mysql",
+			db:       newdbBaseMysql(),
+			wantRes:  "WHERE FIND_IN_SET(?, T0.`name`) > 0 ",
+			wantArgs: []interface{}{"write_all"},
+		},
+		{
+			name:     "tidb",
+			db:       newdbBaseTidb(),
+			wantRes:  "WHERE FIND_IN_SET(?, T0.`name`) > 0 ",
+			wantArgs: []interface{}{"write_all"},
+		},
+		{
+			name:     "postgres",
+			db:       newdbBasePostgres(),
+			wantRes:  `WHERE (',' || T0."name" || ',') LIKE ? ESCAPE '\' `,
+			wantArgs: []interface{}{`%,write\_all,%`},
+		},
+		{
+			name:     "sqlite",
+			db:       newdbBaseSqlite(),
+			wantRes:  "WHERE (',' || T0.`name` || ',') LIKE ? ESCAPE '\\' ",
+			wantArgs: []interface{}{`%,write\_all,%`},
+		},
+	}
+
+	cond := NewCondition().And("name__includes", "write_all")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, args := newDbTables(mi, tc.db).getCondSQL(cond, false, time.Local)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseMysql()).getCondSQL(NewCondition().And("name__includes", "read,write"), false, time.Local)
+	})
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseMysql()).getCondSQL(NewCondition().And("name__includes", 1), false, time.Local)
+	})
+}
+
+func TestDbTables_getCondSQLWithBareBool(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testBoolTab))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testBoolTab))
+
+	assert.True(t, ok)
+
+	filter := querySet{mi: mi}.Filter("is_active").Filter("name", "slene").(*querySet)
+	exclude := querySet{mi: mi}.Exclude("IsActive").(*querySet)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantFilter  string
+		wantExclude string
+	}{
+		{
+			name:        "mysql",
+			db:          newdbBaseMysql(),
+			wantFilter:  "WHERE T0.`is_active` = TRUE AND T0.`name` = ? ",
+			wantExclude: "WHERE NOT T0.`is_active` = TRUE ",
+		},
+		{
+			name:        "postgres",
+			db:          newdbBasePostgres(),
+			wantFilter:  `WHERE T0."is_active" = TRUE AND T0."name" = ? `,
+			wantExclude: `WHERE NOT T0."is_active" = TRUE `,
+		},
+		{
+			name:        "sqlite",
+			db:          newdbBaseSqlite(),
+			wantFilter:  "WHERE T0.`is_active` = 1 AND T0.`name` = ? ",
+			wantExclude: "WHERE NOT T0.`is_active` = 1 ",
+		},
+		{
+			name:        "oracle",
+			db:          newdbBaseOracle(),
+			wantFilter:  "WHERE T0.`is_active` = 1 AND T0.`name` = ? ",
+			wantExclude: "WHERE NOT T0.`is_active` = 1 ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(filter.cond, false, time.Local)
+			assert.Equal(t, tc.wantFilter, res)
+			assert.Equal(t, []interface{}{"slene"}, args)
+
+			res, args = tables.getCondSQL(exclude.cond, false, time.Local)
+			assert.Equal(t, tc.wantExclude, res)
+			assert.Empty(t, args)
+		})
+	}
+
+	// the other fields still need args
+	assert.Panics(t, func() {
+		querySet{mi: mi}.Filter("name")
+	})
+}
+
+func TestDbTables_getCondSQLWithEmptyAsNull(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testEmptyTab))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testEmptyTab))
+
+	assert.True(t, ok)
+	assert.True(t, mi.Fields.GetByName("Nick").Null)
+
+	testCases := []struct {
+		name string
+		cond *Condition
+
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "empty",
+			cond:     NewCondition().And("nick", ""),
+			wantRes:  "WHERE (T0.`nick` IS NULL OR T0.`nick` = ?) ",
+			wantArgs: []interface{}{""},
+		},
+		{
+			name:     "not empty",
+			cond:     NewCondition().AndNot("nick__exact", ""),
+			wantRes:  "WHERE NOT (T0.`nick` IS NULL OR T0.`nick` = ?) ",
+			wantArgs: []interface{}{""},
+		},
+		{
+			name:     "trimmed empty",
+			cond:     NewCondition().And("nick__trim", ""),
+			wantRes:  "WHERE (TRIM(T0.`nick`) IS NULL OR TRIM(T0.`nick`) = ?) ",
+			wantArgs: []interface{}{""},
+		},
+		{
+			name:     "value",
+			cond:     NewCondition().And("nick", "slene"),
+			wantRes:  "WHERE T0.`nick` = ? ",
+			wantArgs: []interface{}{"slene"},
+		},
+		{
+			name:     "field without the tag",
+			cond:     NewCondition().And("name", ""),
+			wantRes:  "WHERE T0.`name` = ? ",
+			wantArgs: []interface{}{""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, args := newDbTables(mi, newdbBaseMysql()).getCondSQL(tc.cond, false, time.Local)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestDbBase_BoolLiteral(t *testing.T) {
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantTrue  string
+		wantFalse string
+	}{
+		{
+			name:      "bool literal with MySQL",
+			db:        newdbBaseMysql(),
+			wantTrue:  "TRUE",
+			wantFalse: "FALSE",
+		},
+		{
+			name:      "bool literal with PostgreSQL",
+			db:        newdbBasePostgres(),
+			wantTrue:  "TRUE",
+			wantFalse: "FALSE",
+		},
+		{
+			name:      "bool literal with TiDB",
+			db:        newdbBaseTidb(),
+			wantTrue:  "TRUE",
+			wantFalse: "FALSE",
+		},
+		{
+			name:      "bool literal with sqlite",
+			db:        newdbBaseSqlite(),
+			wantTrue:  "1",
+			wantFalse: "0",
+		},
+		{
+			name:      "bool literal with Oracle",
+			db:        newdbBaseOracle(),
+			wantTrue:  "1",
+			wantFalse: "0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantTrue, tc.db.BoolLiteral(true))
+			assert.Equal(t, tc.wantFalse, tc.db.BoolLiteral(false))
+		})
+	}
+}
+
+func TestDbTables_getCondSQLWithLength(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+		cond *Condition
+
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "length with MySQL",
+			db:       newdbBaseMysql(),
+			cond:     NewCondition().And("name__len__gt", 10),
+			wantRes:  "WHERE CHAR_LENGTH(T0.`name`) > ? ",
+			wantArgs: []interface{}{int64(10)},
+		},
+		{
+			name:     "length with TiDB",
+			db:       newdbBaseTidb(),
+			cond:     NewCondition().And("name__len__lte", 10),
+			wantRes:  "WHERE CHAR_LENGTH(T0.`name`) <= ? ",
+			wantArgs: []interface{}{int64(10)},
+		},
+		{
+			name:     "length with PostgreSQL",
+			db:       newdbBasePostgres(),
+			cond:     NewCondition().And("name__len__gt", 10),
+			wantRes:  `WHERE LENGTH(T0."name") > ? `,
+			wantArgs: []interface{}{int64(10)},
+		},
+		{
+			name:     "length with sqlite",
+			db:       newdbBaseSqlite(),
+			cond:     NewCondition().And("name__len", 5),
+			wantRes:  "WHERE LENGTH(T0.`name`) = ? ",
+			wantArgs: []interface{}{int64(5)},
+		},
+		{
+			name:     "length with Oracle",
+			db:       newdbBaseOracle(),
+			cond:     NewCondition().And("name__len__in", 3, 4),
+			wantRes:  "WHERE LENGTH(T0.`name`) IN (?, ?) ",
+			wantArgs: []interface{}{int64(3), int64(4)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(tc.cond, false, tz)
+
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestDbTables_getCondSQLWithFn(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("name__lower", Fn("lower", "Slene")).And("score__abs__gt", Fn("ABS", -3))
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE LOWER(T0.`name`) = LOWER(?) AND ABS(T0.`score`) > ABS(?) ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE LOWER(T0."name") = LOWER(?) AND ABS(T0."score") > ABS(?) `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE LOWER(T0.`name`) = LOWER(?) AND ABS(T0.`score`) > ABS(?) ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE LOWER(T0.`name`) = LOWER(?) AND ABS(T0.`score`) > ABS(?) ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{"Slene", int64(-3)}, args)
+		})
+	}
+
+	assert.Panics(t, func() {
+		tables := newDbTables(mi, newdbBaseMysql())
+		tables.getCondSQL(NewCondition().And("name__contains", Fn("LOWER", "s")), false, tz)
+	})
+}
+
+func TestDbTables_getCondSQLWithTyped(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("name", Typed("slene", "nvarchar(50)")).
+		And("age__in", Typed([]int{18, 20}, "int")).
+		And("score__between", Typed([]float64{1, 2}, "decimal(10, 2)"))
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE T0.`name` = CAST(? AS NVARCHAR(50)) AND T0.`age` IN (CAST(? AS INT), CAST(? AS INT)) AND T0.`score` BETWEEN CAST(? AS DECIMAL(10, 2)) AND CAST(? AS DECIMAL(10, 2)) ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE T0."name" = CAST(? AS NVARCHAR(50)) AND T0."age" IN (CAST(? AS INT), CAST(? AS INT)) AND T0."score" BETWEEN CAST(? AS DECIMAL(10, 2)) AND CAST(? AS DECIMAL(10, 2)) `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE T0.`name` = CAST(? AS NVARCHAR(50)) AND T0.`age` IN (CAST(? AS INT), CAST(? AS INT)) AND T0.`score` BETWEEN CAST(? AS DECIMAL(10, 2)) AND CAST(? AS DECIMAL(10, 2)) ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{"slene", int64(18), int64(20), float64(1), float64(2)}, args)
+		})
+	}
+
+	assert.Panics(t, func() {
+		tables := newDbTables(mi, newdbBaseMysql())
+		tables.getCondSQL(NewCondition().And("name__contains", Typed("s", "nvarchar(50)")), false, tz)
+	})
+	assert.Panics(t, func() { Typed("s", "nvarchar(50)) OR 1=1 --") })
+}
+
+func TestDbTables_getCondSQLWithSubQuery(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	mi1, ok := mc.GetByMd(new(testTab1))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	sub := NewSubQuery(&querySet{mi: mi1, cond: NewCondition().And("name_1", "sub")}, "Score1")
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+		cond *Condition
+
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "all with MySQL",
+			db:       newdbBaseMysql(),
+			cond:     NewCondition().And("age", 18).And("score__gt_all", sub).And("name", "outer"),
+			wantRes:  "WHERE T0.`age` = ? AND T0.`score` > ALL (SELECT T0.`score_1` FROM `test_tab1` T0 WHERE T0.`name_1` = ?) AND T0.`name` = ? ",
+			wantArgs: []interface{}{int64(18), "sub", "outer"},
+		},
+		{
+			name:     "any with Oracle",
+			db:       newdbBaseOracle(),
+			cond:     NewCondition().And("score__ne_any", sub),
+			wantRes:  "WHERE T0.`score` <> ANY (SELECT T0.`score_1` FROM `test_tab1` T0 WHERE T0.`name_1` = ?) ",
+			wantArgs: []interface{}{"sub"},
+		},
+		{
+			name:     "in with Sqlite",
+			db:       newdbBaseSqlite(),
+			cond:     NewCondition().And("score__in", sub),
+			wantRes:  "WHERE T0.`score` IN (SELECT T0.`score_1` FROM `test_tab1` T0 WHERE T0.`name_1` = ?) ",
+			wantArgs: []interface{}{"sub"},
+		},
+		{
+			name: "exclude in with Postgres",
+			db:   newdbBasePostgres(),
+			cond: NewCondition().And("age", 18).AndNot("score__in", sub),
+			wantRes: `WHERE T0."age" = ? AND NOT EXISTS (SELECT 1 FROM (SELECT T0."score_1" FROM "test_tab1" T0 WHERE T0."name_1" = ?) S ` +
+				`WHERE S."score_1" = T0."score") `,
+			wantArgs: []interface{}{int64(18), "sub"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(tc.cond, false, tz)
+
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+
+	// marks of the sub query are numbered with the whole query
+	db := newdbBasePostgres().(*dbBasePostgres)
+	cond := NewCondition().And("age", 18).And("score__lte_any", sub).And("name", "outer")
+	tables := newDbTables(mi, db)
+	res, args := db.readBatchSQL(tables, []string{"id"}, cond, querySet{mi: mi, limit: -1}, mi, tz)
+	assert.Equal(t, `SELECT T0."id" FROM "test_tab" T0 WHERE T0."age" = $1 AND T0."score" <= ANY (SELECT T0."score_1" FROM "test_tab1" T0 WHERE T0."name_1" = $2) AND T0."name" = $3 `, res)
+	assert.Equal(t, []interface{}{int64(18), "sub", "outer"}, args)
+
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseSqlite()).getCondSQL(NewCondition().And("score__gt_all", sub), false, tz)
+	})
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseMysql()).getCondSQL(NewCondition().And("score__gt_all", 1), false, tz)
+	})
+}
+
+func TestDbTables_getCondSQLWithSimilar(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("age", 18).And("name__similar", Similar("john", 0.3))
+
+	db := newdbBasePostgres().(*dbBasePostgres)
+	tables := newDbTables(mi, db)
+	res, args := db.readBatchSQL(tables, []string{"id"}, cond, querySet{mi: mi, limit: -1}, mi, tz)
+	assert.Equal(t, `SELECT T0."id" FROM "test_tab" T0 WHERE T0."age" = $1 AND similarity(T0."name", $2) > $3 `, res)
+	assert.Equal(t, []interface{}{int64(18), "john", 0.3}, args)
+
+	for _, db := range []dbBaser{newdbBaseMysql(), newdbBaseSqlite(), newdbBaseOracle(), newdbBaseTidb()} {
+		assert.Panics(t, func() {
+			newDbTables(mi, db).getCondSQL(cond, false, tz)
+		})
+	}
+	assert.Panics(t, func() {
+		newDbTables(mi, db).getCondSQL(NewCondition().And("name__similar", "john"), false, tz)
+	})
+}
+
+func TestDbTables_getCondSQLWithSoundex(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("name__soundex", "smith")
+
+	db := newdbBaseMysql().(*dbBaseMysql)
+	tables := newDbTables(mi, db)
+	res, args := db.readBatchSQL(tables, []string{"id"}, cond, querySet{mi: mi, limit: -1}, mi, tz)
+	assert.Equal(t, "SELECT T0.`id` FROM `test_tab` T0 WHERE SOUNDEX(T0.`name`) = SOUNDEX(?) ", res)
+	assert.Equal(t, []interface{}{"smith"}, args)
+
+	where, _ := newDbTables(mi, newdbBasePostgres()).getCondSQL(cond, false, tz)
+	assert.Equal(t, `WHERE soundex(T0."name") = soundex(?) `, where)
+
+	where, _ = newDbTables(mi, newdbBaseOracle()).getCondSQL(cond, false, tz)
+	assert.Equal(t, "WHERE SOUNDEX(T0.`name`) = SOUNDEX(?) ", where)
+
+	for _, db := range []dbBaser{newdbBaseSqlite(), newdbBaseTidb()} {
+		assert.Panics(t, func() {
+			newDbTables(mi, db).getCondSQL(cond, false, tz)
+		})
+	}
+	assert.Panics(t, func() {
+		newDbTables(mi, db).getCondSQL(NewCondition().And("name__soundex", "smith", "smyth"), false, tz)
+	})
+}
+
+func TestDbTables_getOrderSQLWithValues(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantWhere string
+		wantOrder string
+	}{
+		{
+			name:      "mysql",
+			db:        newdbBaseMysql(),
+			wantWhere: "WHERE T0.`age` IN (?, ?, ?) ",
+			wantOrder: "ORDER BY FIELD(T0.`age`, ?, ?, ?) ASC, T0.`name` DESC ",
+		},
+		{
+			name:      "tidb",
+			db:        newdbBaseTidb(),
+			wantWhere: "WHERE T0.`age` IN (?, ?, ?) ",
+			wantOrder: "ORDER BY FIELD(T0.`age`, ?, ?, ?) ASC, T0.`name` DESC ",
+		},
+		{
+			name:      "postgres",
+			db:        newdbBasePostgres(),
+			wantWhere: `WHERE T0."age" IN (?, ?, ?) `,
+			wantOrder: `ORDER BY CASE T0."age" WHEN ? THEN 0 WHEN ? THEN 1 WHEN ? THEN 2 END ASC, T0."name" DESC `,
+		},
+		{
+			name:      "sqlite",
+			db:        newdbBaseSqlite(),
+			wantWhere: "WHERE T0.`age` IN (?, ?, ?) ",
+			wantOrder: "ORDER BY CASE T0.`age` WHEN ? THEN 0 WHEN ? THEN 1 WHEN ? THEN 2 END ASC, T0.`name` DESC ",
+		},
+	}
+
+	qs := querySet{mi: mi}.OrderBy("-name").FilterInOrdered("age", []interface{}{30, 10, 20}).(*querySet)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			where, args := tables.getCondSQL(qs.cond, false, time.Local)
+			assert.Equal(t, tc.wantWhere, where)
+			assert.Equal(t, []interface{}{int64(30), int64(10), int64(20)}, args)
+
+			order, args := tables.getOrderSQL(qs.orders, time.Local)
+			assert.Equal(t, tc.wantOrder, order)
+			assert.Equal(t, []interface{}{int64(30), int64(10), int64(20)}, args)
+		})
+	}
+
+	assert.Panics(t, func() {
+		querySet{mi: mi}.FilterInOrdered("age", nil)
+	})
+}
+
+func TestDbTables_getOrderSQLWithCollate(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	qs := querySet{mi: mi}.OrderBy("age").OrderByCollate("-name", "utf8mb4_general_ci").(*querySet)
+
+	res, _ := newDbTables(mi, newdbBaseMysql()).getOrderSQL(qs.orders, time.Local)
+	assert.Equal(t, "ORDER BY T0.`age` ASC, T0.`name` COLLATE utf8mb4_general_ci DESC ", res)
+
+	qs = querySet{mi: mi}.OrderByCollate("name", `"und-x-icu"`).(*querySet)
+	res, _ = newDbTables(mi, newdbBasePostgres()).getOrderSQL(qs.orders, time.Local)
+	assert.Equal(t, `ORDER BY T0."name" COLLATE "und-x-icu" ASC `, res)
+
+	qs = querySet{mi: mi}.OrderByCollate("name", "NOCASE").(*querySet)
+	res, _ = newDbTables(mi, newdbBaseSqlite()).getOrderSQL(qs.orders, time.Local)
+	assert.Equal(t, "ORDER BY T0.`name` COLLATE NOCASE ASC ", res)
+
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseOracle()).getOrderSQL(qs.orders, time.Local)
+	})
+	assert.Panics(t, func() {
+		querySet{mi: mi}.OrderByCollate("name", "NOCASE; DROP TABLE x")
+	})
+}
+
+func TestDbTables_getOrderSQLWithDistance(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	qs := querySet{mi: mi}.OrderBy("-name").OrderByDistance("age", "score", 60, 2.35).(*querySet)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "ORDER BY T0.`name` DESC, ((T0.`age` - ?) * (T0.`age` - ?) + (T0.`score` - ?) * (T0.`score` - ?) * ?) ASC ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `ORDER BY T0."name" DESC, ((T0."age" - ?) * (T0."age" - ?) + (T0."score" - ?) * (T0."score" - ?) * ?) ASC `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "ORDER BY T0.`name` DESC, ((T0.`age` - ?) * (T0.`age` - ?) + (T0.`score` - ?) * (T0.`score` - ?) * ?) ASC ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, args := newDbTables(mi, tc.db).getOrderSQL(qs.orders, time.Local)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, 5, len(args))
+			assert.Equal(t, []interface{}{float64(60), float64(60), 2.35, 2.35}, args[:4])
+			// the squared cosine of 60 degrees
+			assert.InDelta(t, 0.25, args[4], 1e-9)
+		})
+	}
+
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseMysql()).getOrderSQL([]*order_clause.Order{order_clause.Clause(order_clause.Column("age"),
+			order_clause.Distance("unknown", 0, 0), order_clause.SortAscending())}, time.Local)
+	})
+	assert.Error(t, querySet{mi: mi}.OrderByDistance("age", "unknown", 0, 0).Err())
+	assert.Panics(t, func() {
+		querySet{mi: mi}.OrderByDistance("age", "score", 91, 0)
+	})
+}
+
+func TestDbTables_getCondSQLWithHasRelated(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab1))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+	db := newdbBaseMysql()
+
+	qs := querySet{mi: mi}.FilterHasRelated("TestTabs", true).(*querySet)
+	where, args := newDbTables(mi, db).getCondSQL(qs.cond, false, tz)
+	assert.Equal(t, "WHERE EXISTS (SELECT 1 FROM `test_tab` R WHERE R.`test_tab_1_id` = T0.`id`) ", where)
+	assert.Empty(t, args)
+
+	qs = querySet{mi: mi}.Filter("age_1__gt", 1).FilterHasRelated("TestTabs", false).(*querySet)
+	where, args = newDbTables(mi, db).getCondSQL(qs.cond, false, tz)
+	assert.Equal(t, "WHERE T0.`age_1` > ? AND NOT EXISTS (SELECT 1 FROM `test_tab` R WHERE R.`test_tab_1_id` = T0.`id`) ", where)
+	assert.Equal(t, []interface{}{int64(1)}, args)
+
+	qs = querySet{mi: mi}.FilterHasRelated("TestTab2", false).(*querySet)
+	where, _ = newDbTables(mi, newdbBasePostgres()).getCondSQL(qs.cond, false, tz)
+	assert.Equal(t, `WHERE NOT EXISTS (SELECT 1 FROM "test_tab2" R WHERE R."id" = T0."test_tab_2_id") `, where)
+
+	qs = querySet{mi: mi}.FilterHasRelated("Name1", true).(*querySet)
+	assert.NotNil(t, qs.Err())
+	qs = querySet{mi: mi}.FilterHasRelated("not_exist", true).(*querySet)
+	assert.NotNil(t, qs.Err())
+}
+
+func TestDbTables_getCondSQLWithBytes(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+	hash := []byte{0x00, 0xff, 'a'}
+
+	for _, db := range []dbBaser{newdbBaseMysql(), newdbBasePostgres(), newdbBaseSqlite(), newdbBaseOracle(), newdbBaseTidb()} {
+		where, args := newDbTables(mi, db).getCondSQL(NewCondition().And("name", hash), false, tz)
+		assert.Equal(t, 1, len(args))
+		assert.Equal(t, hash, args[0])
+		assert.Contains(t, where, " = ?")
+	}
+
+	_, args := newDbTables(mi, newdbBaseMysql()).getCondSQL(NewCondition().And("name__in", hash, []byte("b")), false, tz)
+	assert.Equal(t, []interface{}{hash, []byte("b")}, args)
+}
+
+func TestDbBase_readBatchSQLWithTablePrefix(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+	mc.SetTablePrefix("app1_")
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+	cond := NewCondition().And("TestTab1__name_1", "a")
+
+	db := newdbBaseMysql().(*dbBaseMysql)
+	tables := newDbTables(mi, db)
+	res, _ := db.readBatchSQL(tables, []string{"id"}, cond, querySet{mi: mi, limit: -1}, mi, tz)
+	assert.Equal(t, "SELECT T0.`id` FROM `app1_test_tab` T0 INNER JOIN `app1_test_tab1` T1 ON T1.`id` = T0.`test_tab_1_id` WHERE T1.`name_1` = ? ", res)
+
+	res, _ = db.deleteReturningSQL(&querySet{mi: mi}, mi, NewCondition().And("age", 1), tz)
+	assert.Contains(t, res, "`app1_test_tab`")
+}
+
+func TestDbTables_getCondSQLWithBitmask(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("age__hasall", 6).Or("age__hasany", 9)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "mysql",
+			db:       newdbBaseMysql(),
+			wantRes:  "WHERE (T0.`age` & ?) = ? OR (T0.`age` & ?) <> 0 ",
+			wantArgs: []interface{}{int64(6), int64(6), int64(9)},
+		},
+		{
+			name:     "tidb",
+			db:       newdbBaseTidb(),
+			wantRes:  "WHERE (T0.`age` & ?) = ? OR (T0.`age` & ?) <> 0 ",
+			wantArgs: []interface{}{int64(6), int64(6), int64(9)},
+		},
+		{
+			name:     "postgres",
+			db:       newdbBasePostgres(),
+			wantRes:  `WHERE (T0."age" & ?) = ? OR (T0."age" & ?) <> 0 `,
+			wantArgs: []interface{}{int64(6), int64(6), int64(9)},
+		},
+		{
+			name:     "sqlite",
+			db:       newdbBaseSqlite(),
+			wantRes:  "WHERE (T0.`age` & ?) = ? OR (T0.`age` & ?) <> 0 ",
+			wantArgs: []interface{}{int64(6), int64(6), int64(9)},
+		},
+		{
+			name:     "oracle",
+			db:       newdbBaseOracle(),
+			wantRes:  "WHERE BITAND(T0.`age`, ?) = ? OR BITAND(T0.`age`, ?) <> 0 ",
+			wantArgs: []interface{}{int64(6), int64(6), int64(9)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+
+	cond = NewCondition().AndNot("age__hasall", 6)
+	tables := newDbTables(mi, newdbBaseSqlite())
+	res, args := tables.getCondSQL(cond, false, tz)
+	assert.Equal(t, "WHERE NOT (T0.`age` & ?) = ? ", res)
+	assert.Equal(t, []interface{}{int64(6), int64(6)}, args)
+}
+
+func TestDbTables_getCondSQLWithILikeAny(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("name__ilike_any", []string{"a%", "%b"}).And("age", 1)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE (T0.`name` LIKE ? OR T0.`name` LIKE ?) AND T0.`age` = ? ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE (T0.`name` LIKE ? OR T0.`name` LIKE ?) AND T0.`age` = ? ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE (UPPER(T0."name"::text) LIKE UPPER(?) OR UPPER(T0."name"::text) LIKE UPPER(?)) AND T0."age" = ? `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE (T0.`name` LIKE ? OR T0.`name` LIKE ?) AND T0.`age` = ? ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE (UPPER(T0.`name`) LIKE UPPER(?) OR UPPER(T0.`name`) LIKE UPPER(?)) AND T0.`age` = ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{"a%", "%b", int64(1)}, args)
+		})
+	}
+
+	// the params of the column repeat with every pattern
+	cond = NewCondition().andCoalesce("name__ilike_any", "x", "a%", "b%")
+	tables := newDbTables(mi, newdbBaseSqlite())
+	res, args := tables.getCondSQL(cond, false, tz)
+	assert.Equal(t, "WHERE (COALESCE(T0.`name`, ?) LIKE ? OR COALESCE(T0.`name`, ?) LIKE ?) ", res)
+	assert.Equal(t, []interface{}{"x", "a%", "x", "b%"}, args)
+
+	assert.Panics(t, func() {
+		tables.getCondSQL(NewCondition().And("name__ilike_any", []string{}), false, tz)
+	})
+}
+
+func TestDbTables_getCondSQLWithArrayLength(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("name__size__gt", 3)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE JSON_LENGTH(T0.`name`) > ? ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE JSON_LENGTH(T0.`name`) > ? ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE COALESCE(array_length(T0."name", 1), 0) > ? `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE json_array_length(T0.`name`) > ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{int64(3)}, args)
+		})
+	}
+
+	jsonField := &models.FieldInfo{FieldType: TypeJsonbField}
+	assert.Equal(t, `jsonb_array_length(T0."tags"::jsonb)`, newdbBasePostgres().ArrayLengthSQL(jsonField, `T0."tags"`))
+
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseOracle()).getCondSQL(cond, false, tz)
+	})
+}
+
+func TestDbTables_getCondSQLWithArrayElement(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("name__0", "go").And("name__2__upper__startswith", "G")
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE JSON_UNQUOTE(JSON_EXTRACT(T0.`name`, '$[0]')) = ? AND UPPER(JSON_UNQUOTE(JSON_EXTRACT(T0.`name`, '$[2]'))) LIKE BINARY ? ESCAPE '\\\\' ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE T0."name"[1] = ? AND UPPER(T0."name"[3]) LIKE ? ESCAPE '\' `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE json_extract(T0.`name`, '$[0]') = ? AND UPPER(json_extract(T0.`name`, '$[2]')) LIKE ? ESCAPE '\\' ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE JSON_VALUE(T0.`name`, '$[0]') = ? AND UPPER(JSON_VALUE(T0.`name`, '$[2]')) LIKE ? ESCAPE '\\' ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{"go", "G%"}, args)
+		})
+	}
+
+	jsonField := &models.FieldInfo{FieldType: TypeJSONField}
+	assert.Equal(t, `(T0."tags"::jsonb ->> 0)`, newdbBasePostgres().ArrayElementSQL(jsonField, `T0."tags"`, 0))
+}
+
+func TestDbTables_getCondSQLWithBBox(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	qs := querySet{mi: mi}.FilterBBox("age", "score", 48.8, 2.2, 48.9, 2.4).(*querySet)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE T0.`age` >= ? AND T0.`age` <= ? AND T0.`score` >= ? AND T0.`score` <= ? ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE T0."age" >= ? AND T0."age" <= ? AND T0."score" >= ? AND T0."score" <= ? `,
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE T0.`age` >= ? AND T0.`age` <= ? AND T0.`score` >= ? AND T0.`score` <= ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(qs.cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{48.8, 48.9, 2.2, 2.4}, args)
+		})
+	}
+
+	// across the antimeridian
+	qs = querySet{mi: mi}.FilterBBox("age", "score", -10, 170, 10, -170).(*querySet)
+	tables := newDbTables(mi, newdbBaseSqlite())
+	res, args := tables.getCondSQL(qs.cond, false, tz)
+	assert.Equal(t, "WHERE T0.`age` >= ? AND T0.`age` <= ? AND ( T0.`score` >= ? OR T0.`score` <= ? ) ", res)
+	assert.Equal(t, []interface{}{float64(-10), float64(10), float64(170), float64(-170)}, args)
+
+	assert.Panics(t, func() {
+		querySet{mi: mi}.FilterBBox("age", "score", 10, 0, -10, 1)
+	})
+}
+
+func TestDbTables_getCondSQLWithOpen(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	testCases := []struct {
+		name string
+		lo   interface{}
+		hi   interface{}
+
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "both bounds",
+			lo:       18,
+			hi:       65,
+			wantRes:  "WHERE T0.`age` > ? AND T0.`age` < ? ",
+			wantArgs: []interface{}{int64(18), int64(65)},
+		},
+		{
+			name:     "lower bound",
+			lo:       18,
+			wantRes:  "WHERE T0.`age` > ? ",
+			wantArgs: []interface{}{int64(18)},
+		},
+		{
+			name:     "upper bound",
+			hi:       65,
+			wantRes:  "WHERE T0.`age` < ? ",
+			wantArgs: []interface{}{int64(65)},
+		},
+		{
+			name: "no bound",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			qs := querySet{mi: mi}.FilterOpen("age", tc.lo, tc.hi).(*querySet)
+			tables := newDbTables(mi, newdbBaseMysql())
+			res, args := tables.getCondSQL(qs.cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestDbTables_getCondSQLWithBetween(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	testCases := []struct {
+		name   string
+		db     dbBaser
+		column string
+		lo, hi interface{}
+
+		wantJoin string
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "related column",
+			db:       newdbBaseMysql(),
+			column:   "TestTab1__Age1",
+			lo:       18,
+			hi:       65,
+			wantJoin: "INNER JOIN `test_tab1` T1 ON T1.`id` = T0.`test_tab_1_id` ",
+			wantRes:  "WHERE T1.`age_1` BETWEEN ? AND ? ",
+			wantArgs: []interface{}{int64(18), int64(65)},
+		},
+		{
+			name:     "related column of a related model",
+			db:       newdbBasePostgres(),
+			column:   "TestTab1__TestTab2__Age2",
+			lo:       18,
+			hi:       65,
+			wantJoin: `INNER JOIN "test_tab1" T1 ON T1."id" = T0."test_tab_1_id" INNER JOIN "test_tab2" T2 ON T2."id" = T1."test_tab_2_id" `,
+			wantRes:  `WHERE T2."age_2" BETWEEN ? AND ? `,
+			wantArgs: []interface{}{int64(18), int64(65)},
+		},
+		{
+			name:     "lower bound",
+			db:       newdbBaseMysql(),
+			column:   "TestTab1__Age1",
+			lo:       18,
+			wantJoin: "INNER JOIN `test_tab1` T1 ON T1.`id` = T0.`test_tab_1_id` ",
+			wantRes:  "WHERE T1.`age_1` >= ? ",
+			wantArgs: []interface{}{int64(18)},
+		},
+		{
+			name:     "column",
+			db:       newdbBaseMysql(),
+			column:   "age",
+			hi:       65,
+			wantRes:  "WHERE T0.`age` <= ? ",
+			wantArgs: []interface{}{int64(65)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			qs := querySet{mi: mi}.FilterBetween(tc.column, tc.lo, tc.hi).(*querySet)
+			tables := newDbTables(mi, tc.db)
+			tables.parseRelated(qs.related, qs.relDepth)
+			res, args := tables.getCondSQL(qs.cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+			assert.Equal(t, tc.wantJoin, tables.getJoinSQL())
+		})
+	}
+
+	assert.Nil(t, querySet{mi: mi}.FilterBetween("age", nil, nil).(*querySet).cond)
+}
+
+func TestDbTables_getCondSQLWithInNull(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+		cond *Condition
+
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "mysql with nil",
+			db:       newdbBaseMysql(),
+			cond:     NewCondition().And("name__in", "a", nil, "b"),
+			wantRes:  "WHERE (T0.`name` IN (?, ?) OR T0.`name` IS NULL) ",
+			wantArgs: []interface{}{"a", "b"},
+		},
+		{
+			name:     "postgres with nil in slice",
+			db:       newdbBasePostgres(),
+			cond:     NewCondition().And("name__in", []interface{}{"a", nil}),
+			wantRes:  `WHERE (T0."name" IN (?) OR T0."name" IS NULL) `,
+			wantArgs: []interface{}{"a"},
+		},
+		{
+			name:     "sqlite only nil",
+			db:       newdbBaseSqlite(),
+			cond:     NewCondition().And("name__in", nil),
+			wantRes:  "WHERE T0.`name` IS NULL ",
+			wantArgs: nil,
+		},
+		{
+			name:     "oracle exclude with nil",
+			db:       newdbBaseOracle(),
+			cond:     NewCondition().AndNot("name__in", "a", nil),
+			wantRes:  "WHERE NOT (T0.`name` IN (?) OR T0.`name` IS NULL) ",
+			wantArgs: []interface{}{"a"},
+		},
+		{
+			name:     "coalesce with nil",
+			db:       newdbBaseMysql(),
+			cond:     NewCondition().andCoalesce("name__in", "", "a", nil),
+			wantRes:  "WHERE (COALESCE(T0.`name`, ?) IN (?) OR COALESCE(T0.`name`, ?) IS NULL) ",
+			wantArgs: []interface{}{"", "a", ""},
+		},
+		{
+			name:     "without nil",
+			db:       newdbBaseMysql(),
+			cond:     NewCondition().And("name__in", "a", "b"),
+			wantRes:  "WHERE T0.`name` IN (?, ?) ",
+			wantArgs: []interface{}{"a", "b"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(tc.cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestDbTables_getCaseSQL(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cases := []CaseWhen{
+		{Cond: NewCondition().And("age__lt", 18), Then: "minor"},
+		{Cond: NewCondition().And("age__gte", 65).Or("score__gt", 90), Then: "special"},
+	}
+
+	testCases := []struct {
+		name    string
+		elseVal interface{}
+
+		wantRes   string
+		wantWhere string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "with else",
+			elseVal:   "adult",
+			wantRes:   `CASE WHEN T0."age" < $1 THEN $2 WHEN T0."age" >= $3 OR T0."score" > $4 THEN $5 ELSE $6 END "level"`,
+			wantWhere: `WHERE T0."name" = $7 `,
+			wantArgs:  []interface{}{int64(18), "minor", int64(65), int64(90), "special", "adult", "a"},
+		},
+		{
+			name:      "without else",
+			wantRes:   `CASE WHEN T0."age" < $1 THEN $2 WHEN T0."age" >= $3 OR T0."score" > $4 THEN $5 END "level"`,
+			wantWhere: `WHERE T0."name" = $6 `,
+			wantArgs:  []interface{}{int64(18), "minor", int64(65), int64(90), "special", "a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			qs := querySet{mi: mi}.ValuesCase("level", cases, tc.elseVal).(*querySet)
+			d := newdbBasePostgres()
+			tables := newDbTables(mi, d)
+			caseSQL, params, ok := tables.getCaseSQL(qs.cases, "level", tz)
+			assert.True(t, ok)
+			cols := []string{caseSQL + ` "level"`}
+			query, args := d.(*dbBasePostgres).readValuesSQL(tables, cols, *qs, mi, NewCondition().And("name", "a"), tz)
+			assert.Contains(t, query, tc.wantRes)
+			assert.Contains(t, query, tc.wantWhere)
+			assert.Equal(t, tc.wantArgs, append(params, args...))
+		})
+	}
+
+	_, _, ok = newDbTables(mi, newdbBasePostgres()).getCaseSQL(nil, "level", tz)
+	assert.False(t, ok)
+}
+
+func TestDbTables_getBoolSQL(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	qs := querySet{mi: mi}.ValuesBool("is_senior", NewCondition().And("age__gte", 65).Or("score__gt", 90)).(*querySet)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "CASE WHEN T0.`age` >= ? OR T0.`score` > ? THEN 1 ELSE 0 END",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `CASE WHEN T0."age" >= ? OR T0."score" > ? THEN 1 ELSE 0 END`,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "CASE WHEN T0.`age` >= ? OR T0.`score` > ? THEN 1 ELSE 0 END",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			boolSQL, params, ok := tables.getBoolSQL(qs.bools, "is_senior", tz)
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantRes, boolSQL)
+			assert.Equal(t, []interface{}{int64(65), int64(90)}, params)
+		})
+	}
+
+	// the params of the selected column are numbered before the ones of the conditions
+	d := newdbBasePostgres()
+	tables := newDbTables(mi, d)
+	boolSQL, params, _ := tables.getBoolSQL(qs.bools, "is_senior", tz)
+	query, args := d.(*dbBasePostgres).readValuesSQL(tables, []string{boolSQL + ` "is_senior"`}, *qs, mi, NewCondition().And("name", "a"), tz)
+	assert.Contains(t, query, `CASE WHEN T0."age" >= $1 OR T0."score" > $2 THEN 1 ELSE 0 END "is_senior"`)
+	assert.Contains(t, query, `WHERE T0."name" = $3 `)
+	assert.Equal(t, []interface{}{int64(65), int64(90), "a"}, append(params, args...))
+
+	_, _, ok = newDbTables(mi, newdbBasePostgres()).getBoolSQL(qs.bools, "level", tz)
+	assert.False(t, ok)
+	assert.Panics(t, func() {
+		querySet{mi: mi}.ValuesBool("is_senior", NewCondition())
+	})
+}
+
+func TestDbBase_TableSQL(t *testing.T) {
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantTable      string
+		wantSearchPath string
+	}{
+		{name: "mysql", db: newdbBaseMysql(), wantTable: "`tenant_1`.`user`"},
+		{name: "tidb", db: newdbBaseTidb(), wantTable: "`tenant_1`.`user`"},
+		{name: "postgres", db: newdbBasePostgres(), wantTable: `"tenant_1"."user"`, wantSearchPath: `SET LOCAL search_path TO "tenant_1"`},
+		{name: "sqlite", db: newdbBaseSqlite(), wantTable: "`tenant_1`.`user`"},
+		{name: "oracle", db: newdbBaseOracle(), wantTable: "`tenant_1`.`user`"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			Q := tc.db.TableQuote()
+			assert.Equal(t, Q+"user"+Q, tc.db.TableSQL("user"))
+			assert.Equal(t, "", tc.db.SearchPathSQL())
+
+			db := withSchema(tc.db, "tenant_1")
+			assert.Equal(t, tc.wantTable, db.TableSQL("user"))
+			assert.Equal(t, tc.wantSearchPath, db.SearchPathSQL())
+			// the dialect is kept
+			assert.Equal(t, tc.db.TableQuote(), db.TableQuote())
+			assert.Equal(t, Q+"user"+Q, tc.db.TableSQL("user"))
+		})
+	}
+}
+
+func TestDbTables_getJoinSQLWithSchema(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	d := withSchema(newdbBasePostgres(), "tenant_1").(*dbBasePostgres)
+	tables := newDbTables(mi, d)
+	tables.parseRelated([]string{"TestTab1"}, 0)
+	query, _ := d.readBatchSQL(tables, mi.Fields.DBcols, NewCondition().And("TestTab1__TestTab2__name_2", 1), querySet{mi: mi}, mi, time.Local)
+	assert.Contains(t, query, `FROM "tenant_1"."test_tab" T0 `)
+	assert.Contains(t, query, `INNER JOIN "tenant_1"."test_tab1" T1 ON`)
+	assert.Contains(t, query, `INNER JOIN "tenant_1"."test_tab2" T2 ON`)
+}
+
+func TestDbBase_ExplainSQL(t *testing.T) {
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantExplain string
+		wantAnalyze string
+	}{
+		{name: "mysql", db: newdbBaseMysql(), wantExplain: "EXPLAIN", wantAnalyze: "EXPLAIN ANALYZE"},
+		{name: "tidb", db: newdbBaseTidb(), wantExplain: "EXPLAIN", wantAnalyze: "EXPLAIN ANALYZE"},
+		{name: "postgres", db: newdbBasePostgres(), wantExplain: "EXPLAIN", wantAnalyze: "EXPLAIN ANALYZE"},
+		{name: "sqlite", db: newdbBaseSqlite(), wantExplain: "EXPLAIN QUERY PLAN", wantAnalyze: ""},
+		{name: "oracle", db: newdbBaseOracle(), wantExplain: "", wantAnalyze: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantExplain, tc.db.ExplainSQL(false))
+			assert.Equal(t, tc.wantAnalyze, tc.db.ExplainSQL(true))
+		})
+	}
+}
+
+func TestDbBase_CursorSQL(t *testing.T) {
+	declare, fetch, closeSQL := newdbBasePostgres().CursorSQL("c1", 500)
+	assert.Equal(t, "DECLARE c1 NO SCROLL CURSOR FOR", declare)
+	assert.Equal(t, "FETCH FORWARD 500 FROM c1", fetch)
+	assert.Equal(t, "CLOSE c1", closeSQL)
+
+	for _, db := range []dbBaser{newdbBaseMysql(), newdbBaseTidb(), newdbBaseSqlite(), newdbBaseOracle()} {
+		declare, _, _ = db.CursorSQL("c1", 500)
+		assert.Equal(t, "", declare)
+	}
+}
+
+func TestDbTables_getCondSQLWithCast(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().andCast("name__gt", "int", 10).andCast("name", "date", "2024-01-02")
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE CAST(T0.`name` AS SIGNED) > ? AND CAST(T0.`name` AS DATE) = ? ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE CAST(T0.`name` AS SIGNED) > ? AND CAST(T0.`name` AS DATE) = ? ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE T0."name"::integer > ? AND T0."name"::date = ? `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE CAST(T0.`name` AS INTEGER) > ? AND DATE(T0.`name`) = ? ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE CAST(T0.`name` AS NUMBER(10)) > ? AND CAST(T0.`name` AS DATE) = ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{int64(10), "2024-01-02"}, args)
+		})
+	}
+
+	assert.Panics(t, func() {
+		NewCondition().andCast("name", "int; DROP TABLE x", 1)
+	})
+}
+
+func TestDbTables_getCondSQLWithConcat(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().andConcat("name", []string{"age", "TestTab1__name_1"}, " ", "a 1")
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE CONCAT(T0.`name`, ?, T0.`age`, ?, T1.`name_1`) = ? ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE CONCAT(T0.`name`, ?, T0.`age`, ?, T1.`name_1`) = ? ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE (T0."name" || ? || T0."age" || ? || T1."name_1") = ? `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE (T0.`name` || ? || T0.`age` || ? || T1.`name_1`) = ? ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE (T0.`name` || ? || T0.`age` || ? || T1.`name_1`) = ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{" ", " ", "a 1"}, args)
+		})
+	}
+
+	res, args := newDbTables(mi, newdbBaseMysql()).getCondSQL(NewCondition().andConcat("name", []string{"age"}, "", "a1"), false, tz)
+	assert.Equal(t, "WHERE CONCAT(T0.`name`, T0.`age`) = ? ", res)
+	assert.Equal(t, []interface{}{"a1"}, args)
+}
+
+func TestDbBase_UpsertSQL(t *testing.T) {
+	mc := models.NewModelCacheHandler()
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+	assert.Nil(t, err)
+	mc.Bootstrap()
+	mi, ok := mc.GetByMd(new(testTab))
+	assert.True(t, ok)
+
+	names := []string{"name", "age", "score"}
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes     string
+		wantNothing string
+	}{
+		{
+			name:        "mysql",
+			db:          newdbBaseMysql(),
+			wantRes:     "INSERT INTO `test_tab` (`name`, `age`, `score`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `age` = VALUES(`age`), `score` = VALUES(`score`)",
+			wantNothing: "INSERT INTO `test_tab` (`name`, `age`, `score`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)",
+		},
+		{
+			name:        "tidb",
+			db:          newdbBaseTidb(),
+			wantRes:     "INSERT INTO `test_tab` (`name`, `age`, `score`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `age` = VALUES(`age`), `score` = VALUES(`score`)",
+			wantNothing: "INSERT INTO `test_tab` (`name`, `age`, `score`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)",
+		},
+		{
+			name:        "postgres",
+			db:          newdbBasePostgres(),
+			wantRes:     `INSERT INTO "test_tab" ("name", "age", "score") VALUES (?, ?, ?) ON CONFLICT ("name") DO UPDATE SET "age" = EXCLUDED."age", "score" = EXCLUDED."score"`,
+			wantNothing: `INSERT INTO "test_tab" ("name", "age", "score") VALUES (?, ?, ?) ON CONFLICT ("name", "age", "score") DO NOTHING`,
+		},
+		{
+			name:        "sqlite",
+			db:          newdbBaseSqlite(),
+			wantRes:     "INSERT INTO `test_tab` (`name`, `age`, `score`) VALUES (?, ?, ?) ON CONFLICT (`name`) DO UPDATE SET `age` = EXCLUDED.`age`, `score` = EXCLUDED.`score`",
+			wantNothing: "INSERT INTO `test_tab` (`name`, `age`, `score`) VALUES (?, ?, ?) ON CONFLICT (`name`, `age`, `score`) DO NOTHING",
+		},
+		{
+			name: "oracle",
+			db:   newdbBaseOracle(),
+			wantRes: "MERGE INTO `test_tab` T USING (SELECT ? `name`, ? `age`, ? `score` FROM dual) S ON (T.`name` = S.`name`) " +
+				"WHEN MATCHED THEN UPDATE SET T.`age` = S.`age`, T.`score` = S.`score` " +
+				"WHEN NOT MATCHED THEN INSERT (`name`, `age`, `score`) VALUES (S.`name`, S.`age`, S.`score`)",
+			wantNothing: "MERGE INTO `test_tab` T USING (SELECT ? `name`, ? `age`, ? `score` FROM dual) S ON (T.`name` = S.`name` AND T.`age` = S.`age` AND T.`score` = S.`score`) " +
+				"WHEN NOT MATCHED THEN INSERT (`name`, `age`, `score`) VALUES (S.`name`, S.`age`, S.`score`)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantRes, tc.db.UpsertSQL(mi, names, []string{"name"}))
+			// all the columns conflict, nothing to update
+			assert.Equal(t, tc.wantNothing, tc.db.UpsertSQL(mi, names, names))
+		})
+	}
+}
+
+func TestDbBase_computedSQL(t *testing.T) {
+	mc := models.NewModelCacheHandler()
+	err := mc.Register("", false, new(Person))
+	assert.Nil(t, err)
+	mc.Bootstrap()
+	mi, ok := mc.GetByMd(new(Person))
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(mi.ComputedFields))
+	assert.Equal(t, "first_name || ' ' || UPPER(last_name)", mi.ComputedFields[0].Expr)
+
+	testCases := []struct {
+		name string
+		db   *dbBase
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      &dbBase{ins: newdbBaseMysql()},
+			wantRes: "CONCAT(T0.`first_name`, ' ', UPPER(T0.`last_name`))",
+		},
+		{
+			name:    "tidb",
+			db:      &dbBase{ins: newdbBaseTidb()},
+			wantRes: "CONCAT(T0.`first_name`, ' ', UPPER(T0.`last_name`))",
+		},
+		{
+			name:    "postgres",
+			db:      &dbBase{ins: newdbBasePostgres()},
+			wantRes: `(T0."first_name" || ' ' || UPPER(T0."last_name"))`,
+		},
+		{
+			name:    "sqlite",
+			db:      &dbBase{ins: newdbBaseSqlite()},
+			wantRes: "(T0.`first_name` || ' ' || UPPER(T0.`last_name`))",
+		},
+		{
+			name:    "oracle",
+			db:      &dbBase{ins: newdbBaseOracle()},
+			wantRes: "(T0.`first_name` || ' ' || UPPER(T0.`last_name`))",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, []string{tc.wantRes}, tc.db.computedCols(mi, nil, "T0."))
+		})
+	}
+
+	// the literals and the qualified columns are kept
+	db := &dbBase{ins: newdbBaseMysql()}
+	assert.Equal(t, "LENGTH('last_name') + T1.first_name + `id`", db.computedSQL(mi, "LENGTH('last_name') + T1.first_name + `id`", "T0."))
+	assert.Equal(t, "`first_name`", db.computedSQL(mi, "first_name", ""))
+	assert.Nil(t, db.computedCols(mi, []string{"FirstName"}, "T0."))
+}
+
+func TestDbTables_getCondSQLWithDatePart(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("age__quarter", 1).And("age__isoweek__gte", 52)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE QUARTER(T0.`age`) = ? AND WEEK(T0.`age`, 3) >= ? ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE QUARTER(T0.`age`) = ? AND WEEK(T0.`age`, 3) >= ? ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE CAST(EXTRACT(QUARTER FROM T0."age") AS INTEGER) = ? AND CAST(EXTRACT(WEEK FROM T0."age") AS INTEGER) >= ? `,
+		},
+		{
+			name: "sqlite",
+			db:   newdbBaseSqlite(),
+			wantRes: "WHERE ((CAST(strftime('%m', T0.`age`) AS INTEGER) + 2) / 3) = ? AND " +
+				"((CAST(strftime('%j', date(T0.`age`, '-3 days', 'weekday 4')) AS INTEGER) - 1) / 7 + 1) >= ? ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE TO_NUMBER(TO_CHAR(T0.`age`, 'Q')) = ? AND TO_NUMBER(TO_CHAR(T0.`age`, 'IW')) >= ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{int64(1), int64(52)}, args)
+		})
+	}
+}
+
+func TestDbTables_getCondSQLWithAge(t *testing.T) {
+	mc := models.NewModelCacheHandler()
+	err := mc.Register("", false, new(testTimeTab))
+	assert.Nil(t, err)
+	mc.Bootstrap()
+	mi, ok := mc.GetByMd(new(testTimeTab))
+	assert.True(t, ok)
+
+	tz := time.Local
+	cond := NewCondition().And("created__age__gte", 18)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE TIMESTAMPDIFF(YEAR, T0.`created`, CURDATE()) >= ? ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE TIMESTAMPDIFF(YEAR, T0.`created`, CURDATE()) >= ? ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE CAST(EXTRACT(YEAR FROM AGE(CURRENT_DATE, T0."created")) AS INTEGER) >= ? `,
+		},
+		{
+			name: "sqlite",
+			db:   newdbBaseSqlite(),
+			wantRes: "WHERE (CAST(strftime('%Y', 'now', 'localtime') AS INTEGER) - CAST(strftime('%Y', T0.`created`) AS INTEGER) - " +
+				"(strftime('%m-%d', 'now', 'localtime') < strftime('%m-%d', T0.`created`))) >= ? ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE TRUNC(MONTHS_BETWEEN(SYSDATE, T0.`created`) / 12) >= ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{int64(18)}, args)
+		})
+	}
+}
+
+func TestDbTables_getTimezoneSQL(t *testing.T) {
+	mc := models.NewModelCacheHandler()
+	err := mc.Register("", false, new(testTimeTab))
+	assert.Nil(t, err)
+	mc.Bootstrap()
+	mi, ok := mc.GetByMd(new(testTimeTab))
+	assert.True(t, ok)
+
+	// the times are stored in the timezone of the db alias
+	tz := time.FixedZone("", -3*3600)
+	cols := []tzColumn{{column: "created", alias: "created_ny", tz: "America/New_York"}}
+	cond := NewCondition().andTimezone("created__gte", "America/New_York", "2024-03-01 00:00:00")
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantCol  string
+		wantCond string
+	}{
+		{
+			name:     "mysql",
+			db:       newdbBaseMysql(),
+			wantCol:  "CONVERT_TZ(T0.`created`, '-03:00', 'America/New_York')",
+			wantCond: "WHERE CONVERT_TZ(T0.`created`, '-03:00', 'America/New_York') >= ? ",
+		},
+		{
+			name:     "tidb",
+			db:       newdbBaseTidb(),
+			wantCol:  "CONVERT_TZ(T0.`created`, '-03:00', 'America/New_York')",
+			wantCond: "WHERE CONVERT_TZ(T0.`created`, '-03:00', 'America/New_York') >= ? ",
+		},
+		{
+			name:     "postgres",
+			db:       newdbBasePostgres(),
+			wantCol:  `(T0."created" AT TIME ZONE 'America/New_York')`,
+			wantCond: `WHERE (T0."created" AT TIME ZONE 'America/New_York') >= ? `,
+		},
+		{
+			name:     "oracle",
+			db:       newdbBaseOracle(),
+			wantCol:  "(FROM_TZ(CAST(T0.`created` AS TIMESTAMP), '-03:00') AT TIME ZONE 'America/New_York')",
+			wantCond: "WHERE (FROM_TZ(CAST(T0.`created` AS TIMESTAMP), '-03:00') AT TIME ZONE 'America/New_York') >= ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, ok := tables.getTimezoneSQL(cols, "created_ny", tz)
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantCol, res)
+			_, ok = tables.getTimezoneSQL(cols, "created", tz)
+			assert.False(t, ok)
+
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantCond, res)
+			assert.Equal(t, 1, len(args))
+		})
+	}
+
+	tables := newDbTables(mi, newdbBaseSqlite())
+	assert.Panics(t, func() {
+		tables.getTimezoneSQL(cols, "created_ny", tz)
+	})
+	assert.Panics(t, func() {
+		NewCondition().andTimezone("created", "UTC'; DROP TABLE x", "2024-03-01 00:00:00")
+	})
+}
+
+func TestDbTables_getCondSQLWithRound(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().andRound("score__gt", 2, 9.99)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE ROUND(T0.`score`, 2) > ? ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE ROUND(T0.`score`, 2) > ? ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE ROUND(T0."score"::numeric, 2) > ? `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE ROUND(T0.`score`, 2) > ? ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE ROUND(T0.`score`, 2) > ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, 1, len(args))
+		})
+	}
+
+	assert.Panics(t, func() {
+		NewCondition().andRound("score", -1, 10)
+	})
+}
+
+func TestDbTables_getCondSQLWithApprox(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("age", 18).andApprox("score", 9.5, 0.5)
+
+	db := newdbBasePostgres().(*dbBasePostgres)
+	tables := newDbTables(mi, db)
+	res, args := db.readBatchSQL(tables, []string{"id"}, cond, querySet{mi: mi, limit: -1}, mi, tz)
+	assert.Equal(t, `SELECT T0."id" FROM "test_tab" T0 WHERE T0."age" = $1 AND ABS(T0."score" - $2) <= $3 `, res)
+	assert.Equal(t, []interface{}{int64(18), 9.5, 0.5}, args)
+
+	res, _ = newDbTables(mi, newdbBaseMysql()).getCondSQL(cond, false, tz)
+	assert.Equal(t, "WHERE T0.`age` = ? AND ABS(T0.`score` - ?) <= ? ", res)
+
+	assert.Panics(t, func() {
+		NewCondition().andApprox("score", 9.5, -0.5)
+	})
+}
+
+func TestDbTables_getCondSQLWithContainsAt(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("name__contains_at", SubstrPos("foo", 10))
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE LOCATE(?, T0.`name`, 10) > 0 ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE LOCATE(?, T0.`name`, 10) > 0 ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE STRPOS(SUBSTR(T0."name", 10), ?) > 0 `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE INSTR(SUBSTR(T0.`name`, 10), ?) > 0 ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE INSTR(SUBSTR(T0.`name`, 10), ?) > 0 ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{"foo"}, args)
+		})
+	}
+
+	tables := newDbTables(mi, newdbBaseSqlite())
+	assert.Panics(t, func() {
+		tables.getCondSQL(NewCondition().And("name__contains_at", SubstrPos("foo", 0)), false, tz)
+	})
+	assert.Panics(t, func() {
+		tables.getCondSQL(NewCondition().And("name__contains_at", "foo"), false, tz)
+	})
+}
+
+func TestDbTables_getCondSQLWithModulo(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("age", 18).And("id__mod", Mod(10, 3))
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE T0.`age` = ? AND (T0.`id` % ?) = ? ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE T0.`age` = ? AND (T0.`id` % ?) = ? ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE T0."age" = ? AND (T0."id" % ?) = ? `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE T0.`age` = ? AND (T0.`id` % ?) = ? ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE T0.`age` = ? AND MOD(T0.`id`, ?) = ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{int64(18), int64(10), int64(3)}, args)
+		})
+	}
+
+	tables := newDbTables(mi, newdbBaseSqlite())
+	assert.Panics(t, func() {
+		tables.getCondSQL(NewCondition().And("id__mod", 10), false, tz)
+	})
+	assert.Panics(t, func() {
+		Mod(0, 1)
+	})
+}
+
+func TestDbTables_getCondSQLWithTrimEq(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("name__trimeq", " a01 ")
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE TRIM(T0.`name`) = TRIM(?) ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE TRIM(T0.`name`) = TRIM(?) ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE TRIM(T0."name"::text) = TRIM(?) `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE TRIM(T0.`name`) = TRIM(?) ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE TRIM(T0.`name`) = TRIM(?) ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{" a01 "}, args)
+		})
+	}
+}
+
+func TestDbBase_deleteReturningSQL(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	qs := querySet{mi: mi, limit: 5, orders: order_clause.ParseOrder("id")}
+	cond := NewCondition().And("name", "ready")
+
+	testCases := []struct {
+		name string
+		db   *dbBase
+
+		wantRes string
+	}{
+		{
+			name: "postgres",
+			db:   &newdbBasePostgres().(*dbBasePostgres).dbBase,
+			wantRes: `DELETE FROM "test_tab" WHERE "id" IN (SELECT T0."id" FROM "test_tab" T0 WHERE T0."name" = $1 ` +
+				`ORDER BY T0."id" ASC LIMIT 5 FOR UPDATE OF T0 SKIP LOCKED) RETURNING "id", "name", "age", "score", "test_tab_1_id"`,
+		},
+		{
+			name: "sqlite",
+			db:   &newdbBaseSqlite().(*dbBaseSqlite).dbBase,
+			wantRes: "DELETE FROM `test_tab` WHERE `id` IN (SELECT T0.`id` FROM `test_tab` T0 WHERE T0.`name` = ? " +
+				"ORDER BY T0.`id` ASC LIMIT 5) RETURNING `id`, `name`, `age`, `score`, `test_tab_1_id`",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, args := tc.db.deleteReturningSQL(&qs, mi, cond, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{"ready"}, args)
+		})
+	}
+
+	_, err = newdbBaseMysql().DeleteBatchReturning(context.Background(), nil, &qs, mi, cond, &[]*testTab{}, tz)
+	assert.Equal(t, ErrNotImplement, err)
+}
+
+func TestDbTables_getCondSQLWithJSONExists(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("age", 18).And("name__json_exists", "$.user.id")
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "mysql",
+			db:       newdbBaseMysql(),
+			wantRes:  "WHERE T0.`age` = ? AND JSON_CONTAINS_PATH(T0.`name`, 'one', ?) ",
+			wantArgs: []interface{}{int64(18), "$.user.id"},
+		},
+		{
+			name:     "tidb",
+			db:       newdbBaseTidb(),
+			wantRes:  "WHERE T0.`age` = ? AND JSON_CONTAINS_PATH(T0.`name`, 'one', ?) ",
+			wantArgs: []interface{}{int64(18), "$.user.id"},
+		},
+		{
+			name:     "postgres",
+			db:       newdbBasePostgres(),
+			wantRes:  `WHERE T0."age" = ? AND jsonb_path_exists(T0."name"::jsonb, CAST(? AS jsonpath)) `,
+			wantArgs: []interface{}{int64(18), "$.user.id"},
+		},
+		{
+			name:     "sqlite",
+			db:       newdbBaseSqlite(),
+			wantRes:  "WHERE T0.`age` = ? AND json_type(T0.`name`, ?) IS NOT NULL ",
+			wantArgs: []interface{}{int64(18), "$.user.id"},
+		},
+		{
+			name:     "oracle",
+			db:       newdbBaseOracle(),
+			wantRes:  "WHERE T0.`age` = ? AND JSON_EXISTS(T0.`name`, '$.user.id') ",
+			wantArgs: []interface{}{int64(18)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+
+	res, args := newDbTables(mi, newdbBaseOracle()).getCondSQL(NewCondition().And("name__json_exists", "$.a'b"), false, tz)
+	assert.Equal(t, "WHERE JSON_EXISTS(T0.`name`, '$.a''b') ", res)
+	assert.Empty(t, args)
+
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseMysql()).getCondSQL(NewCondition().And("name__json_exists", 1), false, tz)
+	})
+}
+
+func TestDbTables_getCondSQLWithJSONValid(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("age", 18).And("name__is_json", true)
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE T0.`age` = ? AND JSON_VALID(T0.`name`) = 1 ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE T0.`age` = ? AND JSON_VALID(T0.`name`) = 1 ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE T0."age" = ? AND T0."name" IS JSON `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "WHERE T0.`age` = ? AND json_valid(T0.`name`) = 1 ",
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE T0.`age` = ? AND T0.`name` IS JSON ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{int64(18)}, args)
+		})
+	}
+
+	res, args := newDbTables(mi, newdbBaseOracle()).getCondSQL(NewCondition().And("name__is_json", false), false, tz)
+	assert.Equal(t, "WHERE NOT (T0.`name` IS JSON) ", res)
+	assert.Empty(t, args)
+
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseMysql()).getCondSQL(NewCondition().And("name__is_json", "yes"), false, tz)
+	})
+}
+
+func TestDbTables_getCondSQLWithUnaccent(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	cond := NewCondition().And("age", 18).And("name__unaccent", "cafe")
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE T0.`age` = ? AND T0.`name` COLLATE utf8mb4_0900_ai_ci = ? ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE T0.`age` = ? AND T0.`name` COLLATE utf8mb4_0900_ai_ci = ? ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE T0."age" = ? AND unaccent(T0."name") = unaccent(?) `,
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE T0.`age` = ? AND T0.`name` COLLATE BINARY_AI = ? ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{int64(18), "cafe"}, args)
+		})
+	}
+
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseSqlite()).getCondSQL(NewCondition().And("name__unaccent", "cafe"), false, tz)
+	})
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBasePostgres()).getCondSQL(NewCondition().And("name__unaccent", 1), false, tz)
+	})
+}
+
+func TestDbTables_getCondSQLWithJSONEqual(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	doc := map[string]interface{}{"name": "slene", "tags": []string{"a", "b"}}
+	cond := NewCondition().And("name__json_eq", doc)
+	wantArgs := []interface{}{`{"name":"slene","tags":["a","b"]}`}
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "WHERE T0.`name` = CAST(? AS JSON) ",
+		},
+		{
+			name:    "tidb",
+			db:      newdbBaseTidb(),
+			wantRes: "WHERE T0.`name` = CAST(? AS JSON) ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `WHERE T0."name"::jsonb = CAST(? AS jsonb) `,
+		},
+		{
+			name:    "oracle",
+			db:      newdbBaseOracle(),
+			wantRes: "WHERE JSON_EQUAL(T0.`name`, ?) ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, wantArgs, args)
+		})
+	}
+
+	_, args := newDbTables(mi, newdbBasePostgres()).getCondSQL(NewCondition().And("name__json_eq", json.RawMessage(`{"b": 1, "a": 2}`)), false, tz)
+	assert.Equal(t, []interface{}{`{"b": 1, "a": 2}`}, args)
+
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBaseSqlite()).getCondSQL(cond, false, tz)
+	})
+	assert.Panics(t, func() {
+		newDbTables(mi, newdbBasePostgres()).getCondSQL(NewCondition().And("name__json_eq", []byte("{")), false, tz)
+	})
+}
+
+func TestDbTables_getOrderSQLWithCoalesce(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	orders := []*order_clause.Order{
+		order_clause.Clause(order_clause.Coalesce("name", "TestTab1__name_1"), order_clause.SortDescending()),
+		order_clause.Clause(order_clause.Column("id"), order_clause.SortAscending()),
+	}
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "ORDER BY COALESCE(T0.`name`, T1.`name_1`) DESC, T0.`id` ASC ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `ORDER BY COALESCE(T0."name", T1."name_1") DESC, T0."id" ASC `,
+		},
+		{
+			name:    "sqlite",
+			db:      newdbBaseSqlite(),
+			wantRes: "ORDER BY COALESCE(T0.`name`, T1.`name_1`) DESC, T0.`id` ASC ",
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			res, args := tc.db.countSQL(tc.qs, mi, cond, tz)
+			tables := newDbTables(mi, tc.db)
+			res, _ := tables.getOrderSQL(orders, time.Local)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
+
+func TestDbTables_getCondSQLWithTopNPerGroup(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	topN := &topNPerGroup{
+		partition: []string{"TestTab1__name_1"},
+		order:     order_clause.ParseOrder("-score")[0],
+		n:         3,
+		cond:      NewCondition().And("age__gt", 18),
+	}
+	cond := &Condition{params: []condValue{{topN: topN}}}
+	cond = cond.And("name", "slene")
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name: "mysql",
+			db:   newdbBaseMysql(),
+			wantRes: "WHERE T0.`id` IN (SELECT T.`id` FROM (SELECT T0.`id`, ROW_NUMBER() OVER (PARTITION BY T1.`name_1` ORDER BY T0.`score` DESC) `row_num` " +
+				"FROM `test_tab` T0 INNER JOIN `test_tab1` T1 ON T1.`id` = T0.`test_tab_1_id` WHERE T0.`age` > ? ) T WHERE T.`row_num` <= 3) AND T0.`name` = ? ",
+		},
+		{
+			name: "postgres",
+			db:   newdbBasePostgres(),
+			wantRes: `WHERE T0."id" IN (SELECT T."id" FROM (SELECT T0."id", ROW_NUMBER() OVER (PARTITION BY T1."name_1" ORDER BY T0."score" DESC) "row_num" ` +
+				`FROM "test_tab" T0 INNER JOIN "test_tab1" T1 ON T1."id" = T0."test_tab_1_id" WHERE T0."age" > ? ) T WHERE T."row_num" <= 3) AND T0."name" = ? `,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{int64(18), "slene"}, args)
+		})
+	}
+}
+
+func TestDbTables_getCondSQLWithDescendants(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	pk, _ := mi.Fields.GetByAny("id")
+	parent, _ := mi.Fields.GetByAny("TestTab1")
+	cond := &Condition{params: []condValue{{tree: &descendantsTree{pk: pk, parent: parent, root: 7}}}}
+	cond = cond.And("name", "slene")
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name: "mysql",
+			db:   newdbBaseMysql(),
+			wantRes: "WHERE T0.`id` IN (WITH RECURSIVE `tree` (`id`) AS (SELECT `id` FROM `test_tab` WHERE `id` = ? " +
+				"UNION ALL SELECT C.`id` FROM `test_tab` C INNER JOIN `tree` P ON C.`test_tab_1_id` = P.`id`) SELECT `id` FROM `tree`) AND T0.`name` = ? ",
+		},
+		{
+			name: "postgres",
+			db:   newdbBasePostgres(),
+			wantRes: `WHERE T0."id" IN (WITH RECURSIVE "tree" ("id") AS (SELECT "id" FROM "test_tab" WHERE "id" = ? ` +
+				`UNION ALL SELECT C."id" FROM "test_tab" C INNER JOIN "tree" P ON C."test_tab_1_id" = P."id") SELECT "id" FROM "tree") AND T0."name" = ? `,
+		},
+		{
+			name: "sqlite",
+			db:   newdbBaseSqlite(),
+			wantRes: "WHERE T0.`id` IN (WITH RECURSIVE `tree` (`id`) AS (SELECT `id` FROM `test_tab` WHERE `id` = ? " +
+				"UNION ALL SELECT C.`id` FROM `test_tab` C INNER JOIN `tree` P ON C.`test_tab_1_id` = P.`id`) SELECT `id` FROM `tree`) AND T0.`name` = ? ",
+		},
+		{
+			name: "oracle",
+			db:   newdbBaseOracle(),
+			wantRes: "WHERE T0.`id` IN (WITH `tree` (`id`) AS (SELECT `id` FROM `test_tab` WHERE `id` = ? " +
+				"UNION ALL SELECT C.`id` FROM `test_tab` C INNER JOIN `tree` P ON C.`test_tab_1_id` = P.`id`) SELECT `id` FROM `tree`) AND T0.`name` = ? ",
+		},
+	}
 
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
 			assert.Equal(t, tc.wantRes, res)
-			assert.Equal(t, tc.wantArgs, args)
+			assert.Equal(t, []interface{}{int64(7), "slene"}, args)
+		})
+	}
+}
+
+func TestDbTables_getCondSQLWithWindow(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	tz := time.Local
+
+	window := &windowFilter{
+		aggr:     WindowAggr{Func: "SUM", Column: "score", Partition: []string{"TestTab1__name_1"}, OrderBy: "age", Preceding: 6},
+		operator: "gt",
+		value:    "100",
+		cond:     NewCondition().And("age__gt", 18),
+	}
+	cond := &Condition{params: []condValue{{window: window}}}
+	cond = cond.And("name", "slene")
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name: "mysql",
+			db:   newdbBaseMysql(),
+			wantRes: "WHERE T0.`id` IN (SELECT T.`id` FROM (SELECT T0.`id`, SUM(T0.`score`) OVER (PARTITION BY T1.`name_1` ORDER BY T0.`age` ASC ROWS 6 PRECEDING) `window_aggr` " +
+				"FROM `test_tab` T0 INNER JOIN `test_tab1` T1 ON T1.`id` = T0.`test_tab_1_id` WHERE T0.`age` > ? ) T WHERE T.`window_aggr` > ?) AND T0.`name` = ? ",
+		},
+		{
+			name: "postgres",
+			db:   newdbBasePostgres(),
+			wantRes: `WHERE T0."id" IN (SELECT T."id" FROM (SELECT T0."id", SUM(T0."score") OVER (PARTITION BY T1."name_1" ORDER BY T0."age" ASC ROWS 6 PRECEDING) "window_aggr" ` +
+				`FROM "test_tab" T0 INNER JOIN "test_tab1" T1 ON T1."id" = T0."test_tab_1_id" WHERE T0."age" > ? ) T WHERE T."window_aggr" > ?) AND T0."name" = ? `,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getCondSQL(cond, false, tz)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{int64(18), "100", "slene"}, args)
+		})
+	}
+
+	// the value of COUNT is not converted to the column, no partition nor frame
+	qs := querySet{mi: mi}.FilterWindow(WindowAggr{Func: "count", Column: "name", OrderBy: "-age"}, "gte", 2).(*querySet)
+	res, args := newDbTables(mi, newdbBaseSqlite()).getCondSQL(qs.cond, false, tz)
+	assert.Equal(t, "WHERE T0.`id` IN (SELECT T.`id` FROM (SELECT T0.`id`, COUNT(T0.`name`) OVER (ORDER BY T0.`age` DESC) `window_aggr` FROM `test_tab` T0 ) T WHERE T.`window_aggr` >= ?) ", res)
+	assert.Equal(t, []interface{}{int64(2)}, args)
+
+	assert.Panics(t, func() {
+		querySet{mi: mi}.FilterWindow(WindowAggr{Func: "MEDIAN", Column: "score"}, "gt", 1)
+	})
+	assert.Panics(t, func() {
+		querySet{mi: mi}.FilterWindow(WindowAggr{Func: "SUM", Column: "score", Preceding: 6}, "gt", 1)
+	})
+	assert.Panics(t, func() {
+		querySet{mi: mi}.FilterWindow(WindowAggr{Func: "SUM", Column: "score"}, "in", 1)
+	})
+}
+
+func TestDbTables_getAggregationSQL(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	aggrs := []Aggregation{Avg("score", CoalesceZero()), Count("TestTab1__age_1", As("total"))}
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "T0.`name`, AVG(COALESCE(T0.`score`, 0)) `avg_score`, COUNT(T1.`age_1`) `total`",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `T0."name", AVG(COALESCE(T0."score", 0)) "avg_score", COUNT(T1."age_1") "total"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res := tables.getAggregationSQL([]string{"name"}, aggrs)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+
+	aggrs = []Aggregation{Percentile("score", 0.5), Percentile("TestTab1__age_1", 0.95, As("p95"))}
+	res := newDbTables(mi, newdbBasePostgres()).getAggregationSQL([]string{"name"}, aggrs)
+	assert.Equal(t, `T0."name", PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY T0."score") "percentile_score", `+
+		`PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY T1."age_1") "p95"`, res)
+	res = newDbTables(mi, newdbBaseOracle()).getAggregationSQL(nil, aggrs[:1])
+	assert.Equal(t, "PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY T0.`score`) `percentile_score`", res)
+
+	for _, db := range []dbBaser{newdbBaseMysql(), newdbBaseSqlite(), newdbBaseTidb()} {
+		assert.Panics(t, func() {
+			newDbTables(mi, db).getAggregationSQL(nil, aggrs)
+		})
+	}
+	assert.Panics(t, func() {
+		Percentile("score", 1.5)
+	})
+	assert.Panics(t, func() {
+		Percentile("*", 0.5)
+	})
+}
+
+func TestDbTables_getAggregationSQLWithGroupConcat(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	aggrs := []Aggregation{GroupConcat("name", "', ", "-score", As("names")), GroupConcat("TestTab1__age_1", `\`, "")}
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name: "mysql",
+			db:   newdbBaseMysql(),
+			wantRes: "GROUP_CONCAT(T0.`name` ORDER BY T0.`score` DESC SEPARATOR ''', ') `names`, " +
+				"GROUP_CONCAT(T1.`age_1` SEPARATOR '\\\\') `group_concat_TestTab1_age_1`",
+		},
+		{
+			name: "tidb",
+			db:   newdbBaseTidb(),
+			wantRes: "GROUP_CONCAT(T0.`name` ORDER BY T0.`score` DESC SEPARATOR ''', ') `names`, " +
+				"GROUP_CONCAT(T1.`age_1` SEPARATOR '\\\\') `group_concat_TestTab1_age_1`",
+		},
+		{
+			name: "postgres",
+			db:   newdbBasePostgres(),
+			wantRes: `string_agg(T0."name"::text, ''', ' ORDER BY T0."score" DESC) "names", ` +
+				`string_agg(T1."age_1"::text, '\') "group_concat_TestTab1_age_1"`,
+		},
+		{
+			name: "sqlite",
+			db:   newdbBaseSqlite(),
+			wantRes: "group_concat(T0.`name`, ''', ' ORDER BY T0.`score` DESC) `names`, " +
+				"group_concat(T1.`age_1`, '\\') `group_concat_TestTab1_age_1`",
+		},
+		{
+			name: "oracle",
+			db:   newdbBaseOracle(),
+			wantRes: "LISTAGG(T0.`name`, ''', ') WITHIN GROUP (ORDER BY T0.`score` DESC) `names`, " +
+				"LISTAGG(T1.`age_1`, '\\') WITHIN GROUP (ORDER BY NULL) `group_concat_TestTab1_age_1`",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res := tables.getAggregationSQL(nil, aggrs)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+
+	assert.Panics(t, func() {
+		GroupConcat("*", ",", "")
+	})
+}
+
+func TestDbTables_getHavingSQL(t *testing.T) {
+
+	mc := models.NewModelCacheHandler()
+
+	err := mc.Register("", false, new(testTab), new(testTab1), new(testTab2))
+
+	assert.Nil(t, err)
+
+	mc.Bootstrap()
+
+	mi, ok := mc.GetByMd(new(testTab))
+
+	assert.True(t, ok)
+
+	havings := []HavingCond{Ratio(Sum("score"), Count("*")).Gt(0.5), Count("age", CoalesceZero()).Lte(3)}
+
+	testCases := []struct {
+		name string
+		db   dbBaser
+
+		wantRes string
+	}{
+		{
+			name:    "mysql",
+			db:      newdbBaseMysql(),
+			wantRes: "HAVING 1.0 * SUM(T0.`score`) / NULLIF(COUNT(*), 0) > ? AND COUNT(COALESCE(T0.`age`, 0)) <= ? ",
+		},
+		{
+			name:    "postgres",
+			db:      newdbBasePostgres(),
+			wantRes: `HAVING 1.0 * SUM(T0."score") / NULLIF(COUNT(*), 0) > ? AND COUNT(COALESCE(T0."age", 0)) <= ? `,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := newDbTables(mi, tc.db)
+			res, args := tables.getHavingSQL(havings)
+			assert.Equal(t, tc.wantRes, res)
+			assert.Equal(t, []interface{}{0.5, 3}, args)
 		})
 	}
+
+	tables := newDbTables(mi, newdbBaseMysql())
+	res, args := tables.getHavingSQL(nil)
+	assert.Equal(t, "", res)
+	assert.Nil(t, args)
+	assert.Equal(t, "COUNT(*) `count`", tables.getAggregationSQL(nil, []Aggregation{Count("*")}))
 }
 
 type testTab struct {
@@ -1444,11 +5197,12 @@ type testTab struct {
 }
 
 type testTab1 struct {
-	ID       int64     `orm:"auto;pk;column(id)"`
-	Name1    string    `orm:"column(name_1)"`
-	Age1     int64     `orm:"column(age_1)"`
-	Score1   int64     `orm:"column(score_1)"`
-	TestTab2 *testTab2 `orm:"rel(fk);column(test_tab_2_id)"`
+	ID       int64      `orm:"auto;pk;column(id)"`
+	Name1    string     `orm:"column(name_1)"`
+	Age1     int64      `orm:"column(age_1)"`
+	Score1   int64      `orm:"column(score_1)"`
+	TestTab2 *testTab2  `orm:"rel(fk);column(test_tab_2_id)"`
+	TestTabs []*testTab `orm:"reverse(many)"`
 }
 
 type testTab2 struct {
@@ -1457,3 +5211,20 @@ type testTab2 struct {
 	Age2   int64 `orm:"column(age_2)"`
 	Score2 int64 `orm:"column(score_2)"`
 }
+
+type testTimeTab struct {
+	ID      int64     `orm:"auto;pk;column(id)"`
+	Created time.Time `orm:"column(created)"`
+}
+
+type testBoolTab struct {
+	ID       int64  `orm:"auto;pk;column(id)"`
+	Name     string `orm:"column(name)"`
+	IsActive bool   `orm:"column(is_active)"`
+}
+
+type testEmptyTab struct {
+	ID   int64  `orm:"auto;pk;column(id)"`
+	Name string `orm:"column(name)"`
+	Nick string `orm:"column(nick);empty_as_null"`
+}
@@ -17,6 +17,9 @@ package orm
 import (
 	"context"
 	"fmt"
+	"strings"
+
+	"github.com/beego/beego/v2/client/orm/internal/models"
 )
 
 // mysql dbBaser implementation.
@@ -26,8 +29,11 @@ type dbBaseTidb struct {
 
 var _ dbBaser = new(dbBaseTidb)
 
-// Get mysql operator.
+// Get mysql operator, tidb has no SOUNDEX.
 func (d *dbBaseTidb) OperatorSQL(operator string) string {
+	if operator == "soundex" {
+		return ""
+	}
 	return mysqlOperators[operator]
 }
 
@@ -47,6 +53,106 @@ func (d *dbBaseTidb) ShowColumnsQuery(table string) string {
 		"WHERE table_schema = DATABASE() AND table_name = '%s'", table)
 }
 
+// tidb orders by the FIELD of column in the values like mysql.
+func (d *dbBaseTidb) PositionSQL(col string, n int) string {
+	return fmt.Sprintf("FIELD(%s%s)", col, strings.Repeat(", ?", n))
+}
+
+// tidb LENGTH counts bytes like mysql, use CHAR_LENGTH for characters.
+func (d *dbBaseTidb) LengthSQL(col string) string {
+	return fmt.Sprintf("CHAR_LENGTH(%s)", col)
+}
+
+// tidb counts the elements of a json array by JSON_LENGTH like mysql.
+func (d *dbBaseTidb) ArrayLengthSQL(_ *models.FieldInfo, col string) string {
+	return fmt.Sprintf("JSON_LENGTH(%s)", col)
+}
+
+// tidb extracts the element of a json array like mysql.
+func (d *dbBaseTidb) ArrayElementSQL(_ *models.FieldInfo, col string, index int) string {
+	return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, '$[%d]'))", col, index)
+}
+
+// tidb joins the values by GROUP_CONCAT like mysql.
+func (d *dbBaseTidb) GroupConcatSQL(col string, sep string, orderBy string) string {
+	return mysqlGroupConcatSQL(col, sep, orderBy)
+}
+
+// tidb formats the column to the start of the unit like mysql.
+func (d *dbBaseTidb) TimeBucketSQL(col string, unit string) string {
+	return fmt.Sprintf("DATE_FORMAT(%s, '%s')", col, mysqlTimeBucketFormats[unit])
+}
+
+// tidb casts to the CAST types of mysql.
+func (d *dbBaseTidb) CastSQL(col string, castType string) string {
+	return mysqlCastSQL(col, castType)
+}
+
+// tidb concatenates by CONCAT like mysql.
+func (d *dbBaseTidb) ConcatSQL(exprs []string) string {
+	return mysqlConcatSQL(exprs)
+}
+
+// tidb explains the query like mysql.
+func (d *dbBaseTidb) ExplainSQL(analyze bool) string {
+	return mysqlExplainSQL(analyze)
+}
+
+// tidb compares by the accent insensitive collation like mysql, since tidb 7.4.
+func (d *dbBaseTidb) UnaccentSQL(col string) string {
+	return fmt.Sprintf("%s COLLATE utf8mb4_0900_ai_ci = ?", col)
+}
+
+// tidb checks the document by JSON_VALID like mysql.
+func (d *dbBaseTidb) JSONValidSQL(fi *models.FieldInfo, col string) string {
+	return fmt.Sprintf("JSON_VALID(%s) = 1", col)
+}
+
+// tidb checks the json path by JSON_CONTAINS_PATH like mysql.
+func (d *dbBaseTidb) JSONExistsSQL(col string, path string) (string, []interface{}) {
+	return fmt.Sprintf("JSON_CONTAINS_PATH(%s, 'one', ?)", col), []interface{}{path}
+}
+
+// tidb finds the value by FIND_IN_SET like mysql.
+func (d *dbBaseTidb) SetIncludesSQL(col string, elem string) (string, []interface{}) {
+	return fmt.Sprintf("FIND_IN_SET(?, %s) > 0", col), []interface{}{elem}
+}
+
+// tidb compares the json values like mysql.
+func (d *dbBaseTidb) JSONEqualSQL(col string, doc string) (string, []interface{}) {
+	return fmt.Sprintf("%s = CAST(? AS JSON)", col), []interface{}{doc}
+}
+
+// tidb has the date parts of mysql.
+func (d *dbBaseTidb) DatePartSQL(part string, col string) string {
+	return mysqlDatePartSQL(part, col)
+}
+
+// tidb updates the row of a duplicate key like mysql.
+func (d *dbBaseTidb) UpsertSQL(mi *models.ModelInfo, names []string, conflicts []string) string {
+	return mysqlUpsertSQL(&d.dbBase, mi, names, conflicts)
+}
+
+// tidb converts by CONVERT_TZ like mysql.
+func (d *dbBaseTidb) TimezoneConvertSQL(col string, from string, tz string) string {
+	return fmt.Sprintf("CONVERT_TZ(%s, '%s', '%s')", col, from, tz)
+}
+
+// tidb finds the substring by LOCATE like mysql.
+func (d *dbBaseTidb) SubstringIndexSQL(col string, pos int) string {
+	return fmt.Sprintf("LOCATE(?, %s, %d)", col, pos)
+}
+
+// tidb takes named locks by GET_LOCK like mysql.
+func (d *dbBaseTidb) AdvisoryLockSQL() (string, string) {
+	return mysqlAdvisoryLockSQL()
+}
+
+// tidb adds INTERVAL to NOW() like mysql.
+func (d *dbBaseTidb) IntervalSQL(expr string, amount int, unit IntervalUnit) string {
+	return mysqlIntervalSQL(expr, amount, unit)
+}
+
 // execute sql to check index exist.
 func (d *dbBaseTidb) IndexExists(ctx context.Context, db dbQuerier, table string, name string) bool {
 	row := db.QueryRowContext(ctx, "SELECT count(*) FROM information_schema.statistics "+
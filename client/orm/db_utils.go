@@ -16,6 +16,7 @@ package orm
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
 	"time"
 
@@ -47,6 +48,10 @@ func getExistPk(mi *models.ModelInfo, ind reflect.Value) (column string, value i
 		value = vu
 	} else if fi.FieldType&IsRelField > 0 {
 		_, value, exist = getExistPk(fi.RelModelInfo, reflect.Indirect(v))
+	} else if fi.FieldType == TypeBinaryField {
+		vu := v.Bytes()
+		exist = len(vu) > 0
+		value = vu
 	} else {
 		vu := v.String()
 		exist = vu != ""
@@ -57,6 +62,14 @@ func getExistPk(mi *models.ModelInfo, ind reflect.Value) (column string, value i
 	return
 }
 
+// the pk value usable as a map key, the binary pks are keyed by their string.
+func pkKey(pk interface{}) interface{} {
+	if b, ok := pk.([]byte); ok {
+		return string(b)
+	}
+	return pk
+}
+
 // Get Fields description as flatted string.
 func getFlatParams(fi *models.FieldInfo, args []interface{}, tz *time.Location) (params []interface{}) {
 outFor:
@@ -77,7 +90,8 @@ outFor:
 		switch kind {
 		case reflect.String:
 			v := val.String()
-			if fi != nil {
+			// the string of a formatted time is compared as it is
+			if fi != nil && fi.TimeFormat == "" {
 				if fi.FieldType == TypeTimeField || fi.FieldType == TypeDateField || fi.FieldType == TypeDateTimeField {
 					var t time.Time
 					var err error
@@ -121,13 +135,19 @@ outFor:
 			arg = val.Bool()
 		case reflect.Slice, reflect.Array:
 			if _, ok := arg.([]byte); ok {
-				continue outFor
+				// bound as bytes, not flattened to its elements
+				break
 			}
 
 			var args []interface{}
 			for i := 0; i < val.Len(); i++ {
 				v := val.Index(i)
 
+				// skip nil model pointers, such as []*User{u, nil}
+				if v.Kind() == reflect.Ptr && v.IsNil() {
+					continue
+				}
+
 				var vu interface{}
 				if v.CanInterface() {
 					vu = v.Interface()
@@ -146,8 +166,18 @@ outFor:
 			}
 			continue outFor
 		case reflect.Struct:
-			if v, ok := arg.(time.Time); ok {
-				if fi != nil && fi.FieldType == TypeDateField {
+			if v, ok := arg.(big.Rat); ok {
+				if fi != nil && fi.FieldType == TypeDecimalField {
+					arg = v.FloatString(fi.Decimals)
+				} else {
+					arg, _ = v.Float64()
+				}
+			} else if v, ok := arg.(exactTime); ok {
+				arg = time.Time(v)
+			} else if v, ok := arg.(time.Time); ok {
+				if fi != nil && fi.TimeFormat != "" {
+					arg = v.In(tz).Format(fi.TimeFormat)
+				} else if fi != nil && fi.FieldType == TypeDateField {
 					arg = v.In(tz).Format(utils.FormatDate)
 				} else if fi != nil && fi.FieldType == TypeDateTimeField {
 					arg = v.In(tz).Format(utils.FormatDateTime)
@@ -177,3 +207,110 @@ outFor:
 	}
 	return
 }
+
+// split the refs of a row with the computed fields selected after n columns,
+// return the refs of the columns and the related models and the refs of the k computed fields.
+func splitComputedRefs(refs []interface{}, n, k int) ([]interface{}, []interface{}) {
+	if k == 0 {
+		return refs, nil
+	}
+	rowRefs := make([]interface{}, 0, len(refs)-k)
+	rowRefs = append(rowRefs, refs[:n]...)
+	rowRefs = append(rowRefs, refs[n+k:]...)
+	return rowRefs, refs[n : n+k]
+}
+
+// set the scanned values of the computed fields of mi to the model ind.
+func setComputedValues(mi *models.ModelInfo, ind reflect.Value, refs []interface{}) {
+	for i, ref := range refs {
+		setComputedValue(ind.FieldByIndex(mi.ComputedFields[i].FieldIndex), reflect.Indirect(reflect.ValueOf(ref)).Interface())
+	}
+}
+
+// set the value scanned by the driver to the field of a computed field, converting it to the type of field.
+func setComputedValue(field reflect.Value, value interface{}) {
+	if b, ok := value.([]byte); ok && field.Type() != reflect.TypeOf(b) {
+		value = string(b)
+	}
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return
+	}
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+	val := reflect.ValueOf(value)
+	if val.Type().AssignableTo(field.Type()) {
+		field.Set(val)
+		return
+	}
+	str := utils.StrTo(utils.ToStr(value))
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(str.String())
+	case reflect.Bool:
+		v, _ := str.Bool()
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, _ := str.Int64()
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, _ := str.Uint64()
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, _ := str.Float64()
+		field.SetFloat(v)
+	default:
+		if val.Type().ConvertibleTo(field.Type()) {
+			field.Set(val.Convert(field.Type()))
+		}
+	}
+}
+
+// read the 1 or 0 of ValuesBool as a bool.
+func flagBool(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		return v, nil
+	case int64:
+		return v != 0, nil
+	}
+	b, err := utils.StrTo(utils.ToStr(val)).Bool()
+	if err != nil {
+		return nil, fmt.Errorf("unknown bool value `%v`: %w", val, err)
+	}
+	return b, nil
+}
+
+// get the utc offset of loc, such as +08:00, which is the current one for the timezones with daylight saving time.
+func tzOffset(loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.Now().In(loc).Format("-07:00")
+}
+
+// read the wall time of the time bucket val, converted to a timezone by the db, as the time of loc.
+func wallTimeIn(val interface{}, loc *time.Location) (interface{}, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), loc), nil
+	}
+	s := utils.ToStr(val)
+	layout := utils.FormatDateTime
+	if len(s) < len(layout) {
+		layout = utils.FormatDate
+	} else {
+		s = s[:len(layout)]
+	}
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time bucket value `%v`: %w", val, err)
+	}
+	return t, nil
+}
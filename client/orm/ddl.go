@@ -15,6 +15,7 @@
 package orm
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -22,6 +23,123 @@ import (
 	imodels "github.com/beego/beego/v2/client/orm/internal/models"
 )
 
+// GenerateTableSQL return the CREATE TABLE and CREATE INDEX statements of the registered model md
+// in the dialect of the DataBase alias name, the same ones run by syncdb, without running them.
+func GenerateTableSQL(md interface{}, name string) (string, error) {
+	al, ok := dataBaseCache.get(name)
+	if !ok {
+		return "", fmt.Errorf("<orm.GenerateTableSQL> unknown DataBase alias name %s", name)
+	}
+	mi, ok := defaultModelCache.GetByMd(md)
+	if !ok {
+		return "", fmt.Errorf("<orm.GenerateTableSQL> model `%T` is not registered", md)
+	}
+	return generateTableSQL(mi, al), nil
+}
+
+// the create sql of the table followed by the ones of its indexes, one statement per line.
+func generateTableSQL(mi *imodels.ModelInfo, al *alias) string {
+	sql, indexes := getTableCreateSQL(mi, al)
+	queries := []string{sql}
+	for _, idx := range indexes {
+		queries = append(queries, idx.SQL)
+	}
+	return strings.Join(queries, "\n")
+}
+
+// MigrationStatement is a statement syncdb runs with the statement reversing it.
+type MigrationStatement struct {
+	Table string
+	Up    string
+	// Down reverses Up, empty when Irreversible.
+	Down string
+	// Irreversible is true for the destructive statements, such as a dropped table losing its rows.
+	Irreversible bool
+}
+
+// GenerateMigrationSQL return the statements syncdb would run on the DataBase alias name for the registered models,
+// compared with the tables, columns and indexes in the database, each with its down statement, without running them.
+// The down statements must run in the reverse order of the statements.
+// force drops the tables first like syncdb -force, the drops are Irreversible and have no down statement.
+// for example:
+//
+//	stmts, err := orm.GenerateMigrationSQL("default", false)
+//	for i := len(stmts) - 1; i >= 0; i-- {
+//		fmt.Println(stmts[i].Down)
+//	}
+func GenerateMigrationSQL(name string, force bool) ([]MigrationStatement, error) {
+	al, ok := dataBaseCache.get(name)
+	if !ok {
+		return nil, fmt.Errorf("<orm.GenerateMigrationSQL> unknown DataBase alias name %s", name)
+	}
+	return getMigrationStatements(context.Background(), defaultModelCache, al, force)
+}
+
+// the statements of syncdb for the models of mc on the database of al, with their down statements.
+func getMigrationStatements(ctx context.Context, mc *imodels.ModelCache, al *alias, force bool) ([]MigrationStatement, error) {
+	if mc.Empty() {
+		return nil, errors.New("no Model found, need Register your model")
+	}
+	Q := al.DbBaser.TableQuote()
+
+	var stmts []MigrationStatement
+	tables := make(map[string]bool)
+	if force {
+		drops, err := getDbDropSQL(mc, al)
+		if err != nil {
+			return nil, err
+		}
+		for i, mi := range mc.AllOrdered() {
+			stmts = append(stmts, MigrationStatement{Table: mi.Table, Up: drops[i], Irreversible: true})
+		}
+	} else {
+		var err error
+		if tables, err = al.DbBaser.GetTables(al.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, mi := range mc.AllOrdered() {
+		if !imodels.IsApplicableTableForDB(mi.AddrField, al.Name) {
+			continue
+		}
+		sql, indexes := getTableCreateSQL(mi, al)
+
+		if !tables[mi.Table] {
+			stmts = append(stmts, MigrationStatement{
+				Table: mi.Table,
+				Up:    sql,
+				Down:  fmt.Sprintf("DROP TABLE %s%s%s;", Q, mi.Table, Q),
+			})
+			for _, idx := range indexes {
+				stmts = append(stmts, MigrationStatement{Table: mi.Table, Up: idx.SQL, Down: getIndexDropQuery(al, idx)})
+			}
+			continue
+		}
+
+		columns, err := al.DbBaser.GetColumns(ctx, al.DB, mi.Table)
+		if err != nil {
+			return nil, err
+		}
+		for _, fi := range mi.Fields.FieldsDB {
+			if _, ok := columns[fi.Column]; ok {
+				continue
+			}
+			query, err := getColumnAddQuery(al, fi)
+			if err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, MigrationStatement{Table: mi.Table, Up: query, Down: getColumnDropQuery(al, fi)})
+		}
+		for _, idx := range indexes {
+			if !al.DbBaser.IndexExists(ctx, al.DB, idx.Table, idx.Name) {
+				stmts = append(stmts, MigrationStatement{Table: mi.Table, Up: idx.SQL, Down: getIndexDropQuery(al, idx)})
+			}
+		}
+	}
+	return stmts, nil
+}
+
 // getDbDropSQL Get database scheme drop sql queries
 func getDbDropSQL(mc *imodels.ModelCache, al *alias) (queries []string, err error) {
 	if mc.Empty() {
@@ -44,153 +162,206 @@ func getDbCreateSQL(mc *imodels.ModelCache, al *alias) (queries []string, tableI
 		return
 	}
 
+	tableIndexes = make(map[string][]dbIndex)
+
+	for _, mi := range mc.AllOrdered() {
+		sql, indexes := getTableCreateSQL(mi, al)
+		queries = append(queries, sql)
+		if len(indexes) > 0 {
+			tableIndexes[mi.Table] = indexes
+		}
+	}
+
+	return
+}
+
+// getTableCreateSQL Get the table creation sql of the model and the sql of its indexes
+func getTableCreateSQL(mi *imodels.ModelInfo, al *alias) (sql string, indexes []dbIndex) {
 	Q := al.DbBaser.TableQuote()
 	T := al.DbBaser.DbTypes()
 	sep := fmt.Sprintf("%s, %s", Q, Q)
 
-	tableIndexes = make(map[string][]dbIndex)
+	sql = fmt.Sprintf("-- %s\n", strings.Repeat("-", 50))
+	sql += fmt.Sprintf("--  Table Structure for `%s`\n", mi.FullName)
+	sql += fmt.Sprintf("-- %s\n", strings.Repeat("-", 50))
 
-	for _, mi := range mc.AllOrdered() {
-		sql := fmt.Sprintf("-- %s\n", strings.Repeat("-", 50))
-		sql += fmt.Sprintf("--  Table Structure for `%s`\n", mi.FullName)
-		sql += fmt.Sprintf("-- %s\n", strings.Repeat("-", 50))
-
-		sql += fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s%s%s (\n", Q, mi.Table, Q)
-
-		columns := make([]string, 0, len(mi.Fields.FieldsDB))
-
-		sqlIndexes := [][]string{}
-		var commentIndexes []int // store comment indexes for postgres
-
-		for i, fi := range mi.Fields.FieldsDB {
-			column := fmt.Sprintf("    %s%s%s ", Q, fi.Column, Q)
-			col := getColumnTyp(al, fi)
-			if fi.DBType != "" {
-				column += fi.DBType
-			} else if fi.Auto {
-				switch al.Driver {
-				case DRSqlite, DRPostgres:
-					column += T["auto"]
-				default:
-					column += col + " " + T["auto"]
-				}
-			} else if fi.Pk {
-				column += col + " " + T["pk"]
-			} else {
-				column += col
+	sql += fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s%s%s (\n", Q, mi.Table, Q)
 
-				if !fi.Null {
-					column += " " + "NOT NULL"
-				}
+	columns := make([]string, 0, len(mi.Fields.FieldsDB))
 
-				// if fi.initial.String() != "" {
-				//	column += " DEFAULT " + fi.initial.String()
-				// }
+	sqlIndexes := [][]string{}
+	var commentIndexes []int // store comment indexes for postgres
 
-				// Append attribute DEFAULT
-				column += getColumnDefault(fi)
+	for i, fi := range mi.Fields.FieldsDB {
+		column := fmt.Sprintf("    %s%s%s ", Q, fi.Column, Q)
+		col := getColumnTyp(al, fi)
+		if fi.DBType != "" {
+			column += fi.DBType
+		} else if fi.Auto {
+			switch al.Driver {
+			case DRSqlite, DRPostgres:
+				column += T["auto"]
+			default:
+				column += col + " " + T["auto"]
+			}
+		} else if fi.Pk {
+			column += col + " " + T["pk"]
+		} else {
+			column += col
 
-				if fi.Unique {
-					column += " " + "UNIQUE"
-				}
+			if !fi.Null {
+				column += " " + "NOT NULL"
+			}
 
-				if fi.Index {
-					sqlIndexes = append(sqlIndexes, []string{fi.Column})
-				}
+			// if fi.initial.String() != "" {
+			//	column += " DEFAULT " + fi.initial.String()
+			// }
+
+			// Append attribute DEFAULT
+			column += getColumnDefault(al, fi)
+
+			if fi.Unique {
+				column += " " + "UNIQUE"
 			}
 
-			if strings.Contains(column, "%COL%") {
-				column = strings.Replace(column, "%COL%", fi.Column, -1)
+			if fi.Index {
+				sqlIndexes = append(sqlIndexes, []string{fi.Column})
 			}
+		}
+
+		if strings.Contains(column, "%COL%") {
+			column = strings.Replace(column, "%COL%", fi.Column, -1)
+		}
 
-			if fi.Description != "" && al.Driver != DRSqlite {
-				if al.Driver == DRPostgres {
-					commentIndexes = append(commentIndexes, i)
+		if fi.Description != "" && al.Driver != DRSqlite {
+			if al.Driver == DRPostgres {
+				commentIndexes = append(commentIndexes, i)
+			} else {
+				column += " " + fmt.Sprintf("COMMENT '%s'", fi.Description)
+			}
+		}
+
+		columns = append(columns, column)
+	}
+
+	if mi.Model != nil {
+		allnames := imodels.GetTableUnique(mi.AddrField)
+		if !mi.Manual && len(mi.Uniques) > 0 {
+			allnames = append(allnames, mi.Uniques)
+		}
+		for _, names := range allnames {
+			cols := make([]string, 0, len(names))
+			for _, name := range names {
+				if fi, ok := mi.Fields.GetByAny(name); ok && fi.DBcol {
+					cols = append(cols, fi.Column)
 				} else {
-					column += " " + fmt.Sprintf("COMMENT '%s'", fi.Description)
+					panic(fmt.Errorf("cannot found column `%s` when parse UNIQUE in `%s.TableUnique`", name, mi.FullName))
 				}
 			}
-
+			column := fmt.Sprintf("    UNIQUE (%s%s%s)", Q, strings.Join(cols, sep), Q)
 			columns = append(columns, column)
 		}
+	}
+
+	sql += strings.Join(columns, ",\n")
+	sql += "\n)"
 
+	if al.Driver == DRMySQL {
+		var engine string
 		if mi.Model != nil {
-			allnames := imodels.GetTableUnique(mi.AddrField)
-			if !mi.Manual && len(mi.Uniques) > 0 {
-				allnames = append(allnames, mi.Uniques)
-			}
-			for _, names := range allnames {
-				cols := make([]string, 0, len(names))
-				for _, name := range names {
-					if fi, ok := mi.Fields.GetByAny(name); ok && fi.DBcol {
-						cols = append(cols, fi.Column)
-					} else {
-						panic(fmt.Errorf("cannot found column `%s` when parse UNIQUE in `%s.TableUnique`", name, mi.FullName))
-					}
-				}
-				column := fmt.Sprintf("    UNIQUE (%s%s%s)", Q, strings.Join(cols, sep), Q)
-				columns = append(columns, column)
-			}
+			engine = imodels.GetTableEngine(mi.AddrField)
+		}
+		if engine == "" {
+			engine = al.Engine
 		}
+		sql += " ENGINE=" + engine
+	}
 
-		sql += strings.Join(columns, ",\n")
-		sql += "\n)"
+	sql += ";"
+	if al.Driver == DRPostgres && len(commentIndexes) > 0 {
+		// append comments for postgres only
+		for _, index := range commentIndexes {
+			sql += fmt.Sprintf("\nCOMMENT ON COLUMN %s%s%s.%s%s%s is '%s';",
+				Q,
+				mi.Table,
+				Q,
+				Q,
+				mi.Fields.FieldsDB[index].Column,
+				Q,
+				mi.Fields.FieldsDB[index].Description)
+		}
+	}
 
-		if al.Driver == DRMySQL {
-			var engine string
-			if mi.Model != nil {
-				engine = imodels.GetTableEngine(mi.AddrField)
+	// the columns leading an index
+	leading := make(map[string]bool)
+	if mi.Model != nil {
+		for _, spec := range imodels.GetTableIndexSpec(mi.AddrField) {
+			names := make([]string, 0, len(spec.Columns))
+			cols := make([]string, 0, len(spec.Columns))
+			for _, col := range spec.Columns {
+				fi, ok := mi.Fields.GetByAny(col.Name)
+				if !ok || !fi.DBcol {
+					panic(fmt.Errorf("cannot found column `%s` when parse INDEX in `%s.TableIndexSpec`", col.Name, mi.FullName))
+				}
+				names = append(names, fi.Column)
+				column := Q + fi.Column + Q
+				if col.Desc {
+					column += " DESC"
+				}
+				cols = append(cols, column)
 			}
-			if engine == "" {
-				engine = al.Engine
+			if len(names) > 0 {
+				leading[names[0]] = true
 			}
-			sql += " ENGINE=" + engine
-		}
-
-		sql += ";"
-		if al.Driver == DRPostgres && len(commentIndexes) > 0 {
-			// append comments for postgres only
-			for _, index := range commentIndexes {
-				sql += fmt.Sprintf("\nCOMMENT ON COLUMN %s%s%s.%s%s%s is '%s';",
-					Q,
-					mi.Table,
-					Q,
-					Q,
-					mi.Fields.FieldsDB[index].Column,
-					Q,
-					mi.Fields.FieldsDB[index].Description)
+			name := spec.Name
+			if name == "" {
+				name = mi.Table + "_" + strings.Join(names, "_")
 			}
+			index := dbIndex{}
+			index.Table = mi.Table
+			index.Name = name
+			index.SQL = fmt.Sprintf("CREATE INDEX %s%s%s ON %s%s%s (%s);", Q, name, Q, Q, mi.Table, Q, strings.Join(cols, ", "))
+
+			indexes = append(indexes, index)
 		}
-		queries = append(queries, sql)
 
-		if mi.Model != nil {
-			for _, names := range imodels.GetTableIndex(mi.AddrField) {
-				cols := make([]string, 0, len(names))
-				for _, name := range names {
-					if fi, ok := mi.Fields.GetByAny(name); ok && fi.DBcol {
-						cols = append(cols, fi.Column)
-					} else {
-						panic(fmt.Errorf("cannot found column `%s` when parse INDEX in `%s.TableIndex`", name, mi.FullName))
-					}
+		for _, names := range imodels.GetTableIndex(mi.AddrField) {
+			cols := make([]string, 0, len(names))
+			for _, name := range names {
+				if fi, ok := mi.Fields.GetByAny(name); ok && fi.DBcol {
+					cols = append(cols, fi.Column)
+				} else {
+					panic(fmt.Errorf("cannot found column `%s` when parse INDEX in `%s.TableIndex`", name, mi.FullName))
 				}
-				sqlIndexes = append(sqlIndexes, cols)
 			}
+			sqlIndexes = append(sqlIndexes, cols)
 		}
+	}
 
+	if al.IndexForeignKeys {
 		for _, names := range sqlIndexes {
-			name := mi.Table + "_" + strings.Join(names, "_")
-			cols := strings.Join(names, sep)
-			sql := fmt.Sprintf("CREATE INDEX %s%s%s ON %s%s%s (%s%s%s);", Q, name, Q, Q, mi.Table, Q, Q, cols, Q)
+			if len(names) > 0 {
+				leading[names[0]] = true
+			}
+		}
+		for _, fi := range mi.Fields.FieldsDB {
+			if fi.FieldType == RelForeignKey && !fi.Unique && !leading[fi.Column] {
+				sqlIndexes = append(sqlIndexes, []string{fi.Column})
+			}
+		}
+	}
 
-			index := dbIndex{}
-			index.Table = mi.Table
-			index.Name = name
-			index.SQL = sql
+	for _, names := range sqlIndexes {
+		name := mi.Table + "_" + strings.Join(names, "_")
+		cols := strings.Join(names, sep)
+		sql := fmt.Sprintf("CREATE INDEX %s%s%s ON %s%s%s (%s%s%s);", Q, name, Q, Q, mi.Table, Q, Q, cols, Q)
 
-			tableIndexes[mi.Table] = append(tableIndexes[mi.Table], index)
-		}
+		index := dbIndex{}
+		index.Table = mi.Table
+		index.Name = name
+		index.SQL = sql
 
+		indexes = append(indexes, index)
 	}
-
 	return
 }
@@ -15,7 +15,10 @@
 package orm
 
 import (
+	"context"
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/beego/beego/v2/client/orm/internal/models"
 
@@ -87,3 +90,290 @@ func TestGetDbCreateSQLWithComment(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDbCreateSQLWithComposite(t *testing.T) {
+	al := getDbAlias("default")
+	testModelCache := models.NewModelCacheHandler()
+	RegisterComposite(new(Money))
+	err := testModelCache.Register("", true, new(Product))
+	assert.NoError(t, err)
+
+	queries, _, err := getDbCreateSQL(testModelCache, al)
+	assert.NoError(t, err)
+	Q := al.DbBaser.TableQuote()
+	for _, column := range []string{"price_amount", "price_currency", "unit_cost_amount", "unit_cost_currency"} {
+		assert.Contains(t, queries[0], Q+column+Q)
+	}
+	assert.NotContains(t, queries[0], Q+"price"+Q)
+	if al.Driver == DRSqlite {
+		assert.Contains(t, queries[0], "`price_amount` integer NOT NULL DEFAULT 0 ")
+		assert.Contains(t, queries[0], "`price_currency` varchar(3) NOT NULL DEFAULT '' ")
+	}
+}
+
+type ModelWithIndexSpec struct {
+	ID       int    `orm:"column(id)"`
+	UserName string `orm:"size(30)"`
+	Score    int
+	Created  time.Time
+}
+
+func (m *ModelWithIndexSpec) TableIndexSpec() []IndexSpec {
+	return []IndexSpec{
+		{Name: "idx_recent", Columns: []IndexColumn{{Name: "UserName"}, {Name: "Created", Desc: true}}},
+		{Columns: []IndexColumn{{Name: "score", Desc: true}}},
+	}
+}
+
+func TestGetDbCreateSQLWithIndexSpec(t *testing.T) {
+	testModelCache := models.NewModelCacheHandler()
+	err := testModelCache.Register("", true, new(ModelWithIndexSpec))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		al      *alias
+		wantSQL []string
+	}{
+		{
+			name: "mysql",
+			al:   &alias{Driver: DRMySQL, DbBaser: newdbBaseMysql(), Engine: "INNODB"},
+			wantSQL: []string{
+				"CREATE INDEX `idx_recent` ON `model_with_index_spec` (`user_name`, `created` DESC);",
+				"CREATE INDEX `model_with_index_spec_score` ON `model_with_index_spec` (`score` DESC);",
+			},
+		},
+		{
+			name: "postgres",
+			al:   &alias{Driver: DRPostgres, DbBaser: newdbBasePostgres()},
+			wantSQL: []string{
+				`CREATE INDEX "idx_recent" ON "model_with_index_spec" ("user_name", "created" DESC);`,
+				`CREATE INDEX "model_with_index_spec_score" ON "model_with_index_spec" ("score" DESC);`,
+			},
+		},
+		{
+			name: "sqlite",
+			al:   &alias{Driver: DRSqlite, DbBaser: newdbBaseSqlite()},
+			wantSQL: []string{
+				"CREATE INDEX `idx_recent` ON `model_with_index_spec` (`user_name`, `created` DESC);",
+				"CREATE INDEX `model_with_index_spec_score` ON `model_with_index_spec` (`score` DESC);",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, indexes, err := getDbCreateSQL(testModelCache, tc.al)
+			assert.NoError(t, err)
+			sqls := make([]string, 0, len(indexes["model_with_index_spec"]))
+			for _, index := range indexes["model_with_index_spec"] {
+				sqls = append(sqls, index.SQL)
+			}
+			assert.Equal(t, tc.wantSQL, sqls)
+		})
+	}
+}
+
+func TestGenerateTableSQL(t *testing.T) {
+	testModelCache := models.NewModelCacheHandler()
+	err := testModelCache.Register("", true, new(ModelWithIndexSpec))
+	assert.NoError(t, err)
+	mi, ok := testModelCache.GetByMd(new(ModelWithIndexSpec))
+	assert.True(t, ok)
+
+	testCases := []struct {
+		name    string
+		al      *alias
+		wantSQL string
+	}{
+		{
+			name: "mysql",
+			al:   &alias{Driver: DRMySQL, DbBaser: newdbBaseMysql(), Engine: "INNODB"},
+			wantSQL: "CREATE TABLE IF NOT EXISTS `model_with_index_spec` (\n" +
+				"    `id` integer AUTO_INCREMENT NOT NULL PRIMARY KEY,\n" +
+				"    `user_name` varchar(30) NOT NULL DEFAULT '' ,\n" +
+				"    `score` integer NOT NULL DEFAULT 0 ,\n" +
+				"    `created` datetime NOT NULL\n" +
+				") ENGINE=INNODB;\n" +
+				"CREATE INDEX `idx_recent` ON `model_with_index_spec` (`user_name`, `created` DESC);\n" +
+				"CREATE INDEX `model_with_index_spec_score` ON `model_with_index_spec` (`score` DESC);",
+		},
+		{
+			name: "postgres",
+			al:   &alias{Driver: DRPostgres, DbBaser: newdbBasePostgres()},
+			wantSQL: "CREATE TABLE IF NOT EXISTS \"model_with_index_spec\" (\n" +
+				"    \"id\" bigserial NOT NULL PRIMARY KEY,\n" +
+				"    \"user_name\" varchar(30) NOT NULL DEFAULT '' ,\n" +
+				"    \"score\" integer NOT NULL DEFAULT 0 ,\n" +
+				"    \"created\" timestamp with time zone NOT NULL\n" +
+				");\n" +
+				`CREATE INDEX "idx_recent" ON "model_with_index_spec" ("user_name", "created" DESC);` + "\n" +
+				`CREATE INDEX "model_with_index_spec_score" ON "model_with_index_spec" ("score" DESC);`,
+		},
+		{
+			name: "sqlite",
+			al:   &alias{Driver: DRSqlite, DbBaser: newdbBaseSqlite()},
+			wantSQL: "CREATE TABLE IF NOT EXISTS `model_with_index_spec` (\n" +
+				"    `id` integer NOT NULL PRIMARY KEY AUTOINCREMENT,\n" +
+				"    `user_name` varchar(30) NOT NULL DEFAULT '' ,\n" +
+				"    `score` integer NOT NULL DEFAULT 0 ,\n" +
+				"    `created` datetime NOT NULL\n" +
+				");\n" +
+				"CREATE INDEX `idx_recent` ON `model_with_index_spec` (`user_name`, `created` DESC);\n" +
+				"CREATE INDEX `model_with_index_spec_score` ON `model_with_index_spec` (`score` DESC);",
+		},
+	}
+	header := "-- --------------------------------------------------\n" +
+		"--  Table Structure for `github.com/beego/beego/v2/client/orm.ModelWithIndexSpec`\n" +
+		"-- --------------------------------------------------\n"
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, header+tc.wantSQL, generateTableSQL(mi, tc.al))
+		})
+	}
+}
+
+func TestGenerateTableSQLWithTablePrefix(t *testing.T) {
+	testModelCache := models.NewModelCacheHandler()
+	testModelCache.SetTablePrefix("app1_")
+	err := testModelCache.Register("", true, new(ModelWithIndexSpec))
+	assert.NoError(t, err)
+	mi, ok := testModelCache.GetByMd(new(ModelWithIndexSpec))
+	assert.True(t, ok)
+
+	sql := generateTableSQL(mi, &alias{Driver: DRSqlite, DbBaser: newdbBaseSqlite()})
+	assert.Contains(t, sql, "CREATE TABLE IF NOT EXISTS `app1_model_with_index_spec` (")
+	assert.Contains(t, sql, "CREATE INDEX `idx_recent` ON `app1_model_with_index_spec` (`user_name`, `created` DESC);")
+}
+
+type ModelWithMigration struct {
+	ID    int    `orm:"column(id)"`
+	Name  string `orm:"size(30)"`
+	Score int    `orm:"index"`
+}
+
+func TestGetMigrationStatements(t *testing.T) {
+	testModelCache := models.NewModelCacheHandler()
+	err := testModelCache.Register("", true, new(ModelWithMigration))
+	assert.NoError(t, err)
+	testModelCache.Bootstrap()
+
+	db, err := sql.Open("sqlite3", t.TempDir()+"/migration.db")
+	assert.NoError(t, err)
+	al, err := newAliasWithDb("migration", "sqlite3", db)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	// the created table is dropped back
+	stmts, err := getMigrationStatements(ctx, testModelCache, al, false)
+	assert.NoError(t, err)
+	assert.Len(t, stmts, 2)
+	assert.Contains(t, stmts[0].Up, "CREATE TABLE IF NOT EXISTS `model_with_migration` (")
+	assert.Equal(t, "DROP TABLE `model_with_migration`;", stmts[0].Down)
+	assert.Equal(t, "CREATE INDEX `model_with_migration_score` ON `model_with_migration` (`score`);", stmts[1].Up)
+	assert.Equal(t, "DROP INDEX `model_with_migration_score`;", stmts[1].Down)
+
+	// the added column is dropped back
+	_, err = db.Exec("CREATE TABLE `model_with_migration` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `name` varchar(30) NOT NULL DEFAULT '')")
+	assert.NoError(t, err)
+	stmts, err = getMigrationStatements(ctx, testModelCache, al, false)
+	assert.NoError(t, err)
+	assert.Len(t, stmts, 2)
+	assert.Equal(t, "ALTER TABLE `model_with_migration` ADD COLUMN `score` integer NOT NULL  DEFAULT 0 ", stmts[0].Up)
+	assert.Equal(t, "ALTER TABLE `model_with_migration` DROP COLUMN `score`", stmts[0].Down)
+	assert.False(t, stmts[0].Irreversible)
+	assert.Equal(t, "DROP INDEX `model_with_migration_score`;", stmts[1].Down)
+
+	for _, stmt := range stmts {
+		_, err = db.Exec(stmt.Up)
+		assert.NoError(t, err)
+	}
+	stmts2, err := getMigrationStatements(ctx, testModelCache, al, false)
+	assert.NoError(t, err)
+	assert.Empty(t, stmts2)
+	for i := len(stmts) - 1; i >= 0; i-- {
+		_, err = db.Exec(stmts[i].Down)
+		assert.NoError(t, err)
+	}
+	columns, err := al.DbBaser.GetColumns(ctx, al.DB, "model_with_migration")
+	assert.NoError(t, err)
+	assert.NotContains(t, columns, "score")
+	assert.Contains(t, columns, "name")
+
+	// the dropped table cannot be reversed
+	stmts, err = getMigrationStatements(ctx, testModelCache, al, true)
+	assert.NoError(t, err)
+	assert.Len(t, stmts, 3)
+	assert.Equal(t, "DROP TABLE IF EXISTS `model_with_migration`", stmts[0].Up)
+	assert.True(t, stmts[0].Irreversible)
+	assert.Equal(t, "", stmts[0].Down)
+	assert.Equal(t, "DROP TABLE `model_with_migration`;", stmts[1].Down)
+
+	assert.Equal(t, "DROP INDEX `model_with_migration_score` ON `model_with_migration`;",
+		getIndexDropQuery(&alias{Driver: DRMySQL, DbBaser: newdbBaseMysql()}, dbIndex{Table: "model_with_migration", Name: "model_with_migration_score"}))
+}
+
+type ModelWithForeignKeys struct {
+	ID       int                   `orm:"column(id)"`
+	Owner    *ModelWithIndexSpec   `orm:"rel(fk)"`
+	Reviewer *ModelWithComments    `orm:"rel(fk)"`
+	Editor   *ModelWithoutComments `orm:"rel(fk);index"`
+	Profile  *ModelWithDBTypes     `orm:"rel(one)"`
+}
+
+func (m *ModelWithForeignKeys) TableIndex() [][]string {
+	return [][]string{
+		{"Reviewer", "ID"},
+	}
+}
+
+func TestGetDbCreateSQLWithForeignKeyIndexes(t *testing.T) {
+	testModelCache := models.NewModelCacheHandler()
+	err := testModelCache.Register("", true, new(ModelWithIndexSpec), new(ModelWithComments),
+		new(ModelWithoutComments), new(ModelWithDBTypes), new(ModelWithForeignKeys))
+	assert.NoError(t, err)
+	testModelCache.Bootstrap()
+
+	testCases := []struct {
+		name    string
+		al      *alias
+		wantSQL []string
+	}{
+		{
+			name: "postgres",
+			al:   &alias{Driver: DRPostgres, DbBaser: newdbBasePostgres(), IndexForeignKeys: true},
+			wantSQL: []string{
+				`CREATE INDEX "model_with_foreign_keys_editor_id" ON "model_with_foreign_keys" ("editor_id");`,
+				`CREATE INDEX "model_with_foreign_keys_reviewer_id_id" ON "model_with_foreign_keys" ("reviewer_id", "id");`,
+				`CREATE INDEX "model_with_foreign_keys_owner_id" ON "model_with_foreign_keys" ("owner_id");`,
+			},
+		},
+		{
+			name: "mysql",
+			al:   &alias{Driver: DRMySQL, DbBaser: newdbBaseMysql(), Engine: "INNODB", IndexForeignKeys: true},
+			wantSQL: []string{
+				"CREATE INDEX `model_with_foreign_keys_editor_id` ON `model_with_foreign_keys` (`editor_id`);",
+				"CREATE INDEX `model_with_foreign_keys_reviewer_id_id` ON `model_with_foreign_keys` (`reviewer_id`, `id`);",
+				"CREATE INDEX `model_with_foreign_keys_owner_id` ON `model_with_foreign_keys` (`owner_id`);",
+			},
+		},
+		{
+			name: "disabled",
+			al:   &alias{Driver: DRPostgres, DbBaser: newdbBasePostgres()},
+			wantSQL: []string{
+				`CREATE INDEX "model_with_foreign_keys_editor_id" ON "model_with_foreign_keys" ("editor_id");`,
+				`CREATE INDEX "model_with_foreign_keys_reviewer_id_id" ON "model_with_foreign_keys" ("reviewer_id", "id");`,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, indexes, err := getDbCreateSQL(testModelCache, tc.al)
+			assert.NoError(t, err)
+			sqls := make([]string, 0, len(indexes["model_with_foreign_keys"]))
+			for _, index := range indexes["model_with_foreign_keys"] {
+				sqls = append(sqls, index.SQL)
+			}
+			assert.Equal(t, tc.wantSQL, sqls)
+		})
+	}
+}
@@ -17,6 +17,7 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/beego/beego/v2/core/utils"
 )
@@ -29,6 +30,66 @@ var _ Ormer = new(DoNothingOrm)
 
 type DoNothingOrm struct{}
 
+func (d *DoNothingOrm) AdvisoryLock(ctx context.Context, key string, timeout time.Duration) (func() error, bool, error) {
+	return func() error { return nil }, true, nil
+}
+
+func (d *DoNothingOrm) UsingSchema(schema string) Ormer {
+	return d
+}
+
+func (d *DoNothingOrm) ReadOnly() Ormer {
+	return d
+}
+
+func (d *DoNothingOrm) HealthCheck(ctx context.Context) HealthReport {
+	return HealthReport{}
+}
+
+func (d *DoNothingOrm) Pipeline() *Pipeline {
+	return &Pipeline{o: d}
+}
+
+func (d *DoNothingOrm) PrepareUpsert(md interface{}, conflictCols ...string) (*UpsertStmt, error) {
+	return nil, nil
+}
+
+func (d *DoNothingOrm) PrepareUpsertWithCtx(ctx context.Context, md interface{}, conflictCols ...string) (*UpsertStmt, error) {
+	return nil, nil
+}
+
+func (d *DoNothingOrm) Refresh(md interface{}) error {
+	return nil
+}
+
+func (d *DoNothingOrm) RefreshWithCtx(ctx context.Context, md interface{}) error {
+	return nil
+}
+
+func (d *DoNothingOrm) Diff(incoming interface{}, keyCol string) (interface{}, interface{}, interface{}, error) {
+	return nil, nil, nil, nil
+}
+
+func (d *DoNothingOrm) DiffWithCtx(ctx context.Context, incoming interface{}, keyCol string) (interface{}, interface{}, interface{}, error) {
+	return nil, nil, nil, nil
+}
+
+func (d *DoNothingOrm) CompareAndSwap(md interface{}, expected, new map[string]interface{}, pks []interface{}) ([]interface{}, error) {
+	return nil, nil
+}
+
+func (d *DoNothingOrm) CompareAndSwapWithCtx(ctx context.Context, md interface{}, expected, new map[string]interface{}, pks []interface{}) ([]interface{}, error) {
+	return nil, nil
+}
+
+func (d *DoNothingOrm) NextSequence(name string) (int64, error) {
+	return 0, nil
+}
+
+func (d *DoNothingOrm) NextSequenceWithCtx(ctx context.Context, name string) (int64, error) {
+	return 0, nil
+}
+
 func (d *DoNothingOrm) Read(md interface{}, cols ...string) error {
 	return nil
 }
@@ -61,6 +122,14 @@ func (d *DoNothingOrm) LoadRelatedWithCtx(ctx context.Context, md interface{}, n
 	return 0, nil
 }
 
+func (d *DoNothingOrm) Preload(md interface{}, paths ...string) error {
+	return nil
+}
+
+func (d *DoNothingOrm) PreloadWithCtx(ctx context.Context, md interface{}, paths ...string) error {
+	return nil
+}
+
 func (d *DoNothingOrm) QueryM2M(md interface{}, name string) QueryM2Mer {
 	return nil
 }
@@ -107,6 +176,14 @@ func (d *DoNothingOrm) InsertMultiWithCtx(ctx context.Context, bulk int, mds int
 	return 0, nil
 }
 
+func (d *DoNothingOrm) InsertMultiReturning(bulk int, mds interface{}, container interface{}) error {
+	return nil
+}
+
+func (d *DoNothingOrm) InsertMultiReturningWithCtx(ctx context.Context, bulk int, mds interface{}, container interface{}) error {
+	return nil
+}
+
 func (d *DoNothingOrm) Update(md interface{}, cols ...string) (int64, error) {
 	return 0, nil
 }
@@ -167,6 +244,14 @@ func (d *DoNothingOrm) DoTxWithCtxAndOpts(ctx context.Context, opts *sql.TxOptio
 	return nil
 }
 
+func (d *DoNothingOrm) DoTxWithPropagation(ctx context.Context, propagation Propagation, task func(ctx context.Context, txOrm TxOrmer) error) error {
+	return nil
+}
+
+func (d *DoNothingOrm) DoTxWithTimeout(ctx context.Context, opts *TxOptions, task func(ctx context.Context, txOrm TxOrmer) error) error {
+	return nil
+}
+
 // DoNothingTxOrm is similar with DoNothingOrm, usually you use it to test
 type DoNothingTxOrm struct {
 	DoNothingOrm
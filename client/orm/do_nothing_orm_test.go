@@ -25,6 +25,9 @@ func TestDoNothingOrm(t *testing.T) {
 	err := o.DoTxWithCtxAndOpts(nil, nil, nil)
 	assert.Nil(t, err)
 
+	err = o.DoTxWithTimeout(nil, nil, nil)
+	assert.Nil(t, err)
+
 	err = o.DoTxWithCtx(nil, nil)
 	assert.Nil(t, err)
 
@@ -91,6 +94,12 @@ func TestDoNothingOrm(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, int64(0), i)
 
+	err = o.PreloadWithCtx(nil, nil, "")
+	assert.Nil(t, err)
+
+	err = o.Preload(nil, "")
+	assert.Nil(t, err)
+
 	assert.Nil(t, o.QueryTable(nil))
 
 	assert.Nil(t, o.Read(nil))
@@ -125,6 +134,7 @@ func TestDoNothingOrm(t *testing.T) {
 	assert.Equal(t, int64(0), i)
 
 	assert.Nil(t, o.DBStats())
+	assert.False(t, o.HealthCheck(nil).Healthy)
 
 	to := &DoNothingTxOrm{}
 	assert.Nil(t, to.Commit())
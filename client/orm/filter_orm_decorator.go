@@ -165,6 +165,44 @@ func (f *filterOrmDecorator) LoadRelatedWithCtx(ctx context.Context, md interfac
 	return res[0].(int64), f.convertError(res[1])
 }
 
+func (f *filterOrmDecorator) Preload(md interface{}, paths ...string) error {
+	return f.PreloadWithCtx(context.Background(), md, paths...)
+}
+
+func (f *filterOrmDecorator) PreloadWithCtx(ctx context.Context, md interface{}, paths ...string) error {
+	var (
+		emd interface{}
+		mi  *models.ModelInfo
+	)
+
+	sind := reflect.Indirect(reflect.ValueOf(md))
+
+	if sind.Kind() == reflect.Slice {
+		if sind.Len() > 0 {
+			emd = reflect.Indirect(sind.Index(0)).Interface()
+			mi, _ = defaultModelCache.GetByMd(emd)
+		}
+	} else {
+		emd = md
+		mi, _ = defaultModelCache.GetByMd(md)
+	}
+
+	inv := &Invocation{
+		Method:      "PreloadWithCtx",
+		Args:        []interface{}{md, paths},
+		Md:          emd,
+		mi:          mi,
+		InsideTx:    f.insideTx,
+		TxStartTime: f.txStartTime,
+		f: func(c context.Context) []interface{} {
+			err := f.ormer.PreloadWithCtx(c, md, paths...)
+			return []interface{}{err}
+		},
+	}
+	res := f.root(ctx, inv)
+	return f.convertError(res[0])
+}
+
 func (f *filterOrmDecorator) QueryM2M(md interface{}, name string) QueryM2Mer {
 	mi, _ := defaultModelCache.GetByMd(md)
 	inv := &Invocation{
@@ -334,6 +372,41 @@ func (f *filterOrmDecorator) InsertMultiWithCtx(ctx context.Context, bulk int, m
 	return res[0].(int64), f.convertError(res[1])
 }
 
+func (f *filterOrmDecorator) InsertMultiReturning(bulk int, mds interface{}, container interface{}) error {
+	return f.InsertMultiReturningWithCtx(context.Background(), bulk, mds, container)
+}
+
+// InsertMultiReturningWithCtx uses the first element's model info
+func (f *filterOrmDecorator) InsertMultiReturningWithCtx(ctx context.Context, bulk int, mds interface{}, container interface{}) error {
+	var (
+		md interface{}
+		mi *models.ModelInfo
+	)
+
+	sind := reflect.Indirect(reflect.ValueOf(mds))
+
+	if (sind.Kind() == reflect.Array || sind.Kind() == reflect.Slice) && sind.Len() > 0 {
+		ind := reflect.Indirect(sind.Index(0))
+		md = ind.Interface()
+		mi, _ = defaultModelCache.GetByMd(md)
+	}
+
+	inv := &Invocation{
+		Method:      "InsertMultiReturningWithCtx",
+		Args:        []interface{}{bulk, mds, container},
+		Md:          md,
+		mi:          mi,
+		InsideTx:    f.insideTx,
+		TxStartTime: f.txStartTime,
+		f: func(c context.Context) []interface{} {
+			err := f.ormer.InsertMultiReturningWithCtx(c, bulk, mds, container)
+			return []interface{}{err}
+		},
+	}
+	res := f.root(ctx, inv)
+	return f.convertError(res[0])
+}
+
 func (f *filterOrmDecorator) Update(md interface{}, cols ...string) (int64, error) {
 	return f.UpdateWithCtx(context.Background(), md, cols...)
 }
@@ -474,6 +547,213 @@ func (f *filterOrmDecorator) DoTxWithCtxAndOpts(ctx context.Context, opts *sql.T
 	return f.convertError(res[0])
 }
 
+func (f *filterOrmDecorator) DoTxWithPropagation(ctx context.Context, propagation Propagation, task func(ctx context.Context, txOrm TxOrmer) error) error {
+	inv := &Invocation{
+		Method:      "DoTxWithPropagation",
+		Args:        []interface{}{propagation, task},
+		InsideTx:    f.insideTx,
+		TxStartTime: f.txStartTime,
+		TxName:      getTxNameFromCtx(ctx),
+		f: func(c context.Context) []interface{} {
+			err := doTxWithPropagation(c, f, driverName(f.ormer.Driver()), propagation, task)
+			return []interface{}{err}
+		},
+	}
+	res := f.root(ctx, inv)
+	return f.convertError(res[0])
+}
+
+func (f *filterOrmDecorator) DoTxWithTimeout(ctx context.Context, opts *TxOptions, task func(ctx context.Context, txOrm TxOrmer) error) error {
+	inv := &Invocation{
+		Method:      "DoTxWithTimeout",
+		Args:        []interface{}{opts, task},
+		InsideTx:    f.insideTx,
+		TxStartTime: f.txStartTime,
+		TxName:      getTxNameFromCtx(ctx),
+		f: func(c context.Context) []interface{} {
+			err := doTxWithTimeout(c, f, opts, task)
+			return []interface{}{err}
+		},
+	}
+	res := f.root(ctx, inv)
+	return f.convertError(res[0])
+}
+
+func (f *filterOrmDecorator) Refresh(md interface{}) error {
+	return f.RefreshWithCtx(context.Background(), md)
+}
+
+func (f *filterOrmDecorator) RefreshWithCtx(ctx context.Context, md interface{}) error {
+	mi, _ := defaultModelCache.GetByMd(md)
+	inv := &Invocation{
+		Method:      "RefreshWithCtx",
+		Args:        []interface{}{md},
+		Md:          md,
+		mi:          mi,
+		InsideTx:    f.insideTx,
+		TxStartTime: f.txStartTime,
+		f: func(c context.Context) []interface{} {
+			err := f.TxBeginner.(Ormer).RefreshWithCtx(c, md)
+			return []interface{}{err}
+		},
+	}
+	res := f.root(ctx, inv)
+	return f.convertError(res[0])
+}
+
+func (f *filterOrmDecorator) Diff(incoming interface{}, keyCol string) (interface{}, interface{}, interface{}, error) {
+	return f.DiffWithCtx(context.Background(), incoming, keyCol)
+}
+
+func (f *filterOrmDecorator) DiffWithCtx(ctx context.Context, incoming interface{}, keyCol string) (interface{}, interface{}, interface{}, error) {
+	var (
+		md interface{}
+		mi *models.ModelInfo
+	)
+
+	if typ := reflect.Indirect(reflect.ValueOf(incoming)).Type(); typ.Kind() == reflect.Slice {
+		md = reflect.New(utils2.IndirectType(typ.Elem())).Interface()
+		mi, _ = defaultModelCache.GetByMd(md)
+	}
+
+	inv := &Invocation{
+		Method:      "DiffWithCtx",
+		Args:        []interface{}{incoming, keyCol},
+		Md:          md,
+		mi:          mi,
+		InsideTx:    f.insideTx,
+		TxStartTime: f.txStartTime,
+		f: func(c context.Context) []interface{} {
+			toInsert, toUpdate, toDelete, err := f.TxBeginner.(Ormer).DiffWithCtx(c, incoming, keyCol)
+			return []interface{}{toInsert, toUpdate, toDelete, err}
+		},
+	}
+	res := f.root(ctx, inv)
+	return res[0], res[1], res[2], f.convertError(res[3])
+}
+
+func (f *filterOrmDecorator) CompareAndSwap(md interface{}, expected, new map[string]interface{}, pks []interface{}) ([]interface{}, error) {
+	return f.CompareAndSwapWithCtx(context.Background(), md, expected, new, pks)
+}
+
+func (f *filterOrmDecorator) CompareAndSwapWithCtx(ctx context.Context, md interface{}, expected, new map[string]interface{}, pks []interface{}) ([]interface{}, error) {
+	mi, _ := defaultModelCache.GetByMd(md)
+	inv := &Invocation{
+		Method:      "CompareAndSwapWithCtx",
+		Args:        []interface{}{expected, new, pks},
+		Md:          md,
+		mi:          mi,
+		InsideTx:    f.insideTx,
+		TxStartTime: f.txStartTime,
+		f: func(c context.Context) []interface{} {
+			swapped, err := f.TxBeginner.(Ormer).CompareAndSwapWithCtx(c, md, expected, new, pks)
+			return []interface{}{swapped, err}
+		},
+	}
+	res := f.root(ctx, inv)
+	swapped, _ := res[0].([]interface{})
+	return swapped, f.convertError(res[1])
+}
+
+func (f *filterOrmDecorator) NextSequence(name string) (int64, error) {
+	return f.NextSequenceWithCtx(context.Background(), name)
+}
+
+func (f *filterOrmDecorator) NextSequenceWithCtx(ctx context.Context, name string) (int64, error) {
+	inv := &Invocation{
+		Method:      "NextSequenceWithCtx",
+		Args:        []interface{}{name},
+		InsideTx:    f.insideTx,
+		TxStartTime: f.txStartTime,
+		f: func(c context.Context) []interface{} {
+			res, err := f.ormer.NextSequenceWithCtx(c, name)
+			return []interface{}{res, err}
+		},
+	}
+	res := f.root(ctx, inv)
+	return res[0].(int64), f.convertError(res[1])
+}
+
+// UsingSchema keeps the filters on the Ormer of schema.
+func (f *filterOrmDecorator) UsingSchema(schema string) Ormer {
+	o := f.TxBeginner.(Ormer).UsingSchema(schema)
+	return &filterOrmDecorator{
+		ormer:      o,
+		TxBeginner: o,
+		root:       f.root,
+	}
+}
+
+// ReadOnly keeps the filters on the read-only Ormer.
+func (f *filterOrmDecorator) ReadOnly() Ormer {
+	o := f.TxBeginner.(Ormer).ReadOnly()
+	return &filterOrmDecorator{
+		ormer:      o,
+		TxBeginner: o,
+		root:       f.root,
+	}
+}
+
+func (f *filterOrmDecorator) PrepareUpsert(md interface{}, conflictCols ...string) (*UpsertStmt, error) {
+	return f.PrepareUpsertWithCtx(context.Background(), md, conflictCols...)
+}
+
+func (f *filterOrmDecorator) PrepareUpsertWithCtx(ctx context.Context, md interface{}, conflictCols ...string) (*UpsertStmt, error) {
+	mi, _ := defaultModelCache.GetByMd(md)
+	inv := &Invocation{
+		Method:      "PrepareUpsertWithCtx",
+		Args:        []interface{}{md, conflictCols},
+		Md:          md,
+		mi:          mi,
+		InsideTx:    f.insideTx,
+		TxStartTime: f.txStartTime,
+		f: func(c context.Context) []interface{} {
+			stmt, err := f.TxBeginner.(Ormer).PrepareUpsertWithCtx(c, md, conflictCols...)
+			return []interface{}{stmt, err}
+		},
+	}
+	res := f.root(ctx, inv)
+	stmt, _ := res[0].(*UpsertStmt)
+	return stmt, f.convertError(res[1])
+}
+
+// Pipeline runs the queries with the filters.
+func (f *filterOrmDecorator) HealthCheck(ctx context.Context) HealthReport {
+	inv := &Invocation{
+		Method:      "HealthCheck",
+		InsideTx:    f.insideTx,
+		TxStartTime: f.txStartTime,
+		f: func(c context.Context) []interface{} {
+			res := f.TxBeginner.(Ormer).HealthCheck(c)
+			return []interface{}{res}
+		},
+	}
+	res := f.root(ctx, inv)
+	return res[0].(HealthReport)
+}
+
+func (f *filterOrmDecorator) Pipeline() *Pipeline {
+	p := f.TxBeginner.(Ormer).Pipeline()
+	p.o = f
+	return p
+}
+
+func (f *filterOrmDecorator) AdvisoryLock(ctx context.Context, key string, timeout time.Duration) (func() error, bool, error) {
+	inv := &Invocation{
+		Method:      "AdvisoryLock",
+		Args:        []interface{}{key, timeout},
+		InsideTx:    f.insideTx,
+		TxStartTime: f.txStartTime,
+		f: func(c context.Context) []interface{} {
+			unlock, acquired, err := f.TxBeginner.(AdvisoryLocker).AdvisoryLock(c, key, timeout)
+			return []interface{}{unlock, acquired, err}
+		},
+	}
+	res := f.root(ctx, inv)
+	unlock, _ := res[0].(func() error)
+	return unlock, res[1].(bool), f.convertError(res[2])
+}
+
 func (f *filterOrmDecorator) Commit() error {
 	inv := &Invocation{
 		Method:      "Commit",
@@ -522,6 +802,22 @@ func (f *filterOrmDecorator) RollbackUnlessCommit() error {
 	return f.convertError(res[0])
 }
 
+func (f *filterOrmDecorator) WithReadCache() TxOrmer {
+	delegate := f.ormer.(TxOrmer).WithReadCache()
+	res := *f
+	res.ormer = delegate
+	res.TxCommitter = delegate
+	return &res
+}
+
+func (f *filterOrmDecorator) OnCommit(fn func()) {
+	f.ormer.(TxOrmer).OnCommit(fn)
+}
+
+func (f *filterOrmDecorator) OnRollback(fn func()) {
+	f.ormer.(TxOrmer).OnRollback(fn)
+}
+
 func (*filterOrmDecorator) convertError(v interface{}) error {
 	if v == nil {
 		return nil
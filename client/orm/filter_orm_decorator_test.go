@@ -20,6 +20,7 @@ import (
 	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -93,6 +94,79 @@ func TestFilterOrmDecoratorBeginTx(t *testing.T) {
 	err = to.Rollback()
 	assert.NotNil(t, err)
 	assert.Equal(t, "rollback", err.Error())
+
+	// the filters and tx name are kept
+	cached := to.WithReadCache()
+	_, ok := cached.(*filterOrmDecorator).TxCommitter.(*filterMockOrm)
+	assert.True(t, ok)
+	err = cached.Rollback()
+	assert.NotNil(t, err)
+	assert.Equal(t, "rollback", err.Error())
+}
+
+func TestFilterOrmDecoratorUsingSchema(t *testing.T) {
+	o := &filterMockOrm{}
+	called := false
+	od := NewFilterOrmDecorator(o, func(next Filter) Filter {
+		return func(ctx context.Context, inv *Invocation) []interface{} {
+			assert.Equal(t, "AdvisoryLock", inv.Method)
+			called = true
+			return next(ctx, inv)
+		}
+	})
+	so := od.UsingSchema("tenant_1")
+	_, _, _ = so.AdvisoryLock(context.Background(), "cron", time.Second)
+	assert.True(t, called)
+}
+
+func TestFilterOrmDecoratorReadOnly(t *testing.T) {
+	o := &filterMockOrm{}
+	called := false
+	od := NewFilterOrmDecorator(o, func(next Filter) Filter {
+		return func(ctx context.Context, inv *Invocation) []interface{} {
+			assert.Equal(t, "AdvisoryLock", inv.Method)
+			called = true
+			return next(ctx, inv)
+		}
+	})
+	ro := od.ReadOnly()
+	_, _, _ = ro.AdvisoryLock(context.Background(), "cron", time.Second)
+	assert.True(t, called)
+}
+
+func TestFilterOrmDecoratorPipeline(t *testing.T) {
+	o := &filterMockOrm{}
+	called := false
+	od := NewFilterOrmDecorator(o, func(next Filter) Filter {
+		return func(ctx context.Context, inv *Invocation) []interface{} {
+			assert.Equal(t, "AdvisoryLock", inv.Method)
+			called = true
+			return next(ctx, inv)
+		}
+	})
+	results := od.Pipeline().Add(func(ctx context.Context, o Ormer) (interface{}, error) {
+		_, acquired, err := o.AdvisoryLock(ctx, "cron", time.Second)
+		return acquired, err
+	}).Exec(context.Background())
+	assert.Equal(t, 1, len(results))
+	assert.True(t, called)
+}
+
+func TestFilterOrmDecoratorAdvisoryLock(t *testing.T) {
+	o := &filterMockOrm{}
+	od := NewFilterOrmDecorator(o, func(next Filter) Filter {
+		return func(ctx context.Context, inv *Invocation) []interface{} {
+			assert.Equal(t, "AdvisoryLock", inv.Method)
+			assert.Equal(t, 2, len(inv.Args))
+			assert.Equal(t, "cron", inv.Args[0])
+			assert.False(t, inv.InsideTx)
+			return next(ctx, inv)
+		}
+	})
+	unlock, acquired, err := od.AdvisoryLock(context.Background(), "cron", time.Second)
+	assert.Nil(t, unlock)
+	assert.False(t, acquired)
+	assert.Equal(t, "advisory lock error", err.Error())
 }
 
 func TestFilterOrmDecoratorDBStats(t *testing.T) {
@@ -110,6 +184,19 @@ func TestFilterOrmDecoratorDBStats(t *testing.T) {
 	assert.Equal(t, -1, res.MaxOpenConnections)
 }
 
+func TestFilterOrmDecoratorHealthCheck(t *testing.T) {
+	o := &filterMockOrm{}
+	od := NewFilterOrmDecorator(o, func(next Filter) Filter {
+		return func(ctx context.Context, inv *Invocation) []interface{} {
+			assert.Equal(t, "HealthCheck", inv.Method)
+			assert.Equal(t, 0, len(inv.Args))
+			return next(ctx, inv)
+		}
+	})
+	res := od.HealthCheck(context.Background())
+	assert.False(t, res.Healthy)
+}
+
 func TestFilterOrmDecoratorDelete(t *testing.T) {
 	register()
 	o := &filterMockOrm{}
@@ -174,6 +261,51 @@ func TestFilterOrmDecoratorDoTx(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestFilterOrmDecoratorDoTxWithPropagation(t *testing.T) {
+	o := &filterMockOrm{}
+	od := NewFilterOrmDecorator(o, func(next Filter) Filter {
+		return func(ctx context.Context, inv *Invocation) []interface{} {
+			if inv.Method == "DoTxWithPropagation" {
+				assert.Equal(t, 2, len(inv.Args))
+				assert.Equal(t, PropagationNested, inv.Args[0])
+				assert.Equal(t, "do tx name", inv.TxName)
+				assert.False(t, inv.InsideTx)
+			}
+			return next(ctx, inv)
+		}
+	})
+
+	ctx := context.WithValue(context.Background(), TxNameKey, "do tx name")
+	err := od.DoTxWithPropagation(ctx, PropagationNested, func(c context.Context, txOrm TxOrmer) error {
+		return nil
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, "begin tx", err.Error())
+}
+
+func TestFilterOrmDecoratorDoTxWithTimeout(t *testing.T) {
+	o := &filterMockOrm{}
+	opts := &TxOptions{MaxDuration: time.Second}
+	od := NewFilterOrmDecorator(o, func(next Filter) Filter {
+		return func(ctx context.Context, inv *Invocation) []interface{} {
+			if inv.Method == "DoTxWithTimeout" {
+				assert.Equal(t, 2, len(inv.Args))
+				assert.Equal(t, opts, inv.Args[0])
+				assert.Equal(t, "do tx name", inv.TxName)
+				assert.False(t, inv.InsideTx)
+			}
+			return next(ctx, inv)
+		}
+	})
+
+	ctx := context.WithValue(context.Background(), TxNameKey, "do tx name")
+	err := od.DoTxWithTimeout(ctx, opts, func(c context.Context, txOrm TxOrmer) error {
+		return nil
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, "begin tx", err.Error())
+}
+
 func TestFilterOrmDecoratorDriver(t *testing.T) {
 	o := &filterMockOrm{}
 	od := NewFilterOrmDecorator(o, func(next Filter) Filter {
@@ -228,6 +360,26 @@ func TestFilterOrmDecoratorInsertMulti(t *testing.T) {
 	assert.Equal(t, int64(2), i)
 }
 
+func TestFilterOrmDecoratorInsertMultiReturning(t *testing.T) {
+	register()
+	o := &filterMockOrm{}
+	od := NewFilterOrmDecorator(o, func(next Filter) Filter {
+		return func(ctx context.Context, inv *Invocation) []interface{} {
+			assert.Equal(t, "InsertMultiReturningWithCtx", inv.Method)
+			assert.Equal(t, 3, len(inv.Args))
+			assert.Equal(t, "FILTER_TEST", inv.GetTableName())
+			assert.False(t, inv.InsideTx)
+			return next(ctx, inv)
+		}
+	})
+
+	bulk := []*FilterTestEntity{{}, {}}
+	var inserted []*FilterTestEntity
+	err := od.InsertMultiReturning(2, bulk, &inserted)
+	assert.NotNil(t, err)
+	assert.Equal(t, "insert multi returning error", err.Error())
+}
+
 func TestFilterOrmDecoratorInsertOrUpdate(t *testing.T) {
 	register()
 	o := &filterMockOrm{}
@@ -263,6 +415,22 @@ func TestFilterOrmDecoratorLoadRelated(t *testing.T) {
 	assert.Equal(t, int64(99), i)
 }
 
+func TestFilterOrmDecoratorPreload(t *testing.T) {
+	o := &filterMockOrm{}
+	od := NewFilterOrmDecorator(o, func(next Filter) Filter {
+		return func(ctx context.Context, inv *Invocation) []interface{} {
+			assert.Equal(t, "PreloadWithCtx", inv.Method)
+			assert.Equal(t, 2, len(inv.Args))
+			assert.Equal(t, "FILTER_TEST", inv.GetTableName())
+			assert.False(t, inv.InsideTx)
+			return next(ctx, inv)
+		}
+	})
+	err := od.Preload([]*FilterTestEntity{{}}, "hello")
+	assert.NotNil(t, err)
+	assert.Equal(t, "preload error", err.Error())
+}
+
 func TestFilterOrmDecoratorQueryM2M(t *testing.T) {
 	o := &filterMockOrm{}
 	od := NewFilterOrmDecorator(o, func(next Filter) Filter {
@@ -357,6 +525,10 @@ func (f *filterMockOrm) ReadOrCreateWithCtx(ctx context.Context, md interface{},
 	return true, 13, errors.New("read or create error")
 }
 
+func (f *filterMockOrm) AdvisoryLock(ctx context.Context, key string, timeout time.Duration) (func() error, bool, error) {
+	return nil, false, errors.New("advisory lock error")
+}
+
 func (f *filterMockOrm) ReadForUpdateWithCtx(ctx context.Context, md interface{}, cols ...string) error {
 	return errors.New("read for update error")
 }
@@ -365,6 +537,10 @@ func (f *filterMockOrm) LoadRelatedWithCtx(ctx context.Context, md interface{},
 	return 99, errors.New("load related error")
 }
 
+func (f *filterMockOrm) PreloadWithCtx(ctx context.Context, md interface{}, paths ...string) error {
+	return errors.New("preload error")
+}
+
 func (f *filterMockOrm) InsertOrUpdateWithCtx(ctx context.Context, md interface{}, colConflitAndArgs ...string) (int64, error) {
 	return 1, errors.New("insert or update error")
 }
@@ -373,6 +549,10 @@ func (f *filterMockOrm) InsertMultiWithCtx(ctx context.Context, bulk int, mds in
 	return 2, errors.New("insert multi error")
 }
 
+func (f *filterMockOrm) InsertMultiReturningWithCtx(ctx context.Context, bulk int, mds interface{}, container interface{}) error {
+	return errors.New("insert multi returning error")
+}
+
 func (f *filterMockOrm) InsertWithCtx(ctx context.Context, md interface{}) (int64, error) {
 	return 100, errors.New("insert error")
 }
@@ -405,6 +585,14 @@ func (f *filterMockOrm) RollbackUnlessCommit() error {
 	return errors.New("rollback unless commit")
 }
 
+func (f *filterMockOrm) WithReadCache() TxOrmer {
+	return &filterMockOrm{}
+}
+
+func (f *filterMockOrm) OnCommit(func()) {}
+
+func (f *filterMockOrm) OnRollback(func()) {}
+
 func (f *filterMockOrm) DBStats() *sql.DBStats {
 	return &sql.DBStats{
 		MaxOpenConnections: -1,
@@ -28,6 +28,7 @@ const (
 	KeyOffset
 	KeyOrderBy
 	KeyRelDepth
+	KeyIncludeZeroPtr
 )
 
 type Hint struct {
@@ -94,6 +95,11 @@ func OrderBy(s string) *Hint {
 	return NewHint(KeyOrderBy, s)
 }
 
+// IncludeZeroPtr return a hint about including non-nil pointer fields holding zero values
+func IncludeZeroPtr() *Hint {
+	return NewHint(KeyIncludeZeroPtr, true)
+}
+
 // NewHint return a hint
 func NewHint(key interface{}, value interface{}) *Hint {
 	return &Hint{
@@ -15,6 +15,7 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"runtime/debug"
@@ -29,6 +30,7 @@ type ModelCache struct {
 	cache           map[string]*ModelInfo
 	cacheByFullName map[string]*ModelInfo
 	done            bool
+	tablePrefix     string
 }
 
 // NewModelCacheHandler generator of ModelCache
@@ -313,69 +315,95 @@ end:
 	mc.done = true
 }
 
+// SetTablePrefix set the prefix of the tables of the models registered after it,
+// before the prefix or suffix of Register, except the models whose TableNoPrefix is true.
+func (mc *ModelCache) SetTablePrefix(prefix string) {
+	mc.tablePrefix = prefix
+}
+
 // Register Register models to model cache
 func (mc *ModelCache) Register(prefixOrSuffixStr string, prefixOrSuffix bool, models ...interface{}) (err error) {
+	prefix, suffix := prefixOrSuffixStr, ""
+	if !prefixOrSuffix {
+		prefix, suffix = "", prefixOrSuffixStr
+	}
 	for _, model := range models {
-		val := reflect.ValueOf(model)
-		typ := reflect.Indirect(val).Type()
-
-		if val.Kind() != reflect.Ptr {
-			err = fmt.Errorf("<orm.RegisterModel> cannot use non-ptr model struct `%s`", GetFullName(typ))
+		var exist bool
+		if exist, err = mc.register(prefix, suffix, model); err != nil || exist {
 			return
 		}
-		// For this case:
-		// u := &User{}
-		// registerModel(&u)
-		if typ.Kind() == reflect.Ptr {
-			err = fmt.Errorf("<orm.RegisterModel> only allow ptr model struct, it looks you use two reference to the struct `%s`", typ)
-			return
-		}
-		if val.Elem().Kind() == reflect.Slice {
-			val = reflect.New(val.Elem().Type().Elem())
-		}
-		table := GetTableName(val)
+	}
+	return
+}
 
-		if prefixOrSuffixStr != "" {
-			if prefixOrSuffix {
-				table = prefixOrSuffixStr + table
-			} else {
-				table = table + prefixOrSuffixStr
-			}
+// RegisterBatch register all the models with the prefix and the suffix of their tables,
+// the errors of the models failed are joined, the others are registered.
+func (mc *ModelCache) RegisterBatch(prefix string, suffix string, models ...interface{}) error {
+	var errs []error
+	for _, model := range models {
+		if _, err := mc.register(prefix, suffix, model); err != nil {
+			errs = append(errs, err)
 		}
+	}
+	return errors.Join(errs...)
+}
 
-		// models's fullname is pkgpath + struct name
-		name := GetFullName(typ)
-		if _, ok := mc.GetByFullName(name); ok {
-			err = fmt.Errorf("<orm.RegisterModel> model `%s` repeat Register, must be unique\n", name)
-			return
-		}
+// register the model with the prefix and the suffix of its table, exist is true if the table is registered already.
+func (mc *ModelCache) register(prefix string, suffix string, model interface{}) (exist bool, err error) {
+	val := reflect.ValueOf(model)
+	typ := reflect.Indirect(val).Type()
 
-		if _, ok := mc.Get(table); ok {
-			return nil
-		}
+	if val.Kind() != reflect.Ptr {
+		err = fmt.Errorf("<orm.RegisterModel> cannot use non-ptr model struct `%s`", GetFullName(typ))
+		return
+	}
+	// For this case:
+	// u := &User{}
+	// registerModel(&u)
+	if typ.Kind() == reflect.Ptr {
+		err = fmt.Errorf("<orm.RegisterModel> only allow ptr model struct, it looks you use two reference to the struct `%s`", typ)
+		return
+	}
+	if val.Elem().Kind() == reflect.Slice {
+		val = reflect.New(val.Elem().Type().Elem())
+	}
+	table := prefix + GetTableName(val) + suffix
+	if mc.tablePrefix != "" && !GetTableNoPrefix(val) {
+		table = mc.tablePrefix + table
+	}
 
-		mi := NewModelInfo(val)
-		if mi.Fields.Pk == nil {
-		outFor:
-			for _, fi := range mi.Fields.FieldsDB {
-				if strings.ToLower(fi.Name) == "id" {
-					switch fi.AddrValue.Elem().Kind() {
-					case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
-						fi.Auto = true
-						fi.Pk = true
-						mi.Fields.Pk = fi
-						break outFor
-					}
+	// models's fullname is pkgpath + struct name
+	name := GetFullName(typ)
+	if _, ok := mc.GetByFullName(name); ok {
+		err = fmt.Errorf("<orm.RegisterModel> model `%s` repeat Register, must be unique\n", name)
+		return
+	}
+
+	if _, ok := mc.Get(table); ok {
+		return true, nil
+	}
+
+	mi := NewModelInfo(val)
+	if mi.Fields.Pk == nil {
+	outFor:
+		for _, fi := range mi.Fields.FieldsDB {
+			if strings.ToLower(fi.Name) == "id" {
+				switch fi.AddrValue.Elem().Kind() {
+				case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+					fi.Auto = true
+					fi.Pk = true
+					mi.Fields.Pk = fi
+					break outFor
 				}
 			}
 		}
+	}
 
-		mi.Table = table
-		mi.Pkg = typ.PkgPath()
-		mi.Model = model
-		mi.Manual = true
+	mi.Table = table
+	mi.Pkg = typ.PkgPath()
+	mi.Model = model
+	mi.Manual = true
 
-		mc.Set(table, mi)
-	}
+	mc.Set(table, mi)
 	return
 }
@@ -43,6 +43,7 @@ const (
 	TypeDecimalField
 	TypeJSONField
 	TypeJsonbField
+	TypeBinaryField
 	RelForeignKey
 	RelOneToOne
 	RelManyToMany
@@ -54,7 +55,7 @@ const (
 const (
 	IsIntegerField         = ^-TypePositiveBigIntegerField >> 6 << 7
 	IsPositiveIntegerField = ^-TypePositiveBigIntegerField >> 10 << 11
-	IsRelField             = ^-RelReverseMany >> 18 << 19
+	IsRelField             = ^-RelReverseMany >> 19 << 20
 	IsFieldType            = ^-RelReverseMany<<1 + 1
 )
 
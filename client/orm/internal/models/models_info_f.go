@@ -17,6 +17,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 
@@ -112,11 +113,13 @@ type FieldInfo struct {
 	Unique              bool
 	ColDefault          bool // whether has default tag
 	ToText              bool
+	EmptyAsNull         bool // the empty string is written as NULL, and the filters of it match NULL too
 	AutoNow             bool
 	AutoNowAdd          bool
 	Rel                 bool // if type equal to RelForeignKey, RelOneToOne, RelManyToMany then true
 	Reverse             bool
 	IsFielder           bool // implement Fielder interface
+	IsBigRat            bool // big.Rat or *big.Rat decimal, keeps the exact value
 	Mi                  *ModelInfo
 	FieldIndex          []int
 	FieldType           int
@@ -141,6 +144,8 @@ type FieldInfo struct {
 	Description         string
 	TimePrecision       *int
 	DBType              string
+	SetValues           []string // the members of the set(...) tag, a SET column on mysql
+	TimeFormat          string   // the layout of the time_format(...) tag, the time is stored as a string of it
 }
 
 // NewFieldInfo new field info
@@ -249,6 +254,7 @@ checkType:
 		if err != nil {
 			goto end
 		}
+		fi.IsBigRat = addrField.Type() == reflect.TypeOf(new(big.Rat))
 		if fieldType == TypeVarCharField {
 			switch tags["type"] {
 			case "char":
@@ -306,12 +312,34 @@ checkType:
 	fi.FullName = mi.FullName + mName + "." + sf.Name
 
 	fi.Description = tags["description"]
-	fi.Null = attrs["null"]
+	fi.Null = attrs["null"] || IsSQLNullType(field.Type())
 	fi.Index = attrs["index"]
 	fi.Auto = attrs["auto"]
 	fi.DBType = tags["db_type"]
+	if v := tags["set"]; v != "" {
+		if fieldType != TypeVarCharField && fieldType != TypeCharField {
+			err = fmt.Errorf("set(...) only supports the string fields")
+			goto end
+		}
+		for _, member := range strings.Split(v, ",") {
+			fi.SetValues = append(fi.SetValues, strings.TrimSpace(member))
+		}
+	}
+	if tags["time_format"] != "" && fieldType != TypeTimeField && fieldType != TypeDateField && fieldType != TypeDateTimeField {
+		err = fmt.Errorf("time_format(...) only supports the time fields")
+		goto end
+	}
 	fi.Pk = attrs["pk"]
 	fi.Unique = attrs["unique"]
+	fi.EmptyAsNull = attrs["empty_as_null"]
+	if fi.EmptyAsNull {
+		if fieldType != TypeVarCharField && fieldType != TypeCharField && fieldType != TypeTextField {
+			err = fmt.Errorf("empty_as_null only supports the string fields")
+			goto end
+		}
+		// the empty strings are NULL in the column
+		fi.Null = true
+	}
 
 	// Mark object property if there is attribute "default" in the orm configuration
 	if _, ok := tags["default"]; ok {
@@ -381,6 +409,21 @@ checkType:
 	case TypeTextField:
 		fi.Index = false
 		fi.Unique = false
+	case TypeBinaryField:
+		// sized is a fixed length binary, can be a pk or unique, unsized is a blob
+		if size != "" {
+			v, e := utils.StrTo(size).Int32()
+			if e != nil {
+				err = fmt.Errorf("wrong size value `%s`", size)
+			} else {
+				fi.Size = int(v)
+			}
+		} else if fi.Pk {
+			err = fmt.Errorf("binary primary key needs a size(...)")
+		} else {
+			fi.Index = false
+			fi.Unique = false
+		}
 	case TypeTimeField, TypeDateField, TypeDateTimeField:
 		if fieldType == TypeDateTimeField {
 			if precision != "" {
@@ -393,6 +436,20 @@ checkType:
 			}
 		}
 
+		// the formatted time is a string column
+		if v := tags["time_format"]; v != "" {
+			fi.TimeFormat = v
+			fi.Size = 255
+			if size != "" {
+				v, e := utils.StrTo(size).Int32()
+				if e != nil {
+					err = fmt.Errorf("wrong size value `%s`", size)
+				} else {
+					fi.Size = int(v)
+				}
+			}
+		}
+
 		if attrs["auto_now"] {
 			fi.AutoNow = true
 		} else if attrs["auto_now_add"] {
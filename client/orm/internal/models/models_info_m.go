@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"sync"
 )
 
 // ModelInfo single model info
@@ -32,6 +33,39 @@ type ModelInfo struct {
 	Fields    *Fields
 	AddrField reflect.Value // store the original struct value
 	Uniques   []string
+	// fields loading a relation lazily, declared by the lazy tag
+	LazyFields []*LazyField
+	// polymorphic relations by name, declared by the poly tag
+	Polymorphics map[string]*Polymorphic
+	// fields read by a sql expression, declared by the computed tag
+	ComputedFields []*ComputedField
+	// the order expressions returned by the DefaultOrderBy method of the model
+	DefaultOrderBy []string
+}
+
+// Polymorphic is a relation declared by poly(Name) on a string and an integer field,
+// which store the table name and the pk of the related model of any type.
+type Polymorphic struct {
+	Name      string
+	TypeField *FieldInfo
+	IDField   *FieldInfo
+}
+
+// LazyField is a field declared by lazy(RelName), which is not a db column.
+type LazyField struct {
+	Name       string
+	RelName    string
+	FieldIndex []int
+}
+
+// ComputedField is a field declared by computed(Expr), which is not a db column
+// but read by the sql expression Expr of the columns of the model, such as
+// `orm:"-;computed(first_name || ' ' || last_name)"`. It is not written, and read by Read, One, All and AllInto
+// of all the columns, the top level || of Expr are concatenated by the dialect. Expr cannot have ";".
+type ComputedField struct {
+	Name       string
+	Expr       string
+	FieldIndex []int
 }
 
 // NewModelInfo new model info
@@ -43,11 +77,47 @@ func NewModelInfo(val reflect.Value) (mi *ModelInfo) {
 	mi.Name = ind.Type().Name()
 	mi.FullName = GetFullName(ind.Type())
 	AddModelFields(mi, ind, "", []int{})
+	mi.DefaultOrderBy = GetDefaultOrderBy(val)
+	for _, poly := range mi.Polymorphics {
+		if poly.TypeField == nil || poly.IDField == nil {
+			fmt.Println(fmt.Errorf("model: %s, polymorphic relation `%s` needs a string and an integer field", ind.Type(), poly.Name))
+			os.Exit(2)
+		}
+	}
 	return
 }
 
+// the struct types whose fields are split into columns of the models using them.
+var compositeTypes sync.Map
+
+// RegisterCompositeType register the struct type typ as composite,
+// a field of a model with this type maps every field of typ to a column prefixed by the field column.
+func RegisterCompositeType(typ reflect.Type) error {
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("composite type must be a struct, got `%s`", typ)
+	}
+	compositeTypes.Store(typ, true)
+	return nil
+}
+
+// IsCompositeType check whether typ is registered by RegisterCompositeType
+func IsCompositeType(typ reflect.Type) bool {
+	_, ok := compositeTypes.Load(typ)
+	return ok
+}
+
+// the prefix of the names and columns of the fields of a composite field.
+type compositePrefix struct {
+	name   string
+	column string
+}
+
 // AddModelFields index: FieldByIndex returns the nested field corresponding to index
 func AddModelFields(mi *ModelInfo, ind reflect.Value, mName string, index []int) {
+	addModelFields(mi, ind, mName, index, nil)
+}
+
+func addModelFields(mi *ModelInfo, ind reflect.Value, mName string, index []int, prefix *compositePrefix) {
 	var (
 		err error
 		fi  *FieldInfo
@@ -63,7 +133,38 @@ func AddModelFields(mi *ModelInfo, ind reflect.Value, mName string, index []int)
 		}
 		// add anonymous struct Fields
 		if sf.Anonymous {
-			AddModelFields(mi, field, mName+"."+sf.Name, append(index, i))
+			addModelFields(mi, field, mName+"."+sf.Name, append(index, i), prefix)
+			continue
+		}
+
+		// add the fields of composite struct as columns prefixed by the field
+		if IsCompositeType(sf.Type) {
+			_, tags := ParseStructTag(sf.Tag.Get(DefaultStructTagName))
+			sub := &compositePrefix{name: sf.Name, column: tags["column"]}
+			if sub.column == "" {
+				sub.column = NameStrategyMap[NameStrategy](sf.Name)
+			}
+			if prefix != nil {
+				sub.name = prefix.name + sub.name
+				sub.column = prefix.column + "_" + sub.column
+			}
+			addModelFields(mi, field, mName+"."+sf.Name, append(index, i), sub)
+			continue
+		}
+
+		if _, tags := ParseStructTag(sf.Tag.Get(DefaultStructTagName)); tags["lazy"] != "" {
+			lf := &LazyField{Name: sf.Name, RelName: tags["lazy"]}
+			lf.FieldIndex = append(lf.FieldIndex, index...)
+			lf.FieldIndex = append(lf.FieldIndex, i)
+			mi.LazyFields = append(mi.LazyFields, lf)
+			continue
+		}
+
+		if _, tags := ParseStructTag(sf.Tag.Get(DefaultStructTagName)); tags["computed"] != "" {
+			cf := &ComputedField{Name: sf.Name, Expr: tags["computed"]}
+			cf.FieldIndex = append(cf.FieldIndex, index...)
+			cf.FieldIndex = append(cf.FieldIndex, i)
+			mi.ComputedFields = append(mi.ComputedFields, cf)
 			continue
 		}
 
@@ -74,6 +175,10 @@ func AddModelFields(mi *ModelInfo, ind reflect.Value, mName string, index []int)
 		} else if err != nil {
 			break
 		}
+		if prefix != nil {
+			fi.Name = prefix.name + fi.Name
+			fi.Column = prefix.column + "_" + fi.Column
+		}
 		// record current field index
 		fi.FieldIndex = append(fi.FieldIndex, index...)
 		fi.FieldIndex = append(fi.FieldIndex, i)
@@ -83,6 +188,11 @@ func AddModelFields(mi *ModelInfo, ind reflect.Value, mName string, index []int)
 			err = fmt.Errorf("duplicate column name: %s", fi.Column)
 			break
 		}
+		if _, tags := ParseStructTag(sf.Tag.Get(DefaultStructTagName)); tags["poly"] != "" {
+			if err = addPolymorphicField(mi, tags["poly"], fi); err != nil {
+				break
+			}
+		}
 		if fi.Pk {
 			if mi.Fields.Pk != nil {
 				err = fmt.Errorf("one model must have one pk field only")
@@ -99,6 +209,33 @@ func AddModelFields(mi *ModelInfo, ind reflect.Value, mName string, index []int)
 	}
 }
 
+// add fi as the type or the id field of the polymorphic relation name.
+func addPolymorphicField(mi *ModelInfo, name string, fi *FieldInfo) error {
+	if mi.Polymorphics == nil {
+		mi.Polymorphics = make(map[string]*Polymorphic)
+	}
+	poly := mi.Polymorphics[name]
+	if poly == nil {
+		poly = &Polymorphic{Name: name}
+		mi.Polymorphics[name] = poly
+	}
+	switch {
+	case fi.FieldType == TypeVarCharField || fi.FieldType == TypeCharField || fi.FieldType == TypeTextField:
+		if poly.TypeField != nil {
+			return fmt.Errorf("polymorphic relation `%s` has two type fields", name)
+		}
+		poly.TypeField = fi
+	case fi.FieldType&IsIntegerField > 0:
+		if poly.IDField != nil {
+			return fmt.Errorf("polymorphic relation `%s` has two id fields", name)
+		}
+		poly.IDField = fi
+	default:
+		return fmt.Errorf("field of polymorphic relation `%s` must be a string or an integer field", name)
+	}
+	return nil
+}
+
 // NewM2MModelInfo combine related model info to new model info.
 // prepare for relation models query.
 func NewM2MModelInfo(m1, m2 *ModelInfo) (mi *ModelInfo) {
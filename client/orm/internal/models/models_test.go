@@ -47,3 +47,59 @@ func TestDbBase_GetTables(t *testing.T) {
 	indexes := GetTableIndex(mi.AddrField)
 	assert.Equal(t, [][]string{{"index1"}, {"index2"}}, indexes)
 }
+
+type NoPrefix struct {
+	Id int
+}
+
+func (n *NoPrefix) TableNoPrefix() bool {
+	return true
+}
+
+type Prefixed struct {
+	Id int
+}
+
+func TestModelCache_SetTablePrefix(t *testing.T) {
+	c := NewModelCacheHandler()
+	c.SetTablePrefix("app1_")
+	assert.Nil(t, c.Register("", true, &Interface{}, &NoPrefix{}))
+	assert.Nil(t, c.Register("blog_", true, &Prefixed{}))
+
+	// the table of TableName is prefixed too
+	mi, ok := c.Get("app1_INTERFACE_")
+	assert.True(t, ok)
+	assert.Equal(t, "app1_INTERFACE_", mi.Table)
+	mi, ok = c.Get("no_prefix")
+	assert.True(t, ok)
+	assert.Equal(t, "no_prefix", mi.Table)
+	mi, ok = c.Get("app1_blog_prefixed")
+	assert.True(t, ok)
+	assert.Equal(t, "app1_blog_prefixed", mi.Table)
+}
+
+func TestModelCache_RegisterBatch(t *testing.T) {
+	c := NewModelCacheHandler()
+	assert.Nil(t, c.RegisterBatch("app1_", "_v2", &Interface{}, &Prefixed{}))
+
+	mi, ok := c.Get("app1_INTERFACE__v2")
+	assert.True(t, ok)
+	assert.Equal(t, "app1_INTERFACE__v2", mi.Table)
+	_, ok = c.Get("app1_prefixed_v2")
+	assert.True(t, ok)
+
+	// the bad models are named by the error, the good ones are registered
+	err := c.RegisterBatch("", "", NoPrefix{}, &Prefixed{}, &Interface{}, new(*Interface))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot use non-ptr model struct `github.com/beego/beego/v2/client/orm/internal/models.NoPrefix`")
+	assert.Contains(t, err.Error(), "model `github.com/beego/beego/v2/client/orm/internal/models.Prefixed` repeat Register")
+	assert.Contains(t, err.Error(), "two reference to the struct `*models.Interface`")
+	_, ok = c.Get("no_prefix")
+	assert.False(t, ok)
+
+	c = NewModelCacheHandler()
+	err = c.RegisterBatch("", "", NoPrefix{}, &Prefixed{})
+	assert.NotNil(t, err)
+	_, ok = c.Get("prefixed")
+	assert.True(t, ok)
+}
@@ -17,6 +17,7 @@ package models
 import (
 	"database/sql"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 	"time"
@@ -25,28 +26,34 @@ import (
 // 1 is attr
 // 2 is tag
 var supportTag = map[string]int{
-	"-":            1,
-	"null":         1,
-	"index":        1,
-	"unique":       1,
-	"pk":           1,
-	"auto":         1,
-	"auto_now":     1,
-	"auto_now_add": 1,
-	"size":         2,
-	"column":       2,
-	"default":      2,
-	"rel":          2,
-	"reverse":      2,
-	"rel_table":    2,
-	"rel_through":  2,
-	"digits":       2,
-	"decimals":     2,
-	"on_delete":    2,
-	"type":         2,
-	"description":  2,
-	"precision":    2,
-	"db_type":      2,
+	"-":             1,
+	"null":          1,
+	"index":         1,
+	"unique":        1,
+	"empty_as_null": 1,
+	"pk":            1,
+	"auto":          1,
+	"auto_now":      1,
+	"auto_now_add":  1,
+	"size":          2,
+	"column":        2,
+	"default":       2,
+	"rel":           2,
+	"reverse":       2,
+	"rel_table":     2,
+	"rel_through":   2,
+	"digits":        2,
+	"decimals":      2,
+	"on_delete":     2,
+	"type":          2,
+	"description":   2,
+	"precision":     2,
+	"db_type":       2,
+	"lazy":          2,
+	"poly":          2,
+	"computed":      2,
+	"set":           2,
+	"time_format":   2,
 }
 
 type fn func(string) string
@@ -82,6 +89,17 @@ func GetTableName(val reflect.Value) string {
 	return SnakeString(reflect.Indirect(val).Type().Name())
 }
 
+// GetTableNoPrefix get whether the table name is not prefixed by the table prefix of the model cache.
+func GetTableNoPrefix(val reflect.Value) bool {
+	if fun := val.MethodByName("TableNoPrefix"); fun.IsValid() {
+		vals := fun.Call([]reflect.Value{})
+		if len(vals) > 0 && vals[0].Kind() == reflect.Bool {
+			return vals[0].Bool()
+		}
+	}
+	return false
+}
+
 // GetTableEngine get table engine, myisam or innodb.
 func GetTableEngine(val reflect.Value) string {
 	fun := val.MethodByName("TableEngine")
@@ -108,6 +126,46 @@ func GetTableIndex(val reflect.Value) [][]string {
 	return nil
 }
 
+// IndexColumn is a column of an IndexSpec, Desc orders it descending in the index.
+type IndexColumn struct {
+	Name string
+	Desc bool
+}
+
+// IndexSpec is a named index of the columns in order.
+type IndexSpec struct {
+	Name    string
+	Columns []IndexColumn
+}
+
+// GetTableIndexSpec get table index specs from method.
+func GetTableIndexSpec(val reflect.Value) []IndexSpec {
+	fun := val.MethodByName("TableIndexSpec")
+	if fun.IsValid() {
+		vals := fun.Call([]reflect.Value{})
+		if len(vals) > 0 && vals[0].CanInterface() {
+			if d, ok := vals[0].Interface().([]IndexSpec); ok {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+// GetDefaultOrderBy get the default order expressions from method.
+func GetDefaultOrderBy(val reflect.Value) []string {
+	fun := val.MethodByName("DefaultOrderBy")
+	if fun.IsValid() {
+		vals := fun.Call([]reflect.Value{})
+		if len(vals) > 0 && vals[0].CanInterface() {
+			if d, ok := vals[0].Interface().([]string); ok {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
 // GetTableUnique get table unique from method
 func GetTableUnique(val reflect.Value) [][]string {
 	fun := val.MethodByName("TableUnique")
@@ -154,6 +212,17 @@ func getColumnName(ft int, addrField reflect.Value, sf reflect.StructField, col
 	return column
 }
 
+// IsSQLNullType returns true if typ is one of the sql.Null* wrappers,
+// which are nullable columns without the null tag
+func IsSQLNullType(typ reflect.Type) bool {
+	switch reflect.New(typ).Elem().Interface().(type) {
+	case sql.NullString, sql.NullBool, sql.NullByte, sql.NullInt16, sql.NullInt32,
+		sql.NullInt64, sql.NullFloat64, sql.NullTime:
+		return true
+	}
+	return false
+}
+
 // return field type as type constant from reflect.Value
 func getFieldType(val reflect.Value) (ft int, err error) {
 	switch val.Type() {
@@ -184,6 +253,10 @@ func getFieldType(val reflect.Value) (ft int, err error) {
 		ft = TypeVarCharField
 	case reflect.TypeOf(new(time.Time)):
 		ft = TypeDateTimeField
+	case reflect.TypeOf(new(big.Rat)):
+		ft = TypeDecimalField
+	case reflect.TypeOf([]byte(nil)):
+		ft = TypeBinaryField
 	default:
 		elm := reflect.Indirect(val)
 		switch elm.Kind() {
@@ -216,13 +289,19 @@ func getFieldType(val reflect.Value) (ft int, err error) {
 			switch elm.Interface().(type) {
 			case sql.NullInt64:
 				ft = TypeBigIntegerField
+			case sql.NullInt32:
+				ft = TypeIntegerField
+			case sql.NullInt16:
+				ft = TypeSmallIntegerField
+			case sql.NullByte:
+				ft = TypePositiveBitField
 			case sql.NullFloat64:
 				ft = TypeFloatField
 			case sql.NullBool:
 				ft = TypeBooleanField
 			case sql.NullString:
 				ft = TypeVarCharField
-			case time.Time:
+			case sql.NullTime, time.Time:
 				ft = TypeDateTimeField
 			}
 		}
@@ -244,7 +323,7 @@ func ParseStructTag(data string) (attrs map[string]bool, tags map[string]string)
 		v = strings.TrimSpace(v)
 		if t := strings.ToLower(v); supportTag[t] == 1 {
 			attrs[t] = true
-		} else if i := strings.Index(v, "("); i > 0 && strings.Index(v, ")") == len(v)-1 {
+		} else if i := strings.Index(v, "("); i > 0 && strings.HasSuffix(v, ")") {
 			name := t[:i]
 			if supportTag[name] == 2 {
 				v = v[i+1 : len(v)-1]
@@ -87,6 +87,11 @@ func MockInsertMultiWithCtx(tableName string, cnt int64, err error) *Mock {
 	return NewMock(NewSimpleCondition(tableName, "InsertMultiWithCtx"), []interface{}{cnt, err}, nil)
 }
 
+// MockInsertMultiReturningWithCtx support InsertMultiReturning and InsertMultiReturningWithCtx
+func MockInsertMultiReturningWithCtx(tableName string, err error) *Mock {
+	return NewMock(NewSimpleCondition(tableName, "InsertMultiReturningWithCtx"), []interface{}{err}, nil)
+}
+
 // MockInsertOrUpdateWithCtx support InsertOrUpdate and InsertOrUpdateWithCtx
 func MockInsertOrUpdateWithCtx(tableName string, id int64, err error) *Mock {
 	return NewMock(NewSimpleCondition(tableName, "InsertOrUpdateWithCtx"), []interface{}{id, err}, nil)
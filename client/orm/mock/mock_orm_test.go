@@ -120,6 +120,17 @@ func TestMockInsertMultiWithCtx(t *testing.T) {
 	assert.Equal(t, mock, err)
 }
 
+func TestMockInsertMultiReturningWithCtx(t *testing.T) {
+	s := StartMock()
+	defer s.Clear()
+	mock := errors.New(mockErrorMsg)
+	s.Mock(MockInsertMultiReturningWithCtx((&User{}).TableName(), mock))
+	o := orm.NewOrm()
+	var users []*User
+	err := o.InsertMultiReturning(11, []interface{}{&User{}}, &users)
+	assert.Equal(t, mock, err)
+}
+
 func TestMockInsertWithCtx(t *testing.T) {
 	s := StartMock()
 	defer s.Clear()
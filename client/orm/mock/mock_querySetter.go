@@ -16,9 +16,11 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/beego/beego/v2/client/orm"
 	"github.com/beego/beego/v2/client/orm/clauses/order_clause"
+	"github.com/beego/beego/v2/core/utils"
 )
 
 // DoNothingQuerySetter do nothing
@@ -45,6 +47,14 @@ func (d *DoNothingQuerySetter) DeleteWithCtx(ctx context.Context) (int64, error)
 	return 0, nil
 }
 
+func (d *DoNothingQuerySetter) DeleteReturning(container interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (d *DoNothingQuerySetter) DeleteReturningWithCtx(ctx context.Context, container interface{}) (int64, error) {
+	return 0, nil
+}
+
 func (d *DoNothingQuerySetter) PrepareInsertWithCtx(ctx context.Context) (orm.Inserter, error) {
 	return nil, nil
 }
@@ -57,6 +67,20 @@ func (d *DoNothingQuerySetter) OneWithCtx(ctx context.Context, container interfa
 	return nil
 }
 
+func (d *DoNothingQuerySetter) Explain(analyze bool) (string, error) {
+	return "", nil
+}
+
+func (d *DoNothingQuerySetter) ExplainWithCtx(ctx context.Context, analyze bool) (string, error) {
+	return "", nil
+}
+
+func (d *DoNothingQuerySetter) Stream(ctx context.Context) (<-chan orm.RowResult, error) {
+	ch := make(chan orm.RowResult)
+	close(ch)
+	return ch, nil
+}
+
 func (d *DoNothingQuerySetter) ValuesWithCtx(ctx context.Context, results *[]orm.Params, exprs ...string) (int64, error) {
 	return 0, nil
 }
@@ -81,6 +105,62 @@ func (d *DoNothingQuerySetter) FilterRaw(s string, s2 string) orm.QuerySeter {
 	return d
 }
 
+func (d *DoNothingQuerySetter) FilterByExample(example interface{}, args ...utils.KV) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterCoalesce(column string, def interface{}, operator string, value interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterConcat(cols []string, separator string, operator string, value interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterRound(column string, decimals int, operator string, value interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterTZ(column string, tz string, operator string, value interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterCast(column string, castType string, operator string, value interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterHasRelated(relation string, has bool) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) Descendants(pkColumn, parentColumn string, rootPK interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterApprox(column string, target float64, tolerance float64) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) Cursor(orderCols []string) *orm.Cursor {
+	return nil
+}
+
+func (d *DoNothingQuerySetter) FilterInOrdered(column string, values []interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterRecent(column string, within time.Duration) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterCollate(column string, collation string, operator string, value interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) Search(term string, columns ...string) orm.QuerySeter {
+	return d
+}
+
 func (d *DoNothingQuerySetter) Exclude(s string, i ...interface{}) orm.QuerySeter {
 	return d
 }
@@ -89,6 +169,10 @@ func (d *DoNothingQuerySetter) SetCond(condition *orm.Condition) orm.QuerySeter
 	return d
 }
 
+func (d *DoNothingQuerySetter) Err() error {
+	return nil
+}
+
 func (d *DoNothingQuerySetter) GetCond() *orm.Condition {
 	return orm.NewCondition()
 }
@@ -105,10 +189,98 @@ func (d *DoNothingQuerySetter) GroupBy(exprs ...string) orm.QuerySeter {
 	return d
 }
 
+func (d *DoNothingQuerySetter) TimeBucketTZ(column string, unit string, alias string, tz string) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) TimeBucket(column string, unit string, alias string) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) ValuesTZ(column string, alias string, tz string) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) ValuesCase(alias string, cases []orm.CaseWhen, elseVal interface{}) orm.QuerySeter {
+	return d
+}
+
 func (d *DoNothingQuerySetter) OrderBy(exprs ...string) orm.QuerySeter {
 	return d
 }
 
+func (d *DoNothingQuerySetter) FilterDateEq(column string, date time.Time) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) AggregateBy(groups []string, aggrs ...orm.Aggregation) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) Having(conds ...orm.HavingCond) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) MarshalSpec() ([]byte, error) {
+	return nil, nil
+}
+
+func (d *DoNothingQuerySetter) StreamBuffer(size int, consumerTimeout time.Duration) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) Map(fn func(interface{}) interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterBetween(column string, lo interface{}, hi interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) ValuesBool(alias string, cond *orm.Condition) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FetchSize(n int) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) MaxResultBytes(n int64) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterWindow(aggr orm.WindowAggr, operator string, value interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) TopNPerGroup(partitionCols []string, orderBy string, n int) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterOpen(column string, lo interface{}, hi interface{}) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) FilterBBox(latCol, lngCol string, minLat, minLng, maxLat, maxLng float64) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) NoDefaultOrder() orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) OrderByCoalesce(cols ...string) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) OrderByDistance(latCol, lngCol string, lat, lng float64) orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) OrderByCollate(col string, collation string) orm.QuerySeter {
+	return d
+}
+
 func (d *DoNothingQuerySetter) ForceIndex(indexes ...string) orm.QuerySeter {
 	return d
 }
@@ -125,6 +297,14 @@ func (d *DoNothingQuerySetter) RelatedSel(params ...interface{}) orm.QuerySeter
 	return d
 }
 
+func (d *DoNothingQuerySetter) DedupByPK() orm.QuerySeter {
+	return d
+}
+
+func (d *DoNothingQuerySetter) LenientScan(warnings *[]orm.ScanWarning) orm.QuerySeter {
+	return d
+}
+
 func (d *DoNothingQuerySetter) Distinct() orm.QuerySeter {
 	return d
 }
@@ -133,10 +313,38 @@ func (d *DoNothingQuerySetter) ForUpdate() orm.QuerySeter {
 	return d
 }
 
+func (d *DoNothingQuerySetter) NoPrepare() orm.QuerySeter {
+	return d
+}
+
 func (d *DoNothingQuerySetter) Count() (int64, error) {
 	return 0, nil
 }
 
+func (d *DoNothingQuerySetter) CountColumn(col string) (int64, error) {
+	return 0, nil
+}
+
+func (d *DoNothingQuerySetter) CountColumnWithCtx(ctx context.Context, col string) (int64, error) {
+	return 0, nil
+}
+
+func (d *DoNothingQuerySetter) GroupCount(groupCol string) (map[interface{}]int64, error) {
+	return nil, nil
+}
+
+func (d *DoNothingQuerySetter) GroupCountWithCtx(ctx context.Context, groupCol string) (map[interface{}]int64, error) {
+	return nil, nil
+}
+
+func (d *DoNothingQuerySetter) GroupCountColumn(groupCol string, col string) (map[interface{}]int64, error) {
+	return nil, nil
+}
+
+func (d *DoNothingQuerySetter) GroupCountColumnWithCtx(ctx context.Context, groupCol string, col string) (map[interface{}]int64, error) {
+	return nil, nil
+}
+
 func (d *DoNothingQuerySetter) Exist() bool {
 	return true
 }
@@ -157,6 +365,14 @@ func (d *DoNothingQuerySetter) All(container interface{}, cols ...string) (int64
 	return 0, nil
 }
 
+func (d *DoNothingQuerySetter) AllInto(container interface{}, cols ...string) (int64, error) {
+	return 0, nil
+}
+
+func (d *DoNothingQuerySetter) AllIntoWithCtx(ctx context.Context, container interface{}, cols ...string) (int64, error) {
+	return 0, nil
+}
+
 func (d *DoNothingQuerySetter) One(container interface{}, cols ...string) error {
 	return nil
 }
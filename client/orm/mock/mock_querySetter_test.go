@@ -15,19 +15,24 @@
 package mock
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/beego/beego/v2/client/orm"
 )
 
 func TestDoNothingQuerySetter(t *testing.T) {
 	setter := &DoNothingQuerySetter{}
 	setter.GroupBy().Filter("").Limit(10).
 		Distinct().Exclude("a").FilterRaw("", "").
-		ForceIndex().ForUpdate().IgnoreIndex().
-		Offset(11).OrderBy().RelatedSel().SetCond(nil).UseIndex()
+		ForceIndex().ForUpdate().LenientScan(nil).NoPrepare().IgnoreIndex().
+		Offset(11).OrderBy().OrderByCoalesce().OrderByCollate("", "").OrderByDistance("", "", 0, 0).NoDefaultOrder().TopNPerGroup(nil, "", 0).FilterWindow(orm.WindowAggr{}, "", nil).AggregateBy(nil).Having().FetchSize(0).MaxResultBytes(0).TimeBucketTZ("", "", "", "").StreamBuffer(0, 0).Map(nil).FilterDateEq("", time.Now()).FilterRecent("", 0).FilterInOrdered("", nil).FilterCast("", "", "", nil).FilterConcat(nil, "", "", nil).FilterRound("", 0, "", nil).FilterApprox("", 0, 0).FilterTZ("", "", "", nil).FilterBBox("", "", 0, 0, 0, 0).FilterOpen("", nil, nil).FilterBetween("", nil, nil).FilterHasRelated("", false).Descendants("", "", nil).ValuesCase("", nil, nil).ValuesBool("", nil).ValuesTZ("", "", "").RelatedSel().SetCond(nil).UseIndex()
 
 	assert.True(t, setter.Exist())
+	assert.Nil(t, setter.Err())
 	err := setter.One(nil)
 	assert.Nil(t, err)
 	i, err := setter.Count()
@@ -38,10 +43,33 @@ func TestDoNothingQuerySetter(t *testing.T) {
 	assert.Equal(t, int64(0), i)
 	assert.Nil(t, err)
 
+	i, err = setter.DeleteReturning(nil)
+	assert.Equal(t, int64(0), i)
+	assert.Nil(t, err)
+
 	i, err = setter.All(nil)
 	assert.Equal(t, int64(0), i)
 	assert.Nil(t, err)
 
+	i, err = setter.AllInto(nil)
+	assert.Equal(t, int64(0), i)
+	assert.Nil(t, err)
+
+	plan, err := setter.Explain(true)
+	assert.Equal(t, "", plan)
+	assert.Nil(t, err)
+
+	spec, err := setter.MarshalSpec()
+	assert.Nil(t, spec)
+	assert.Nil(t, err)
+
+	assert.Nil(t, setter.Cursor(nil))
+
+	ch, err := setter.Stream(context.Background())
+	assert.Nil(t, err)
+	_, ok := <-ch
+	assert.False(t, ok)
+
 	i, err = setter.Update(nil)
 	assert.Equal(t, int64(0), i)
 	assert.Nil(t, err)
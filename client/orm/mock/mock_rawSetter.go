@@ -38,6 +38,10 @@ func (d *DoNothingRawSetter) SetArgs(i ...interface{}) orm.RawSeter {
 	return d
 }
 
+func (d *DoNothingRawSetter) NoPrepare() orm.RawSeter {
+	return d
+}
+
 func (d *DoNothingRawSetter) Values(container *[]orm.Params, cols ...string) (int64, error) {
 	return 0, nil
 }
@@ -58,6 +62,18 @@ func (d *DoNothingRawSetter) RowsToStruct(ptrStruct interface{}, keyCol, valueCo
 	return 0, nil
 }
 
+func (d *DoNothingRawSetter) QueryRowsFunc(fn func(cols []string, vals []interface{}) error) error {
+	return nil
+}
+
+func (d *DoNothingRawSetter) QueryRowsWith(scanners map[string]func([]byte) (interface{}, error), container interface{}) (int64, error) {
+	return 0, nil
+}
+
+func (d *DoNothingRawSetter) QueryRowsNested(container interface{}) (int64, error) {
+	return 0, nil
+}
+
 func (d *DoNothingRawSetter) Prepare() (orm.RawPreparer, error) {
 	return nil, nil
 }
@@ -46,6 +46,14 @@ func TestDoNothingRawSetter(t *testing.T) {
 	assert.Equal(t, int64(0), i)
 	assert.Nil(t, err)
 
+	i, err = rs.QueryRowsWith(nil, nil)
+	assert.Equal(t, int64(0), i)
+	assert.Nil(t, err)
+
+	i, err = rs.QueryRowsNested(nil)
+	assert.Equal(t, int64(0), i)
+	assert.Nil(t, err)
+
 	err = rs.QueryRow()
 	// assert.Equal(t, int64(0), i)
 	assert.Nil(t, err)
@@ -60,4 +68,7 @@ func TestDoNothingRawSetter(t *testing.T) {
 
 	rrs := rs.SetArgs()
 	assert.Equal(t, rrs, rs)
+
+	rrs = rs.NoPrepare()
+	assert.Equal(t, rrs, rs)
 }
@@ -16,6 +16,7 @@ package orm
 
 import (
 	"fmt"
+	"reflect"
 	"runtime/debug"
 
 	imodels "github.com/beego/beego/v2/client/orm/internal/models"
@@ -28,6 +29,14 @@ func RegisterModel(models ...interface{}) {
 	RegisterModelWithPrefix("", models...)
 }
 
+// SetTablePrefix Set the prefix of the tables of all the models registered after it, such as app1_ of app1_user,
+// the tables named by TableName are prefixed too, unless TableNoPrefix of the model returns true.
+// It is added before the prefix or suffix of RegisterModelWithPrefix and RegisterModelWithSuffix,
+// and applies to all the statements and the ddl of the models, since they use the registered table.
+func SetTablePrefix(prefix string) {
+	defaultModelCache.SetTablePrefix(prefix)
+}
+
 // RegisterModelWithPrefix Register models with a prefix
 func RegisterModelWithPrefix(prefix string, models ...interface{}) {
 	if err := defaultModelCache.Register(prefix, true, models...); err != nil {
@@ -42,6 +51,48 @@ func RegisterModelWithSuffix(suffix string, models ...interface{}) {
 	}
 }
 
+// ModelOptions are the options shared by the models of RegisterModelBatch.
+// The schema is not an option of the models, it is chosen by Ormer.UsingSchema,
+// nor the db alias, the models are of all the aliases unless their IsApplicableTableForDB says otherwise.
+type ModelOptions struct {
+	// TablePrefix is added before the tables of the models, like RegisterModelWithPrefix
+	TablePrefix string
+	// TableSuffix is added after the tables of the models, like RegisterModelWithSuffix
+	TableSuffix string
+}
+
+// RegisterModelBatch Register models with the shared options,
+// all the models are tried, the returned error joins the errors of the models failed, which name them.
+//
+//	err := orm.RegisterModelBatch(orm.ModelOptions{TablePrefix: "app1_"}, new(User), new(Post), new(Tag))
+func RegisterModelBatch(opts ModelOptions, models ...interface{}) error {
+	return defaultModelCache.RegisterBatch(opts.TablePrefix, opts.TableSuffix, models...)
+}
+
+// RegisterComposite Register struct types whose fields map to multiple columns,
+// register them before the models using them:
+//
+//	type Money struct {
+//		Amount   int64
+//		Currency string `orm:"size(3)"`
+//	}
+//
+//	type Product struct {
+//		Id    int
+//		Price Money
+//	}
+//
+// the Price field maps to the columns price_amount and price_currency,
+// filter them by the names PriceAmount and PriceCurrency or by the columns.
+// A column tag on the Price field replaces the price prefix.
+func RegisterComposite(composites ...interface{}) {
+	for _, c := range composites {
+		if err := imodels.RegisterCompositeType(reflect.Indirect(reflect.ValueOf(c)).Type()); err != nil {
+			panic(err)
+		}
+	}
+}
+
 // BootStrap Bootstrap models.
 // make All model parsed and can not add more models
 func BootStrap() {
@@ -39,6 +39,7 @@ const (
 	TypeDecimalField              = models.TypeDecimalField
 	TypeJSONField                 = models.TypeJSONField
 	TypeJsonbField                = models.TypeJsonbField
+	TypeBinaryField               = models.TypeBinaryField
 	RelForeignKey                 = models.RelForeignKey
 	RelOneToOne                   = models.RelOneToOne
 	RelManyToMany                 = models.RelManyToMany
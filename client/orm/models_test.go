@@ -18,6 +18,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
 	"strings"
 	"time"
@@ -236,6 +237,25 @@ type DataCustom struct {
 	Decimal Float64 `orm:"digits(8);decimals(4)"`
 }
 
+// sql.Null* fields are nullable without the null tag
+type DataSQLNull struct {
+	ID          int `orm:"column(id)"`
+	NullString  sql.NullString
+	NullBool    sql.NullBool
+	NullByte    sql.NullByte
+	NullInt16   sql.NullInt16
+	NullInt32   sql.NullInt32
+	NullInt64   sql.NullInt64
+	NullFloat64 sql.NullFloat64
+	NullTime    sql.NullTime
+}
+
+type DataDecimal struct {
+	ID       int      `orm:"column(id)"`
+	Price    big.Rat  `orm:"digits(30);decimals(10)"`
+	Discount *big.Rat `orm:"digits(30);decimals(10);null"`
+}
+
 // only for mysql
 type UserBig struct {
 	ID   uint64 `orm:"column(id)"`
@@ -252,6 +272,12 @@ func (t *TM) TableName() string {
 	return "tm"
 }
 
+type LegacyLog struct {
+	ID     int        `orm:"column(id)"`
+	Logged time.Time  `orm:"time_format(2006/01/02 15:04:05)"`
+	Day    *time.Time `orm:"null;type(date);size(10);time_format(02.01.2006)"`
+}
+
 func NewTM() *TM {
 	obj := new(TM)
 	return obj
@@ -265,6 +291,68 @@ type DeptInfo struct {
 	Salary       int
 }
 
+type Money struct {
+	Amount   int64
+	Currency string `orm:"size(3)"`
+}
+
+type Product struct {
+	ID    int    `orm:"column(id)"`
+	Name  string `orm:"size(30)"`
+	Price Money
+	Cost  Money `orm:"column(unit_cost)"`
+}
+
+type Task struct {
+	ID       int    `orm:"column(id)"`
+	Title    string `orm:"size(30)"`
+	Priority int
+}
+
+func (t *Task) DefaultOrderBy() []string {
+	return []string{"-Priority", "ID"}
+}
+
+type Attachment struct {
+	ID             int    `orm:"column(id)"`
+	Name           string `orm:"size(30)"`
+	AttachableType string `orm:"size(30);poly(attachable)"`
+	AttachableID   int    `orm:"column(attachable_id);poly(attachable)"`
+}
+
+type Person struct {
+	ID        int    `orm:"column(id)"`
+	FirstName string `orm:"size(30)"`
+	LastName  string `orm:"size(30)"`
+	FullName  string `orm:"-;computed(first_name || ' ' || UPPER(last_name))"`
+}
+
+type Rating struct {
+	ID      int `orm:"column(id)"`
+	Stars   int
+	Comment string `orm:"size(255);empty_as_null"`
+}
+
+// Validate keeps the stars from 1 to 5.
+func (r *Rating) Validate() error {
+	if r.Stars < 1 || r.Stars > 5 {
+		return fmt.Errorf("stars %d out of 1 to 5", r.Stars)
+	}
+	return nil
+}
+
+type Device struct {
+	UUID []byte `orm:"pk;size(16);column(uuid)"`
+	Name string `orm:"size(30)"`
+	Key  []byte `orm:"null"`
+}
+
+type DeviceLog struct {
+	ID      int     `orm:"column(id)"`
+	Device  *Device `orm:"rel(fk)"`
+	Message string  `orm:"size(100)"`
+}
+
 type UnregisterModel struct {
 	ID           int       `orm:"column(id)"`
 	Created      time.Time `orm:"auto_now_add"`
@@ -383,6 +471,8 @@ type Comment struct {
 	Content string    `orm:"type(text)"`
 	Parent  *Comment  `orm:"null;rel(fk)"`
 	Created time.Time `orm:"auto_now_add"`
+
+	LazyPost Lazy `orm:"lazy(Post)"`
 }
 
 func NewComment() *Comment {
@@ -80,12 +80,19 @@ var (
 	DefaultRelsDepth = 2
 	DefaultTimeLoc   = iutils.DefaultTimeLoc
 	ErrTxDone        = errors.New("<TxOrmer.Commit/Rollback> transaction already done")
+	ErrTxTimeout     = errors.New("<Ormer.DoTxWithTimeout> transaction rolled back by the timeout")
 	ErrMultiRows     = errors.New("<QuerySeter> return multi rows")
 	ErrNoRows        = errors.New("<QuerySeter> no row found")
 	ErrStmtClosed    = errors.New("<QuerySeter> stmt already closed")
 	ErrArgs          = errors.New("<Ormer> args error may be empty")
 	ErrNotImplement  = errors.New("have not implement")
 
+	ErrReadOnlyOrmer   = errors.New("<Ormer.ReadOnly> the ormer is read-only, the write is rejected")
+	ErrShutdown        = errors.New("<orm.Shutdown> the db is shut down, no new operation is accepted")
+	ErrQueryThrottled  = errors.New("<orm.SetMaxConcurrentQueries> too many queries are running on the db, the query is throttled")
+	ErrConsumerTimeout = errors.New("<QuerySeter.Stream> the consumer did not receive a row in time, the stream is aborted")
+	ErrResultTooLarge  = errors.New("<QuerySeter.MaxResultBytes> the scanned rows exceed the max result bytes, the query is aborted")
+
 	ErrLastInsertIdUnavailable = errors.New("<Ormer> last insert id is unavailable")
 )
 
@@ -98,6 +105,10 @@ type ParamsList []interface{}
 type ormBase struct {
 	alias *alias
 	db    dbQuerier
+	// the changes waiting for the commit of the transaction, nil out of a transaction
+	changes *pendingChanges
+	// set by Ormer.ReadOnly, the writes are rejected with ErrReadOnlyOrmer
+	readOnly bool
 }
 
 var (
@@ -144,6 +155,15 @@ func (*ormBase) getFieldInfo(mi *models.ModelInfo, name string) *models.FieldInf
 	return fi
 }
 
+// Get ormBase executing queries directly, bypassing the prepared statement cache.
+// transactions do not cache prepared statements, o is returned as is.
+func (o *ormBase) noPrepare() *ormBase {
+	if db, ok := o.db.(*DB); ok && db.stmtDecorators != nil {
+		return &ormBase{alias: o.alias, db: db.withoutStmtCache()}
+	}
+	return o
+}
+
 // read data to model
 func (o *ormBase) Read(md interface{}, cols ...string) error {
 	return o.ReadWithCtx(context.Background(), md, cols...)
@@ -151,7 +171,11 @@ func (o *ormBase) Read(md interface{}, cols ...string) error {
 
 func (o *ormBase) ReadWithCtx(ctx context.Context, md interface{}, cols ...string) error {
 	mi, ind := o.getPtrMiInd(md)
-	return o.alias.DbBaser.Read(ctx, o.db, mi, ind, o.alias.TZ, cols, false)
+	err := o.alias.DbBaser.Read(ctx, o.db, mi, ind, o.alias.TZ, cols, false)
+	if err == nil && len(mi.LazyFields) > 0 {
+		o.bindLazy(ctx, mi, ind)
+	}
+	return err
 }
 
 // read data to model, like Read(), but use "SELECT FOR UPDATE" form
@@ -161,7 +185,27 @@ func (o *ormBase) ReadForUpdate(md interface{}, cols ...string) error {
 
 func (o *ormBase) ReadForUpdateWithCtx(ctx context.Context, md interface{}, cols ...string) error {
 	mi, ind := o.getPtrMiInd(md)
-	return o.alias.DbBaser.Read(ctx, o.db, mi, ind, o.alias.TZ, cols, true)
+	err := o.alias.DbBaser.Read(ctx, o.db, mi, ind, o.alias.TZ, cols, true)
+	if err == nil && len(mi.LazyFields) > 0 {
+		o.bindLazy(ctx, mi, ind)
+	}
+	return err
+}
+
+// re-read all the fields of the model by its pk from the database, bypassing the entity cache
+func (o *ormBase) Refresh(md interface{}) error {
+	return o.RefreshWithCtx(context.Background(), md)
+}
+
+func (o *ormBase) RefreshWithCtx(ctx context.Context, md interface{}) error {
+	mi, ind := o.getPtrMiInd(md)
+	// the cached copy is stale as the model
+	o.evictEntity(mi, ind)
+	err := o.alias.DbBaser.Read(ctx, o.db, mi, ind, o.alias.TZ, nil, false)
+	if err == nil && len(mi.LazyFields) > 0 {
+		o.bindLazy(ctx, mi, ind)
+	}
+	return err
 }
 
 // Try to read a row from the database, or insert one if it doesn't exist
@@ -197,13 +241,22 @@ func (o *ormBase) Insert(md interface{}) (int64, error) {
 }
 
 func (o *ormBase) InsertWithCtx(ctx context.Context, md interface{}) (int64, error) {
+	if o.readOnly {
+		return 0, ErrReadOnlyOrmer
+	}
 	mi, ind := o.getPtrMiInd(md)
+	if o.alias.CheckUnique {
+		if err := o.checkUnique(ctx, mi, ind); err != nil {
+			return 0, err
+		}
+	}
 	id, err := o.alias.DbBaser.Insert(ctx, o.db, mi, ind, o.alias.TZ)
 	if err != nil {
 		return id, err
 	}
 
 	o.setPk(mi, ind, id)
+	o.notifyChange(mi, ChangeInsert, nil, ind)
 
 	return id, nil
 }
@@ -225,6 +278,9 @@ func (o *ormBase) InsertMulti(bulk int, mds interface{}) (int64, error) {
 }
 
 func (o *ormBase) InsertMultiWithCtx(ctx context.Context, bulk int, mds interface{}) (int64, error) {
+	if o.readOnly {
+		return 0, ErrReadOnlyOrmer
+	}
 	var cnt int64
 
 	sind := reflect.Indirect(reflect.ValueOf(mds))
@@ -238,8 +294,17 @@ func (o *ormBase) InsertMultiWithCtx(ctx context.Context, bulk int, mds interfac
 		return cnt, ErrArgs
 	}
 
+	errs := validateRows(sind)
+	if len(errs) > 0 && (!o.alias.SkipInvalid || len(errs) == sind.Len()) {
+		return cnt, errs
+	}
+
 	if bulk <= 1 {
-		for i := 0; i < sind.Len(); i++ {
+		for i, j := 0, 0; i < sind.Len(); i++ {
+			if j < len(errs) && errs[j].Index == i {
+				j++
+				continue
+			}
 			ind := reflect.Indirect(sind.Index(i))
 			mi := o.getMi(ind.Interface())
 			id, err := o.alias.DbBaser.Insert(ctx, o.db, mi, ind, o.alias.TZ)
@@ -248,12 +313,29 @@ func (o *ormBase) InsertMultiWithCtx(ctx context.Context, bulk int, mds interfac
 			}
 
 			o.setPk(mi, ind, id)
+			o.notifyChange(mi, ChangeInsert, nil, ind)
 
 			cnt++
 		}
 	} else {
+		if len(errs) > 0 {
+			sind = withoutRows(sind, errs)
+		}
 		mi := o.getMi(sind.Index(0).Interface())
-		return o.alias.DbBaser.InsertMulti(ctx, o.db, mi, sind, bulk, o.alias.TZ)
+		var err error
+		cnt, err = o.alias.DbBaser.InsertMulti(ctx, o.db, mi, sind, bulk, o.alias.TZ)
+		if err != nil {
+			return cnt, err
+		}
+		inds := make([]reflect.Value, sind.Len())
+		for i := range inds {
+			inds[i] = reflect.Indirect(sind.Index(i))
+		}
+		o.notifyChange(mi, ChangeInsert, nil, inds...)
+	}
+	// the invalid rows skipped by SkipInvalid
+	if len(errs) > 0 {
+		return cnt, errs
 	}
 	return cnt, nil
 }
@@ -264,6 +346,9 @@ func (o *ormBase) InsertOrUpdate(md interface{}, colConflictAndArgs ...string) (
 }
 
 func (o *ormBase) InsertOrUpdateWithCtx(ctx context.Context, md interface{}, colConflitAndArgs ...string) (int64, error) {
+	if o.readOnly {
+		return 0, ErrReadOnlyOrmer
+	}
 	mi, ind := o.getPtrMiInd(md)
 	id, err := o.alias.DbBaser.InsertOrUpdate(ctx, o.db, mi, ind, o.alias, colConflitAndArgs...)
 	if err != nil {
@@ -271,10 +356,57 @@ func (o *ormBase) InsertOrUpdateWithCtx(ctx context.Context, md interface{}, col
 	}
 
 	o.setPk(mi, ind, id)
+	o.evictEntity(mi, ind)
+	o.notifyChange(mi, ChangeUpsert, nil, ind)
 
 	return id, nil
 }
 
+// insert some models to database and scan the inserted rows to container.
+func (o *ormBase) InsertMultiReturning(bulk int, mds interface{}, container interface{}) error {
+	return o.InsertMultiReturningWithCtx(context.Background(), bulk, mds, container)
+}
+
+func (o *ormBase) InsertMultiReturningWithCtx(ctx context.Context, bulk int, mds interface{}, container interface{}) error {
+	if o.readOnly {
+		return ErrReadOnlyOrmer
+	}
+	sind := reflect.Indirect(reflect.ValueOf(mds))
+
+	switch sind.Kind() {
+	case reflect.Array, reflect.Slice:
+		if sind.Len() == 0 {
+			return ErrArgs
+		}
+	default:
+		return ErrArgs
+	}
+
+	mi := o.getMi(sind.Index(0).Interface())
+
+	val := reflect.ValueOf(container)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Slice {
+		panic(fmt.Errorf("<Ormer.InsertMultiReturning> container need a ptr to slice, but got `%T`", container))
+	}
+	if typ := val.Elem().Type().Elem(); typ != mi.AddrField.Type() && typ != mi.AddrField.Elem().Type() {
+		panic(fmt.Errorf("<Ormer.InsertMultiReturning> container `%T` does not match model `%s`", container, mi.FullName))
+	}
+
+	if bulk < 1 {
+		bulk = 1
+	}
+	if err := o.alias.DbBaser.InsertMultiReturning(ctx, o.db, mi, sind, bulk, container, o.alias.TZ); err != nil {
+		return err
+	}
+	rows := val.Elem()
+	inds := make([]reflect.Value, rows.Len())
+	for i := range inds {
+		inds[i] = reflect.Indirect(rows.Index(i))
+	}
+	o.notifyChange(mi, ChangeInsert, nil, inds...)
+	return nil
+}
+
 // update model to database.
 // cols Set the Columns those want to update.
 func (o *ormBase) Update(md interface{}, cols ...string) (int64, error) {
@@ -282,8 +414,16 @@ func (o *ormBase) Update(md interface{}, cols ...string) (int64, error) {
 }
 
 func (o *ormBase) UpdateWithCtx(ctx context.Context, md interface{}, cols ...string) (int64, error) {
+	if o.readOnly {
+		return 0, ErrReadOnlyOrmer
+	}
 	mi, ind := o.getPtrMiInd(md)
-	return o.alias.DbBaser.Update(ctx, o.db, mi, ind, o.alias.TZ, cols)
+	num, err := o.alias.DbBaser.Update(ctx, o.db, mi, ind, o.alias.TZ, cols)
+	if err == nil {
+		o.evictEntity(mi, ind)
+		o.notifyChange(mi, ChangeUpdate, cols, ind)
+	}
+	return num, err
 }
 
 // delete model in database
@@ -293,8 +433,15 @@ func (o *ormBase) Delete(md interface{}, cols ...string) (int64, error) {
 }
 
 func (o *ormBase) DeleteWithCtx(ctx context.Context, md interface{}, cols ...string) (int64, error) {
+	if o.readOnly {
+		return 0, ErrReadOnlyOrmer
+	}
 	mi, ind := o.getPtrMiInd(md)
 	num, err := o.alias.DbBaser.Delete(ctx, o.db, mi, ind, o.alias.TZ, cols)
+	if err == nil {
+		o.evictEntity(mi, ind)
+		o.notifyChange(mi, ChangeDelete, nil, ind)
+	}
 	return num, err
 }
 
@@ -396,6 +543,24 @@ func (o *ormBase) LoadRelatedWithCtx(_ context.Context, md interface{}, name str
 	return nums, err
 }
 
+// preload related models of md along the relation paths.
+// md can be a ptr to model struct, a slice of models or a ptr to it.
+// every relation level is loaded by one batched query,
+// many to many relation needs one more query for the rel table.
+//
+// example:
+//
+//	orm.Preload(&users, "Posts.Comments", "Profile")
+//	for _, post := range users[0].Posts{...}
+func (o *ormBase) Preload(md interface{}, paths ...string) error {
+	return o.PreloadWithCtx(context.Background(), md, paths...)
+}
+
+func (o *ormBase) PreloadWithCtx(ctx context.Context, md interface{}, paths ...string) error {
+	mi, inds := o.getPreloadMiInds(md)
+	return o.preload(ctx, mi, inds, paths)
+}
+
 // Get QuerySeter for related models to md model
 func (o *ormBase) queryRelated(md interface{}, name string) (*models.ModelInfo, *models.FieldInfo, reflect.Value, *querySet) {
 	mi, ind := o.getPtrMiInd(md)
@@ -543,17 +708,28 @@ func (o *orm) BeginWithCtxAndOpts(ctx context.Context, opts *sql.TxOptions) (TxO
 	if err != nil {
 		return nil, err
 	}
+	if query := o.alias.DbBaser.SearchPathSQL(); query != "" {
+		if _, err = tx.ExecContext(ctx, query); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
 
 	_txOrm := &txOrm{
 		ormBase: ormBase{
-			alias: o.alias,
-			db:    &TxDB{tx: tx},
+			alias:    o.alias,
+			db:       &TxDB{tx: tx},
+			changes:  new(pendingChanges),
+			readOnly: o.readOnly,
 		},
 	}
 
 	if Debug {
 		_txOrm.db = newDbQueryLog(o.alias, _txOrm.db)
 	}
+	if o.readOnly {
+		_txOrm.db = newDbReadOnly(_txOrm.db)
+	}
 
 	var taskTxOrm TxOrmer = _txOrm
 	return taskTxOrm, nil
@@ -575,6 +751,14 @@ func (o *orm) DoTxWithCtxAndOpts(ctx context.Context, opts *sql.TxOptions, task
 	return doTxTemplate(ctx, o, opts, task)
 }
 
+func (o *orm) DoTxWithPropagation(ctx context.Context, propagation Propagation, task func(ctx context.Context, txOrm TxOrmer) error) error {
+	return doTxWithPropagation(ctx, o, o.alias.Name, propagation, task)
+}
+
+func (o *orm) DoTxWithTimeout(ctx context.Context, opts *TxOptions, task func(ctx context.Context, txOrm TxOrmer) error) error {
+	return doTxWithTimeout(ctx, o, opts, task)
+}
+
 func doTxTemplate(ctx context.Context, o TxBeginner, opts *sql.TxOptions,
 	task func(ctx context.Context, txOrm TxOrmer) error) error {
 	_txOrm, err := o.BeginWithCtxAndOpts(ctx, opts)
@@ -608,15 +792,49 @@ type txOrm struct {
 var _ TxOrmer = new(txOrm)
 
 func (t *txOrm) Commit() error {
-	return t.db.(txEnder).Commit()
+	err := t.db.(txEnder).Commit()
+	t.endChanges(err == nil)
+	return err
 }
 
 func (t *txOrm) Rollback() error {
-	return t.db.(txEnder).Rollback()
+	err := t.db.(txEnder).Rollback()
+	t.endChanges(false)
+	return err
 }
 
 func (t *txOrm) RollbackUnlessCommit() error {
-	return t.db.(txEnder).RollbackUnlessCommit()
+	err := t.db.(txEnder).RollbackUnlessCommit()
+	t.endChanges(false)
+	return err
+}
+
+func (t *txOrm) endChanges(commit bool) {
+	if t.changes != nil {
+		t.changes.end(commit)
+	}
+}
+
+func (t *txOrm) OnCommit(fn func()) {
+	t.changes.addFunc(true, fn)
+}
+
+func (t *txOrm) OnRollback(fn func()) {
+	t.changes.addFunc(false, fn)
+}
+
+func (t *txOrm) WithReadCache() TxOrmer {
+	if _, ok := t.db.(*dbReadCache); ok {
+		return t
+	}
+	return &txOrm{
+		ormBase: ormBase{
+			alias:    t.alias,
+			db:       newDbReadCache(t.db),
+			changes:  t.changes,
+			readOnly: t.readOnly,
+		},
+	}
 }
 
 // NewOrm create new orm
@@ -0,0 +1,81 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// the interval of retrying to take an advisory lock held by another.
+var advisoryLockRetryInterval = 50 * time.Millisecond
+
+// AdvisoryLock see AdvisoryLocker, the lock is tried on a connection of the db of o until taken or timeout.
+func (o *orm) AdvisoryLock(ctx context.Context, key string, timeout time.Duration) (func() error, bool, error) {
+	lockSQL, unlockSQL := o.alias.DbBaser.AdvisoryLockSQL()
+	if lockSQL == "" {
+		return nil, false, ErrNotImplement
+	}
+	o.alias.DbBaser.ReplaceMarks(&lockSQL)
+	o.alias.DbBaser.ReplaceMarks(&unlockSQL)
+
+	conn, err := o.alias.DB.DB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		var acquired sql.NullBool
+		err = conn.QueryRowContext(ctx, lockSQL, key).Scan(&acquired)
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+		if err != nil {
+			_ = conn.Close()
+			return nil, false, err
+		}
+		if acquired.Valid && acquired.Bool {
+			break
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil, false, conn.Close()
+		}
+		if wait > advisoryLockRetryInterval {
+			wait = advisoryLockRetryInterval
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			_ = conn.Close()
+			return nil, false, ctx.Err()
+		}
+	}
+
+	unlock := func() error {
+		var released sql.NullBool
+		err := conn.QueryRowContext(context.Background(), unlockSQL, key).Scan(&released)
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+		if e := conn.Close(); err == nil {
+			err = e
+		}
+		return err
+	}
+	return unlock, true, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// CompareAndSwap sets the columns of new on the rows of the table of md with the pks,
+// only on the rows whose columns still have the values of expected, and return the pks of the swapped rows.
+// Each row is compared and swapped by its own update, WHERE pk = ? AND col = expected, so it is atomic per row,
+// a nil expected value matches NULL. The swapped rows are evicted from the entity cache and reported by one ChangeUpdate,
+// after the commit in a transaction.
+// for example:
+//
+//	swapped, err := o.CompareAndSwap(&Item{}, map[string]interface{}{"stock": 5}, map[string]interface{}{"stock": 4}, ids)
+func (o *ormBase) CompareAndSwap(md interface{}, expected, new map[string]interface{}, pks []interface{}) ([]interface{}, error) {
+	return o.CompareAndSwapWithCtx(context.Background(), md, expected, new, pks)
+}
+
+func (o *ormBase) CompareAndSwapWithCtx(ctx context.Context, md interface{}, expected, new map[string]interface{}, pks []interface{}) ([]interface{}, error) {
+	mi := o.getMi(md)
+	if mi.Fields.Pk == nil {
+		return nil, fmt.Errorf("<Ormer.CompareAndSwap> table `%s` has no pk", mi.Table)
+	}
+	if len(expected) == 0 || len(new) == 0 {
+		return nil, fmt.Errorf("<Ormer.CompareAndSwap> expected and new values cannot be empty")
+	}
+	for _, values := range []map[string]interface{}{expected, new} {
+		for col := range values {
+			if fi, ok := mi.Fields.GetByAny(col); !ok || !fi.DBcol {
+				return nil, fmt.Errorf("<Ormer.CompareAndSwap> unknown column `%s`", col)
+			}
+		}
+	}
+
+	cols := make([]string, 0, len(expected))
+	for col := range expected {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	swapped := make([]interface{}, 0, len(pks))
+	for _, pk := range pks {
+		cond := NewCondition().And(mi.Fields.Pk.Column, pk)
+		for _, col := range cols {
+			if v := expected[col]; v == nil {
+				cond = cond.And(col+ExprSep+"isnull", true)
+			} else {
+				cond = cond.And(col, v)
+			}
+		}
+		num, err := newQuerySet(o, mi).SetCond(cond).UpdateWithCtx(ctx, Params(new))
+		if err != nil {
+			return swapped, err
+		}
+		if num > 0 {
+			swapped = append(swapped, pk)
+			o.evictEntityPk(mi, pk)
+		}
+	}
+	if len(swapped) > 0 {
+		names := make([]string, 0, len(new))
+		for col := range new {
+			names = append(names, col)
+		}
+		sort.Strings(names)
+		o.notifyChangePks(mi, ChangeUpdate, names, swapped)
+	}
+	return swapped, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/beego/beego/v2/client/orm/internal/models"
+)
+
+// ChangeOp is the operation of a ChangeEvent.
+type ChangeOp string
+
+const (
+	ChangeInsert ChangeOp = "insert"
+	ChangeUpdate ChangeOp = "update"
+	ChangeDelete ChangeOp = "delete"
+	// changed by InsertOrUpdate, which may insert or update the row
+	ChangeUpsert ChangeOp = "upsert"
+)
+
+// ChangeEvent is a change of the rows of a model reported to the hooks of OnChange.
+type ChangeEvent struct {
+	// the full name of the model, such as github.com/beego/beego/v2/client/orm.User
+	Model string
+	Table string
+	Op    ChangeOp
+	// the pks of the changed models, the models without pk values are missing,
+	// such as the ones inserted by InsertMulti with bulk greater than 1.
+	Pks []interface{}
+	// the columns given to Update, empty means all columns
+	Columns []string
+}
+
+var (
+	changeHooks   []func(ev ChangeEvent)
+	changeHooksMu sync.RWMutex
+)
+
+// OnChange add the hook fn called after the successful Insert, InsertMulti, InsertMultiReturning,
+// InsertOrUpdate, Update and Delete of the models by Ormer and TxOrmer. The changes of a transaction are reported after its commit in order,
+// and dropped by its rollback, the changes rolled back to a savepoint are still reported.
+// Like the EntityCacher, the writes by QuerySeter, QueryM2Mer and Raw are not reported.
+// The hooks are called in the goroutine of the write or the commit, in the order they are added.
+func OnChange(fn func(ev ChangeEvent)) {
+	changeHooksMu.Lock()
+	defer changeHooksMu.Unlock()
+	changeHooks = append(changeHooks, fn)
+}
+
+func hasChangeHooks() bool {
+	changeHooksMu.RLock()
+	defer changeHooksMu.RUnlock()
+	return len(changeHooks) > 0
+}
+
+func fireChange(ev ChangeEvent) {
+	changeHooksMu.RLock()
+	hooks := changeHooks
+	changeHooksMu.RUnlock()
+	for _, fn := range hooks {
+		fn(ev)
+	}
+}
+
+// the changes of a transaction waiting for its end, with the functions to run on its outcome.
+type pendingChanges struct {
+	mu     sync.Mutex
+	events []ChangeEvent
+	// the entity cache entries deleted by the commit, before the events fire
+	evictions  []pendingEviction
+	onCommit   []func()
+	onRollback []func()
+}
+
+// the key of a model to delete from an entity cache.
+type pendingEviction struct {
+	cache EntityCacher
+	key   string
+}
+
+func (p *pendingChanges) add(ev ChangeEvent) {
+	p.mu.Lock()
+	p.events = append(p.events, ev)
+	p.mu.Unlock()
+}
+
+func (p *pendingChanges) addEviction(cache EntityCacher, key string) {
+	p.mu.Lock()
+	p.evictions = append(p.evictions, pendingEviction{cache: cache, key: key})
+	p.mu.Unlock()
+}
+
+func (p *pendingChanges) addFunc(commit bool, fn func()) {
+	p.mu.Lock()
+	if commit {
+		p.onCommit = append(p.onCommit, fn)
+	} else {
+		p.onRollback = append(p.onRollback, fn)
+	}
+	p.mu.Unlock()
+}
+
+// remove the pending changes, evicting and firing them if commit,
+// then run the functions of the outcome and drop the others.
+func (p *pendingChanges) end(commit bool) {
+	p.mu.Lock()
+	events, evictions, fns := p.events, p.evictions, p.onRollback
+	if commit {
+		fns = p.onCommit
+	}
+	p.events, p.evictions, p.onCommit, p.onRollback = nil, nil, nil, nil
+	p.mu.Unlock()
+	if commit {
+		for _, e := range evictions {
+			e.cache.Delete(e.key)
+		}
+		for _, ev := range events {
+			fireChange(ev)
+		}
+	}
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// report the change of the models inds, queued until the commit in a transaction.
+func (o *ormBase) notifyChange(mi *models.ModelInfo, op ChangeOp, cols []string, inds ...reflect.Value) {
+	if !hasChangeHooks() {
+		return
+	}
+	var pks []interface{}
+	if mi.Fields.Pk != nil {
+		for _, ind := range inds {
+			if _, pk, exist := getExistPk(mi, ind); exist {
+				pks = append(pks, pk)
+			}
+		}
+	}
+	o.notifyChangePks(mi, op, cols, pks)
+}
+
+// report the change of the rows of mi whose pk is in pks, like notifyChange.
+func (o *ormBase) notifyChangePks(mi *models.ModelInfo, op ChangeOp, cols []string, pks []interface{}) {
+	if !hasChangeHooks() {
+		return
+	}
+	ev := ChangeEvent{Model: mi.FullName, Table: mi.Table, Op: op, Columns: cols, Pks: pks}
+	if o.changes != nil {
+		o.changes.add(ev)
+		return
+	}
+	fireChange(ev)
+}
@@ -16,9 +16,12 @@ package orm
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/beego/beego/v2/client/orm/clauses"
+	"github.com/beego/beego/v2/client/orm/clauses/order_clause"
+	"github.com/beego/beego/v2/client/orm/internal/models"
 )
 
 // ExprSep define the expression separation
@@ -35,8 +38,415 @@ type condValue struct {
 	isCond bool
 	isRaw  bool
 	sql    string
+	// the default value of COALESCE on the column, used when set
+	coalesce []interface{}
+	// the collation of the column in the comparison
+	collate string
+	// the type of castTypes the column is cast to in the comparison
+	cast string
+	// the columns concatenated after the column, with concatSep between each, in the comparison
+	concat    []string
+	concatSep string
+	// the column is rounded to round decimals in the comparison
+	rounded bool
+	round   int
+	// the timezone the time column is converted to in the comparison
+	timezone string
+	// the column is within tolerance of target, by the absolute difference
+	approx    bool
+	target    float64
+	tolerance float64
+	// keep the top rows of each group, set by TopNPerGroup
+	topN *topNPerGroup
+	// compare the window aggregate of the rows, set by FilterWindow
+	window *windowFilter
+	// the relation field having related rows, set by FilterHasRelated, isNot for having none
+	hasRelated *models.FieldInfo
+	// the bare boolean column is compared to the true literal of the driver, set by a Filter without args
+	boolean bool
+	// the rows of the subtree of a root, set by Descendants
+	tree *descendantsTree
 }
 
+// the rows of the subtree under the row whose pk column is root, linked to their parent by the parent column.
+type descendantsTree struct {
+	pk     *models.FieldInfo
+	parent *models.FieldInfo
+	root   interface{}
+}
+
+// the first n rows of each partition ordered by order among the rows matching cond.
+type topNPerGroup struct {
+	partition []string
+	order     *order_clause.Order
+	n         int
+	cond      *Condition
+}
+
+// WindowAggr is the aggregate Func, one of SUM, AVG, COUNT, MIN and MAX, of Column over the rows
+// partitioned by Partition and ordered by OrderBy, framed by the Preceding rows and the current row,
+// such as the 7-day rolling sum of daily rows with Preceding 6.
+// A zero Preceding has no frame, the aggregate runs over all the rows before.
+type WindowAggr struct {
+	Func      string
+	Column    string
+	Partition []string
+	OrderBy   string
+	Preceding int
+}
+
+// the window aggregate compared by operator with value among the rows matching cond.
+type windowFilter struct {
+	aggr     WindowAggr
+	operator string
+	value    interface{}
+	cond     *Condition
+}
+
+var windowFuncs = map[string]bool{
+	"SUM":   true,
+	"AVG":   true,
+	"COUNT": true,
+	"MIN":   true,
+	"MAX":   true,
+}
+
+// Similarity is the value of the similar operator,
+// matching the rows whose trigram similarity with Text is greater than Threshold.
+// it needs postgres with the pg_trgm extension.
+type Similarity struct {
+	Text      string
+	Threshold float64
+}
+
+// Similar return the Similarity of text and threshold.
+// for example:
+//
+//	qs.Filter("name__similar", orm.Similar("john", 0.3))
+//	//sql-> WHERE similarity(T0."name", $1) > $2
+func Similar(text string, threshold float64) Similarity {
+	return Similarity{Text: text, Threshold: threshold}
+}
+
+// SubstringPos is the value of the contains_at operator,
+// matching the rows in which Substr occurs at or after the 1-based position Pos.
+type SubstringPos struct {
+	Substr string
+	Pos    int
+}
+
+// SubstrPos return the SubstringPos of substr and pos.
+// for example:
+//
+//	qs.Filter("notes__contains_at", orm.SubstrPos("foo", 10))
+//	//sql-> WHERE LOCATE(?, T0.`notes`, 10) > 0 in mysql
+func SubstrPos(substr string, pos int) SubstringPos {
+	return SubstringPos{Substr: substr, Pos: pos}
+}
+
+// Modulo is the value of the mod operator,
+// matching the rows whose column divided by Divisor leaves Remainder.
+type Modulo struct {
+	Divisor   int64
+	Remainder int64
+}
+
+// Mod return the Modulo of divisor and remainder, for sampling every divisor-th row or sharding by the column.
+// for example:
+//
+//	qs.Filter("id__mod", orm.Mod(10, 3))
+//	//sql-> WHERE (T0.`id` % ?) = ?
+func Mod(divisor, remainder int64) Modulo {
+	if divisor == 0 {
+		panic(fmt.Errorf("<orm.Mod> divisor cannot be 0"))
+	}
+	return Modulo{Divisor: divisor, Remainder: remainder}
+}
+
+// FnValue is a value with a sql function applied, compared with a column in Filter.
+type FnValue struct {
+	name string
+	arg  interface{}
+}
+
+// Fn return the FnValue of the sql function name applied to arg,
+// name is one of LOWER, UPPER, TRIM, LTRIM, RTRIM and ABS.
+// apply the function to the column by the transform of the same name in lower case.
+// for example:
+//
+//	qs.Filter("email__lower", orm.Fn("LOWER", email))
+//	//sql-> WHERE LOWER(T0.`email`) = LOWER(?)
+func Fn(name string, arg interface{}) FnValue {
+	name = strings.ToUpper(name)
+	if !sqlFunctions[name] {
+		panic(fmt.Errorf("<orm.Fn> sql function `%s` is not allowed", name))
+	}
+	return FnValue{name: name, arg: arg}
+}
+
+// TypedValue is a value sent as a parameter cast to an explicit sql type, compared with a column in Filter.
+type TypedValue struct {
+	arg interface{}
+	typ string
+}
+
+// sql types written to sql as is, such as nvarchar(50) or decimal(10, 2)
+var typedRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_ ]*(\(\s*(\d+|max|MAX)\s*(,\s*\d+\s*)?\))?$`)
+
+// Typed return the TypedValue of arg cast to the sql type typ of the driver,
+// for the drivers inferring another type of the parameter than the one of the column,
+// which converts the column in the comparison and cannot use its index.
+// It can be compared by the exact, eq, ne, gt, gte, lt, lte, in and between operators.
+// for example:
+//
+//	qs.Filter("name", orm.Typed(name, "nvarchar(50)"))
+//	//sql-> WHERE T0.`name` = CAST(? AS NVARCHAR(50))
+func Typed(arg interface{}, typ string) TypedValue {
+	typ = strings.TrimSpace(typ)
+	if !typedRegexp.MatchString(typ) {
+		panic(fmt.Errorf("<orm.Typed> wrong sql type `%s`", typ))
+	}
+	if arg == nil {
+		panic(fmt.Errorf("<orm.Typed> arg cannot be nil"))
+	}
+	return TypedValue{arg: arg, typ: strings.ToUpper(typ)}
+}
+
+// IntervalUnit is the unit of the intervals of DBTime.
+type IntervalUnit string
+
+// the units of the intervals of DBTime.
+const (
+	Second IntervalUnit = "second"
+	Minute IntervalUnit = "minute"
+	Hour   IntervalUnit = "hour"
+	Day    IntervalUnit = "day"
+	Month  IntervalUnit = "month"
+)
+
+// DBTime is the current time of the database plus some intervals,
+// comparing a time column with it needs no value from the app's clock.
+type DBTime struct {
+	intervals []dbInterval
+}
+
+type dbInterval struct {
+	amount int
+	unit   IntervalUnit
+}
+
+// Now return the DBTime of the current time of the database.
+// for example:
+//
+//	qs.Filter("Expires__before", orm.Now().Minus(7, orm.Day))
+//	// mysql sql-> WHERE T0.`expires` < NOW() - INTERVAL 7 DAY
+func Now() DBTime {
+	return DBTime{}
+}
+
+// Plus return the DBTime of t later by amount of unit.
+func (t DBTime) Plus(amount int, unit IntervalUnit) DBTime {
+	switch unit {
+	case Second, Minute, Hour, Day, Month:
+	default:
+		panic(fmt.Errorf("<orm.DBTime> unsupported interval unit `%s`", unit))
+	}
+	intervals := make([]dbInterval, 0, len(t.intervals)+1)
+	intervals = append(intervals, t.intervals...)
+	return DBTime{intervals: append(intervals, dbInterval{amount: amount, unit: unit})}
+}
+
+// Minus return the DBTime of t earlier by amount of unit.
+func (t DBTime) Minus(amount int, unit IntervalUnit) DBTime {
+	return t.Plus(-amount, unit)
+}
+
+// Aggregation is an aggregate function of a column selected by AggregateBy.
+type Aggregation struct {
+	fn           string
+	column       string
+	alias        string
+	coalesceZero bool
+	// the fraction of the PERCENTILE_CONT aggregation
+	percentile float64
+	// the separator and the order of the values of the GROUP_CONCAT aggregation
+	separator string
+	orderBy   string
+}
+
+// AggregationOption configure an Aggregation.
+type AggregationOption func(a *Aggregation)
+
+// CoalesceZero aggregate the NULL values of the column as 0.
+func CoalesceZero() AggregationOption {
+	return func(a *Aggregation) {
+		a.coalesceZero = true
+	}
+}
+
+// As select the aggregation as alias, fn_column by default, such as avg_rating.
+func As(alias string) AggregationOption {
+	return func(a *Aggregation) {
+		a.alias = alias
+	}
+}
+
+func newAggregation(fn string, column string, opts []AggregationOption) Aggregation {
+	a := Aggregation{fn: fn, column: column}
+	a.alias = strings.ToLower(fn) + "_" + strings.ReplaceAll(column, ExprSep, "_")
+	if column == "*" {
+		a.alias = strings.ToLower(fn)
+	}
+	for _, opt := range opts {
+		opt(&a)
+	}
+	return a
+}
+
+// Avg return the AVG aggregation of column.
+// for example:
+//
+//	qs.AggregateBy([]string{"product_id"}, orm.Avg("rating", orm.CoalesceZero())).All(&res)
+//	//sql-> SELECT T0.`product_id`, AVG(COALESCE(T0.`rating`, 0)) `avg_rating` ... GROUP BY T0.`product_id`
+func Avg(column string, opts ...AggregationOption) Aggregation {
+	return newAggregation("AVG", column, opts)
+}
+
+// Sum return the SUM aggregation of column.
+func Sum(column string, opts ...AggregationOption) Aggregation {
+	return newAggregation("SUM", column, opts)
+}
+
+// Min return the MIN aggregation of column.
+func Min(column string, opts ...AggregationOption) Aggregation {
+	return newAggregation("MIN", column, opts)
+}
+
+// Max return the MAX aggregation of column.
+func Max(column string, opts ...AggregationOption) Aggregation {
+	return newAggregation("MAX", column, opts)
+}
+
+// Count return the COUNT aggregation of column, counting its not NULL values.
+// Count("*") counts the rows, selected as count by default.
+func Count(column string, opts ...AggregationOption) Aggregation {
+	return newAggregation("COUNT", column, opts)
+}
+
+// Percentile return the continuous percentile p of column, interpolated between its values, p is from 0 to 1.
+// its default alias is percentile_column, not supported by mysql and sqlite.
+// for example:
+//
+//	qs.AggregateBy([]string{"product_id"}, orm.Percentile("rating", 0.5, orm.As("median"))).All(&res)
+//	//postgres sql-> SELECT T0."product_id", PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY T0."rating") "median" ...
+func Percentile(column string, p float64, opts ...AggregationOption) Aggregation {
+	if p < 0 || p > 1 {
+		panic(fmt.Errorf("<orm.Percentile> percentile must be between 0 and 1, not %v", p))
+	}
+	if column == "*" {
+		panic(fmt.Errorf("<orm.Percentile> need a column"))
+	}
+	opts = append([]AggregationOption{As("percentile_" + strings.ReplaceAll(column, ExprSep, "_"))}, opts...)
+	a := newAggregation("PERCENTILE_CONT", column, opts)
+	a.percentile = p
+	return a
+}
+
+// GroupConcat return the values of column in a group joined by sep into a string, in the order of the orderBy column,
+// descending if it starts with -, in no specific order when orderBy is empty. its default alias is group_concat_column.
+// sql : GROUP_CONCAT(col ORDER BY o SEPARATOR sep) on mysql, string_agg(col::text, sep ORDER BY o) on postgres,
+// LISTAGG(col, sep) WITHIN GROUP (ORDER BY o) on oracle, sqlite needs 3.44+ to order the values.
+// for example:
+//
+//	qs.AggregateBy([]string{"user_id"}, orm.GroupConcat("title", ", ", "-created", orm.As("titles"))).All(&res)
+//	//mysql sql-> SELECT T0.`user_id`, GROUP_CONCAT(T0.`title` ORDER BY T0.`created` DESC SEPARATOR ', ') `titles` ...
+func GroupConcat(column string, sep string, orderBy string, opts ...AggregationOption) Aggregation {
+	if column == "*" {
+		panic(fmt.Errorf("<orm.GroupConcat> need a column"))
+	}
+	a := newAggregation("GROUP_CONCAT", column, opts)
+	a.separator = sep
+	a.orderBy = orderBy
+	return a
+}
+
+// Gt return the HAVING condition of the aggregation greater than value.
+func (a Aggregation) Gt(value interface{}) HavingCond {
+	return HavingCond{aggrs: []Aggregation{a}, operator: ">", value: value}
+}
+
+// Gte return the HAVING condition of the aggregation greater than or equal to value.
+func (a Aggregation) Gte(value interface{}) HavingCond {
+	return HavingCond{aggrs: []Aggregation{a}, operator: ">=", value: value}
+}
+
+// Lt return the HAVING condition of the aggregation less than value.
+func (a Aggregation) Lt(value interface{}) HavingCond {
+	return HavingCond{aggrs: []Aggregation{a}, operator: "<", value: value}
+}
+
+// Lte return the HAVING condition of the aggregation less than or equal to value.
+func (a Aggregation) Lte(value interface{}) HavingCond {
+	return HavingCond{aggrs: []Aggregation{a}, operator: "<=", value: value}
+}
+
+// AggregationRatio is the ratio of two aggregations, compared in HAVING by QuerySeter.Having.
+type AggregationRatio struct {
+	numerator   Aggregation
+	denominator Aggregation
+}
+
+// Ratio return the ratio numerator / denominator of the aggregations.
+// The denominator is guarded by NULLIF, so the ratio is NULL instead of failing when it is 0
+// and the groups are not matched. The ratio is computed as decimal, not as integer division.
+// for example:
+//
+//	qs.AggregateBy([]string{"campaign"}, orm.Count("*")).Having(orm.Ratio(orm.Sum("converted"), orm.Count("*")).Gt(0.5))
+//	//sql-> ... GROUP BY T0.`campaign` HAVING 1.0 * SUM(T0.`converted`) / NULLIF(COUNT(*), 0) > ?
+func Ratio(numerator, denominator Aggregation) AggregationRatio {
+	return AggregationRatio{numerator: numerator, denominator: denominator}
+}
+
+// Gt return the HAVING condition of the ratio greater than value.
+func (r AggregationRatio) Gt(value interface{}) HavingCond {
+	return HavingCond{aggrs: []Aggregation{r.numerator, r.denominator}, operator: ">", value: value}
+}
+
+// Gte return the HAVING condition of the ratio greater than or equal to value.
+func (r AggregationRatio) Gte(value interface{}) HavingCond {
+	return HavingCond{aggrs: []Aggregation{r.numerator, r.denominator}, operator: ">=", value: value}
+}
+
+// Lt return the HAVING condition of the ratio less than value.
+func (r AggregationRatio) Lt(value interface{}) HavingCond {
+	return HavingCond{aggrs: []Aggregation{r.numerator, r.denominator}, operator: "<", value: value}
+}
+
+// Lte return the HAVING condition of the ratio less than or equal to value.
+func (r AggregationRatio) Lte(value interface{}) HavingCond {
+	return HavingCond{aggrs: []Aggregation{r.numerator, r.denominator}, operator: "<=", value: value}
+}
+
+// HavingCond is a comparison of an aggregation or of a ratio of aggregations, used by QuerySeter.Having.
+type HavingCond struct {
+	// one aggregation, or the numerator and the denominator of a ratio
+	aggrs    []Aggregation
+	operator string
+	value    interface{}
+}
+
+// CaseWhen is a branch of the CASE expression of QuerySeter.ValuesCase, selecting Then when Cond is true.
+type CaseWhen struct {
+	Cond *Condition
+	Then interface{}
+}
+
+// timezone names and offsets are written to sql as is, such as America/New_York or +08:00
+var timezoneRegexp = regexp.MustCompile(`^[A-Za-z0-9_+\-:/]+$`)
+
+// collation names are written to sql as is, such as utf8mb4_bin, NOCASE or "en_US"
+var collationRegexp = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$|^"[A-Za-z0-9_.\-]+"$`)
+
 // Condition struct.
 // work for WHERE conditions.
 type Condition struct {
@@ -67,6 +477,96 @@ func (c Condition) And(expr string, args ...interface{}) *Condition {
 	return &c
 }
 
+// add expression comparing COALESCE(column, def) to condition
+func (c Condition) andCoalesce(expr string, def interface{}, args ...interface{}) *Condition {
+	if expr == "" || len(args) == 0 {
+		panic(fmt.Errorf("<Condition.And> args cannot empty"))
+	}
+	c.params = append(c.params, condValue{exprs: strings.Split(expr, ExprSep), args: args, coalesce: []interface{}{def}})
+	return &c
+}
+
+// add expression comparing the column with COLLATE collation to condition
+func (c Condition) andCollate(expr string, collation string, args ...interface{}) *Condition {
+	if expr == "" || len(args) == 0 {
+		panic(fmt.Errorf("<Condition.And> args cannot empty"))
+	}
+	if !collationRegexp.MatchString(collation) {
+		panic(fmt.Errorf("<Condition.And> wrong collation `%s`", collation))
+	}
+	c.params = append(c.params, condValue{exprs: strings.Split(expr, ExprSep), args: args, collate: collation})
+	return &c
+}
+
+// add expression of the boolean column being true, or being not true when not, to condition
+func (c Condition) andBool(expr string, not bool) *Condition {
+	if expr == "" {
+		panic(fmt.Errorf("<Condition.And> args cannot empty"))
+	}
+	c.params = append(c.params, condValue{exprs: strings.Split(expr, ExprSep), boolean: true, isNot: not})
+	return &c
+}
+
+// add expression comparing the column cast to castType to condition
+func (c Condition) andCast(expr string, castType string, args ...interface{}) *Condition {
+	if expr == "" || len(args) == 0 {
+		panic(fmt.Errorf("<Condition.And> args cannot empty"))
+	}
+	if !castTypes[castType] {
+		panic(fmt.Errorf("<Condition.And> unknown cast type `%s`", castType))
+	}
+	c.params = append(c.params, condValue{exprs: strings.Split(expr, ExprSep), args: args, cast: castType})
+	return &c
+}
+
+// add expression comparing the column concatenated with the columns cols by sep to condition
+func (c Condition) andConcat(expr string, cols []string, sep string, args ...interface{}) *Condition {
+	if expr == "" || len(args) == 0 {
+		panic(fmt.Errorf("<Condition.And> args cannot empty"))
+	}
+	if len(cols) == 0 {
+		panic(fmt.Errorf("<Condition.And> columns to concatenate cannot empty"))
+	}
+	c.params = append(c.params, condValue{exprs: strings.Split(expr, ExprSep), args: args, concat: cols, concatSep: sep})
+	return &c
+}
+
+// add expression comparing the column rounded to decimals to condition
+func (c Condition) andRound(expr string, decimals int, args ...interface{}) *Condition {
+	if expr == "" || len(args) == 0 {
+		panic(fmt.Errorf("<Condition.And> args cannot empty"))
+	}
+	if decimals < 0 {
+		panic(fmt.Errorf("<Condition.And> decimals to round cannot be negative"))
+	}
+	c.params = append(c.params, condValue{exprs: strings.Split(expr, ExprSep), args: args, rounded: true, round: decimals})
+	return &c
+}
+
+// add expression of the column within tolerance of target to condition
+func (c Condition) andApprox(column string, target float64, tolerance float64) *Condition {
+	if column == "" {
+		panic(fmt.Errorf("<Condition.And> args cannot empty"))
+	}
+	if tolerance < 0 {
+		panic(fmt.Errorf("<Condition.And> tolerance cannot be negative"))
+	}
+	c.params = append(c.params, condValue{exprs: strings.Split(column, ExprSep), approx: true, target: target, tolerance: tolerance})
+	return &c
+}
+
+// add expression comparing the time column converted to the timezone tz to condition
+func (c Condition) andTimezone(expr string, tz string, args ...interface{}) *Condition {
+	if expr == "" || len(args) == 0 {
+		panic(fmt.Errorf("<Condition.And> args cannot empty"))
+	}
+	if !timezoneRegexp.MatchString(tz) {
+		panic(fmt.Errorf("<Condition.And> wrong timezone `%s`", tz))
+	}
+	c.params = append(c.params, condValue{exprs: strings.Split(expr, ExprSep), args: args, timezone: tz})
+	return &c
+}
+
 // AndNot add NOT expression to condition
 func (c Condition) AndNot(expr string, args ...interface{}) *Condition {
 	if expr == "" || len(args) == 0 {
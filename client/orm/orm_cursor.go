@@ -0,0 +1,242 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm/clauses/order_clause"
+	"github.com/beego/beego/v2/client/orm/internal/models"
+)
+
+// Cursor iterates the rows of a QuerySeter by pages of its limit, ordered by the columns of Cursor.
+// Every page is read after the position of the last row, instead of an offset,
+// so the iteration can be resumed from a Position saved before, even by another process.
+type Cursor struct {
+	qs   querySet
+	cols []cursorCol
+	// the values of the columns of the last row
+	position []interface{}
+	page     reflect.Value
+	index    int
+	done     bool
+	err      error
+}
+
+// a time arg bound as is, to compare at the precision the time was written with
+// instead of formatted to the seconds of the column type.
+type exactTime time.Time
+
+// a column of the order of a cursor.
+type cursorCol struct {
+	fi   *models.FieldInfo
+	desc bool
+}
+
+// Cursor return the Cursor of the rows ordered by orderCols, "-column" means DESC,
+// the pk is added as the last column when it is not in orderCols so the order is unique.
+// for example:
+//
+//	cursor := qs.Limit(500).Cursor([]string{"-created"})
+//	cursor.Seek(saved)
+//	var user User
+//	for {
+//		ok, err := cursor.Next(&user)
+//		if err != nil || !ok { ... }
+//		saved = cursor.Position()
+//	}
+func (o querySet) Cursor(orderCols []string) *Cursor {
+	c := &Cursor{qs: o, err: o.err}
+	hasPk := false
+	for _, col := range orderCols {
+		desc := col != "" && col[0] == '-'
+		if desc {
+			col = col[1:]
+		}
+		fi, ok := o.mi.Fields.GetByAny(col)
+		if !ok || !fi.DBcol || fi.Rel {
+			if c.err == nil {
+				c.err = fmt.Errorf("<QuerySeter.Cursor> unknown column `%s` of model `%s`", col, o.mi.Name)
+			}
+			return c
+		}
+		hasPk = hasPk || fi.Pk
+		c.cols = append(c.cols, cursorCol{fi: fi, desc: desc})
+	}
+	if !hasPk {
+		c.cols = append(c.cols, cursorCol{fi: o.mi.Fields.Pk})
+	}
+	return c
+}
+
+// Next read the next row to the model container, false after the last row.
+func (c *Cursor) Next(container interface{}) (bool, error) {
+	return c.NextWithCtx(context.Background(), container)
+}
+
+func (c *Cursor) NextWithCtx(ctx context.Context, container interface{}) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	ind := reflect.Indirect(reflect.ValueOf(container))
+	if reflect.ValueOf(container).Kind() != reflect.Ptr || ind.Type() != reflect.Indirect(c.qs.mi.AddrField).Type() {
+		return false, fmt.Errorf("<Cursor.Next> container must be a pointer to model `%s`", c.qs.mi.FullName)
+	}
+	if !c.page.IsValid() || c.index >= c.page.Len() {
+		if c.done {
+			return false, nil
+		}
+		if err := c.readPage(ctx); err != nil {
+			return false, err
+		}
+		if c.page.Len() == 0 {
+			return false, nil
+		}
+	}
+
+	row := c.page.Index(c.index).Elem()
+	c.index++
+	ind.Set(row)
+	c.position = make([]interface{}, 0, len(c.cols))
+	for _, col := range c.cols {
+		v := row.FieldByIndex(col.fi.FieldIndex)
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		c.position = append(c.position, v.Interface())
+	}
+	return true, nil
+}
+
+// read the page of the rows after the position.
+func (c *Cursor) readPage(ctx context.Context) error {
+	qs := c.qs
+	if qs.limit <= 0 {
+		qs.limit = int64(DefaultRowsLimit)
+	}
+	qs.offset = 0
+	qs.orders = make([]*order_clause.Order, 0, len(c.cols))
+	for _, col := range c.cols {
+		sort := order_clause.SortAscending()
+		if col.desc {
+			sort = order_clause.SortDescending()
+		}
+		qs.orders = append(qs.orders, order_clause.Clause(order_clause.Column(col.fi.Name), sort))
+	}
+	if c.position != nil {
+		after := c.afterCond()
+		if qs.cond == nil {
+			qs.cond = after
+		} else {
+			qs.cond = qs.cond.AndCond(after)
+		}
+	}
+
+	rows := reflect.New(reflect.SliceOf(reflect.PtrTo(reflect.Indirect(c.qs.mi.AddrField).Type())))
+	num, err := qs.AllWithCtx(ctx, rows.Interface())
+	if err != nil && err != ErrNoRows {
+		return err
+	}
+	c.page, c.index = rows.Elem(), 0
+	c.done = num < qs.limit
+	return nil
+}
+
+// the condition of the rows after the position in the order of the columns,
+// such as (a > ?) OR (a = ? AND b > ?).
+func (c *Cursor) afterCond() *Condition {
+	cond := NewCondition()
+	for i, col := range c.cols {
+		op := "gt"
+		if col.desc {
+			op = "lt"
+		}
+		sub := NewCondition()
+		for j := 0; j < i; j++ {
+			sub = sub.And(c.cols[j].fi.Name, c.positionArg(j))
+		}
+		cond = cond.OrCond(sub.And(col.fi.Name+ExprSep+op, c.positionArg(i)))
+	}
+	return cond
+}
+
+// the arg of the value of the column i of the position, the times are bound like they are written.
+func (c *Cursor) positionArg(i int) interface{} {
+	if t, ok := c.position[i].(time.Time); ok {
+		c.qs.orm.alias.DbBaser.TimeToDB(&t, c.qs.orm.alias.TZ)
+		return exactTime(t)
+	}
+	return c.position[i]
+}
+
+// Position return the json position after the last row read by Next, nil before the first one.
+func (c *Cursor) Position() []byte {
+	if c.position == nil {
+		return nil
+	}
+	position, err := json.Marshal(c.position)
+	if err != nil {
+		return nil
+	}
+	return position
+}
+
+// Seek move the cursor to the position of Position, the next row is the one after it.
+// A nil position seeks to the first row.
+func (c *Cursor) Seek(position []byte) error {
+	c.page, c.index, c.done = reflect.Value{}, 0, false
+	if position == nil {
+		c.position = nil
+		return nil
+	}
+
+	var values []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(position))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return fmt.Errorf("<Cursor.Seek> invalid position: %w", err)
+	}
+	if len(values) != len(c.cols) {
+		return fmt.Errorf("<Cursor.Seek> position has %d values, not the %d of the columns", len(values), len(c.cols))
+	}
+	for i, v := range values {
+		switch val := v.(type) {
+		case json.Number:
+			if n, err := val.Int64(); err == nil {
+				values[i] = n
+			} else if f, err := val.Float64(); err == nil {
+				values[i] = f
+			} else {
+				return fmt.Errorf("<Cursor.Seek> invalid number `%s`", val)
+			}
+		case string:
+			switch c.cols[i].fi.FieldType {
+			case TypeDateField, TypeDateTimeField, TypeTimeField:
+				t, err := time.Parse(time.RFC3339Nano, val)
+				if err != nil {
+					return fmt.Errorf("<Cursor.Seek> invalid time `%s`: %w", val, err)
+				}
+				values[i] = t
+			}
+		}
+	}
+	c.position = values
+	return nil
+}
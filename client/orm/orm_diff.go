@@ -0,0 +1,140 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm/internal/models"
+	iutils "github.com/beego/beego/v2/client/orm/internal/utils"
+)
+
+// Diff compares the models of the slice incoming with all the rows of their table matched by the column keyCol,
+// and partitions them into the slices, of the type of incoming, of the models to insert, which have no row of their key,
+// of the models to update, which have a row of their key with different values, and of the rows to delete,
+// which have no model of their key in incoming.
+// The pk, auto_now and auto_now_add fields are not compared, the models to update get the pk of their rows.
+// for example:
+//
+//	users := []*User{{Email: "a@example.com", Name: "a"}}
+//	toInsert, toUpdate, toDelete, err := o.Diff(users, "email")
+//	for _, u := range toUpdate.([]*User) {
+//		o.Update(u)
+//	}
+func (o *ormBase) Diff(incoming interface{}, keyCol string) (interface{}, interface{}, interface{}, error) {
+	return o.DiffWithCtx(context.Background(), incoming, keyCol)
+}
+
+func (o *ormBase) DiffWithCtx(ctx context.Context, incoming interface{}, keyCol string) (toInsert, toUpdate, toDelete interface{}, err error) {
+	sind := reflect.Indirect(reflect.ValueOf(incoming))
+	if sind.Kind() != reflect.Slice {
+		return nil, nil, nil, ErrArgs
+	}
+	typ := sind.Type()
+	mi := o.getMi(reflect.New(iutils.IndirectType(typ.Elem())).Interface())
+	key, ok := mi.Fields.GetByAny(keyCol)
+	if !ok || !key.DBcol {
+		return nil, nil, nil, fmt.Errorf("<Ormer.Diff> unknown key column `%s`", keyCol)
+	}
+
+	rows := reflect.New(reflect.SliceOf(reflect.PtrTo(iutils.IndirectType(typ.Elem()))))
+	if _, err = newQuerySet(o, mi).Limit(-1).AllWithCtx(ctx, rows.Interface()); err != nil && err != ErrNoRows {
+		return nil, nil, nil, err
+	}
+	rows = rows.Elem()
+	byKey := make(map[interface{}]int, rows.Len())
+	for i := 0; i < rows.Len(); i++ {
+		byKey[diffValue(key, rows.Index(i).Elem())] = i
+	}
+
+	inserts, updates, deletes := reflect.MakeSlice(typ, 0, 0), reflect.MakeSlice(typ, 0, 0), reflect.MakeSlice(typ, 0, 0)
+	seen := make(map[interface{}]bool, sind.Len())
+	for i := 0; i < sind.Len(); i++ {
+		ind := reflect.Indirect(sind.Index(i))
+		k := diffValue(key, ind)
+		if seen[k] {
+			return nil, nil, nil, fmt.Errorf("<Ormer.Diff> duplicated key `%v` of column `%s`", k, key.Column)
+		}
+		seen[k] = true
+		r, ok := byKey[k]
+		if !ok {
+			inserts = reflect.Append(inserts, sind.Index(i))
+			continue
+		}
+		row := rows.Index(r).Elem()
+		if !diffEqual(mi, ind, row) {
+			if pk := mi.Fields.Pk; pk != nil && pk != key {
+				ind.FieldByIndex(pk.FieldIndex).Set(row.FieldByIndex(pk.FieldIndex))
+			}
+			updates = reflect.Append(updates, sind.Index(i))
+		}
+	}
+	for i := 0; i < rows.Len(); i++ {
+		row := rows.Index(i)
+		if seen[diffValue(key, row.Elem())] {
+			continue
+		}
+		if typ.Elem().Kind() != reflect.Ptr {
+			row = row.Elem()
+		}
+		deletes = reflect.Append(deletes, row)
+	}
+	return inserts.Interface(), updates.Interface(), deletes.Interface(), nil
+}
+
+// compare the values of the db fields of the models a and b, except the pk and the auto time fields.
+func diffEqual(mi *models.ModelInfo, a, b reflect.Value) bool {
+	for _, fi := range mi.Fields.FieldsDB {
+		if fi.Pk || fi.AutoNow || fi.AutoNowAdd {
+			continue
+		}
+		va, vb := diffValue(fi, a), diffValue(fi, b)
+		if ta, ok := va.(time.Time); ok {
+			if tb, ok := vb.(time.Time); !ok || !ta.Equal(tb) {
+				return false
+			}
+			continue
+		}
+		if !reflect.DeepEqual(va, vb) {
+			return false
+		}
+	}
+	return true
+}
+
+// the comparable value of the field fi of the model ind, the pk of the related model of a rel field.
+func diffValue(fi *models.FieldInfo, ind reflect.Value) interface{} {
+	v := ind.FieldByIndex(fi.FieldIndex)
+	if fi.FieldType&IsRelField > 0 {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil
+		}
+		_, pk, _ := getExistPk(fi.RelModelInfo, reflect.Indirect(v))
+		return pkKey(pk)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return string(v.Bytes())
+	}
+	return v.Interface()
+}
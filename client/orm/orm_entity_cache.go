@@ -0,0 +1,171 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/beego/beego/v2/client/orm/internal/models"
+)
+
+// EntityCacher is the second level cache of the models read by pk, set it by EntityCache or SetEntityCache.
+// Unlike the read cache of a transaction, which is keyed by the sql and args of a query,
+// the keys are the model name and the pk value, and the values are copies of the model structs.
+//
+// Read of an Ormer by pk only gets the model from the cache, or sets it on a miss.
+// Reads with other columns, ReadForUpdate and reads in a transaction bypass it.
+// Update, Delete and InsertOrUpdate of a model delete its entry, in a transaction after its commit,
+// but writes by QuerySeter, QueryM2Mer and Raw do not, so delete their entries by hand.
+// The copies are shallow, the rel fields of the cached models are shared by every hit.
+type EntityCacher interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{})
+	Delete(key string)
+}
+
+type lruEntityCache struct {
+	cache *lru.Cache
+	ttl   time.Duration
+}
+
+// the cached model and the time it expires at, zero if it never expires.
+type lruEntityCacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// NewLRUEntityCache create a EntityCacher keeping the size most recently used models.
+func NewLRUEntityCache(size int) (EntityCacher, error) {
+	return NewLRUEntityCacheWithTTL(size, 0)
+}
+
+// NewLRUEntityCacheWithTTL create a EntityCacher keeping the size most recently used models for ttl after they are set,
+// the expired models are misses and their entries are deleted when they are got. A ttl of 0 never expires.
+func NewLRUEntityCacheWithTTL(size int, ttl time.Duration) (EntityCacher, error) {
+	if ttl < 0 {
+		return nil, fmt.Errorf("<orm.NewLRUEntityCacheWithTTL> ttl cannot be negative")
+	}
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &lruEntityCache{cache: cache, ttl: ttl}, nil
+}
+
+func (c *lruEntityCache) Get(key string) (interface{}, bool) {
+	v, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := v.(lruEntityCacheEntry)
+	if !entry.expires.IsZero() && !time.Now().Before(entry.expires) {
+		c.cache.Remove(key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *lruEntityCache) Set(key string, value interface{}) {
+	entry := lruEntityCacheEntry{value: value}
+	if c.ttl > 0 {
+		entry.expires = time.Now().Add(c.ttl)
+	}
+	c.cache.Add(key, entry)
+}
+
+func (c *lruEntityCache) Delete(key string) {
+	c.cache.Remove(key)
+}
+
+// get the key of the model ind in the entity cache, false if it has no pk value.
+func entityCacheKey(mi *models.ModelInfo, ind reflect.Value) (string, bool) {
+	if mi.Fields.Pk == nil {
+		return "", false
+	}
+	_, value, exist := getExistPk(mi, ind)
+	if !exist {
+		return "", false
+	}
+	return entityPkCacheKey(mi, value), true
+}
+
+// the key of the entity cache entry of the row of mi whose pk is pk.
+func entityPkCacheKey(mi *models.ModelInfo, pk interface{}) string {
+	return fmt.Sprintf("%s:%v", mi.FullName, pk)
+}
+
+// delete the entry of the model ind from the entity cache, queued until the commit in a transaction.
+func (o *ormBase) evictEntity(mi *models.ModelInfo, ind reflect.Value) {
+	if o.alias.EntityCache == nil {
+		return
+	}
+	if key, ok := entityCacheKey(mi, ind); ok {
+		o.evictEntityKey(key)
+	}
+}
+
+// delete the entry of the row of mi whose pk is pk, like evictEntity.
+func (o *ormBase) evictEntityPk(mi *models.ModelInfo, pk interface{}) {
+	if o.alias.EntityCache == nil {
+		return
+	}
+	o.evictEntityKey(entityPkCacheKey(mi, pk))
+}
+
+func (o *ormBase) evictEntityKey(key string) {
+	if o.changes != nil {
+		o.changes.addEviction(o.alias.EntityCache, key)
+		return
+	}
+	o.alias.EntityCache.Delete(key)
+}
+
+// read data to model, consulting the entity cache when reading by pk
+func (o *orm) Read(md interface{}, cols ...string) error {
+	return o.ReadWithCtx(context.Background(), md, cols...)
+}
+
+func (o *orm) ReadWithCtx(ctx context.Context, md interface{}, cols ...string) error {
+	cache := o.alias.EntityCache
+	if cache == nil {
+		return o.ormBase.ReadWithCtx(ctx, md, cols...)
+	}
+	mi, ind := o.getPtrMiInd(md)
+	if len(cols) > 1 || len(cols) == 1 && o.getFieldInfo(mi, cols[0]) != mi.Fields.Pk {
+		return o.ormBase.ReadWithCtx(ctx, md, cols...)
+	}
+	key, ok := entityCacheKey(mi, ind)
+	if !ok {
+		return o.ormBase.ReadWithCtx(ctx, md, cols...)
+	}
+
+	if v, ok := cache.Get(key); ok {
+		ind.Set(reflect.ValueOf(v))
+	} else {
+		if err := o.alias.DbBaser.Read(ctx, o.db, mi, ind, o.alias.TZ, cols, false); err != nil {
+			return err
+		}
+		cache.Set(key, ind.Interface())
+	}
+	if len(mi.LazyFields) > 0 {
+		o.bindLazy(ctx, mi, ind)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"time"
+)
+
+// HealthReport is the health of all the registered db aliases, by HealthCheck.
+// It is healthy if all the aliases are.
+type HealthReport struct {
+	Healthy bool                   `json:"healthy"`
+	Aliases map[string]AliasHealth `json:"aliases"`
+}
+
+// AliasHealth is the health of a db alias, its ping and the state of its connection pool.
+type AliasHealth struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+	// the duration of the ping
+	Latency            time.Duration `json:"latency"`
+	OpenConnections    int           `json:"open_connections"`
+	InUse              int           `json:"in_use"`
+	Idle               int           `json:"idle"`
+	MaxOpenConnections int           `json:"max_open_connections"`
+	// the connections in use out of MaxOpenConnections, 0 if it is unlimited
+	Saturation float64 `json:"saturation"`
+	// the total number of connections waited for and the time waited
+	WaitCount    int64         `json:"wait_count"`
+	WaitDuration time.Duration `json:"wait_duration"`
+}
+
+// HealthCheck ping all the registered db aliases and report their health with the stats of their pools.
+func (o *orm) HealthCheck(ctx context.Context) HealthReport {
+	report := HealthReport{Healthy: true, Aliases: make(map[string]AliasHealth)}
+	for _, al := range dataBaseCache.all() {
+		health := aliasHealth(ctx, al)
+		report.Healthy = report.Healthy && health.Healthy
+		report.Aliases[al.Name] = health
+	}
+	return report
+}
+
+func aliasHealth(ctx context.Context, al *alias) AliasHealth {
+	var health AliasHealth
+	if al.DB == nil || al.DB.DB == nil {
+		health.Error = "db is not opened"
+		return health
+	}
+	start := time.Now()
+	err := al.DB.DB.PingContext(ctx)
+	health.Latency = time.Since(start)
+	if err != nil {
+		health.Error = err.Error()
+	} else {
+		health.Healthy = true
+	}
+
+	stats := al.DB.DB.Stats()
+	health.OpenConnections = stats.OpenConnections
+	health.InUse = stats.InUse
+	health.Idle = stats.Idle
+	health.MaxOpenConnections = stats.MaxOpenConnections
+	if stats.MaxOpenConnections > 0 {
+		health.Saturation = float64(stats.InUse) / float64(stats.MaxOpenConnections)
+	}
+	health.WaitCount = stats.WaitCount
+	health.WaitDuration = stats.WaitDuration
+	return health
+}
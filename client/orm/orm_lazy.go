@@ -0,0 +1,115 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/beego/beego/v2/client/orm/internal/models"
+)
+
+// ErrLazyNotBound is returned by Lazy.Get when the model was not read by an Ormer.
+var ErrLazyNotBound = errors.New("<Lazy.Get> lazy field is not bound, read the model by Ormer first")
+
+// Lazy loads a relation of the model on the first Get, declare it by the lazy tag:
+//
+//	type Comment struct {
+//		Id       int
+//		Post     *Post `orm:"rel(fk)"`
+//		LazyPost orm.Lazy `orm:"lazy(Post)"`
+//	}
+//
+// Read, ReadForUpdate, One and All bind it with the Ormer and the context used to read the model.
+// The first Get runs LoadRelated with them, so a TxOrmer must still be open and the context
+// not canceled by then, and the filters of the Ormer are not applied.
+// Get is safe for concurrent use and loads only once, but the load sets the relation field
+// of the model, which must not be accessed concurrently with the first Get.
+type Lazy struct {
+	loader *lazyLoader
+}
+
+type lazyLoader struct {
+	once  sync.Once
+	load  func() (interface{}, error)
+	value interface{}
+	err   error
+}
+
+// Get return the value of the relation field, loading it on the first call.
+func (l *Lazy) Get() (interface{}, error) {
+	if l.loader == nil {
+		return nil, ErrLazyNotBound
+	}
+	l.loader.once.Do(func() {
+		l.loader.value, l.loader.err = l.loader.load()
+	})
+	return l.loader.value, l.loader.err
+}
+
+func (l *Lazy) bindLazy(load func() (interface{}, error)) {
+	l.loader = &lazyLoader{load: load}
+}
+
+type lazyBinder interface {
+	bindLazy(load func() (interface{}, error))
+}
+
+// bind the lazy fields of the model ind with o and ctx.
+func (o *ormBase) bindLazy(ctx context.Context, mi *models.ModelInfo, ind reflect.Value) {
+	for _, lf := range mi.LazyFields {
+		binder, ok := ind.FieldByIndex(lf.FieldIndex).Addr().Interface().(lazyBinder)
+		if !ok {
+			panic(fmt.Errorf("<Ormer> lazy field `%s` of model `%s` must be orm.Lazy", lf.Name, mi.FullName))
+		}
+		fi := o.getFieldInfo(mi, lf.RelName)
+		if !fi.Rel && !fi.Reverse {
+			panic(fmt.Errorf("<Ormer> lazy field `%s` of model `%s` need a rel/reverse field, got `%s`", lf.Name, mi.FullName, lf.RelName))
+		}
+		md := ind.Addr().Interface()
+		binder.bindLazy(func() (interface{}, error) {
+			if _, err := o.LoadRelatedWithCtx(ctx, md, fi.Name); err != nil {
+				return nil, err
+			}
+			return reflect.Indirect(reflect.ValueOf(md)).FieldByIndex(fi.FieldIndex).Interface(), nil
+		})
+	}
+}
+
+// bind the lazy fields of the models of mi in container,
+// container can be a ptr to model struct or to a slice of models.
+func (o *ormBase) bindLazyContainer(ctx context.Context, mi *models.ModelInfo, container interface{}) {
+	if len(mi.LazyFields) == 0 {
+		return
+	}
+	typ := mi.AddrField.Elem().Type()
+	ind := reflect.Indirect(reflect.ValueOf(container))
+	switch ind.Kind() {
+	case reflect.Struct:
+		if ind.Type() == typ && ind.CanAddr() {
+			o.bindLazy(ctx, mi, ind)
+		}
+	case reflect.Slice:
+		for i := 0; i < ind.Len(); i++ {
+			elm := reflect.Indirect(ind.Index(i))
+			if elm.IsValid() && elm.Type() == typ && elm.CanAddr() {
+				o.bindLazy(ctx, mi, elm)
+			}
+		}
+	}
+}
@@ -63,6 +63,12 @@ func debugLogQueies(alias *alias, operaton, query string, t time.Time, err error
 	DebugLog.Println(con)
 }
 
+// warnLogRows log the table query returned more rows than the threshold of alias.
+func warnLogRows(alias *alias, table string, num int64) {
+	DebugLog.Printf(" -[Queries/%s] - [WARN / %11s] - table `%s` returned %d rows, more than threshold %d\n",
+		alias.Name, "All", table, num, alias.RowWarnThreshold)
+}
+
 // statement query logger struct.
 // if dev mode, use stmtQueryLog, or use stmtQuerier.
 type stmtQueryLog struct {
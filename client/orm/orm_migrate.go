@@ -0,0 +1,143 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MigrationTable records the ids of the applied migrations.
+const MigrationTable = "schema_migrations"
+
+// Migration is a schema migration run by Migrate, identified by ID.
+// Up applies it and Down reverts it, both in the transaction txOrm.
+// Postgres and sqlite roll back DDL with the transaction,
+// mysql, tidb and oracle commit DDL implicitly, so a failed migration may be partly applied on them.
+type Migration struct {
+	ID   string
+	Up   func(txOrm TxOrmer) error
+	Down func(txOrm TxOrmer) error
+}
+
+// Migrate run the pending migrations on the default db, see MigrateWithCtx.
+func Migrate(migrations []Migration) error {
+	return MigrateWithCtx(context.Background(), "default", migrations)
+}
+
+// MigrateWithCtx run the migrations not recorded in MigrationTable of the db alias in order,
+// each one in its own transaction with its record. The table is created if it does not exist.
+// It stops at the first failed migration, the applied ones are skipped when running again.
+func MigrateWithCtx(ctx context.Context, aliasName string, migrations []Migration) error {
+	o, al := NewOrmUsingDB(aliasName), getDbAlias(aliasName)
+	applied, err := appliedMigrations(o, al, migrations)
+	if err != nil {
+		return err
+	}
+	Q := al.DbBaser.TableQuote()
+	query := fmt.Sprintf("INSERT INTO %s%s%s (%sid%s, %sapplied_at%s) VALUES (?, ?)", Q, MigrationTable, Q, Q, Q, Q, Q)
+	for _, m := range migrations {
+		if applied[m.ID] {
+			continue
+		}
+		if m.Up == nil {
+			return fmt.Errorf("<orm.Migrate> migration `%s` has no Up", m.ID)
+		}
+		err = o.DoTxWithCtx(ctx, func(ctx context.Context, txOrm TxOrmer) error {
+			if err := m.Up(txOrm); err != nil {
+				return err
+			}
+			_, err := txOrm.Raw(query, m.ID, time.Now()).Exec()
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("<orm.Migrate> migration `%s` failed: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+// RollbackMigrationsWithCtx revert the last steps applied migrations of the db alias in reverse order by Down,
+// removing their records.
+func RollbackMigrationsWithCtx(ctx context.Context, aliasName string, migrations []Migration, steps int) error {
+	o, al := NewOrmUsingDB(aliasName), getDbAlias(aliasName)
+	applied, err := appliedMigrations(o, al, migrations)
+	if err != nil {
+		return err
+	}
+	Q := al.DbBaser.TableQuote()
+	query := fmt.Sprintf("DELETE FROM %s%s%s WHERE %sid%s = ?", Q, MigrationTable, Q, Q, Q)
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		if !applied[m.ID] {
+			continue
+		}
+		if m.Down == nil {
+			return fmt.Errorf("<orm.Migrate> migration `%s` has no Down", m.ID)
+		}
+		err = o.DoTxWithCtx(ctx, func(ctx context.Context, txOrm TxOrmer) error {
+			if err := m.Down(txOrm); err != nil {
+				return err
+			}
+			_, err := txOrm.Raw(query, m.ID).Exec()
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("<orm.Migrate> rollback of migration `%s` failed: %w", m.ID, err)
+		}
+		steps--
+	}
+	return nil
+}
+
+// check the migration ids, create MigrationTable if needed and return the applied ids.
+func appliedMigrations(o Ormer, al *alias, migrations []Migration) (map[string]bool, error) {
+	seen := make(map[string]bool, len(migrations))
+	for _, m := range migrations {
+		if m.ID == "" {
+			return nil, fmt.Errorf("<orm.Migrate> migration id cannot be empty")
+		}
+		if seen[m.ID] {
+			return nil, fmt.Errorf("<orm.Migrate> duplicate migration id `%s`", m.ID)
+		}
+		seen[m.ID] = true
+	}
+
+	Q := al.DbBaser.TableQuote()
+	tables, err := al.DbBaser.GetTables(al.DB)
+	if err != nil {
+		return nil, err
+	}
+	if !tables[MigrationTable] {
+		types := al.DbBaser.DbTypes()
+		query := fmt.Sprintf("CREATE TABLE %s%s%s (%sid%s %s NOT NULL PRIMARY KEY, %sapplied_at%s %s NOT NULL)",
+			Q, MigrationTable, Q, Q, Q, fmt.Sprintf(types["string"], 255), Q, Q, types["time.Time"])
+		if _, err = o.Raw(query).Exec(); err != nil {
+			return nil, err
+		}
+	}
+
+	var ids []string
+	query := fmt.Sprintf("SELECT %sid%s FROM %s%s%s", Q, Q, Q, MigrationTable, Q)
+	if _, err = o.Raw(query).QueryRows(&ids); err != nil {
+		return nil, err
+	}
+	applied := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		applied[id] = true
+	}
+	return applied, nil
+}
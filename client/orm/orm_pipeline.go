@@ -0,0 +1,99 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// PipelineResult is the result of a query of a Pipeline.
+type PipelineResult struct {
+	Value interface{}
+	Err   error
+}
+
+// Pipeline issues the independent queries added to it concurrently over the connection pool of the Ormer,
+// at most MaxOpenConns of them at a time, and returns their results in the order they are added.
+// The queries must not depend on each other, they are run on different connections,
+// so a Pipeline of an Ormer is not in any transaction.
+// for example:
+//
+//	p := o.Pipeline()
+//	p.Add(func(ctx context.Context, o orm.Ormer) (interface{}, error) {
+//		return o.QueryTable("user").CountWithCtx(ctx)
+//	})
+//	p.Add(func(ctx context.Context, o orm.Ormer) (interface{}, error) {
+//		var posts []*Post
+//		_, err := o.QueryTable("post").Limit(10).AllWithCtx(ctx, &posts)
+//		return posts, err
+//	})
+//	results := p.Exec(ctx)
+type Pipeline struct {
+	o       Ormer
+	db      *sql.DB
+	queries []func(ctx context.Context, o Ormer) (interface{}, error)
+}
+
+// Pipeline return a Pipeline running its queries on o.
+func (o *orm) Pipeline() *Pipeline {
+	return &Pipeline{o: o, db: o.alias.DB.DB}
+}
+
+// Add add the query to the pipeline, its result is at the index of the number of queries added before.
+func (p *Pipeline) Add(query func(ctx context.Context, o Ormer) (interface{}, error)) *Pipeline {
+	p.queries = append(p.queries, query)
+	return p
+}
+
+// Exec run all the queries added concurrently and wait for them, the results are in the order of Add.
+// The queries not started yet when ctx is done have its error.
+func (p *Pipeline) Exec(ctx context.Context) []PipelineResult {
+	results := make([]PipelineResult, len(p.queries))
+	if len(p.queries) == 0 {
+		return results
+	}
+
+	workers := len(p.queries)
+	if p.db != nil {
+		// the queries over MaxOpenConns would wait for a connection anyway
+		if n := p.db.Stats().MaxOpenConnections; n > 0 && n < workers {
+			workers = n
+		}
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Value, results[i].Err = p.queries[i](ctx, p.o)
+			}
+		}()
+	}
+	for i := range p.queries {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,257 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/beego/beego/v2/client/orm/clauses/order_clause"
+	"github.com/beego/beego/v2/client/orm/internal/models"
+)
+
+// PreloadSep separates the relation names of a Preload path, such as "Posts.Comments".
+const PreloadSep = "."
+
+// Get model info and the addressable struct values of md,
+// md can be a ptr to model struct, a slice of models or a ptr to it.
+func (o *ormBase) getPreloadMiInds(md interface{}) (mi *models.ModelInfo, inds []reflect.Value) {
+	val := reflect.ValueOf(md)
+	ind := reflect.Indirect(val)
+	switch ind.Kind() {
+	case reflect.Struct:
+		mi, ind = o.getPtrMiInd(md)
+		return mi, []reflect.Value{ind}
+	case reflect.Slice:
+		typ := ind.Type().Elem()
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		mi = getTypeMi(typ)
+		inds = make([]reflect.Value, 0, ind.Len())
+		for i := 0; i < ind.Len(); i++ {
+			elm := reflect.Indirect(ind.Index(i))
+			if elm.IsValid() {
+				inds = append(inds, elm)
+			}
+		}
+		return mi, inds
+	}
+	panic(fmt.Errorf("<Ormer.Preload> unsupported type `%s`, need ptr to struct or slice of struct", val.Type()))
+}
+
+// load every path on inds, the paths sharing a prefix load the prefix only once.
+func (o *ormBase) preload(ctx context.Context, mi *models.ModelInfo, inds []reflect.Value, paths []string) error {
+	var names []string
+	subPaths := make(map[string][]string, len(paths))
+	for _, path := range paths {
+		name, sub, _ := strings.Cut(path, PreloadSep)
+		if _, ok := subPaths[name]; !ok {
+			names = append(names, name)
+			subPaths[name] = nil
+		}
+		if sub != "" {
+			subPaths[name] = append(subPaths[name], sub)
+		}
+	}
+
+	for _, name := range names {
+		rmi, loaded, err := o.preloadRelated(ctx, mi, inds, name)
+		if err != nil {
+			return err
+		}
+		if len(loaded) > 0 && len(subPaths[name]) > 0 {
+			if err = o.preload(ctx, rmi, loaded, subPaths[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// load the relation name of all inds with one query,
+// many to many relations need one more query for the rel table.
+// return the distinct loaded models for the next level.
+func (o *ormBase) preloadRelated(ctx context.Context, mi *models.ModelInfo, inds []reflect.Value, name string) (*models.ModelInfo, []reflect.Value, error) {
+	fi := o.getFieldInfo(mi, name)
+	if !fi.Rel && !fi.Reverse || !fi.InModel {
+		panic(fmt.Errorf("<Ormer.Preload> name `%s` for model `%s` is not an available rel/reverse field", name, mi.FullName))
+	}
+	rmi := fi.RelModelInfo
+	if len(inds) == 0 {
+		return rmi, nil, nil
+	}
+
+	switch {
+	case fi.FieldType == RelForeignKey || fi.FieldType == RelOneToOne:
+		var pks []interface{}
+		for _, ind := range inds {
+			if pk, ok := o.preloadRelPk(rmi, ind.FieldByIndex(fi.FieldIndex)); ok {
+				pks = append(pks, pk)
+			}
+		}
+		rels, loaded, err := o.preloadByPk(ctx, rmi, pks)
+		if err != nil {
+			return rmi, nil, err
+		}
+		for _, ind := range inds {
+			field := ind.FieldByIndex(fi.FieldIndex)
+			if pk, ok := o.preloadRelPk(rmi, field); ok {
+				if rel, ok := rels[pkKey(pk)]; ok {
+					field.Set(rel)
+				}
+			}
+		}
+		return rmi, loaded, nil
+
+	case fi.FieldType == RelManyToMany || fi.FieldType == RelReverseMany && fi.ReverseFieldInfo.Mi.IsThrough:
+		from, to := fi.ReverseFieldInfo, fi.ReverseFieldInfoTwo
+
+		qs := newQuerySet(o, fi.RelThroughModelInfo).(*querySet)
+		qs.cond = NewCondition().And(from.Name+ExprSep+"in", o.preloadPks(mi, inds)...)
+		qs.orders = order_clause.ParseOrder(fi.RelThroughModelInfo.Fields.Pk.Name)
+		qs.limit = -1
+		var pairs []ParamsList
+		if _, err := qs.ValuesListWithCtx(ctx, &pairs, from.Name, to.Name); err != nil {
+			return rmi, nil, err
+		}
+
+		pks := make([]interface{}, 0, len(pairs))
+		for _, pair := range pairs {
+			pks = append(pks, pair[1])
+		}
+		rels, loaded, err := o.preloadByPk(ctx, rmi, pks)
+		if err != nil {
+			return rmi, nil, err
+		}
+
+		groups := make(map[interface{}][]reflect.Value, len(inds))
+		for _, pair := range pairs {
+			if rel, ok := rels[pkKey(pair[1])]; ok {
+				groups[pkKey(pair[0])] = append(groups[pkKey(pair[0])], rel)
+			}
+		}
+		o.preloadSetReverse(mi, fi, inds, groups)
+		return rmi, loaded, nil
+
+	default:
+		// reverse one and reverse many, query the models pointing to inds
+		qs := newQuerySet(o, rmi).(*querySet)
+		qs.cond = NewCondition().And(fi.ReverseFieldInfo.Name+ExprSep+"in", o.preloadPks(mi, inds)...)
+		qs.orders = order_clause.ParseOrder(rmi.Fields.Pk.Name)
+		qs.limit = -1
+		container := reflect.New(reflect.SliceOf(rmi.AddrField.Type()))
+		if _, err := qs.AllWithCtx(ctx, container.Interface()); err != nil {
+			return rmi, nil, err
+		}
+
+		slice := container.Elem()
+		loaded := make([]reflect.Value, 0, slice.Len())
+		groups := make(map[interface{}][]reflect.Value, len(inds))
+		for i := 0; i < slice.Len(); i++ {
+			rel := slice.Index(i)
+			loaded = append(loaded, rel.Elem())
+			if pk, ok := o.preloadRelPk(mi, rel.Elem().FieldByIndex(fi.ReverseFieldInfo.FieldIndex)); ok {
+				groups[pkKey(pk)] = append(groups[pkKey(pk)], rel)
+			}
+		}
+		o.preloadSetReverse(mi, fi, inds, groups)
+		return rmi, loaded, nil
+	}
+}
+
+// query models of mi by distinct pks, return them indexed by pk.
+func (o *ormBase) preloadByPk(ctx context.Context, mi *models.ModelInfo, pks []interface{}) (map[interface{}]reflect.Value, []reflect.Value, error) {
+	rels := make(map[interface{}]reflect.Value, len(pks))
+	if len(pks) == 0 {
+		return rels, nil, nil
+	}
+
+	distinct := make([]interface{}, 0, len(pks))
+	seen := make(map[interface{}]bool, len(pks))
+	for _, pk := range pks {
+		if !seen[pkKey(pk)] {
+			seen[pkKey(pk)] = true
+			distinct = append(distinct, pk)
+		}
+	}
+
+	qs := newQuerySet(o, mi).(*querySet)
+	qs.cond = NewCondition().And(mi.Fields.Pk.Name+ExprSep+"in", distinct...)
+	qs.orders = order_clause.ParseOrder(mi.Fields.Pk.Name)
+	qs.limit = -1
+	container := reflect.New(reflect.SliceOf(mi.AddrField.Type()))
+	if _, err := qs.AllWithCtx(ctx, container.Interface()); err != nil {
+		return nil, nil, err
+	}
+
+	slice := container.Elem()
+	loaded := make([]reflect.Value, 0, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		rel := slice.Index(i)
+		_, pk, _ := getExistPk(mi, rel.Elem())
+		rels[pkKey(pk)] = rel
+		loaded = append(loaded, rel.Elem())
+	}
+	return rels, loaded, nil
+}
+
+// set the grouped models to the reverse or m2m field of inds.
+func (o *ormBase) preloadSetReverse(mi *models.ModelInfo, fi *models.FieldInfo, inds []reflect.Value, groups map[interface{}][]reflect.Value) {
+	for _, ind := range inds {
+		_, pk, _ := getExistPk(mi, ind)
+		field := ind.FieldByIndex(fi.FieldIndex)
+		rels := groups[pkKey(pk)]
+		if fi.FieldType == RelReverseOne {
+			if len(rels) > 0 {
+				field.Set(rels[0])
+			} else {
+				field.Set(reflect.Zero(field.Type()))
+			}
+			continue
+		}
+		slice := reflect.MakeSlice(field.Type(), 0, len(rels))
+		slice = reflect.Append(slice, rels...)
+		field.Set(slice)
+	}
+}
+
+// Get pk values of inds.
+func (o *ormBase) preloadPks(mi *models.ModelInfo, inds []reflect.Value) []interface{} {
+	pks := make([]interface{}, 0, len(inds))
+	for _, ind := range inds {
+		_, pk, exist := getExistPk(mi, ind)
+		if !exist {
+			panic(ErrMissPK)
+		}
+		pks = append(pks, pk)
+	}
+	return pks
+}
+
+// Get pk value of the rel model field points to.
+func (o *ormBase) preloadRelPk(mi *models.ModelInfo, field reflect.Value) (interface{}, bool) {
+	if field.IsNil() {
+		return nil, false
+	}
+	ind := field.Elem()
+	if ind.FieldByIndex(mi.Fields.Pk.FieldIndex).IsZero() {
+		return nil, false
+	}
+	_, pk, _ := getExistPk(mi, ind)
+	return pk, true
+}
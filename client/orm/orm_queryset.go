@@ -16,14 +16,20 @@ package orm
 
 import (
 	"context"
+	sqldriver "database/sql/driver"
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
-	"github.com/beego/beego/v2/client/orm/internal/utils"
+	iutils "github.com/beego/beego/v2/client/orm/internal/utils"
 
 	"github.com/beego/beego/v2/client/orm/internal/models"
 
+	"github.com/beego/beego/v2/client/orm/clauses"
 	"github.com/beego/beego/v2/client/orm/clauses/order_clause"
 	"github.com/beego/beego/v2/client/orm/hints"
+	"github.com/beego/beego/v2/core/utils"
 )
 
 type colValue struct {
@@ -58,7 +64,7 @@ func ColValue(opt operator, value interface{}) interface{} {
 	default:
 		panic(fmt.Errorf("orm.ColValue wrong operator"))
 	}
-	v, err := utils.StrTo(utils.ToStr(value)).Int64()
+	v, err := iutils.StrTo(iutils.ToStr(value)).Int64()
 	if err != nil {
 		panic(fmt.Errorf("orm.ColValue doesn't support non string/numeric type, %s", err))
 	}
@@ -78,25 +84,292 @@ type querySet struct {
 	offset    int64
 	groups    []string
 	orders    []*order_clause.Order
+	noDefault bool
 	distinct  bool
+	dedupPk   bool
 	forUpdate bool
 	useIndex  int
 	indexes   []string
 	orm       *ormBase
 	aggregate string
+	aggrs     []Aggregation
+	havings   []HavingCond
+	buckets   []timeBucket
+	cases     []caseColumn
+	bools     []boolColumn
+	tzColumns []tzColumn
+	fetchSize int
+	// the buffered rows of Stream and the time a row waits for the consumer before the stream is aborted
+	streamBuffer    int
+	consumerTimeout time.Duration
+	// the bytes of the scanned rows above which All, AllInto and Stream are aborted, unlimited when 0
+	maxResultBytes int64
+	// the column counted by Count and GroupCount, all the rows when empty
+	countCol string
+	// set to the conversion errors of the columns scanned by every All, AllInto and One, they panic when nil
+	scanWarnings *[]ScanWarning
+	// the transformation of every model scanned by All and One
+	mapper func(interface{}) interface{}
+	// the first error of the chained calls, returned by the terminal ones
+	err error
+}
+
+// CASE expression of the branches, selected as alias.
+type caseColumn struct {
+	alias   string
+	whens   []CaseWhen
+	elseVal interface{}
+}
+
+// the condition selected as alias, true when it is.
+type boolColumn struct {
+	alias string
+	cond  *Condition
+}
+
+// a time column converted to a timezone, selected as alias.
+type tzColumn struct {
+	column string
+	alias  string
+	tz     string
+}
+
+// time column truncated to unit, selected as alias.
+// the column is converted to the timezone tz before the truncation when set, the buckets are labeled in loc.
+type timeBucket struct {
+	column string
+	unit   string
+	alias  string
+	tz     string
+	loc    *time.Location
+}
+
+// the units supported by TimeBucket.
+var timeBucketUnits = map[string]bool{
+	"minute": true,
+	"hour":   true,
+	"day":    true,
+	"month":  true,
+	"year":   true,
 }
 
 var _ QuerySeter = new(querySet)
 
 // add condition expression to QuerySeter.
 func (o querySet) Filter(expr string, args ...interface{}) QuerySeter {
+	if err := o.checkExpr(expr); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.Filter> %w", err))
+		return &o
+	}
+	if err := subQueryErr(args); err != nil {
+		o.setErr(err)
+		return &o
+	}
 	if o.cond == nil {
 		o.cond = NewCondition()
 	}
+	if len(args) == 0 && o.isBoolExpr(expr) {
+		o.cond = o.cond.andBool(expr, false)
+		return &o
+	}
 	o.cond = o.cond.And(expr, args...)
 	return &o
 }
 
+// add the condition of the rows having related rows by the relation or, if has is false, having none.
+func (o querySet) FilterHasRelated(relation string, has bool) QuerySeter {
+	fi, ok := o.mi.Fields.GetByAny(relation)
+	if !ok || !fi.Rel && !fi.Reverse {
+		o.setErr(fmt.Errorf("<QuerySeter.FilterHasRelated> unknown relation `%s` of model `%s`", relation, o.mi.Name))
+		return &o
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	cond := *o.cond
+	cond.params = append(cond.params, condValue{exprs: []string{relation}, hasRelated: fi, isNot: !has})
+	o.cond = &cond
+	return &o
+}
+
+// add the condition of the rows in the subtree of the row whose pkColumn is rootPK, the root included,
+// the rows are linked to their parent row by parentColumn.
+func (o querySet) Descendants(pkColumn, parentColumn string, rootPK interface{}) QuerySeter {
+	pk, ok := o.mi.Fields.GetByAny(pkColumn)
+	if !ok || !pk.DBcol {
+		o.setErr(fmt.Errorf("<QuerySeter.Descendants> unknown column `%s` of model `%s`", pkColumn, o.mi.Name))
+		return &o
+	}
+	parent, ok := o.mi.Fields.GetByAny(parentColumn)
+	if !ok || !parent.DBcol {
+		o.setErr(fmt.Errorf("<QuerySeter.Descendants> unknown column `%s` of model `%s`", parentColumn, o.mi.Name))
+		return &o
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	cond := *o.cond
+	cond.params = append(cond.params, condValue{tree: &descendantsTree{pk: pk, parent: parent, root: rootPK}})
+	o.cond = &cond
+	return &o
+}
+
+// add the condition of the column in values and order the rows by the position of their value in values,
+// before the orders already set.
+func (o querySet) FilterInOrdered(column string, values []interface{}) QuerySeter {
+	if len(values) == 0 {
+		panic(fmt.Errorf("<QuerySeter.FilterInOrdered> values cannot be empty"))
+	}
+	qs := o.Filter(column+ExprSep+"in", values...).(*querySet)
+	orders := make([]*order_clause.Order, 0, len(o.orders)+1)
+	orders = append(orders, order_clause.Clause(order_clause.Column(column), order_clause.Values(values...), order_clause.SortAscending()))
+	qs.orders = append(orders, o.orders...)
+	return qs
+}
+
+// add the condition of the time column at or after the current time of the database minus within.
+func (o querySet) FilterRecent(column string, within time.Duration) QuerySeter {
+	return o.Filter(column+ExprSep+"gte", recentDBTime(within))
+}
+
+// the DBTime of within ago, in the largest unit within is a whole number of.
+func recentDBTime(within time.Duration) DBTime {
+	if within <= 0 || within%time.Second != 0 {
+		panic(fmt.Errorf("<QuerySeter.FilterRecent> within must be a positive whole number of seconds, not %s", within))
+	}
+	for _, u := range []struct {
+		d    time.Duration
+		unit IntervalUnit
+	}{{24 * time.Hour, Day}, {time.Hour, Hour}, {time.Minute, Minute}} {
+		if within%u.d == 0 {
+			return Now().Minus(int(within/u.d), u.unit)
+		}
+	}
+	return Now().Minus(int(within/time.Second), Second)
+}
+
+// add the condition of the column within the day of date in the timezone of the db alias.
+func (o querySet) FilterDateEq(column string, date time.Time) QuerySeter {
+	tz := o.orm.alias.TZ
+	if tz == nil {
+		tz = time.Local
+	}
+	y, m, d := date.In(tz).Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, tz)
+	if err := o.checkColumn(column); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.FilterDateEq> %w", err))
+		return &o
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	o.cond = o.cond.And(column+ExprSep+"gte", start).And(column+ExprSep+"lt", start.AddDate(0, 0, 1))
+	return &o
+}
+
+// add the conditions of the point of latCol and lngCol within the bounding box.
+// a box crossing the antimeridian has minLng greater than maxLng.
+func (o querySet) FilterBBox(latCol, lngCol string, minLat, minLng, maxLat, maxLng float64) QuerySeter {
+	if minLat > maxLat {
+		panic(fmt.Errorf("<QuerySeter.FilterBBox> minLat %v is greater than maxLat %v", minLat, maxLat))
+	}
+	for _, col := range []string{latCol, lngCol} {
+		if err := o.checkColumn(col); err != nil {
+			o.setErr(fmt.Errorf("<QuerySeter.FilterBBox> %w", err))
+			return &o
+		}
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	o.cond = o.cond.And(latCol+ExprSep+"gte", minLat).And(latCol+ExprSep+"lte", maxLat)
+	if minLng <= maxLng {
+		o.cond = o.cond.And(lngCol+ExprSep+"gte", minLng).And(lngCol+ExprSep+"lte", maxLng)
+	} else {
+		o.cond = o.cond.AndCond(NewCondition().And(lngCol+ExprSep+"gte", minLng).Or(lngCol+ExprSep+"lte", maxLng))
+	}
+	return &o
+}
+
+// add the condition of the column within the inclusive bounds lo and hi by BETWEEN, a nil bound is compared alone.
+func (o querySet) FilterBetween(column string, lo interface{}, hi interface{}) QuerySeter {
+	switch {
+	case lo == nil && hi == nil:
+		return &o
+	case lo == nil:
+		return o.Filter(column+ExprSep+"lte", hi)
+	case hi == nil:
+		return o.Filter(column+ExprSep+"gte", lo)
+	}
+	return o.Filter(column+ExprSep+"between", lo, hi)
+}
+
+// add the conditions of the column within the exclusive bounds lo and hi, a nil bound is skipped.
+func (o querySet) FilterOpen(column string, lo interface{}, hi interface{}) QuerySeter {
+	if lo == nil && hi == nil {
+		return &o
+	}
+	if err := o.checkColumn(column); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.FilterOpen> %w", err))
+		return &o
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	if lo != nil {
+		o.cond = o.cond.And(column+ExprSep+"gt", lo)
+	}
+	if hi != nil {
+		o.cond = o.cond.And(column+ExprSep+"lt", hi)
+	}
+	return &o
+}
+
+// keep the first n rows ordered by orderBy of each group of partitionCols,
+// ranked among the rows matching the conditions added before.
+func (o querySet) TopNPerGroup(partitionCols []string, orderBy string, n int) QuerySeter {
+	if len(partitionCols) == 0 || orderBy == "" || n <= 0 {
+		panic(fmt.Errorf("<QuerySeter.TopNPerGroup> need partition columns, order and a positive n"))
+	}
+	if o.orm != nil && o.orm.alias.Driver == DRSqlite && !sqliteVersionAtLeast(o.orm.alias.SqliteVersion, 3, 25) {
+		o.setErr(fmt.Errorf("<QuerySeter.TopNPerGroup> window functions need sqlite 3.25 or higher, not %s", o.orm.alias.SqliteVersion))
+		return &o
+	}
+	topN := &topNPerGroup{
+		partition: partitionCols,
+		order:     order_clause.ParseOrder(orderBy)[0],
+		n:         n,
+		cond:      o.cond,
+	}
+	o.cond = &Condition{params: []condValue{{topN: topN}}}
+	return &o
+}
+
+// filter the rows by comparing their window aggregate with value,
+// computed among the rows matching the conditions added before.
+func (o querySet) FilterWindow(aggr WindowAggr, operator string, value interface{}) QuerySeter {
+	aggr.Func = strings.ToUpper(aggr.Func)
+	if !windowFuncs[aggr.Func] || aggr.Column == "" {
+		panic(fmt.Errorf("<QuerySeter.FilterWindow> need a column and one of the SUM, AVG, COUNT, MIN and MAX funcs"))
+	}
+	if aggr.Preceding < 0 || aggr.Preceding > 0 && aggr.OrderBy == "" {
+		panic(fmt.Errorf("<QuerySeter.FilterWindow> the preceding rows cannot be negative and need an order"))
+	}
+	switch operator {
+	case "exact", "eq", "ne", "gt", "gte", "lt", "lte":
+	default:
+		panic(fmt.Errorf("<QuerySeter.FilterWindow> operator `%s` cannot compare the window aggregate", operator))
+	}
+	window := &windowFilter{
+		aggr:     aggr,
+		operator: operator,
+		value:    value,
+		cond:     o.cond,
+	}
+	o.cond = &Condition{params: []condValue{{window: window}}}
+	return &o
+}
+
 // add raw sql to querySeter.
 func (o querySet) FilterRaw(expr string, sql string) QuerySeter {
 	if o.cond == nil {
@@ -106,24 +379,363 @@ func (o querySet) FilterRaw(expr string, sql string) QuerySeter {
 	return &o
 }
 
+// add equality conditions built from the non-zero fields of example.
+func (o querySet) FilterByExample(example interface{}, args ...utils.KV) QuerySeter {
+	ind := reflect.Indirect(reflect.ValueOf(example))
+	if name := models.GetFullName(ind.Type()); name != o.mi.FullName {
+		panic(fmt.Errorf("<QuerySeter.FilterByExample> example `%s` does not match model `%s`", name, o.mi.FullName))
+	}
+
+	var includeZeroPtr bool
+	utils.NewKVs(args...).IfContains(hints.KeyIncludeZeroPtr, func(value interface{}) {
+		includeZeroPtr, _ = value.(bool)
+	})
+
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	for _, fi := range o.mi.Fields.FieldsDB {
+		field := ind.FieldByIndex(fi.FieldIndex)
+		if field.IsZero() {
+			continue
+		}
+		value := field.Interface()
+		switch {
+		case fi.Rel:
+			// related models are matched by their primary key
+			rel := reflect.Indirect(field)
+			if rel.FieldByIndex(fi.RelModelInfo.Fields.Pk.FieldIndex).IsZero() {
+				continue
+			}
+			_, value, _ = getExistPk(fi.RelModelInfo, rel)
+		case field.Kind() == reflect.Ptr:
+			if !includeZeroPtr && field.Elem().IsZero() {
+				continue
+			}
+		case fi.IsFielder:
+			value = field.Addr().Interface().(models.Fielder).RawValue()
+		default:
+			if vu, ok := value.(sqldriver.Valuer); ok {
+				v, err := vu.Value()
+				if err != nil {
+					panic(fmt.Errorf("<QuerySeter.FilterByExample> field `%s`: %s", fi.Name, err))
+				}
+				value = v
+			}
+		}
+		o.cond = o.cond.And(fi.Name, value)
+	}
+	return &o
+}
+
+// add condition comparing COALESCE(column, def) with value by operator.
+func (o querySet) FilterCoalesce(column string, def interface{}, operator string, value interface{}) QuerySeter {
+	expr := column
+	if operator != "" {
+		if !operators[operator] {
+			panic(fmt.Errorf("<QuerySeter.FilterCoalesce> unknown operator `%s`", operator))
+		}
+		expr += ExprSep + operator
+	}
+	if err := o.checkExpr(expr); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.FilterCoalesce> %w", err))
+		return &o
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	o.cond = o.cond.andCoalesce(expr, def, value)
+	return &o
+}
+
+// add condition comparing column with COLLATE collation and value by operator.
+func (o querySet) FilterCollate(column string, collation string, operator string, value interface{}) QuerySeter {
+	expr := column
+	if operator != "" {
+		if !operators[operator] {
+			panic(fmt.Errorf("<QuerySeter.FilterCollate> unknown operator `%s`", operator))
+		}
+		expr += ExprSep + operator
+	}
+	if err := o.checkExpr(expr); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.FilterCollate> %w", err))
+		return &o
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	o.cond = o.cond.andCollate(expr, collation, value)
+	return &o
+}
+
+// add condition comparing the columns concatenated by separator with value by operator.
+func (o querySet) FilterConcat(cols []string, separator string, operator string, value interface{}) QuerySeter {
+	if len(cols) < 2 {
+		panic(fmt.Errorf("<QuerySeter.FilterConcat> need at least two columns"))
+	}
+	expr := cols[0]
+	if operator != "" {
+		if !operators[operator] {
+			panic(fmt.Errorf("<QuerySeter.FilterConcat> unknown operator `%s`", operator))
+		}
+		expr += ExprSep + operator
+	}
+	if err := o.checkExpr(expr); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.FilterConcat> %w", err))
+		return &o
+	}
+	for _, col := range cols[1:] {
+		if err := o.checkColumn(col); err != nil {
+			o.setErr(fmt.Errorf("<QuerySeter.FilterConcat> %w", err))
+			return &o
+		}
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	o.cond = o.cond.andConcat(expr, append([]string{}, cols[1:]...), separator, value)
+	return &o
+}
+
+// add condition comparing column rounded to decimals with value by operator.
+func (o querySet) FilterRound(column string, decimals int, operator string, value interface{}) QuerySeter {
+	expr := column
+	if operator != "" {
+		if !operators[operator] {
+			panic(fmt.Errorf("<QuerySeter.FilterRound> unknown operator `%s`", operator))
+		}
+		expr += ExprSep + operator
+	}
+	if err := o.checkExpr(expr); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.FilterRound> %w", err))
+		return &o
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	o.cond = o.cond.andRound(expr, decimals, value)
+	return &o
+}
+
+// add condition of the column within tolerance of target.
+func (o querySet) FilterApprox(column string, target float64, tolerance float64) QuerySeter {
+	if err := o.checkColumn(column); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.FilterApprox> %w", err))
+		return &o
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	o.cond = o.cond.andApprox(column, target, tolerance)
+	return &o
+}
+
+// add condition comparing time column converted to the timezone tz with value by operator.
+func (o querySet) FilterTZ(column string, tz string, operator string, value interface{}) QuerySeter {
+	expr := column
+	if operator != "" {
+		if !operators[operator] {
+			panic(fmt.Errorf("<QuerySeter.FilterTZ> unknown operator `%s`", operator))
+		}
+		expr += ExprSep + operator
+	}
+	if err := o.checkExpr(expr); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.FilterTZ> %w", err))
+		return &o
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	o.cond = o.cond.andTimezone(expr, tz, value)
+	return &o
+}
+
+// add condition comparing column cast to castType with value by operator.
+func (o querySet) FilterCast(column string, castType string, operator string, value interface{}) QuerySeter {
+	expr := column
+	if operator != "" {
+		if !operators[operator] {
+			panic(fmt.Errorf("<QuerySeter.FilterCast> unknown operator `%s`", operator))
+		}
+		expr += ExprSep + operator
+	}
+	if err := o.checkExpr(expr); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.FilterCast> %w", err))
+		return &o
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	o.cond = o.cond.andCast(expr, castType, value)
+	return &o
+}
+
+// add an OR of case-insensitive contains conditions over columns.
+func (o querySet) Search(term string, columns ...string) QuerySeter {
+	if len(columns) == 0 {
+		return &o
+	}
+	cond := NewCondition()
+	seen := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		if seen[col] {
+			continue
+		}
+		seen[col] = true
+		if err := o.checkColumn(col); err != nil {
+			o.setErr(fmt.Errorf("<QuerySeter.Search> %w", err))
+			return &o
+		}
+		cond = cond.Or(col+ExprSep+"icontains", term)
+	}
+	if o.cond == nil {
+		o.cond = NewCondition()
+	}
+	o.cond = o.cond.AndCond(cond)
+	return &o
+}
+
 // add NOT condition to querySeter.
 func (o querySet) Exclude(expr string, args ...interface{}) QuerySeter {
+	if err := o.checkExpr(expr); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.Exclude> %w", err))
+		return &o
+	}
+	if err := subQueryErr(args); err != nil {
+		o.setErr(err)
+		return &o
+	}
 	if o.cond == nil {
 		o.cond = NewCondition()
 	}
+	if len(args) == 0 && o.isBoolExpr(expr) {
+		o.cond = o.cond.andBool(expr, true)
+		return &o
+	}
 	o.cond = o.cond.AndNot(expr, args...)
 	return &o
 }
 
+// check the fields of the condition expr are the path of a field of the model.
+func (o *querySet) checkExpr(expr string) error {
+	exprs := strings.Split(expr, ExprSep)
+	if num := len(exprs) - 1; num > 0 && operators[exprs[num]] {
+		exprs = exprs[:num]
+	}
+	if num := len(exprs) - 1; num > 0 && transforms[exprs[num]] && !isRelFieldExpr(o.mi, exprs) {
+		exprs = exprs[:num]
+	}
+	if len(exprs) == 1 && o.mi.Polymorphics[exprs[0]] != nil {
+		return nil
+	}
+	if _, _, _, ok := newDbTables(o.mi, nil).parseExprs(o.mi, exprs); !ok {
+		return fmt.Errorf("unknown field/column name `%s` of model `%s`", strings.Join(exprs, ExprSep), o.mi.Name)
+	}
+	return nil
+}
+
+// the first error of the chained calls of the SubQuery args, kept by the query using them.
+func subQueryErr(args []interface{}) error {
+	for _, arg := range args {
+		if sub, ok := arg.(*SubQuery); ok && sub.qs.err != nil {
+			return sub.qs.err
+		}
+	}
+	return nil
+}
+
+// check the exprs of the condition and of its nested conditions like checkExpr.
+func (o *querySet) checkCond(cond *Condition) error {
+	if cond == nil {
+		return nil
+	}
+	for _, p := range cond.params {
+		var err error
+		switch {
+		case p.isCond:
+			err = o.checkCond(p.cond)
+		case p.topN != nil:
+			err = o.checkCond(p.topN.cond)
+		case p.window != nil:
+			err = o.checkCond(p.window.cond)
+		case p.isRaw, p.hasRelated != nil, p.tree != nil:
+		default:
+			err = o.checkExpr(strings.Join(p.exprs, ExprSep))
+			if err == nil {
+				err = subQueryErr(p.args)
+			}
+			for _, col := range p.concat {
+				if err == nil {
+					err = o.checkColumn(col)
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check the column, without operator, is the path of a field of the model.
+func (o *querySet) checkColumn(column string) error {
+	exprs := strings.Split(column, ExprSep)
+	if _, _, _, ok := newDbTables(o.mi, nil).parseExprs(o.mi, exprs); !ok {
+		return fmt.Errorf("unknown field/column name `%s` of model `%s`", strings.Join(exprs, ExprSep), o.mi.Name)
+	}
+	return nil
+}
+
+// check the columns of the orders like checkColumn, the raw orders are not checked.
+func (o *querySet) checkOrders(orders []*order_clause.Order) error {
+	for _, order := range orders {
+		cols := order.GetCoalesce()
+		switch lngColumn, _, _, ok := order.GetDistance(); {
+		case len(cols) > 0:
+		case ok:
+			cols = []string{order.GetColumn(), lngColumn}
+		case order.IsRaw():
+			continue
+		default:
+			cols = []string{order.GetColumn()}
+		}
+		for _, col := range cols {
+			if err := o.checkColumn(strings.ReplaceAll(col, clauses.ExprDot, ExprSep)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// the expr is a boolean field without operator, which Filter and Exclude accept without args.
+func (o *querySet) isBoolExpr(expr string) bool {
+	_, _, fi, ok := newDbTables(o.mi, nil).parseExprs(o.mi, strings.Split(expr, ExprSep))
+	return ok && fi.FieldType == TypeBooleanField
+}
+
+// keep the first error of the chained calls.
+func (o *querySet) setErr(err error) {
+	if o.err == nil {
+		o.err = err
+	}
+}
+
+// Err return the first error of the chained calls, such as the unknown field of a Filter,
+// the terminal calls return it without running the query.
+func (o querySet) Err() error {
+	return o.err
+}
+
 // Set offset number
 func (o *querySet) setOffset(num interface{}) {
-	o.offset = utils.ToInt64(num)
+	o.offset = iutils.ToInt64(num)
 }
 
 // add LIMIT value.
 // args[0] means offset, e.g. LIMIT num,offset.
 func (o querySet) Limit(limit interface{}, args ...interface{}) QuerySeter {
-	o.limit = utils.ToInt64(limit)
+	o.limit = iutils.ToInt64(limit)
 	if len(args) > 0 {
 		o.setOffset(args[0])
 	}
@@ -142,13 +754,174 @@ func (o querySet) GroupBy(exprs ...string) QuerySeter {
 	return &o
 }
 
+// add time column truncated to unit named alias.
+func (o querySet) TimeBucket(column string, unit string, alias string) QuerySeter {
+	if !timeBucketUnits[unit] {
+		panic(fmt.Errorf("<QuerySeter.TimeBucket> unsupported unit `%s`", unit))
+	}
+	if alias == "" {
+		panic(fmt.Errorf("<QuerySeter.TimeBucket> alias of `%s` cannot be empty", column))
+	}
+	buckets := make([]timeBucket, 0, len(o.buckets)+1)
+	buckets = append(buckets, o.buckets...)
+	o.buckets = append(buckets, timeBucket{column: column, unit: unit, alias: alias})
+	return &o
+}
+
+// add time column converted to the timezone tz and truncated to unit named alias.
+func (o querySet) TimeBucketTZ(column string, unit string, alias string, tz string) QuerySeter {
+	if !timezoneRegexp.MatchString(tz) {
+		panic(fmt.Errorf("<QuerySeter.TimeBucketTZ> wrong timezone `%s`", tz))
+	}
+	loc, err := timezoneLocation(tz)
+	if err != nil {
+		panic(fmt.Errorf("<QuerySeter.TimeBucketTZ> unknown timezone `%s`: %w", tz, err))
+	}
+	qs := o.TimeBucket(column, unit, alias).(*querySet)
+	qs.buckets[len(qs.buckets)-1].tz = tz
+	qs.buckets[len(qs.buckets)-1].loc = loc
+	return qs
+}
+
+// the location of the timezone name or offset, like America/New_York or +08:00.
+func timezoneLocation(tz string) (*time.Location, error) {
+	if t, err := time.Parse("-07:00", tz); err == nil {
+		_, offset := t.Zone()
+		return time.FixedZone(tz, offset), nil
+	}
+	return time.LoadLocation(tz)
+}
+
+// add time column converted to the timezone tz named alias.
+func (o querySet) ValuesTZ(column string, alias string, tz string) QuerySeter {
+	if alias == "" {
+		panic(fmt.Errorf("<QuerySeter.ValuesTZ> alias of `%s` cannot be empty", column))
+	}
+	if !timezoneRegexp.MatchString(tz) {
+		panic(fmt.Errorf("<QuerySeter.ValuesTZ> wrong timezone `%s`", tz))
+	}
+	cols := make([]tzColumn, 0, len(o.tzColumns)+1)
+	cols = append(cols, o.tzColumns...)
+	o.tzColumns = append(cols, tzColumn{column: column, alias: alias, tz: tz})
+	return &o
+}
+
+// add CASE expression of the branches named alias.
+func (o querySet) ValuesCase(alias string, cases []CaseWhen, elseVal interface{}) QuerySeter {
+	if alias == "" {
+		panic(fmt.Errorf("<QuerySeter.ValuesCase> alias cannot be empty"))
+	}
+	if len(cases) == 0 {
+		panic(fmt.Errorf("<QuerySeter.ValuesCase> cases of `%s` cannot be empty", alias))
+	}
+	for _, c := range cases {
+		if c.Cond == nil || c.Cond.IsEmpty() {
+			panic(fmt.Errorf("<QuerySeter.ValuesCase> condition of a case of `%s` cannot be empty", alias))
+		}
+	}
+	whens := make([]CaseWhen, len(cases))
+	copy(whens, cases)
+	cols := make([]caseColumn, 0, len(o.cases)+1)
+	cols = append(cols, o.cases...)
+	o.cases = append(cols, caseColumn{alias: alias, whens: whens, elseVal: elseVal})
+	return &o
+}
+
+// add the condition named alias, selected as a bool.
+func (o querySet) ValuesBool(alias string, cond *Condition) QuerySeter {
+	if alias == "" {
+		panic(fmt.Errorf("<QuerySeter.ValuesBool> alias cannot be empty"))
+	}
+	if cond == nil || cond.IsEmpty() {
+		panic(fmt.Errorf("<QuerySeter.ValuesBool> condition of `%s` cannot be empty", alias))
+	}
+	cols := make([]boolColumn, 0, len(o.bools)+1)
+	cols = append(cols, o.bools...)
+	o.bools = append(cols, boolColumn{alias: alias, cond: cond})
+	return &o
+}
+
 // add ORDER expression.
 // "column" means ASC, "-column" means DESC.
 func (o querySet) OrderBy(expressions ...string) QuerySeter {
 	if len(expressions) <= 0 {
 		return &o
 	}
-	o.orders = order_clause.ParseOrder(expressions...)
+	orders := order_clause.ParseOrder(expressions...)
+	if err := o.checkOrders(orders); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.OrderBy> %w", err))
+		return &o
+	}
+	o.orders = orders
+	return &o
+}
+
+// add ORDER by the first non null value of the columns after the current orders.
+// "-column" as the first column means DESC.
+func (o querySet) OrderByCoalesce(cols ...string) QuerySeter {
+	if len(cols) == 0 {
+		return &o
+	}
+	sort := order_clause.SortAscending()
+	if cols[0] != "" && cols[0][0] == '-' {
+		sort = order_clause.SortDescending()
+		cols = append([]string{cols[0][1:]}, cols[1:]...)
+	}
+	for _, col := range cols {
+		if err := o.checkColumn(col); err != nil {
+			o.setErr(fmt.Errorf("<QuerySeter.OrderByCoalesce> %w", err))
+			return &o
+		}
+	}
+	orders := make([]*order_clause.Order, 0, len(o.orders)+1)
+	orders = append(orders, o.orders...)
+	o.orders = append(orders, order_clause.Clause(order_clause.Coalesce(cols...), sort))
+	return &o
+}
+
+// add ORDER by the column with COLLATE collation after the current orders.
+// "-column" means DESC.
+func (o querySet) OrderByCollate(col string, collation string) QuerySeter {
+	if !collationRegexp.MatchString(collation) {
+		panic(fmt.Errorf("<QuerySeter.OrderByCollate> wrong collation `%s`", collation))
+	}
+	sort := order_clause.SortAscending()
+	if col != "" && col[0] == '-' {
+		sort = order_clause.SortDescending()
+		col = col[1:]
+	}
+	if err := o.checkColumn(col); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.OrderByCollate> %w", err))
+		return &o
+	}
+	orders := make([]*order_clause.Order, 0, len(o.orders)+1)
+	orders = append(orders, o.orders...)
+	o.orders = append(orders, order_clause.Clause(order_clause.Column(col), order_clause.Collate(collation), sort))
+	return &o
+}
+
+// add ORDER by the distance of the point of latCol and lngCol to the point lat, lng after the current orders,
+// the nearest first.
+func (o querySet) OrderByDistance(latCol, lngCol string, lat, lng float64) QuerySeter {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		panic(fmt.Errorf("<QuerySeter.OrderByDistance> wrong point %v, %v", lat, lng))
+	}
+	for _, col := range []string{latCol, lngCol} {
+		if err := o.checkColumn(col); err != nil {
+			o.setErr(fmt.Errorf("<QuerySeter.OrderByDistance> %w", err))
+			return &o
+		}
+	}
+	orders := make([]*order_clause.Order, 0, len(o.orders)+1)
+	orders = append(orders, o.orders...)
+	o.orders = append(orders, order_clause.Clause(order_clause.Column(latCol),
+		order_clause.Distance(lngCol, lat, lng), order_clause.SortAscending()))
+	return &o
+}
+
+// do not apply the default order of the model.
+func (o querySet) NoDefaultOrder() QuerySeter {
+	o.noDefault = true
 	return &o
 }
 
@@ -157,6 +930,10 @@ func (o querySet) OrderClauses(orders ...*order_clause.Order) QuerySeter {
 	if len(orders) <= 0 {
 		return &o
 	}
+	if err := o.checkOrders(orders); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.OrderClauses> %w", err))
+		return &o
+	}
 	o.orders = orders
 	return &o
 }
@@ -167,12 +944,48 @@ func (o querySet) Distinct() QuerySeter {
 	return &o
 }
 
+// keep the first row of every primary key in the result of All.
+func (o querySet) DedupByPK() QuerySeter {
+	o.dedupPk = true
+	return &o
+}
+
+// leave the fields whose column fails to convert at their zero value and set the warnings of every read to warnings.
+func (o querySet) LenientScan(warnings *[]ScanWarning) QuerySeter {
+	if warnings == nil {
+		panic(fmt.Errorf("<QuerySeter.LenientScan> warnings cannot be nil"))
+	}
+	o.scanWarnings = warnings
+	return &o
+}
+
+// transform every model scanned by All and One by fn, after the ones set before.
+func (o querySet) Map(fn func(interface{}) interface{}) QuerySeter {
+	if fn == nil {
+		return &o
+	}
+	if prev := o.mapper; prev != nil {
+		o.mapper = func(elem interface{}) interface{} {
+			return fn(prev(elem))
+		}
+	} else {
+		o.mapper = fn
+	}
+	return &o
+}
+
 // add FOR UPDATE to SELECT
 func (o querySet) ForUpdate() QuerySeter {
 	o.forUpdate = true
 	return &o
 }
 
+// execute the query directly instead of by a cached prepared statement
+func (o querySet) NoPrepare() QuerySeter {
+	o.orm = o.orm.noPrepare()
+	return &o
+}
+
 // ForceIndex force index for query
 func (o querySet) ForceIndex(indexes ...string) QuerySeter {
 	o.useIndex = hints.KeyForceIndex
@@ -216,6 +1029,10 @@ func (o querySet) RelatedSel(params ...interface{}) QuerySeter {
 
 // Set condition to QuerySeter.
 func (o querySet) SetCond(cond *Condition) QuerySeter {
+	if err := o.checkCond(cond); err != nil {
+		o.setErr(fmt.Errorf("<QuerySeter.SetCond> %w", err))
+		return &o
+	}
 	o.cond = cond
 	return &o
 }
@@ -231,15 +1048,62 @@ func (o querySet) Count() (int64, error) {
 }
 
 func (o querySet) CountWithCtx(ctx context.Context) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
 	return o.orm.alias.DbBaser.Count(ctx, o.orm.db, o, o.mi, o.cond, o.orm.alias.TZ)
 }
 
+// return the number of rows per distinct value of groupCol
+func (o querySet) GroupCount(groupCol string) (map[interface{}]int64, error) {
+	return o.GroupCountWithCtx(context.Background(), groupCol)
+}
+
+func (o querySet) GroupCountWithCtx(ctx context.Context, groupCol string) (map[interface{}]int64, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+	return o.orm.alias.DbBaser.GroupCount(ctx, o.orm.db, o, o.mi, o.cond, groupCol, o.orm.alias.TZ)
+}
+
+// return the number of rows whose col is not NULL
+func (o querySet) CountColumn(col string) (int64, error) {
+	return o.CountColumnWithCtx(context.Background(), col)
+}
+
+func (o querySet) CountColumnWithCtx(ctx context.Context, col string) (int64, error) {
+	if len(o.groups) > 0 {
+		return 0, fmt.Errorf("<QuerySeter.CountColumn> cannot count the column of the groups of GroupBy, use GroupCountColumn")
+	}
+	if _, _, _, ok := newDbTables(o.mi, nil).parseExprs(o.mi, strings.Split(col, ExprSep)); !ok {
+		return 0, fmt.Errorf("<QuerySeter.CountColumn> unknown field/column name `%s` of model `%s`", col, o.mi.Name)
+	}
+	o.countCol = col
+	return o.CountWithCtx(ctx)
+}
+
+// return the number of rows whose col is not NULL per distinct value of groupCol
+func (o querySet) GroupCountColumn(groupCol string, col string) (map[interface{}]int64, error) {
+	return o.GroupCountColumnWithCtx(context.Background(), groupCol, col)
+}
+
+func (o querySet) GroupCountColumnWithCtx(ctx context.Context, groupCol string, col string) (map[interface{}]int64, error) {
+	if _, _, _, ok := newDbTables(o.mi, nil).parseExprs(o.mi, strings.Split(col, ExprSep)); !ok {
+		return nil, fmt.Errorf("<QuerySeter.GroupCountColumn> unknown field/column name `%s` of model `%s`", col, o.mi.Name)
+	}
+	o.countCol = col
+	return o.GroupCountWithCtx(ctx, groupCol)
+}
+
 // check result empty or not after QuerySeter executed
 func (o querySet) Exist() bool {
 	return o.ExistWithCtx(context.Background())
 }
 
 func (o querySet) ExistWithCtx(ctx context.Context) bool {
+	if o.err != nil {
+		return false
+	}
 	cnt, _ := o.orm.alias.DbBaser.Count(ctx, o.orm.db, o, o.mi, o.cond, o.orm.alias.TZ)
 	return cnt > 0
 }
@@ -250,6 +1114,9 @@ func (o querySet) Update(values Params) (int64, error) {
 }
 
 func (o querySet) UpdateWithCtx(ctx context.Context, values Params) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
 	return o.orm.alias.DbBaser.UpdateBatch(ctx, o.orm.db, &o, o.mi, o.cond, values, o.orm.alias.TZ)
 }
 
@@ -259,9 +1126,31 @@ func (o querySet) Delete() (int64, error) {
 }
 
 func (o querySet) DeleteWithCtx(ctx context.Context) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
 	return o.orm.alias.DbBaser.DeleteBatch(ctx, o.orm.db, &o, o.mi, o.cond, o.orm.alias.TZ)
 }
 
+// delete the rows and scan them to container.
+func (o querySet) DeleteReturning(container interface{}) (int64, error) {
+	return o.DeleteReturningWithCtx(context.Background(), container)
+}
+
+func (o querySet) DeleteReturningWithCtx(ctx context.Context, container interface{}) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
+	val := reflect.ValueOf(container)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Slice {
+		panic(fmt.Errorf("<QuerySeter.DeleteReturning> container need a ptr to slice, but got `%T`", container))
+	}
+	if typ := val.Elem().Type().Elem(); typ != o.mi.AddrField.Type() && typ != o.mi.AddrField.Elem().Type() {
+		panic(fmt.Errorf("<QuerySeter.DeleteReturning> container `%T` does not match model `%s`", container, o.mi.FullName))
+	}
+	return o.orm.alias.DbBaser.DeleteBatchReturning(ctx, o.orm.db, &o, o.mi, o.cond, container, o.orm.alias.TZ)
+}
+
 // PrepareInsert return an insert queryer.
 // it can be used in times.
 // example:
@@ -284,7 +1173,43 @@ func (o querySet) All(container interface{}, cols ...string) (int64, error) {
 
 // AllWithCtx see All
 func (o querySet) AllWithCtx(ctx context.Context, container interface{}, cols ...string) (int64, error) {
-	return o.orm.alias.DbBaser.ReadBatch(ctx, o.orm.db, o, o.mi, o.cond, container, o.orm.alias.TZ, cols)
+	if o.err != nil {
+		return 0, o.err
+	}
+	num, err := o.orm.alias.DbBaser.ReadBatch(ctx, o.orm.db, o, o.mi, o.cond, container, o.orm.alias.TZ, cols)
+	if err == nil && o.dedupPk {
+		num = dedupByPk(o.mi, container, num)
+	}
+	if err == nil {
+		o.orm.bindLazyContainer(ctx, o.mi, container)
+	}
+	if al := o.orm.alias; al.RowWarnThreshold > 0 && num > int64(al.RowWarnThreshold) {
+		warnLogRows(al, o.mi.Table, num)
+	}
+	return num, err
+}
+
+// AllInto query all data into the elements of the preallocated slice container.
+func (o querySet) AllInto(container interface{}, cols ...string) (int64, error) {
+	return o.AllIntoWithCtx(context.Background(), container, cols...)
+}
+
+// AllIntoWithCtx see AllInto
+func (o querySet) AllIntoWithCtx(ctx context.Context, container interface{}, cols ...string) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
+	num, err := o.orm.alias.DbBaser.ReadInto(ctx, o.orm.db, o, o.mi, o.cond, container, o.orm.alias.TZ, cols)
+	if err == nil && o.dedupPk {
+		num = dedupByPk(o.mi, container, num)
+	}
+	if err == nil {
+		o.orm.bindLazyContainer(ctx, o.mi, container)
+	}
+	if al := o.orm.alias; al.RowWarnThreshold > 0 && num > int64(al.RowWarnThreshold) {
+		warnLogRows(al, o.mi.Table, num)
+	}
+	return num, err
 }
 
 // One query one row data and map to containers.
@@ -295,6 +1220,9 @@ func (o querySet) One(container interface{}, cols ...string) error {
 
 // OneWithCtx check One
 func (o querySet) OneWithCtx(ctx context.Context, container interface{}, cols ...string) error {
+	if o.err != nil {
+		return o.err
+	}
 	o.limit = 1
 	num, err := o.orm.alias.DbBaser.ReadBatch(ctx, o.orm.db, o, o.mi, o.cond, container, o.orm.alias.TZ, cols)
 	if err != nil {
@@ -307,9 +1235,119 @@ func (o querySet) OneWithCtx(ctx context.Context, container interface{}, cols ..
 	if num > 1 {
 		return ErrMultiRows
 	}
+	o.orm.bindLazyContainer(ctx, o.mi, container)
 	return nil
 }
 
+// RowResult is a model read by Stream, or the error stopping the stream.
+type RowResult struct {
+	Model interface{}
+	Err   error
+}
+
+// return the plan of the query.
+func (o querySet) Explain(analyze bool) (string, error) {
+	return o.ExplainWithCtx(context.Background(), analyze)
+}
+
+func (o querySet) ExplainWithCtx(ctx context.Context, analyze bool) (string, error) {
+	if o.err != nil {
+		return "", o.err
+	}
+	return o.orm.alias.DbBaser.Explain(ctx, o.orm.db, o, o.mi, o.cond, analyze, o.orm.alias.TZ)
+}
+
+// set the number of rows fetched at a time by Stream.
+func (o querySet) FetchSize(n int) QuerySeter {
+	if n < 0 {
+		panic(fmt.Errorf("<QuerySeter.FetchSize> fetch size cannot be negative"))
+	}
+	o.fetchSize = n
+	return &o
+}
+
+// set the bytes of the strings and bytes scanned by All, AllInto and Stream before they are aborted.
+func (o querySet) MaxResultBytes(n int64) QuerySeter {
+	if n < 0 {
+		panic(fmt.Errorf("<QuerySeter.MaxResultBytes> max result bytes cannot be negative"))
+	}
+	o.maxResultBytes = n
+	return &o
+}
+
+// set the rows buffered by Stream and the time the consumer can take to receive a row from the full buffer.
+func (o querySet) StreamBuffer(size int, consumerTimeout time.Duration) QuerySeter {
+	if size < 0 || consumerTimeout < 0 {
+		panic(fmt.Errorf("<QuerySeter.StreamBuffer> buffer size and consumer timeout cannot be negative"))
+	}
+	o.streamBuffer = size
+	o.consumerTimeout = consumerTimeout
+	return &o
+}
+
+// Stream run the query and send the models read row by row to the returned channel,
+// which is closed after the last row, an error or the cancellation of ctx.
+func (o querySet) Stream(ctx context.Context) (<-chan RowResult, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+	rs, scan, err := o.orm.alias.DbBaser.StreamBatch(ctx, o.orm.db, o, o.mi, o.cond, o.orm.alias.TZ)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan RowResult, o.streamBuffer)
+	go func() {
+		defer close(ch)
+		defer rs.Close()
+		send := func(res RowResult) bool {
+			select {
+			case ch <- res:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		// wait for the consumer at most consumerTimeout, stalled when it does not receive in time
+		sendRow := func(res RowResult) (sent bool, stalled bool) {
+			if o.consumerTimeout <= 0 {
+				return send(res), false
+			}
+			timer := time.NewTimer(o.consumerTimeout)
+			defer timer.Stop()
+			select {
+			case ch <- res:
+				return true, false
+			case <-ctx.Done():
+				return false, false
+			case <-timer.C:
+				return false, true
+			}
+		}
+		for rs.Next() {
+			ind, err := scan()
+			if err != nil {
+				send(RowResult{Err: err})
+				return
+			}
+			o.orm.bindLazy(ctx, o.mi, ind)
+			sent, stalled := sendRow(RowResult{Model: ind.Addr().Interface()})
+			if stalled {
+				// release the connection before waiting to report the timeout after the buffered rows
+				rs.Close()
+				send(RowResult{Err: ErrConsumerTimeout})
+				return
+			}
+			if !sent {
+				return
+			}
+		}
+		if err := rs.Err(); err != nil && ctx.Err() == nil {
+			send(RowResult{Err: err})
+		}
+	}()
+	return ch, nil
+}
+
 // Values query All data and map to []map[string]interface.
 // expres means condition expression.
 // it converts data to []map[column]value.
@@ -319,6 +1357,9 @@ func (o querySet) Values(results *[]Params, exprs ...string) (int64, error) {
 
 // ValuesWithCtx see Values
 func (o querySet) ValuesWithCtx(ctx context.Context, results *[]Params, exprs ...string) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
 	return o.orm.alias.DbBaser.ReadValues(ctx, o.orm.db, o, o.mi, o.cond, exprs, results, o.orm.alias.TZ)
 }
 
@@ -329,6 +1370,9 @@ func (o querySet) ValuesList(results *[]ParamsList, exprs ...string) (int64, err
 }
 
 func (o querySet) ValuesListWithCtx(ctx context.Context, results *[]ParamsList, exprs ...string) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
 	return o.orm.alias.DbBaser.ReadValues(ctx, o.orm.db, o, o.mi, o.cond, exprs, results, o.orm.alias.TZ)
 }
 
@@ -340,6 +1384,9 @@ func (o querySet) ValuesFlat(result *ParamsList, expr string) (int64, error) {
 
 // ValuesFlatWithCtx see ValuesFlat
 func (o querySet) ValuesFlatWithCtx(ctx context.Context, result *ParamsList, expr string) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
 	return o.orm.alias.DbBaser.ReadValues(ctx, o.orm.db, o, o.mi, o.cond, []string{expr}, result, o.orm.alias.TZ)
 }
 
@@ -386,3 +1433,73 @@ func (o querySet) Aggregate(s string) QuerySeter {
 	o.aggregate = s
 	return &o
 }
+
+// select the group columns and the aggregations, grouped by the group columns.
+func (o querySet) AggregateBy(groups []string, aggrs ...Aggregation) QuerySeter {
+	if len(aggrs) == 0 {
+		panic(fmt.Errorf("<QuerySeter.AggregateBy> need at least one aggregation"))
+	}
+	o.groups = groups
+	o.aggrs = aggrs
+	return &o
+}
+
+// filter the groups by the conditions of their aggregations, joined by AND.
+func (o querySet) Having(conds ...HavingCond) QuerySeter {
+	if len(conds) == 0 {
+		panic(fmt.Errorf("<QuerySeter.Having> need at least one condition"))
+	}
+	o.havings = append(o.havings[:len(o.havings):len(o.havings)], conds...)
+	return &o
+}
+
+// remove the rows of container whose primary key has been seen before,
+// rows without primary key value are kept. return the number of rows left.
+func dedupByPk(mi *models.ModelInfo, container interface{}, num int64) int64 {
+	slice := reflect.Indirect(reflect.ValueOf(container))
+	if slice.Kind() != reflect.Slice {
+		return num
+	}
+	seen := make(map[interface{}]bool, slice.Len())
+	n := 0
+	for i := 0; i < slice.Len(); i++ {
+		elm := slice.Index(i)
+		ind := reflect.Indirect(elm)
+		if ind.Kind() == reflect.Struct && ind.Type() == mi.AddrField.Elem().Type() {
+			if pk := ind.FieldByIndex(mi.Fields.Pk.FieldIndex); !pk.IsZero() {
+				if seen[pkKey(pk.Interface())] {
+					continue
+				}
+				seen[pkKey(pk.Interface())] = true
+			}
+		}
+		slice.Index(n).Set(elm)
+		n++
+	}
+	slice.Set(slice.Slice(0, n))
+	return int64(n)
+}
+
+// SubQuery selects one column of a QuerySeter,
+// used as the value of in and of the quantified operators such as gt_all and lt_any.
+// Exclude of in a SubQuery is generated as NOT EXISTS, so that a NULL selected by it
+// does not exclude every row as NOT IN would, and the rows with NULL in the column are kept.
+type SubQuery struct {
+	qs  *querySet
+	col string
+}
+
+// NewSubQuery return a SubQuery selecting col of the rows of qs,
+// the error of the chained calls of qs is kept by the query filtering by the SubQuery.
+// for example:
+//
+//	sub := orm.NewSubQuery(o.QueryTable("post").Filter("User", 3), "ID")
+//	qs.Filter("ID__gt_all", sub)
+//	//sql-> WHERE T0.`id` > ALL (SELECT T0.`id` FROM `post` T0 WHERE T0.`user_id` = ?)
+func NewSubQuery(qs QuerySeter, col string) *SubQuery {
+	q, ok := qs.(*querySet)
+	if !ok {
+		panic(fmt.Errorf("<orm.NewSubQuery> unsupported QuerySeter `%T`", qs))
+	}
+	return &SubQuery{qs: q, col: col}
+}
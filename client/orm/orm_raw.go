@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/beego/beego/v2/client/orm/internal/models"
@@ -79,6 +80,12 @@ func (o rawSet) SetArgs(args ...interface{}) RawSeter {
 	return &o
 }
 
+// execute the query directly instead of by a cached prepared statement
+func (o rawSet) NoPrepare() RawSeter {
+	o.orm = o.orm.noPrepare()
+	return &o
+}
+
 // execute raw sql and return sql.Result
 func (o *rawSet) Exec() (sql.Result, error) {
 	query := o.query
@@ -908,6 +915,379 @@ func (o *rawSet) RowsToStruct(ptrStruct interface{}, keyCol, valueCol string) (i
 	return o.queryRowsTo(ptrStruct, keyCol, valueCol)
 }
 
+// query rows one by one and call fn with the raw scanned values of every row.
+// vals is reused for the next row, an error returned by fn stops the iteration.
+func (o *rawSet) QueryRowsFunc(fn func(cols []string, vals []interface{}) error) error {
+	query := o.query
+	o.orm.alias.DbBaser.ReplaceMarks(&query)
+
+	args := getFlatParams(nil, o.args, o.orm.alias.TZ)
+
+	rs, err := o.orm.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+
+	defer rs.Close()
+
+	cols, err := rs.Columns()
+	if err != nil {
+		return err
+	}
+
+	vals := make([]interface{}, len(cols))
+	refs := make([]interface{}, len(cols))
+	for i := range refs {
+		refs[i] = &vals[i]
+	}
+
+	for rs.Next() {
+		if err := rs.Scan(refs...); err != nil {
+			return err
+		}
+		if err := fn(cols, vals); err != nil {
+			return err
+		}
+	}
+
+	return rs.Err()
+}
+
+// a field of the struct set by QueryRowsWith, fi is nil for the structs not registered.
+type rawField struct {
+	index []int
+	fi    *models.FieldInfo
+}
+
+// get the fields of the struct typ by column, by the model mi if it is registered.
+func rawStructFields(typ reflect.Type, mi *models.ModelInfo) map[string]rawField {
+	fields := make(map[string]rawField)
+	if mi != nil {
+		for _, fi := range mi.Fields.FieldsDB {
+			fields[fi.Column] = rawField{index: fi.FieldIndex, fi: fi}
+		}
+		return fields
+	}
+	var walk func(typ reflect.Type, index []int)
+	walk = func(typ reflect.Type, index []int) {
+		for i := 0; i < typ.NumField(); i++ {
+			fe := typ.Field(i)
+			if fe.PkgPath != "" {
+				continue
+			}
+			fIndex := append(append([]int{}, index...), i)
+			if fe.Type.Kind() == reflect.Struct && fe.Type.String() != "time.Time" {
+				walk(fe.Type, fIndex)
+			}
+			_, tags := models.ParseStructTag(fe.Tag.Get(models.DefaultStructTagName))
+			col := tags["column"]
+			if col == "" {
+				col = models.NameStrategyMap[models.NameStrategy](fe.Name)
+			}
+			if _, ok := fields[col]; !ok {
+				fields[col] = rawField{index: fIndex}
+			}
+		}
+	}
+	walk(typ, nil)
+	return fields
+}
+
+// query rows to the slice of structs, decoding the columns of scanners by them instead of by the field types.
+func (o *rawSet) QueryRowsWith(scanners map[string]func([]byte) (interface{}, error), container interface{}) (int64, error) {
+	val := reflect.ValueOf(container)
+	sInd := reflect.Indirect(val)
+	if val.Kind() != reflect.Ptr || sInd.Kind() != reflect.Slice {
+		panic(errors.New("<RawSeter.QueryRowsWith> container must be a ptr slice"))
+	}
+	eTyp := sInd.Type().Elem()
+	typ := eTyp
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ.String() == "time.Time" {
+		panic(errors.New("<RawSeter.QueryRowsWith> container must be a ptr slice of structs"))
+	}
+	mi, _ := defaultModelCache.GetByFullName(models.GetFullName(typ))
+	fields := rawStructFields(typ, mi)
+
+	query := o.query
+	o.orm.alias.DbBaser.ReplaceMarks(&query)
+
+	args := getFlatParams(nil, o.args, o.orm.alias.TZ)
+	rows, err := o.orm.db.Query(query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	for col := range scanners {
+		found := false
+		for _, c := range columns {
+			if c == col {
+				found = true
+				break
+			}
+		}
+		if _, ok := fields[col]; !ok || !found {
+			return 0, fmt.Errorf("<RawSeter.QueryRowsWith> column `%s` of scanners is not in the result or the struct", col)
+		}
+	}
+
+	vals := make([]interface{}, len(columns))
+	refs := make([]interface{}, len(columns))
+	for i := range refs {
+		refs[i] = &vals[i]
+	}
+
+	var cnt int64
+	nInd := reflect.New(sInd.Type()).Elem()
+	for rows.Next() {
+		if err := rows.Scan(refs...); err != nil {
+			return 0, err
+		}
+
+		ind := reflect.New(typ).Elem()
+		for i, col := range columns {
+			f, ok := fields[col]
+			if !ok {
+				continue
+			}
+			field := ind.FieldByIndex(f.index)
+			if scan, ok := scanners[col]; ok {
+				value, err := scan(rawScannerBytes(vals[i]))
+				if err != nil {
+					return 0, fmt.Errorf("<RawSeter.QueryRowsWith> scan column `%s`: %w", col, err)
+				}
+				if err := setScannedValue(field, value); err != nil {
+					return 0, fmt.Errorf("<RawSeter.QueryRowsWith> column `%s`: %w", col, err)
+				}
+				continue
+			}
+			if err := o.setRawField(field, f.fi, vals[i]); err != nil {
+				return 0, err
+			}
+		}
+
+		if eTyp.Kind() == reflect.Ptr {
+			ind = ind.Addr()
+		}
+		nInd = reflect.Append(nInd, ind)
+		cnt++
+	}
+
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if cnt > 0 {
+		sInd.Set(nInd)
+	}
+	return cnt, nil
+}
+
+// set the raw value scanned by the driver to the field of the model field fi, which is nil for a plain struct.
+// the rel field gets a model of its pk, allocated unless another column already set it.
+func (o *rawSet) setRawField(field reflect.Value, fi *models.FieldInfo, val interface{}) error {
+	if fi != nil {
+		if fi.FieldType&IsRelField > 0 {
+			if val == nil {
+				return nil
+			}
+			if field.IsNil() {
+				field.Set(reflect.New(fi.RelModelInfo.AddrField.Elem().Type()))
+			}
+			field = field.Elem().FieldByIndex(fi.RelModelInfo.Fields.Pk.FieldIndex)
+		}
+		if fi.IsFielder {
+			if err := field.Addr().Interface().(models.Fielder).SetRaw(val); err != nil {
+				return fmt.Errorf("Set raw error: %w", err)
+			}
+			return nil
+		}
+	}
+	o.setFieldValue(field, val)
+	return nil
+}
+
+// a column of QueryRowsNested, set to the field of the struct at the end of the path of nested struct fields.
+type rawNestedColumn struct {
+	path  [][]int
+	field rawField
+	ok    bool
+}
+
+// resolve the column of QueryRowsNested in the struct typ, its prefixes separated by __ name the nested struct fields.
+// ok is false for a column without a field in its nested struct.
+func rawNestedField(typ reflect.Type, col string) (rawNestedColumn, error) {
+	var nc rawNestedColumn
+	names := strings.Split(col, ExprSep)
+	for _, name := range names[:len(names)-1] {
+		index, ok := rawNestedStruct(typ, name)
+		if !ok {
+			return nc, fmt.Errorf("<RawSeter.QueryRowsNested> column `%s` has no nested struct `%s`", col, name)
+		}
+		nc.path = append(nc.path, index)
+		typ = typ.FieldByIndex(index).Type
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+	}
+	mi, _ := defaultModelCache.GetByFullName(models.GetFullName(typ))
+	nc.field, nc.ok = rawStructFields(typ, mi)[names[len(names)-1]]
+	return nc, nil
+}
+
+// find the struct or ptr struct field of typ by its snake name or name.
+func rawNestedStruct(typ reflect.Type, name string) ([]int, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		fe := typ.Field(i)
+		ft := fe.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if fe.PkgPath != "" || ft.Kind() != reflect.Struct || ft.String() == "time.Time" {
+			continue
+		}
+		if models.NameStrategyMap[models.NameStrategy](fe.Name) == name || strings.EqualFold(fe.Name, name) {
+			return fe.Index, true
+		}
+	}
+	return nil, false
+}
+
+// query rows to the ptr slice of structs container, the columns prefixed by the nested struct fields, such as
+// customer__name, are set to them. A nested ptr struct is allocated by its first column which is not NULL.
+func (o *rawSet) QueryRowsNested(container interface{}) (int64, error) {
+	val := reflect.ValueOf(container)
+	sInd := reflect.Indirect(val)
+	if val.Kind() != reflect.Ptr || sInd.Kind() != reflect.Slice {
+		panic(errors.New("<RawSeter.QueryRowsNested> container must be a ptr slice"))
+	}
+	eTyp := sInd.Type().Elem()
+	typ := eTyp
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ.String() == "time.Time" {
+		panic(errors.New("<RawSeter.QueryRowsNested> container must be a ptr slice of structs"))
+	}
+
+	query := o.query
+	o.orm.alias.DbBaser.ReplaceMarks(&query)
+
+	args := getFlatParams(nil, o.args, o.orm.alias.TZ)
+	rows, err := o.orm.db.Query(query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	nested := make([]rawNestedColumn, len(columns))
+	for i, col := range columns {
+		if nested[i], err = rawNestedField(typ, col); err != nil {
+			return 0, err
+		}
+	}
+
+	vals := make([]interface{}, len(columns))
+	refs := make([]interface{}, len(columns))
+	for i := range refs {
+		refs[i] = &vals[i]
+	}
+
+	var cnt int64
+	nInd := reflect.New(sInd.Type()).Elem()
+	for rows.Next() {
+		if err := rows.Scan(refs...); err != nil {
+			return 0, err
+		}
+
+		ind := reflect.New(typ).Elem()
+	next:
+		for i, nc := range nested {
+			if !nc.ok {
+				continue
+			}
+			field := ind
+			for _, index := range nc.path {
+				field = field.FieldByIndex(index)
+				if field.Kind() == reflect.Ptr {
+					if field.IsNil() {
+						if vals[i] == nil {
+							continue next
+						}
+						field.Set(reflect.New(field.Type().Elem()))
+					}
+					field = field.Elem()
+				}
+			}
+			if err := o.setRawField(field.FieldByIndex(nc.field.index), nc.field.fi, vals[i]); err != nil {
+				return 0, err
+			}
+		}
+
+		if eTyp.Kind() == reflect.Ptr {
+			ind = ind.Addr()
+		}
+		nInd = reflect.Append(nInd, ind)
+		cnt++
+	}
+
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if cnt > 0 {
+		sInd.Set(nInd)
+	}
+	return cnt, nil
+}
+
+// the bytes of the raw value scanned by the driver, nil for NULL.
+func rawScannerBytes(val interface{}) []byte {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	case time.Time:
+		return []byte(v.Format(time.RFC3339Nano))
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+// set the value returned by a scanner to the field, converting it if needed.
+func setScannedValue(field reflect.Value, value interface{}) error {
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.Type().AssignableTo(field.Type()):
+		field.Set(rv)
+	case rv.Type().ConvertibleTo(field.Type()):
+		field.Set(rv.Convert(field.Type()))
+	default:
+		return fmt.Errorf("value of type `%T` cannot be set to field of type `%s`", value, field.Type())
+	}
+	return nil
+}
+
 // return prepared raw statement for used in times.
 func (o *rawSet) Prepare() (RawPreparer, error) {
 	return newRawPreparer(o)
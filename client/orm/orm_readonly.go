@@ -0,0 +1,131 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// ReadOnly return an Ormer of the same db rejecting all the writes with ErrReadOnlyOrmer,
+// its transactions are read-only too.
+func (o *orm) ReadOnly() Ormer {
+	if o.readOnly {
+		return o
+	}
+	ro := new(orm)
+	ro.alias = o.alias
+	ro.db = newDbReadOnly(o.db)
+	ro.readOnly = true
+	return ro
+}
+
+// read-only query struct.
+// Exec is rejected, the other statements only run when they start with a reading keyword.
+type dbReadOnly struct {
+	db dbQuerier
+}
+
+var (
+	_ dbQuerier = new(dbReadOnly)
+	_ txer      = new(dbReadOnly)
+	_ txEnder   = new(dbReadOnly)
+)
+
+func newDbReadOnly(db dbQuerier) dbQuerier {
+	return &dbReadOnly{db: db}
+}
+
+// the first keywords of the statements which do not write
+var readKeywords = []string{"SELECT", "WITH", "SHOW", "EXPLAIN", "DESCRIBE", "DESC"}
+
+// check the query is a statement reading only by its first keyword.
+func isReadQuery(query string) bool {
+	query = strings.TrimLeft(query, " \t\r\n(")
+	for _, kw := range readKeywords {
+		if len(query) >= len(kw) && strings.EqualFold(query[:len(kw)], kw) &&
+			(len(query) == len(kw) || !isIdentByte(query[len(kw)])) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func (d *dbReadOnly) Prepare(query string) (*sql.Stmt, error) {
+	return d.PrepareContext(context.Background(), query)
+}
+
+func (d *dbReadOnly) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if !isReadQuery(query) {
+		return nil, ErrReadOnlyOrmer
+	}
+	return d.db.PrepareContext(ctx, query)
+}
+
+func (d *dbReadOnly) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return d.ExecContext(context.Background(), query, args...)
+}
+
+func (d *dbReadOnly) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, ErrReadOnlyOrmer
+}
+
+func (d *dbReadOnly) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.QueryContext(context.Background(), query, args...)
+}
+
+func (d *dbReadOnly) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if !isReadQuery(query) {
+		return nil, ErrReadOnlyOrmer
+	}
+	return d.db.QueryContext(ctx, query, args...)
+}
+
+func (d *dbReadOnly) QueryRow(query string, args ...interface{}) *sql.Row {
+	return d.QueryRowContext(context.Background(), query, args...)
+}
+
+func (d *dbReadOnly) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if !isReadQuery(query) {
+		// the replayed error is returned by Scan of the row
+		return getReplayDB().QueryRowContext(ctx, "", ErrReadOnlyOrmer)
+	}
+	return d.db.QueryRowContext(ctx, query, args...)
+}
+
+func (d *dbReadOnly) Begin() (*sql.Tx, error) {
+	return d.BeginTx(context.Background(), nil)
+}
+
+func (d *dbReadOnly) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return d.db.(txer).BeginTx(ctx, opts)
+}
+
+func (d *dbReadOnly) Commit() error {
+	return d.db.(txEnder).Commit()
+}
+
+func (d *dbReadOnly) Rollback() error {
+	return d.db.(txEnder).Rollback()
+}
+
+func (d *dbReadOnly) RollbackUnlessCommit() error {
+	return d.db.(txEnder).RollbackUnlessCommit()
+}
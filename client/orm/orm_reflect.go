@@ -0,0 +1,103 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReflectedColumn is a column of a table read by ReflectTable.
+type ReflectedColumn struct {
+	Name     string
+	Type     string
+	Nullable bool
+}
+
+// ReflectedTable is an existing table read by ReflectTable,
+// to query without a registered model.
+type ReflectedTable struct {
+	Name    string
+	Columns []ReflectedColumn
+
+	quote string
+}
+
+// ReflectTable read the columns of the existing table tableName of the db alias aliasName,
+// sorted by name, from the columns query of the driver.
+// for example:
+//
+//	t, err := orm.ReflectTable("default", "legacy_user")
+//	var maps []orm.Params
+//	num, err := t.Select(o, "age > ?", 18).Values(&maps)
+func ReflectTable(aliasName, tableName string) (*ReflectedTable, error) {
+	al, ok := dataBaseCache.get(aliasName)
+	if !ok {
+		return nil, fmt.Errorf("<orm.ReflectTable> unknown DataBase alias name %s", aliasName)
+	}
+	if !schemaRegexp.MatchString(tableName) {
+		return nil, fmt.Errorf("<orm.ReflectTable> wrong table name `%s`", tableName)
+	}
+	columns, err := al.DbBaser.GetColumns(context.Background(), al.DB, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("<orm.ReflectTable> table `%s` not exists", tableName)
+	}
+
+	t := &ReflectedTable{Name: tableName, quote: al.DbBaser.TableQuote()}
+	for _, col := range columns {
+		// mysql and postgres tell IS_NULLABLE, sqlite tells notnull
+		null := strings.ToUpper(col[2])
+		t.Columns = append(t.Columns, ReflectedColumn{
+			Name:     col[0],
+			Type:     col[1],
+			Nullable: null == "YES" || null == "0",
+		})
+	}
+	sort.Slice(t.Columns, func(i, j int) bool {
+		return t.Columns[i].Name < t.Columns[j].Name
+	})
+	return t, nil
+}
+
+// Column return the column of the table by its name.
+func (t *ReflectedTable) Column(name string) (ReflectedColumn, bool) {
+	for _, col := range t.Columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return ReflectedColumn{}, false
+}
+
+// Select return the RawSeter selecting all the columns of the table by the query executor o,
+// with the raw sql condition where and its args if where is not empty.
+// Its rows scan into []Params by Values, or into the structs of the columns by QueryRows.
+func (t *ReflectedTable) Select(o QueryExecutor, where string, args ...interface{}) RawSeter {
+	Q := t.quote
+	cols := make([]string, 0, len(t.Columns))
+	for _, col := range t.Columns {
+		cols = append(cols, Q+col.Name+Q)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s%s%s", strings.Join(cols, ", "), Q, t.Name, Q)
+	if where != "" {
+		query += " WHERE " + where
+	}
+	return o.Raw(query, args...)
+}
@@ -0,0 +1,71 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"fmt"
+)
+
+// ScanWarning is the value of Column in the Row, from 0, which a LenientScan query
+// could not convert to its field, the field is left at its zero value.
+type ScanWarning struct {
+	Row    int
+	Column string
+	Value  interface{}
+	Err    error
+}
+
+func (w ScanWarning) Error() string {
+	return fmt.Sprintf("row %d column `%s`: raw value `%v` %s", w.Row, w.Column, w.Value, w.Err)
+}
+
+func (w ScanWarning) Unwrap() error {
+	return w.Err
+}
+
+// the total bytes of the strings and bytes scanned by a query, limited by MaxResultBytes.
+type resultBytes struct {
+	max   int64
+	total int64
+}
+
+// add the bytes of the scanned refs of a row, ErrResultTooLarge once the total exceeds max.
+func (r *resultBytes) add(refs []interface{}) error {
+	if r.max <= 0 {
+		return nil
+	}
+	for _, ref := range refs {
+		switch v := (*ref.(*interface{})).(type) {
+		case []byte:
+			r.total += int64(len(v))
+		case string:
+			r.total += int64(len(v))
+		}
+	}
+	if r.total > r.max {
+		return ErrResultTooLarge
+	}
+	return nil
+}
+
+// the warnings of a read of a LenientScan query, allocated by every All, AllInto and One.
+type scanWarnings struct {
+	row  int
+	list []ScanWarning
+}
+
+func (w *scanWarnings) add(column string, value interface{}, err error) {
+	w.list = append(w.list, ScanWarning{Row: w.row, Column: column, Value: value, Err: err})
+}
@@ -0,0 +1,67 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// schema names are written to sql quoted as is
+var schemaRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// UsingSchema return an Ormer of the same db qualifying the tables of the models by schema,
+// such as "tenant_1"."user", in the statements it generates and in those of its transactions.
+func (o *orm) UsingSchema(schema string) Ormer {
+	if !schemaRegexp.MatchString(schema) {
+		panic(fmt.Errorf("<Ormer.UsingSchema> wrong schema `%s`", schema))
+	}
+	al := *o.alias
+	al.DbBaser = withSchema(o.alias.DbBaser, schema)
+	// the entity cache is keyed by model and pk only, the rows of the schemas would be mixed
+	al.EntityCache = nil
+	so := new(orm)
+	so.alias = &al
+	so.db = o.db
+	so.readOnly = o.readOnly
+	return so
+}
+
+// copy the dialect db qualifying the tables by schema.
+func withSchema(db dbBaser, schema string) dbBaser {
+	switch d := db.(type) {
+	case *dbBaseMysql:
+		b := *d
+		b.ins, b.schema = &b, schema
+		return &b
+	case *dbBaseTidb:
+		b := *d
+		b.ins, b.schema = &b, schema
+		return &b
+	case *dbBasePostgres:
+		b := *d
+		b.ins, b.schema = &b, schema
+		return &b
+	case *dbBaseSqlite:
+		b := *d
+		b.ins, b.schema = &b, schema
+		return &b
+	case *dbBaseOracle:
+		b := *d
+		b.ins, b.schema = &b, schema
+		return &b
+	}
+	panic(fmt.Errorf("<Ormer.UsingSchema> schema is not supported by the driver"))
+}
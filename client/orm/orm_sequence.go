@@ -0,0 +1,154 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// SequenceTable stores the last values of the sequences of NextSequence,
+// a table in each schema of UsingSchema, so the sequences of the tenants are apart.
+const SequenceTable = "orm_sequences"
+
+// the aliases and sequences whose table and row are known to exist, by sequenceKey.
+var ensuredSequences sync.Map
+
+// the key of the sequence name in the SequenceTable of the db alias in ensuredSequences,
+// the table is qualified by the schema of UsingSchema if any, an empty name is the key of the table.
+// the copies of an alias made by UsingSchema have the same keys.
+type sequenceKey struct {
+	alias string
+	table string
+	name  string
+}
+
+func newSequenceKey(al *alias, name string) sequenceKey {
+	return sequenceKey{alias: al.Name, table: al.DbBaser.TableSQL(SequenceTable), name: name}
+}
+
+// NextSequence return the next value of the gap-free sequence name, starting at 1, in a transaction of its own,
+// so the value is never reused even if the row using it is not saved.
+// Call it on the TxOrmer of the transaction using the value to allocate it without gaps.
+func (o *orm) NextSequence(name string) (int64, error) {
+	return o.NextSequenceWithCtx(context.Background(), name)
+}
+
+func (o *orm) NextSequenceWithCtx(ctx context.Context, name string) (value int64, err error) {
+	err = o.DoTxWithCtx(ctx, func(ctx context.Context, txOrm TxOrmer) error {
+		value, err = txOrm.NextSequenceWithCtx(ctx, name)
+		return err
+	})
+	return value, err
+}
+
+// NextSequence return the next value of the gap-free sequence name, starting at 1, in the transaction.
+// The row of the sequence is locked until the end of the transaction,
+// so the concurrent transactions get the following values in turn, and a rollback returns the value.
+func (o *ormBase) NextSequence(name string) (int64, error) {
+	return o.NextSequenceWithCtx(context.Background(), name)
+}
+
+func (o *ormBase) NextSequenceWithCtx(ctx context.Context, name string) (int64, error) {
+	if name == "" {
+		return 0, fmt.Errorf("<Ormer.NextSequence> sequence name cannot be empty")
+	}
+	if err := ensureSequence(ctx, o.alias, name); err != nil {
+		return 0, err
+	}
+
+	Q := o.alias.DbBaser.TableQuote()
+	table := o.alias.DbBaser.TableSQL(SequenceTable)
+	query := fmt.Sprintf("UPDATE %s SET %svalue%s = %svalue%s + 1 WHERE %sname%s = ?", table, Q, Q, Q, Q, Q, Q)
+	o.alias.DbBaser.ReplaceMarks(&query)
+	res, err := o.db.ExecContext(ctx, query, name)
+	if err == nil {
+		var num int64
+		if num, err = res.RowsAffected(); err == nil && num == 0 {
+			// the row was deleted since it was ensured, create it again in the transaction
+			if _, err = o.db.ExecContext(ctx, sequenceInsertSQL(o.alias), name); err == nil {
+				_, err = o.db.ExecContext(ctx, query, name)
+			}
+		}
+	}
+	if err != nil {
+		forgetSequence(o.alias, name)
+		return 0, err
+	}
+	var value int64
+	query = fmt.Sprintf("SELECT %svalue%s FROM %s WHERE %sname%s = ?", Q, Q, table, Q, Q)
+	o.alias.DbBaser.ReplaceMarks(&query)
+	if err := o.db.QueryRowContext(ctx, query, name).Scan(&value); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// create SequenceTable and the row of the sequence name at 0 if needed,
+// by the db of the alias outside of the transaction, so their creation is kept after a rollback.
+func ensureSequence(ctx context.Context, al *alias, name string) error {
+	key := newSequenceKey(al, name)
+	if _, ok := ensuredSequences.Load(key); ok {
+		return nil
+	}
+
+	Q := al.DbBaser.TableQuote()
+	table := al.DbBaser.TableSQL(SequenceTable)
+	if _, ok := ensuredSequences.Load(newSequenceKey(al, "")); !ok {
+		// GetTables lists the tables of the default schema only, the table of a schema is probed by a query
+		var cnt int64
+		if err := al.DB.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE 1 = 0", table)).Scan(&cnt); err != nil {
+			types := al.DbBaser.DbTypes()
+			query := fmt.Sprintf("CREATE TABLE %s (%sname%s %s NOT NULL PRIMARY KEY, %svalue%s %s NOT NULL)",
+				table, Q, Q, fmt.Sprintf(types["string"], 255), Q, Q, types["int64"])
+			if _, err := al.DB.ExecContext(ctx, query); err != nil {
+				return err
+			}
+		}
+		ensuredSequences.Store(newSequenceKey(al, ""), true)
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %sname%s = ?", table, Q, Q)
+	al.DbBaser.ReplaceMarks(&query)
+	var cnt int64
+	if err := al.DB.QueryRowContext(ctx, query, name).Scan(&cnt); err != nil {
+		return err
+	}
+	if cnt == 0 {
+		if _, err := al.DB.ExecContext(ctx, sequenceInsertSQL(al), name); err != nil {
+			// inserted by another at the same time
+			if e := al.DB.QueryRowContext(ctx, query, name).Scan(&cnt); e != nil || cnt == 0 {
+				return err
+			}
+		}
+	}
+	ensuredSequences.Store(key, true)
+	return nil
+}
+
+// get the sql inserting the row of a sequence at 0.
+func sequenceInsertSQL(al *alias) string {
+	Q := al.DbBaser.TableQuote()
+	query := fmt.Sprintf("INSERT INTO %s (%sname%s, %svalue%s) VALUES (?, 0)", al.DbBaser.TableSQL(SequenceTable), Q, Q, Q, Q)
+	al.DbBaser.ReplaceMarks(&query)
+	return query
+}
+
+// forget the table and the row of the sequence name, they are checked again by the next NextSequence.
+func forgetSequence(al *alias, name string) {
+	ensuredSequences.Delete(newSequenceKey(al, ""))
+	ensuredSequences.Delete(newSequenceKey(al, name))
+}
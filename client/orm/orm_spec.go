@@ -0,0 +1,299 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/beego/beego/v2/client/orm/clauses"
+	"github.com/beego/beego/v2/client/orm/clauses/order_clause"
+	"github.com/beego/beego/v2/client/orm/internal/models"
+)
+
+// the operators allowed in a query spec, the exact operator is used when there is none.
+var specOperators = map[string]bool{
+	"exact":       true,
+	"iexact":      true,
+	"contains":    true,
+	"icontains":   true,
+	"gt":          true,
+	"gte":         true,
+	"lt":          true,
+	"lte":         true,
+	"eq":          true,
+	"ne":          true,
+	"startswith":  true,
+	"endswith":    true,
+	"istartswith": true,
+	"iendswith":   true,
+	"in":          true,
+	"between":     true,
+	"isnull":      true,
+}
+
+// the json spec of the conditions, ordering and pagination of a QuerySeter.
+type querySpec struct {
+	Cond   []condSpec `json:"cond,omitempty"`
+	Orders []string   `json:"orders,omitempty"`
+	Limit  int64      `json:"limit,omitempty"`
+	Offset int64      `json:"offset,omitempty"`
+}
+
+// a condition of the spec, either expr with its args or the sub conditions cond.
+type condSpec struct {
+	Expr string        `json:"expr,omitempty"`
+	Args []interface{} `json:"args,omitempty"`
+	Cond []condSpec    `json:"cond,omitempty"`
+	Or   bool          `json:"or,omitempty"`
+	Not  bool          `json:"not,omitempty"`
+}
+
+// MarshalSpec return the json spec of the conditions, orders, limit and offset of the query.
+// such as {"cond":[{"expr":"age__gt","args":[18]},{"expr":"name","args":["slene"],"or":true}],"orders":["-id"],"limit":10}
+// The conditions can only have the comparison, in, between and isnull operators and args of bool, number and string,
+// the other parts of a query, like RelatedSel, GroupBy or raw conditions, are not supported.
+func (o querySet) MarshalSpec() ([]byte, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+	if len(o.related) > 0 || o.relDepth > 0 || len(o.groups) > 0 || o.noDefault || o.distinct || o.dedupPk ||
+		o.forUpdate || len(o.indexes) > 0 || o.aggregate != "" || len(o.aggrs) > 0 || len(o.havings) > 0 || len(o.buckets) > 0 ||
+		len(o.cases) > 0 || len(o.bools) > 0 || len(o.tzColumns) > 0 || o.scanWarnings != nil || o.mapper != nil {
+		return nil, fmt.Errorf("<QuerySeter.MarshalSpec> only conditions, orders, limit and offset can be in a spec")
+	}
+	if o.limit < 0 || o.offset < 0 {
+		return nil, fmt.Errorf("<QuerySeter.MarshalSpec> limit and offset cannot be negative")
+	}
+	spec := querySpec{Limit: o.limit, Offset: o.offset}
+	if o.cond != nil {
+		cond, err := marshalCondSpec(o.mi, o.cond)
+		if err != nil {
+			return nil, err
+		}
+		spec.Cond = cond
+	}
+	for _, order := range o.orders {
+		_, _, _, distance := order.GetDistance()
+		if order.IsRaw() || len(order.GetCoalesce()) > 0 || order.GetCollate() != "" || len(order.GetValues()) > 0 || distance {
+			return nil, fmt.Errorf("<QuerySeter.MarshalSpec> raw, coalesce, collate, values and distance orders cannot be in a spec")
+		}
+		expr := strings.ReplaceAll(order.GetColumn(), clauses.ExprDot, ExprSep)
+		if order.GetSort() == order_clause.Descending {
+			expr = "-" + expr
+		}
+		spec.Orders = append(spec.Orders, expr)
+	}
+	return json.Marshal(spec)
+}
+
+func marshalCondSpec(mi *models.ModelInfo, cond *Condition) ([]condSpec, error) {
+	specs := make([]condSpec, 0, len(cond.params))
+	for _, p := range cond.params {
+		spec := condSpec{Or: p.isOr, Not: p.isNot}
+		switch {
+		case p.isCond:
+			sub, err := marshalCondSpec(mi, p.cond)
+			if err != nil {
+				return nil, err
+			}
+			spec.Cond = sub
+		case p.isRaw || p.topN != nil || p.window != nil || p.hasRelated != nil || p.coalesce != nil || p.collate != "" || p.cast != "" || len(p.concat) > 0 || p.rounded || p.timezone != "" || p.approx:
+			return nil, fmt.Errorf("<QuerySeter.MarshalSpec> condition `%s` cannot be in a spec", strings.Join(p.exprs, ExprSep))
+		case p.tree != nil:
+			return nil, errors.New("<QuerySeter.MarshalSpec> condition of Descendants cannot be in a spec")
+		case p.boolean:
+			spec.Expr, spec.Args = strings.Join(p.exprs, ExprSep), []interface{}{true}
+			if err := checkSpecField(mi, p.exprs); err != nil {
+				return nil, fmt.Errorf("<QuerySeter.MarshalSpec> %w", err)
+			}
+		default:
+			spec.Expr = strings.Join(p.exprs, ExprSep)
+			if err := checkSpecField(mi, specExprFields(p.exprs)); err != nil {
+				return nil, fmt.Errorf("<QuerySeter.MarshalSpec> %w, or its operator cannot be in a spec", err)
+			}
+			for _, arg := range p.args {
+				args, err := specArgs(arg)
+				if err != nil {
+					return nil, err
+				}
+				spec.Args = append(spec.Args, args...)
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// flatten the arg to the bool, number and string args of a spec.
+func specArgs(arg interface{}) ([]interface{}, error) {
+	if arg == nil {
+		return []interface{}{nil}, nil
+	}
+	val := reflect.Indirect(reflect.ValueOf(arg))
+	switch val.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return []interface{}{val.Interface()}, nil
+	case reflect.Slice, reflect.Array:
+		if val.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		args := make([]interface{}, 0, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			sub, err := specArgs(val.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, sub...)
+		}
+		return args, nil
+	}
+	return nil, fmt.Errorf("<QuerySeter.MarshalSpec> arg of type `%T` cannot be in a spec", arg)
+}
+
+// QuerySeterFromSpec return the QuerySeter of the table name model with the spec of MarshalSpec.
+// The spec is safe to come from clients: its exprs and orders must be registered fields of the model or
+// of its related models, its operators must be allowed in a spec and its args be json scalars,
+// so it never becomes raw sql. limit and offset cannot be negative, a limit of 0 is the default limit.
+func QuerySeterFromSpec(o Ormer, model string, spec []byte) (QuerySeter, error) {
+	mi, ok := defaultModelCache.Get(models.NameStrategyMap[models.DefaultNameStrategy](model))
+	if !ok {
+		return nil, fmt.Errorf("<orm.QuerySeterFromSpec> table name: `%s` not exists", model)
+	}
+
+	var qs querySpec
+	decoder := json.NewDecoder(bytes.NewReader(spec))
+	decoder.DisallowUnknownFields()
+	decoder.UseNumber()
+	if err := decoder.Decode(&qs); err != nil {
+		return nil, fmt.Errorf("<orm.QuerySeterFromSpec> invalid spec: %w", err)
+	}
+	if qs.Limit < 0 || qs.Offset < 0 {
+		return nil, fmt.Errorf("<orm.QuerySeterFromSpec> limit and offset cannot be negative")
+	}
+
+	cond, err := unmarshalCondSpec(mi, qs.Cond)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range qs.Orders {
+		if err = checkSpecField(mi, strings.Split(strings.TrimPrefix(order, "-"), ExprSep)); err != nil {
+			return nil, fmt.Errorf("<orm.QuerySeterFromSpec> %w", err)
+		}
+	}
+
+	q := o.QueryTable(model)
+	if cond != nil {
+		q = q.SetCond(cond)
+	}
+	if len(qs.Orders) > 0 {
+		q = q.OrderBy(qs.Orders...)
+	}
+	if qs.Limit > 0 {
+		q = q.Limit(qs.Limit)
+	}
+	if qs.Offset > 0 {
+		q = q.Offset(qs.Offset)
+	}
+	return q, nil
+}
+
+func unmarshalCondSpec(mi *models.ModelInfo, specs []condSpec) (*Condition, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	cond := NewCondition()
+	for _, spec := range specs {
+		p := condValue{isOr: spec.Or, isNot: spec.Not}
+		if len(spec.Cond) > 0 {
+			if spec.Expr != "" || len(spec.Args) > 0 {
+				return nil, fmt.Errorf("<orm.QuerySeterFromSpec> condition cannot have both expr and cond")
+			}
+			sub, err := unmarshalCondSpec(mi, spec.Cond)
+			if err != nil {
+				return nil, err
+			}
+			p.cond, p.isCond = sub, true
+		} else {
+			exprs := strings.Split(spec.Expr, ExprSep)
+			if err := checkSpecField(mi, specExprFields(exprs)); err != nil {
+				return nil, fmt.Errorf("<orm.QuerySeterFromSpec> %w", err)
+			}
+			if len(spec.Args) == 0 {
+				return nil, fmt.Errorf("<orm.QuerySeterFromSpec> condition `%s` needs args", spec.Expr)
+			}
+			for _, arg := range spec.Args {
+				switch v := arg.(type) {
+				case nil, bool, string:
+					p.args = append(p.args, v)
+				case json.Number:
+					if i, err := v.Int64(); err == nil {
+						p.args = append(p.args, i)
+					} else if f, err := v.Float64(); err == nil {
+						p.args = append(p.args, f)
+					} else {
+						return nil, fmt.Errorf("<orm.QuerySeterFromSpec> invalid number `%s`", v)
+					}
+				default:
+					return nil, fmt.Errorf("<orm.QuerySeterFromSpec> arg of condition `%s` must be a bool, number or string", spec.Expr)
+				}
+			}
+			p.exprs = exprs
+		}
+		cond.params = append(cond.params, p)
+	}
+	return cond, nil
+}
+
+// check the names are the path of a registered field through the rel fields.
+func checkSpecField(mi *models.ModelInfo, names []string) error {
+	if len(names) == 0 {
+		return errors.New("condition without field")
+	}
+	for i, name := range names {
+		fi, ok := mi.Fields.GetByAny(name)
+		if !ok && name == "pk" && mi.Fields.Pk != nil {
+			fi, ok = mi.Fields.Pk, true
+		}
+		if !ok {
+			return fmt.Errorf("unknown field `%s`", strings.Join(names, ExprSep))
+		}
+		if i < len(names)-1 {
+			if fi.RelModelInfo == nil {
+				return fmt.Errorf("field `%s` is not a relation", name)
+			}
+			mi = fi.RelModelInfo
+		}
+	}
+	return nil
+}
+
+// split the fields and the operator of the expr of a condition.
+func specExprFields(exprs []string) []string {
+	if len(exprs) == 0 {
+		return nil
+	}
+	if op := exprs[len(exprs)-1]; specOperators[op] && len(exprs) > 1 {
+		return exprs[:len(exprs)-1]
+	}
+	return exprs
+}
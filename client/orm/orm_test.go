@@ -18,13 +18,17 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -190,7 +194,8 @@ func TestGetDB(t *testing.T) {
 }
 
 func TestSyncDb(t *testing.T) {
-	RegisterModel(new(Data), new(DataNull), new(DataCustom))
+	RegisterComposite(new(Money))
+	RegisterModel(new(Data), new(DataNull), new(DataCustom), new(DataDecimal), new(DataSQLNull))
 	RegisterModel(new(User))
 	RegisterModel(new(Profile))
 	RegisterModel(new(Post))
@@ -210,7 +215,14 @@ func TestSyncDb(t *testing.T) {
 	RegisterModel(new(Index))
 	RegisterModel(new(StrPk))
 	RegisterModel(new(TM))
+	RegisterModel(new(LegacyLog))
 	RegisterModel(new(DeptInfo))
+	RegisterModel(new(Product))
+	RegisterModel(new(Task))
+	RegisterModel(new(Attachment))
+	RegisterModel(new(Person))
+	RegisterModel(new(Rating))
+	RegisterModel(new(Device), new(DeviceLog))
 
 	err := RunSyncdb("default", true, Debug)
 	throwFail(t, err)
@@ -219,7 +231,8 @@ func TestSyncDb(t *testing.T) {
 }
 
 func TestRegisterModels(_ *testing.T) {
-	RegisterModel(new(Data), new(DataNull), new(DataCustom))
+	RegisterComposite(new(Money))
+	RegisterModel(new(Data), new(DataNull), new(DataCustom), new(DataDecimal), new(DataSQLNull))
 	RegisterModel(new(User))
 	RegisterModel(new(Profile))
 	RegisterModel(new(Post))
@@ -239,7 +252,14 @@ func TestRegisterModels(_ *testing.T) {
 	RegisterModel(new(Index))
 	RegisterModel(new(StrPk))
 	RegisterModel(new(TM))
+	RegisterModel(new(LegacyLog))
 	RegisterModel(new(DeptInfo))
+	RegisterModel(new(Product))
+	RegisterModel(new(Task))
+	RegisterModel(new(Attachment))
+	RegisterModel(new(Person))
+	RegisterModel(new(Rating))
+	RegisterModel(new(Device), new(DeviceLog))
 
 	BootStrap()
 
@@ -401,10 +421,47 @@ func TestUnregisterModel(t *testing.T) {
 		qs.Aggregate("dept_name,max(salary) as max").GroupBy("dept_name").OrderBy("dept_name").All(&max)
 		throwFail(t, AssertIs(max[1].DeptName, "B"))
 		throwFail(t, AssertIs(max[1].Max, 4000))
+
+		type Stat struct {
+			DeptName  string
+			AvgSalary float64
+			Lowest    int
+		}
+		var stats []Stat
+		_, err = qs.AggregateBy([]string{"dept_name"}, Avg("salary", CoalesceZero()), Min("salary", As("lowest"))).
+			OrderBy("dept_name").All(&stats)
+		throwFail(t, err)
+		throwFail(t, AssertIs(len(stats), 2))
+		throwFail(t, AssertIs(stats[1].DeptName, "B"))
+		throwFail(t, AssertIs(stats[1].AvgSalary, 3000))
+		throwFail(t, AssertIs(stats[1].Lowest, 2000))
 	}
 	for i := 0; i < 5; i++ {
 		f()
 	}
+
+	type Dept struct {
+		DeptName string
+		Count    int
+	}
+	var depts []Dept
+	_, err := qs.AggregateBy([]string{"dept_name"}, Count("*")).
+		Having(Ratio(Sum("salary"), Count("*")).Gt(2000)).All(&depts)
+	throwFail(t, err)
+	throwFail(t, AssertIs(len(depts), 1))
+	throwFail(t, AssertIs(depts[0].DeptName, "B"))
+	throwFail(t, AssertIs(depts[0].Count, 3))
+
+	type Employees struct {
+		DeptName string
+		Names    string
+	}
+	var employees []Employees
+	_, err = qs.AggregateBy([]string{"dept_name"}, GroupConcat("employee_name", ",", "-salary", As("names"))).
+		OrderBy("dept_name").All(&employees)
+	throwFail(t, err)
+	throwFailNow(t, AssertIs(len(employees), 2))
+	throwFail(t, AssertIs(employees[1].Names, "B2,B3,B1"))
 }
 
 func TestNullDataTypes(t *testing.T) {
@@ -604,6 +661,206 @@ func TestDataCustomTypes(t *testing.T) {
 	}
 }
 
+func TestDataSQLNullTypes(t *testing.T) {
+	mi, ok := defaultModelCache.GetByMd(&DataSQLNull{})
+	throwFailNow(t, AssertIs(ok, true))
+	for _, fi := range mi.Fields.FieldsDB {
+		if !fi.Pk {
+			throwFail(t, AssertIs(fi.Null, true), fi.Name)
+		}
+	}
+
+	d := DataSQLNull{}
+	id, err := dORM.Insert(&d)
+	throwFail(t, err)
+	throwFail(t, AssertIs(id, 1))
+
+	d = DataSQLNull{ID: 1}
+	err = dORM.Read(&d)
+	throwFail(t, err)
+	throwFail(t, AssertIs(d.NullString.Valid, false))
+	throwFail(t, AssertIs(d.NullBool.Valid, false))
+	throwFail(t, AssertIs(d.NullByte.Valid, false))
+	throwFail(t, AssertIs(d.NullInt16.Valid, false))
+	throwFail(t, AssertIs(d.NullInt32.Valid, false))
+	throwFail(t, AssertIs(d.NullInt64.Valid, false))
+	throwFail(t, AssertIs(d.NullFloat64.Valid, false))
+	throwFail(t, AssertIs(d.NullTime.Valid, false))
+
+	now := time.Date(2023, 4, 5, 6, 7, 8, 0, DefaultTimeLoc)
+	d.NullString = sql.NullString{String: "beego", Valid: true}
+	d.NullBool = sql.NullBool{Bool: true, Valid: true}
+	d.NullByte = sql.NullByte{Byte: 8, Valid: true}
+	d.NullInt16 = sql.NullInt16{Int16: -16, Valid: true}
+	d.NullInt32 = sql.NullInt32{Int32: 32, Valid: true}
+	d.NullInt64 = sql.NullInt64{Int64: 64, Valid: true}
+	d.NullFloat64 = sql.NullFloat64{Float64: 64.5, Valid: true}
+	d.NullTime = sql.NullTime{Time: now, Valid: true}
+	num, err := dORM.Update(&d)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	d = DataSQLNull{ID: 1}
+	err = dORM.Read(&d)
+	throwFail(t, err)
+	throwFail(t, AssertIs(d.NullString, sql.NullString{String: "beego", Valid: true}))
+	throwFail(t, AssertIs(d.NullBool, sql.NullBool{Bool: true, Valid: true}))
+	throwFail(t, AssertIs(d.NullByte, sql.NullByte{Byte: 8, Valid: true}))
+	throwFail(t, AssertIs(d.NullInt16, sql.NullInt16{Int16: -16, Valid: true}))
+	throwFail(t, AssertIs(d.NullInt32, sql.NullInt32{Int32: 32, Valid: true}))
+	throwFail(t, AssertIs(d.NullInt64, sql.NullInt64{Int64: 64, Valid: true}))
+	throwFail(t, AssertIs(d.NullFloat64, sql.NullFloat64{Float64: 64.5, Valid: true}))
+	throwFail(t, AssertIs(d.NullTime.Valid, true))
+	throwFail(t, AssertIs(d.NullTime.Time.Equal(now), true), d.NullTime.Time)
+
+	// back to NULL
+	d.NullInt32 = sql.NullInt32{}
+	d.NullTime = sql.NullTime{}
+	num, err = dORM.Update(&d, "NullInt32", "NullTime")
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	d = DataSQLNull{ID: 1}
+	err = dORM.Read(&d)
+	throwFail(t, err)
+	throwFail(t, AssertIs(d.NullInt32.Valid, false))
+	throwFail(t, AssertIs(d.NullTime.Valid, false))
+	throwFail(t, AssertIs(d.NullInt16.Valid, true))
+}
+
+func TestInsertMultiHeterogeneous(t *testing.T) {
+	rows := []*DataSQLNull{
+		{NullString: sql.NullString{String: "multi", Valid: true}},
+		{ID: 10, NullInt32: sql.NullInt32{Int32: 32, Valid: true}},
+		{NullString: sql.NullString{String: "multi", Valid: true}, NullBool: sql.NullBool{Bool: true, Valid: true}},
+	}
+	al := getDbAlias("default")
+	q := &execRecordQuerier{recordQuerier{dbQuerier: al.DB}}
+	o := &orm{ormBase: ormBase{alias: al, db: q}}
+	num, err := o.InsertMulti(len(rows), rows)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	// the rows without the auto pk do not bind it
+	throwFailNow(t, AssertIs(len(q.queries), 3))
+	Q := al.DbBaser.TableQuote()
+	for i, query := range q.queries {
+		throwFail(t, AssertIs(strings.Contains(query, Q+"id"+Q), i == 1))
+	}
+
+	d := DataSQLNull{ID: 10}
+	err = dORM.Read(&d)
+	throwFail(t, err)
+	throwFail(t, AssertIs(d.NullInt32, sql.NullInt32{Int32: 32, Valid: true}))
+	throwFail(t, AssertIs(d.NullString.Valid, false))
+
+	var ds []*DataSQLNull
+	num, err = dORM.QueryTable(new(DataSQLNull)).Filter("NullString", "multi").OrderBy("ID").All(&ds)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+	throwFail(t, AssertIs(ds[0].NullBool.Valid, false))
+	throwFail(t, AssertIs(ds[0].NullInt32.Valid, false))
+	throwFail(t, AssertIs(ds[1].NullBool, sql.NullBool{Bool: true, Valid: true}))
+	throwFail(t, AssertNot(ds[0].ID, 0))
+	throwFail(t, AssertNot(ds[0].ID, ds[1].ID))
+}
+
+func TestFilterCoalesce(t *testing.T) {
+	qs := dORM.QueryTable(new(DataSQLNull))
+	num, err := qs.FilterCoalesce("NullInt32", 0, "", 0).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+
+	num, err = qs.FilterCoalesce("NullInt32", 100, "gt", 50).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+
+	num, err = qs.FilterCoalesce("NullString", "none", "in", []string{"none", "multi"}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+}
+
+func TestInsertMultiReturning(t *testing.T) {
+	rows := []DataSQLNull{
+		{NullString: sql.NullString{String: "returning 1", Valid: true}},
+		{NullString: sql.NullString{String: "returning 2", Valid: true}, NullInt64: sql.NullInt64{Int64: 2, Valid: true}},
+		{NullString: sql.NullString{String: "returning 3", Valid: true}},
+	}
+	var inserted []*DataSQLNull
+	err := dORM.InsertMultiReturning(2, rows, &inserted)
+	if !IsPostgres && !IsSqlite {
+		throwFail(t, AssertIs(err, ErrNotImplement))
+		return
+	}
+	throwFail(t, err)
+	throwFailNow(t, AssertIs(len(inserted), 3))
+	for i, d := range inserted {
+		throwFail(t, AssertIs(d.NullString.String, rows[i].NullString.String))
+		throwFail(t, AssertNot(d.ID, 0))
+		if i > 0 {
+			throwFail(t, AssertIs(d.ID > inserted[i-1].ID, true))
+		}
+
+		read := DataSQLNull{ID: d.ID}
+		throwFail(t, dORM.Read(&read))
+		throwFail(t, AssertIs(read.NullString.String, rows[i].NullString.String))
+	}
+	throwFail(t, AssertIs(inserted[1].NullInt64, sql.NullInt64{Int64: 2, Valid: true}))
+	throwFail(t, AssertIs(inserted[2].NullInt64.Valid, false))
+
+	var values []DataSQLNull
+	err = dORM.InsertMultiReturning(1, []*DataSQLNull{{NullString: sql.NullString{String: "returning 4", Valid: true}}}, &values)
+	throwFail(t, err)
+	throwFailNow(t, AssertIs(len(values), 1))
+	throwFail(t, AssertIs(values[0].ID > inserted[2].ID, true))
+
+	assert.Panics(t, func() {
+		var users []*User
+		_ = dORM.InsertMultiReturning(1, rows, &users)
+	})
+}
+
+func TestDataDecimalTypes(t *testing.T) {
+	price, _ := new(big.Rat).SetString("12345678901234567890.0123456789")
+	d := DataDecimal{}
+	d.Price.Set(price)
+
+	id, err := dORM.Insert(&d)
+	throwFail(t, err)
+	throwFail(t, AssertIs(id, 1))
+
+	d = DataDecimal{ID: 1}
+	err = dORM.Read(&d)
+	throwFail(t, err)
+	throwFail(t, AssertIs(d.Price.Cmp(price), 0))
+	throwFail(t, AssertIs(d.Discount, nil))
+
+	discount, _ := new(big.Rat).SetString("0.1000000001")
+	d.Discount = discount
+	num, err := dORM.Update(&d, "Discount")
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	d = DataDecimal{ID: 1}
+	err = dORM.Read(&d)
+	throwFail(t, err)
+	throwFail(t, AssertIs(d.Price.FloatString(10), "12345678901234567890.0123456789"))
+	throwFail(t, AssertIs(d.Discount.Cmp(discount), 0))
+
+	num, err = dORM.QueryTable("data_decimal").Filter("price", price).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	// sqlite keeps the exact decimals as text, which are compared as text
+	nine := new(big.Rat).SetInt64(9)
+	num, err = dORM.QueryTable("data_decimal").Filter("price__gt", nine).Count()
+	throwFail(t, err)
+	if IsSqlite {
+		throwFail(t, AssertIs(num, 0))
+	} else {
+		throwFail(t, AssertIs(num, 1))
+	}
+}
+
 func TestCRUD(t *testing.T) {
 	profile := NewProfile()
 	profile.Age = 30
@@ -872,6 +1129,14 @@ func TestCustomField(t *testing.T) {
 	throwFailNow(t, AssertIs(user.Extra.Name, "beego"))
 	throwFailNow(t, AssertIs(user.Extra.Data, "orm"))
 
+	// the langs are stored comma-separated
+	num, err := dORM.QueryTable("user").Filter("Langs__includes", "en-US").Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	num, err = dORM.QueryTable("user").Filter("Langs__includes", "en").Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 0))
+
 	var users []User
 	Q := dDbBaser.TableQuote()
 	n, err := dORM.Raw(fmt.Sprintf("SELECT * FROM %suser%s where id=?", Q, Q), 2).QueryRows(&users)
@@ -1063,6 +1328,50 @@ func TestOperators(t *testing.T) {
 	throwFail(t, err)
 	throwFail(t, AssertIs(num, 2))
 
+	num, err = qs.Filter("user_name__len__gt", 5).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = qs.Filter("user_name__len", 5).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("user_name__upper", Fn("upper", "Slene")).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("user_name__lower__gt", Fn("LOWER", "Nobody")).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	assert.Panics(t, func() { Fn("LENGTH", "slene") })
+
+	typ := "char(30)"
+	if IsPostgres {
+		typ = "varchar(30)"
+	} else if IsSqlite {
+		typ = "text"
+	}
+	num, err = qs.Filter("user_name", Typed("slene", typ)).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("user_name__in", Typed([]string{"slene", "astaxie"}, typ)).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	assert.Panics(t, func() { Typed("slene", "text; DROP TABLE user") })
+
+	num, err = qs.Filter("user_name__ilike_any", []string{"SLE%", "%TAXIE"}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = qs.FilterCast("id", "string", "", "2").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	assert.Panics(t, func() { qs.FilterCast("id", "blob", "", "2") })
+
 	num, err = qs.FilterRaw("user_name", "= 'slene'").Count()
 	throwFail(t, err)
 	throwFail(t, AssertIs(num, 1))
@@ -1076,6 +1385,27 @@ func TestOperators(t *testing.T) {
 	throwFail(t, AssertIs(num, 1))
 }
 
+func TestFilterInModels(t *testing.T) {
+	qs := dORM.QueryTable("post")
+	users := []*User{{ID: 2}, {ID: 3}}
+	num, err := qs.Filter("user__in", users).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+
+	num, err = qs.Filter("user__in", []User{{ID: 4}}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	// nil models are skipped
+	num, err = qs.Filter("user__in", []*User{nil, {ID: 4}}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("tags__tag__in", []*Tag{{ID: 2}, {ID: 3}}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+}
+
 func TestSetCond(t *testing.T) {
 	cond := NewCondition()
 	cond1 := cond.And("profile__isnull", false).AndNot("status__in", 1).Or("profile__age__gt", 2000)
@@ -1105,6 +1435,92 @@ func TestSetCond(t *testing.T) {
 	throwFail(t, err)
 	throwFail(t, AssertIs(num, 3))
 }
+func TestQuerySeterSpec(t *testing.T) {
+	cond := NewCondition()
+	cond1 := cond.And("profile__isnull", false).AndNot("status__in", []int{1}).Or("profile__age__gt", 2000)
+	cond2 := cond.AndCond(cond1).OrCond(cond.And("user_name", "slene"))
+	qs := dORM.QueryTable("user").SetCond(cond2).OrderBy("-user_name").Limit(10)
+
+	spec, err := qs.MarshalSpec()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(string(spec), `{"cond":[{"cond":[{"expr":"profile__isnull","args":[false]},`+
+		`{"expr":"status__in","args":[1],"not":true},{"expr":"profile__age__gt","args":[2000],"or":true}]},`+
+		`{"cond":[{"expr":"user_name","args":["slene"]}],"or":true}],"orders":["-user_name"],"limit":10}`))
+
+	var users []*User
+	fromSpec, err := QuerySeterFromSpec(dORM, "user", spec)
+	throwFailNow(t, err)
+	num, err := fromSpec.All(&users)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFailNow(t, AssertIs(users[0].UserName, "slene"))
+	throwFailNow(t, AssertIs(users[1].UserName, "astaxie"))
+
+	again, err := fromSpec.MarshalSpec()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(string(again), string(spec)))
+
+	_, err = dORM.QueryTable("user").SetCond(cond.Raw("user_name", "= 'slene'")).MarshalSpec()
+	throwFailNow(t, AssertIs(err != nil, true))
+	_, err = dORM.QueryTable("user").RelatedSel().MarshalSpec()
+	throwFailNow(t, AssertIs(err != nil, true))
+	_, err = dORM.QueryTable("user").Filter("created__gt", time.Now()).MarshalSpec()
+	throwFailNow(t, AssertIs(err != nil, true))
+	_, err = dORM.QueryTable("comment").Descendants("ID", "Parent", 1).MarshalSpec()
+	throwFailNow(t, AssertIs(err != nil, true))
+
+	// nothing of a spec becomes raw sql
+	for _, unsafe := range []string{
+		`{"cond":[{"expr":"user_name = 'slene' OR 1=1 --","args":[1]}]}`,
+		`{"cond":[{"expr":"user_name__similar","args":["slene"]}]}`,
+		`{"cond":[{"expr":"profile__age__gt__len","args":[1]}]}`,
+		`{"cond":[{"expr":"user_name","args":[{"raw":"1=1"}]}]}`,
+		`{"cond":[{"expr":"user_name","args":[["slene"]]}]}`,
+		`{"cond":[{"expr":"user_name"}]}`,
+		`{"cond":[{"expr":"user_name","args":["slene"],"cond":[{"expr":"id","args":[1]}]}]}`,
+		`{"orders":["id; DROP TABLE user"]}`,
+		`{"orders":["-profile__secret"]}`,
+		`{"raw":"DELETE FROM user"}`,
+		`{"limit":-1}`,
+		`not json`,
+	} {
+		_, err = QuerySeterFromSpec(dORM, "user", []byte(unsafe))
+		throwFailNow(t, AssertIs(err != nil, true), unsafe)
+	}
+	_, err = QuerySeterFromSpec(dORM, "not_exist", []byte(`{}`))
+	throwFailNow(t, AssertIs(err != nil, true))
+}
+
+func TestPolymorphicFilter(t *testing.T) {
+	var post Post
+	throwFailNow(t, dORM.QueryTable("post").OrderBy("id").One(&post))
+	// a user of the same id
+	user := &User{ID: post.ID}
+
+	for _, a := range []*Attachment{
+		{Name: "post", AttachableType: "post", AttachableID: post.ID},
+		{Name: "user", AttachableType: "user", AttachableID: user.ID},
+	} {
+		_, err := dORM.Insert(a)
+		throwFailNow(t, err)
+	}
+
+	al := getDbAlias("default")
+	q := &recordQuerier{dbQuerier: al.DB}
+	o := &ormBase{alias: al, db: q}
+	var attachments []*Attachment
+	num, err := o.QueryTable("attachment").Filter("attachable", &post).All(&attachments)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFailNow(t, AssertIs(attachments[0].Name, "post"))
+	Q := al.DbBaser.TableQuote()
+	throwFailNow(t, AssertIs(strings.Contains(q.queries[0],
+		fmt.Sprintf("(T0.%sattachable_type%s = ? AND T0.%sattachable_id%s = ?)", Q, Q, Q, Q)), true))
+
+	num, err = dORM.QueryTable("attachment").Exclude("attachable", user).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+}
 
 func TestLimit(t *testing.T) {
 	var posts []*Post
@@ -1200,6 +1616,44 @@ func TestOrderBy(t *testing.T) {
 	throwFail(t, err)
 	throwFail(t, AssertIs(num, 1))
 
+	num, err = qs.OrderByCoalesce("profile__age", "id").All(&users)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	throwFail(t, AssertIs(users[0].UserName, "nobody"))
+	throwFail(t, AssertIs(users[1].UserName, "slene"))
+	throwFail(t, AssertIs(users[2].UserName, "astaxie"))
+
+	num, err = qs.OrderBy("-is_staff").OrderByCoalesce("-profile__age", "id").All(&users)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	throwFail(t, AssertIs(users[0].UserName, "astaxie"))
+
+	collation := "NOCASE"
+	if IsMysql || IsTidb {
+		collation = "utf8mb4_general_ci"
+	} else if IsPostgres {
+		collation = `"C"`
+	}
+	num, err = qs.OrderByCollate("-user_name", collation).All(&users)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	throwFail(t, AssertIs(users[0].UserName, "slene"))
+	throwFail(t, AssertIs(users[1].UserName, "nobody"))
+	throwFail(t, AssertIs(users[2].UserName, "astaxie"))
+
+	num, err = qs.FilterInOrdered("user_name", []interface{}{"slene", "nobody", "astaxie"}).All(&users)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	throwFail(t, AssertIs(users[0].UserName, "slene"))
+	throwFail(t, AssertIs(users[1].UserName, "nobody"))
+	throwFail(t, AssertIs(users[2].UserName, "astaxie"))
+
+	num, err = qs.FilterInOrdered("user_name", []interface{}{"nobody", "slene", "unknown"}).Filter("is_staff", false).All(&users)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+	throwFail(t, AssertIs(users[0].UserName, "nobody"))
+	throwFail(t, AssertIs(users[1].UserName, "slene"))
+
 	if IsMysql {
 		num, err = qs.OrderClauses(
 			order_clause.Clause(
@@ -1212,6 +1666,132 @@ func TestOrderBy(t *testing.T) {
 	}
 }
 
+func TestCursor(t *testing.T) {
+	qs := dORM.QueryTable("user")
+
+	// read a row and resume from its position by a new cursor, the pages have one row
+	cursor := qs.Limit(1).Cursor([]string{"user_name"})
+	var user User
+	ok, err := cursor.Next(&user)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(ok, true))
+	throwFailNow(t, AssertIs(user.UserName, "astaxie"))
+	position := cursor.Position()
+	throwFailNow(t, AssertNot(position, nil))
+
+	cursor = qs.Limit(1).Cursor([]string{"user_name"})
+	throwFailNow(t, cursor.Seek(position))
+	var names []string
+	for {
+		ok, err = cursor.Next(&user)
+		throwFailNow(t, err)
+		if !ok {
+			break
+		}
+		names = append(names, user.UserName)
+	}
+	throwFailNow(t, AssertIs(strings.Join(names, ","), "nobody,slene"))
+
+	// the pk breaks the ties of the order
+	cursor = qs.Cursor([]string{"-is_staff"})
+	names = nil
+	for ok, err = cursor.Next(&user); ok; ok, err = cursor.Next(&user) {
+		names = append(names, user.UserName)
+		if len(names) == 2 {
+			position = cursor.Position()
+			break
+		}
+	}
+	throwFailNow(t, err)
+	cursor = qs.Limit(1).Cursor([]string{"-is_staff"})
+	throwFailNow(t, cursor.Seek(position))
+	for ok, err = cursor.Next(&user); ok; ok, err = cursor.Next(&user) {
+		names = append(names, user.UserName)
+	}
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(len(names), 3))
+	throwFailNow(t, AssertIs(names[2], "nobody"))
+
+	// a time column
+	var post Post
+	cursor = dORM.QueryTable("post").Limit(3).Cursor([]string{"-created"})
+	num := 0
+	for ok, err = cursor.Next(&post); ok; ok, err = cursor.Next(&post) {
+		num++
+		if num == 2 {
+			position = cursor.Position()
+		}
+	}
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 4))
+	cursor = dORM.QueryTable("post").Cursor([]string{"-created"})
+	throwFailNow(t, cursor.Seek(position))
+	num = 0
+	for ok, err = cursor.Next(&post); ok; ok, err = cursor.Next(&post) {
+		num++
+	}
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+
+	throwFailNow(t, AssertNot(cursor.Seek([]byte(`[1]`)), nil))
+	_, err = qs.Cursor([]string{"unknown"}).Next(&user)
+	throwFailNow(t, AssertNot(err, nil))
+	_, err = qs.Cursor([]string{"user_name"}).Next(&post)
+	throwFailNow(t, AssertNot(err, nil))
+}
+
+func TestTopNPerGroup(t *testing.T) {
+	var posts []*Post
+	qs := dORM.QueryTable("post")
+	num, err := qs.TopNPerGroup([]string{"user"}, "-id", 1).OrderBy("id").All(&posts)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(posts[0].ID, 1))
+	throwFailNow(t, AssertIs(posts[1].ID, 3))
+	throwFailNow(t, AssertIs(posts[2].ID, 4))
+
+	num, err = qs.Filter("title__in", "Examples", "Commentary").TopNPerGroup([]string{"user"}, "-id", 1).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+
+	num, err = qs.Filter("title__in", "Examples", "Commentary").
+		TopNPerGroup([]string{"user__user_name"}, "-id", 1).Filter("user__user_name", "astaxie").All(&posts)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFailNow(t, AssertIs(posts[0].Title, "Examples"))
+
+	if IsSqlite {
+		throwFailNow(t, AssertNot(getDbAlias("default").SqliteVersion, ""))
+		// the window functions are missing before sqlite 3.25
+		al := *getDbAlias("default")
+		al.SqliteVersion = "3.24.0"
+		o := &ormBase{alias: &al, db: al.DB}
+		_, err = o.QueryTable("post").TopNPerGroup([]string{"user"}, "-id", 1).All(&posts)
+		throwFailNow(t, AssertNot(err, nil))
+	}
+}
+
+func TestFilterWindow(t *testing.T) {
+	var posts []*Post
+	qs := dORM.QueryTable("post")
+	// the posts with one before them of the same user
+	aggr := WindowAggr{Func: "COUNT", Column: "id", Partition: []string{"user"}, OrderBy: "id", Preceding: 1}
+	num, err := qs.FilterWindow(aggr, "gt", 1).All(&posts)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFailNow(t, AssertIs(posts[0].ID, 3))
+
+	// the window is among the conditions before it
+	num, err = qs.Filter("id__gt", 2).FilterWindow(aggr, "gt", 1).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 0))
+
+	aggr.Preceding = 0
+	num, err = qs.FilterWindow(aggr, "lte", 1).Filter("user__user_name", "astaxie").Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+}
+
 func TestCount(t *testing.T) {
 	qs := dORM.QueryTable("user")
 	num, err := qs.Filter("user_name", "nobody").Count()
@@ -1231,31 +1811,391 @@ func TestCount(t *testing.T) {
 	throwFail(t, AssertIs(num, 1))
 }
 
-func TestAll(t *testing.T) {
-	var users []*User
+func TestGroupCount(t *testing.T) {
 	qs := dORM.QueryTable("user")
-	num, err := qs.OrderBy("Id").All(&users)
+	counts, err := qs.GroupCount("status")
 	throwFail(t, err)
-	throwFailNow(t, AssertIs(num, 3))
+	assert.Equal(t, map[interface{}]int64{int64(1): 1, int64(2): 1, int64(3): 1}, counts)
 
-	throwFail(t, AssertIs(users[0].UserName, "slene"))
-	throwFail(t, AssertIs(users[1].UserName, "astaxie"))
-	throwFail(t, AssertIs(users[2].UserName, "nobody"))
+	counts, err = qs.Filter("status__gt", 1).GroupCount("is_staff")
+	throwFail(t, err)
+	assert.Equal(t, map[interface{}]int64{true: 1, false: 1}, counts)
 
-	var users2 []User
-	qs = dORM.QueryTable("user")
-	num, err = qs.OrderBy("Id").All(&users2)
+	// nobody has no profile, so it is counted under the nil key
+	counts, err = qs.GroupCount("profile")
 	throwFail(t, err)
-	throwFailNow(t, AssertIs(num, 3))
+	assert.Equal(t, map[interface{}]int64{int64(2): 1, int64(3): 1, nil: 1}, counts)
 
-	throwFailNow(t, AssertIs(users2[0].UserName, "slene"))
-	throwFailNow(t, AssertIs(users2[1].UserName, "astaxie"))
-	throwFailNow(t, AssertIs(users2[2].UserName, "nobody"))
+	counts, err = dORM.QueryTable("post").GroupCount("user__user_name")
+	throwFail(t, err)
+	assert.Equal(t, map[interface{}]int64{"slene": 1, "astaxie": 2, "nobody": 1}, counts)
 
-	qs = dORM.QueryTable("user")
-	num, err = qs.OrderBy("Id").RelatedSel().All(&users2, "UserName")
+	// NULL and zero are different groups
+	zero := 0
+	d := &DataNull{IntPtr: &zero}
+	_, err = dORM.Insert(d)
+	throwFailNow(t, err)
+	defer dORM.Delete(d)
+	nulls, err := dORM.QueryTable("data_null").Filter("IntPtr__isnull", true).Count()
 	throwFail(t, err)
-	throwFailNow(t, AssertIs(num, 3))
+	zeros, err := dORM.QueryTable("data_null").Filter("IntPtr", 0).Count()
+	throwFail(t, err)
+	counts, err = dORM.QueryTable("data_null").GroupCount("int_ptr")
+	throwFail(t, err)
+	throwFail(t, AssertIs(counts[nil], nulls))
+	throwFail(t, AssertIs(counts[int64(0)], zeros))
+	throwFail(t, AssertIs(nulls > 0 && zeros > 0, true))
+
+	// COUNT of a column does not count its NULL values
+	all, err := dORM.QueryTable("data_null").Count()
+	throwFail(t, err)
+	num, err := dORM.QueryTable("data_null").CountColumn("int_ptr")
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, all-nulls))
+	counts, err = qs.GroupCountColumn("is_staff", "profile")
+	throwFail(t, err)
+	total, err := qs.GroupCount("is_staff")
+	throwFail(t, err)
+	throwFail(t, AssertIs(counts[false], total[false]-1))
+	throwFail(t, AssertIs(counts[true], total[true]))
+
+	_, err = qs.GroupCount("not_exist")
+	assert.NotNil(t, err)
+	_, err = qs.CountColumn("not_exist")
+	assert.NotNil(t, err)
+	_, err = qs.GroupBy("status").CountColumn("profile")
+	assert.NotNil(t, err)
+}
+
+func TestCheckUnique(t *testing.T) {
+	al := *getDbAlias("default")
+	al.CheckUnique = true
+	o := &orm{ormBase: ormBase{alias: &al, db: al.DB}}
+
+	_, err := o.Insert(&User{UserName: "slene", Email: "slene-dup@gmail.com"})
+	var dup *ErrDuplicate
+	throwFailNow(t, AssertIs(errors.As(err, &dup), true))
+	throwFail(t, AssertIs(dup.Column, "user_name"))
+	throwFail(t, AssertIs(dup.Value, "slene"))
+	num, err := o.QueryTable("user").Filter("email", "slene-dup@gmail.com").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+
+	user := &User{UserName: "unique", Email: "unique@gmail.com"}
+	_, err = o.Insert(user)
+	throwFailNow(t, err)
+	_, err = o.Delete(user)
+	throwFail(t, err)
+
+	// off, the insert fails by the unique constraint
+	al.CheckUnique = false
+	_, err = o.Insert(&User{UserName: "slene"})
+	throwFail(t, AssertNot(err, nil))
+	throwFail(t, AssertIs(errors.As(err, &dup), false))
+}
+
+func TestInsertMultiValidate(t *testing.T) {
+	qs := dORM.QueryTable("rating")
+	for _, bulk := range []int{1, 3} {
+		ratings := []*Rating{{Stars: 3}, {Stars: 0}, {Stars: 5}, {Stars: 6}}
+		num, err := dORM.InsertMulti(bulk, ratings)
+		var errs MultiError
+		throwFailNow(t, AssertIs(errors.As(err, &errs), true))
+		throwFailNow(t, AssertIs(num, 0))
+		throwFailNow(t, AssertIs(len(errs), 2))
+		throwFail(t, AssertIs(errs[0].Index, 1))
+		throwFail(t, AssertIs(errs[1].Index, 3))
+		cnt, err := qs.Count()
+		throwFailNow(t, err)
+		throwFailNow(t, AssertIs(cnt, 0))
+	}
+
+	al := *getDbAlias("default")
+	al.SkipInvalid = true
+	o := &orm{ormBase: ormBase{alias: &al, db: al.DB}}
+	for _, bulk := range []int{1, 3} {
+		ratings := []Rating{{Stars: 0}, {Stars: 2}, {Stars: 4}, {Stars: 9}}
+		num, err := o.InsertMulti(bulk, ratings)
+		var errs MultiError
+		throwFailNow(t, AssertIs(errors.As(err, &errs), true))
+		throwFailNow(t, AssertIs(num, 2))
+		throwFailNow(t, AssertIs(len(errs), 2))
+		throwFail(t, AssertIs(errs[0].Index, 0))
+		throwFail(t, AssertIs(errs[1].Index, 3))
+	}
+	cnt, err := qs.Filter("stars__in", 2, 4).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(cnt, 4))
+
+	// none is valid
+	_, err = o.InsertMulti(2, []*Rating{{Stars: 0}})
+	throwFailNow(t, AssertNot(err, nil))
+	cnt, err = qs.Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(cnt, 4))
+
+	num, err := dORM.InsertMulti(2, []*Rating{{Stars: 1}, {Stars: 1}})
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+}
+
+func TestEmptyAsNull(t *testing.T) {
+	qs := dORM.QueryTable("rating").Filter("stars", 3)
+	rating := &Rating{Stars: 3}
+	id, err := dORM.Insert(rating)
+	throwFailNow(t, err)
+	cnt, err := qs.Filter("comment__isnull", true).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(cnt, 1))
+
+	rating.Comment = "good"
+	_, err = dORM.Update(rating)
+	throwFailNow(t, err)
+	cnt, err = qs.Filter("comment__isnull", true).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(cnt, 0))
+
+	rating.Comment = ""
+	_, err = dORM.Update(rating, "Comment")
+	throwFailNow(t, err)
+	cnt, err = qs.Filter("comment__isnull", true).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(cnt, 1))
+
+	// read back as the empty string
+	read := &Rating{ID: int(id)}
+	throwFailNow(t, dORM.Read(read))
+	throwFail(t, AssertIs(read.Comment, ""))
+
+	// the empty strings written before the tag still match
+	_, err = dORM.Raw("INSERT INTO rating (stars, comment) VALUES (?, ?)", 3, "").Exec()
+	throwFailNow(t, err)
+	_, err = dORM.Raw("INSERT INTO rating (stars, comment) VALUES (?, ?)", 3, "bad").Exec()
+	throwFailNow(t, err)
+	cnt, err = qs.Filter("comment", "").Count()
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(cnt, 2))
+	cnt, err = qs.Exclude("comment", "").Count()
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(cnt, 1))
+	cnt, err = qs.Filter("comment", "bad").Count()
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(cnt, 1))
+}
+
+func TestBinaryPk(t *testing.T) {
+	uuid := []byte{0x10, 0x00, 0xff, 0x3c, 0, 0, 0x41, 0x7e, 0x80, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x00}
+	device := &Device{UUID: uuid, Name: "router", Key: []byte("secret\x00key")}
+	_, err := dORM.Insert(device)
+	throwFailNow(t, err)
+	_, err = dORM.Insert(&Device{UUID: bytes.Repeat([]byte{0x7f}, 16), Name: "switch"})
+	throwFailNow(t, err)
+
+	read := &Device{UUID: append([]byte{}, uuid...)}
+	throwFailNow(t, dORM.Read(read))
+	throwFail(t, AssertIs(read.Name, "router"))
+	throwFail(t, AssertIs(bytes.Equal(read.Key, []byte("secret\x00key")), true))
+
+	read.Name = "gateway"
+	num, err := dORM.Update(read, "Name")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+
+	var devices []*Device
+	num, err = dORM.QueryTable("device").Filter("uuid", uuid).All(&devices)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(bytes.Equal(devices[0].UUID, uuid), true))
+	throwFail(t, AssertIs(devices[0].Name, "gateway"))
+	throwFail(t, AssertIs(devices[0].Key == nil, false))
+
+	empty := &Device{UUID: bytes.Repeat([]byte{0x7f}, 16)}
+	throwFailNow(t, dORM.Read(empty))
+	throwFail(t, AssertIs(empty.Key == nil, true))
+
+	// the binary values are keyed by their string
+	counts, err := dORM.QueryTable("device").GroupCount("Key")
+	throwFailNow(t, err)
+	assert.Equal(t, map[interface{}]int64{"secret\x00key": 1, nil: 1}, counts)
+
+	_, err = dORM.Insert(&DeviceLog{Device: read, Message: "boot"})
+	throwFailNow(t, err)
+	var log DeviceLog
+	err = dORM.QueryTable("device_log").Filter("Device", uuid).RelatedSel().One(&log)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(bytes.Equal(log.Device.UUID, uuid), true))
+	throwFail(t, AssertIs(log.Device.Name, "gateway"))
+
+	var logs []*DeviceLog
+	num, err = dORM.QueryTable("device_log").Filter("Device__Name", "gateway").All(&logs)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFailNow(t, dORM.Preload(&logs, "Device"))
+	throwFail(t, AssertIs(logs[0].Device.Name, "gateway"))
+
+	num, err = dORM.Delete(&Device{UUID: uuid})
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(dORM.Read(&Device{UUID: uuid}), ErrNoRows))
+	num, err = dORM.QueryTable("device_log").Count()
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(num, 0))
+}
+
+func TestFilterHasRelated(t *testing.T) {
+	var users []*User
+	qs := dORM.QueryTable("user")
+	num, err := qs.FilterHasRelated("Profile", false).All(&users)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(users[0].UserName, "nobody"))
+
+	num, err = qs.FilterHasRelated("Profile", true).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = qs.FilterHasRelated("Posts", true).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+
+	num, err = qs.FilterHasRelated("Posts", false).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+
+	// m2m by the through table
+	all, err := dORM.QueryTable("post").Count()
+	throwFail(t, err)
+	tagged, err := dORM.QueryTable("post").FilterHasRelated("Tags", true).Count()
+	throwFail(t, err)
+	untagged, err := dORM.QueryTable("post").FilterHasRelated("Tags", false).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(tagged > 0, true))
+	throwFail(t, AssertIs(tagged+untagged, all))
+
+	// reverse m2m
+	num, err = dORM.QueryTable("tag").FilterHasRelated("Posts", true).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num > 0, true))
+
+	_, err = qs.FilterHasRelated("user_name", true).Count()
+	throwFail(t, AssertNot(err, nil))
+}
+
+func TestSearch(t *testing.T) {
+	qs := dORM.QueryTable("user")
+	num, err := qs.Search("ASTA", "UserName", "Email").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Search("GMAIL", "UserName", "Email").Filter("Status", 1).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Search("nothing").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+}
+
+func TestFilterCollate(t *testing.T) {
+	if !IsSqlite {
+		return
+	}
+	num, err := dORM.QueryTable("user").FilterCollate("UserName", "NOCASE", "", "SLENE").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = dORM.QueryTable("user").FilterCollate("UserName", "BINARY", "", "SLENE").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+}
+
+func TestFilterByExample(t *testing.T) {
+	qs := dORM.QueryTable("user")
+	num, err := qs.FilterByExample(&User{UserName: "slene"}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	var user User
+	err = qs.FilterByExample(&User{Status: 2, IsStaff: true}).One(&user)
+	throwFail(t, err)
+	throwFail(t, AssertIs(user.UserName, "astaxie"))
+
+	num, err = qs.FilterByExample(&User{Profile: &Profile{ID: 2}}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	// zero values carry no condition
+	num, err = qs.FilterByExample(&User{}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+
+	zero := 0
+	qs = dORM.QueryTable("data_null")
+	all, err := qs.Count()
+	throwFail(t, err)
+	num, err = qs.FilterByExample(&DataNull{IntPtr: &zero}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, all))
+
+	want, err := qs.Filter("IntPtr", 0).Count()
+	throwFail(t, err)
+	num, err = qs.FilterByExample(&DataNull{IntPtr: &zero}, hints.IncludeZeroPtr()).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, want))
+
+	assert.Panics(t, func() {
+		dORM.QueryTable("user").FilterByExample(&Post{})
+	})
+}
+
+func TestRowWarnThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	oldLog := DebugLog
+	DebugLog = NewLog(&buf)
+	defer func() {
+		DebugLog = oldLog
+		_ = SetRowWarnThreshold("default", 0)
+	}()
+
+	var users []*User
+	throwFailNow(t, SetRowWarnThreshold("default", 2))
+	num, err := dORM.QueryTable("user").All(&users)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	assert.Contains(t, buf.String(), "table `user` returned 3 rows, more than threshold 2")
+
+	buf.Reset()
+	throwFailNow(t, SetRowWarnThreshold("default", 3))
+	_, err = dORM.QueryTable("user").All(&users)
+	throwFailNow(t, err)
+	assert.NotContains(t, buf.String(), "WARN")
+}
+
+func TestAll(t *testing.T) {
+	var users []*User
+	qs := dORM.QueryTable("user")
+	num, err := qs.OrderBy("Id").All(&users)
+	throwFail(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+
+	throwFail(t, AssertIs(users[0].UserName, "slene"))
+	throwFail(t, AssertIs(users[1].UserName, "astaxie"))
+	throwFail(t, AssertIs(users[2].UserName, "nobody"))
+
+	var users2 []User
+	qs = dORM.QueryTable("user")
+	num, err = qs.OrderBy("Id").All(&users2)
+	throwFail(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+
+	throwFailNow(t, AssertIs(users2[0].UserName, "slene"))
+	throwFailNow(t, AssertIs(users2[1].UserName, "astaxie"))
+	throwFailNow(t, AssertIs(users2[2].UserName, "nobody"))
+
+	qs = dORM.QueryTable("user")
+	num, err = qs.OrderBy("Id").RelatedSel().All(&users2, "UserName")
+	throwFail(t, err)
+	throwFailNow(t, AssertIs(num, 3))
 	throwFailNow(t, AssertIs(len(users2), 3))
 	throwFailNow(t, AssertIs(users2[0].UserName, "slene"))
 	throwFailNow(t, AssertIs(users2[1].UserName, "astaxie"))
@@ -1267,114 +2207,1650 @@ func TestAll(t *testing.T) {
 	throwFailNow(t, AssertIs(users2[1].Profile == nil, false))
 	throwFailNow(t, AssertIs(users2[2].Profile == nil, true))
 
-	qs = dORM.QueryTable("user")
-	num, err = qs.Filter("user_name", "nothing").All(&users)
+	qs = dORM.QueryTable("user")
+	num, err = qs.Filter("user_name", "nothing").All(&users)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 0))
+
+	var users3 []*User
+	qs = dORM.QueryTable("user")
+	num, err = qs.Filter("user_name", "nothing").All(&users3)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 0))
+	throwFailNow(t, AssertIs(users3 == nil, false))
+}
+
+func TestAllInto(t *testing.T) {
+	qs := dORM.QueryTable("user").OrderBy("Id")
+	count, err := qs.Count()
+	throwFailNow(t, err)
+
+	users := make([]User, count)
+	num, err := qs.AllInto(&users)
+	throwFail(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(len(users), 3))
+	throwFail(t, AssertIs(users[0].UserName, "slene"))
+	throwFail(t, AssertIs(users[2].UserName, "nobody"))
+
+	// reused elements are reset, the columns not read stay zero
+	ptrs := []*User{{UserName: "stale", Email: "stale"}}
+	first := ptrs[0]
+	num, err = qs.AllInto(&ptrs, "UserName")
+	throwFail(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(len(ptrs), 3))
+	throwFail(t, AssertIs(ptrs[0] == first, true))
+	throwFail(t, AssertIs(ptrs[0].UserName, "slene"))
+	throwFail(t, AssertIs(ptrs[0].Email, ""))
+	throwFail(t, AssertIs(ptrs[1].UserName, "astaxie"))
+
+	// cut to the rows
+	users = make([]User, 10)
+	num, err = qs.Filter("user_name", "astaxie").RelatedSel().AllInto(&users)
+	throwFail(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFailNow(t, AssertIs(len(users), 1))
+	throwFail(t, AssertIs(users[0].Profile.Age, 30))
+
+	_, err = qs.AllInto(&[]Post{})
+	throwFail(t, AssertIs(err != nil, true))
+}
+
+// register the test models again, the model cache is cleaned by the tests before the benchmarks.
+// run the benchmarks with the tests for their data, such as go test -bench QuerySeterAll
+func benchModels() {
+	defaultModelCache.Clean()
+	TestRegisterModels(nil)
+}
+
+func BenchmarkQuerySeterAll(b *testing.B) {
+	benchModels()
+	qs := dORM.QueryTable("user").OrderBy("Id")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var users []User
+		if _, err := qs.All(&users); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkQuerySeterAllInto(b *testing.B) {
+	benchModels()
+	qs := dORM.QueryTable("user").OrderBy("Id")
+	users := make([]User, 3)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := qs.AllInto(&users); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDedupByPK(t *testing.T) {
+	qs := dORM.QueryTable("user").Filter("Posts__Title__isnull", false).OrderBy("ID")
+
+	// astaxie has two posts
+	var users []*User
+	num, err := qs.All(&users)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 4))
+
+	users = nil
+	num, err = qs.DedupByPK().All(&users)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(len(users), 3))
+	throwFail(t, AssertIs(users[0].UserName, "slene"))
+	throwFail(t, AssertIs(users[1].UserName, "astaxie"))
+	throwFail(t, AssertIs(users[2].UserName, "nobody"))
+
+	var values []User
+	num, err = qs.DedupByPK().All(&values)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	throwFail(t, AssertIs(len(values), 3))
+}
+
+func TestOne(t *testing.T) {
+	var user User
+	qs := dORM.QueryTable("user")
+	err := qs.One(&user)
+	throwFail(t, err)
+
+	user = User{}
+	err = qs.OrderBy("Id").Limit(1).One(&user)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(user.UserName, "slene"))
+	throwFail(t, AssertNot(err, ErrMultiRows))
+
+	user = User{}
+	err = qs.OrderBy("-Id").Limit(100).One(&user)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(user.UserName, "nobody"))
+	throwFail(t, AssertNot(err, ErrMultiRows))
+
+	err = qs.Filter("user_name", "nothing").One(&user)
+	throwFail(t, AssertIs(err, ErrNoRows))
+}
+
+func TestValues(t *testing.T) {
+	var maps []Params
+	qs := dORM.QueryTable("user")
+
+	num, err := qs.OrderBy("Id").Values(&maps)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	if num == 3 {
+		throwFail(t, AssertIs(maps[0]["UserName"], "slene"))
+		throwFail(t, AssertIs(maps[2]["Profile"], nil))
+	}
+
+	num, err = qs.OrderClauses(
+		order_clause.Clause(
+			order_clause.Column("Id"),
+			order_clause.SortAscending(),
+		),
+	).Values(&maps)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	if num == 3 {
+		throwFail(t, AssertIs(maps[0]["UserName"], "slene"))
+		throwFail(t, AssertIs(maps[2]["Profile"], nil))
+	}
+
+	num, err = qs.OrderBy("Id").Values(&maps, "UserName", "Profile__Age")
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	if num == 3 {
+		throwFail(t, AssertIs(maps[0]["UserName"], "slene"))
+		throwFail(t, AssertIs(maps[0]["Profile__Age"], 28))
+		throwFail(t, AssertIs(maps[2]["Profile__Age"], nil))
+	}
+
+	num, err = qs.Filter("UserName", "slene").Values(&maps)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+}
+
+func TestValuesList(t *testing.T) {
+	var list []ParamsList
+	qs := dORM.QueryTable("user")
+
+	num, err := qs.OrderBy("Id").ValuesList(&list)
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	if num == 3 {
+		throwFail(t, AssertIs(list[0][1], "slene")) // username
+		throwFail(t, AssertIs(list[2][10], nil))    // profile
+	}
+
+	num, err = qs.OrderBy("Id").ValuesList(&list, "UserName", "Profile__Age")
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	if num == 3 {
+		throwFail(t, AssertIs(list[0][0], "slene"))
+		throwFail(t, AssertIs(list[0][1], 28))
+		throwFail(t, AssertIs(list[2][1], nil))
+	}
+}
+
+func TestValuesFlat(t *testing.T) {
+	var list ParamsList
+	qs := dORM.QueryTable("user")
+
+	num, err := qs.OrderBy("id").ValuesFlat(&list, "UserName")
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+	if num == 3 {
+		throwFail(t, AssertIs(list[0], "slene"))
+		throwFail(t, AssertIs(list[1], "astaxie"))
+		throwFail(t, AssertIs(list[2], "nobody"))
+	}
+}
+
+func TestFilterInNull(t *testing.T) {
+	user := &User{UserName: "slene"}
+	throwFailNow(t, dORM.Read(user, "UserName"))
+	qs := dORM.QueryTable("user")
+
+	num, err := qs.Filter("Profile__in", user.Profile.ID, nil).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = qs.Filter("Profile__in", []interface{}{nil}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	var users []*User
+	num, err = qs.Exclude("Profile__in", user.Profile.ID, nil).All(&users)
+	throwFail(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(users[0].UserName, "astaxie"))
+}
+
+func TestFilterConcat(t *testing.T) {
+	qs := dORM.QueryTable("user")
+	num, err := qs.FilterConcat([]string{"UserName", "Email"}, ":", "", "slene:vslene@gmail.com").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.FilterConcat([]string{"UserName", "Email"}, "", "startswith", "astaxieastaxie@").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+}
+
+func TestFilterDatePart(t *testing.T) {
+	var ids []interface{}
+	// sunday and thursday of the week 53 of 2020, mondays of the week 1 of 2021 and 2025
+	for _, day := range []string{"2021-01-03", "2020-12-31", "2021-01-04", "2024-12-30"} {
+		date, err := time.ParseInLocation("2006-01-02", day, DefaultTimeLoc)
+		throwFailNow(t, err)
+		d := Data{}
+		ind := reflect.Indirect(reflect.ValueOf(&d))
+		for name, value := range DataValues {
+			if name != "JSON" {
+				ind.FieldByName(name).Set(reflect.ValueOf(value))
+			}
+		}
+		d.Date = date
+		id, err := dORM.Insert(&d)
+		throwFailNow(t, err)
+		ids = append(ids, id)
+	}
+	defer func() {
+		_, err := dORM.QueryTable("data").Filter("ID__in", ids...).Delete()
+		throwFail(t, err)
+	}()
+
+	qs := dORM.QueryTable("data").Filter("ID__in", ids...)
+	num, err := qs.Filter("Date__isoweek", 53).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = qs.Filter("Date__isoweek", 1).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = qs.Filter("Date__quarter", 4).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = qs.Filter("Date__quarter__lt", 4).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+}
+
+func TestFilterAge(t *testing.T) {
+	var ids []interface{}
+	today := time.Now().In(DefaultTimeLoc)
+	// 18 years ago yesterday, today and tomorrow
+	for _, days := range []int{-1, 0, 1} {
+		d := Data{}
+		ind := reflect.Indirect(reflect.ValueOf(&d))
+		for name, value := range DataValues {
+			if name != "JSON" {
+				ind.FieldByName(name).Set(reflect.ValueOf(value))
+			}
+		}
+		d.Date = today.AddDate(-18, 0, days)
+		id, err := dORM.Insert(&d)
+		throwFailNow(t, err)
+		ids = append(ids, id)
+	}
+	defer func() {
+		_, err := dORM.QueryTable("data").Filter("ID__in", ids...).Delete()
+		throwFail(t, err)
+	}()
+
+	qs := dORM.QueryTable("data").Filter("ID__in", ids...)
+	num, err := qs.Filter("Date__age__gte", 18).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = qs.Filter("Date__age", 17).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+}
+
+func TestFilterJSONEqual(t *testing.T) {
+	qs := dORM.QueryTable("data")
+	doc := json.RawMessage(`{"b": [1, 2], "a": {"y": true, "x": "s"}}`)
+	if !IsPostgres && !IsMysql && !IsTidb {
+		assert.Panics(t, func() { qs.Filter("Jsonb__json_eq", doc).Count() })
+		return
+	}
+
+	d := Data{}
+	ind := reflect.Indirect(reflect.ValueOf(&d))
+	for name, value := range DataValues {
+		if name != "JSON" {
+			ind.FieldByName(name).Set(reflect.ValueOf(value))
+		}
+	}
+	d.Jsonb = string(doc)
+	id, err := dORM.Insert(&d)
+	throwFailNow(t, err)
+	defer func() {
+		_, err := dORM.Delete(&Data{ID: int(id)})
+		throwFail(t, err)
+	}()
+
+	qs = qs.Filter("ID", id)
+	// the keys are in another order
+	num, err := qs.Filter("Jsonb__json_eq", map[string]interface{}{
+		"a": map[string]interface{}{"x": "s", "y": true},
+		"b": []int{1, 2},
+	}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("Jsonb__json_eq", map[string]interface{}{"b": []int{2, 1}}).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+}
+
+func TestFilterRound(t *testing.T) {
+	qs := dORM.QueryTable("user_profile")
+	num, err := qs.FilterRound("Money", 1, "", 1234.1).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.FilterRound("Money", 0, "gte", 1234).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = qs.FilterRound("Money", 2, "", 1234.1).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+}
+
+func TestFilterApprox(t *testing.T) {
+	qs := dORM.QueryTable("user_profile")
+	num, err := qs.FilterApprox("Money", 1234.0, 0.5).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.FilterApprox("Money", 1234.0, 0.01).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+
+	num, err = qs.FilterApprox("Money", 1234.0, 10000).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	assert.Panics(t, func() {
+		qs.FilterApprox("Money", 1234.0, -1)
+	})
+}
+
+func TestFilterContainsAt(t *testing.T) {
+	qs := dORM.QueryTable("user")
+	num, err := qs.Filter("Email__contains_at", SubstrPos("@gmail", 7)).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+
+	num, err = qs.Filter("Email__contains_at", SubstrPos("@gmail", 8)).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("Email__contains_at", SubstrPos("@gmail", 9)).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+
+	// the e of vslene before the position is skipped, the one after it matches
+	qs = qs.Filter("Email", "vslene@gmail.com")
+	num, err = qs.Filter("Email__contains_at", SubstrPos("e", 5)).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+	num, err = qs.Filter("Email__contains_at", SubstrPos("e", 7)).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+	num, err = qs.Filter("Email__contains_at", SubstrPos("vs", 2)).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+}
+
+func TestFilterTrimEq(t *testing.T) {
+	qs := dORM.QueryTable("user")
+	num, err := qs.Filter("UserName__trimeq", "  slene ").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("UserName", "  slene ").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+}
+
+func TestFilterModulo(t *testing.T) {
+	qs := dORM.QueryTable("user")
+	total, err := qs.Count()
+	throwFailNow(t, err)
+
+	even, err := qs.Filter("ID__mod", Mod(2, 0)).Count()
+	throwFail(t, err)
+	odd, err := qs.Filter("ID__mod", Mod(2, 1)).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(even > 0, true))
+	throwFail(t, AssertIs(odd > 0, true))
+	throwFail(t, AssertIs(even+odd, total))
+
+	var users []*User
+	num, err := qs.Filter("ID__mod", Mod(2, 1)).All(&users)
+	throwFail(t, err)
+	throwFailNow(t, AssertIs(num, odd))
+	for _, u := range users {
+		throwFail(t, AssertIs(u.ID%2, 1))
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	tx, err := dORM.Begin()
+	throwFailNow(t, err)
+	defer tx.Rollback()
+
+	post := &Post{ID: 1}
+	root := &Comment{Post: post, Content: "root"}
+	_, err = tx.Insert(root)
+	throwFailNow(t, err)
+	child := &Comment{Post: post, Content: "child", Parent: root}
+	_, err = tx.Insert(child)
+	throwFailNow(t, err)
+	_, err = tx.Insert(&Comment{Post: post, Content: "child 2", Parent: root})
+	throwFailNow(t, err)
+	_, err = tx.Insert(&Comment{Post: post, Content: "grandchild", Parent: child})
+	throwFailNow(t, err)
+	other := &Comment{Post: post, Content: "other root"}
+	_, err = tx.Insert(other)
+	throwFailNow(t, err)
+	_, err = tx.Insert(&Comment{Post: post, Content: "other child", Parent: other})
+	throwFailNow(t, err)
+
+	var comments []*Comment
+	num, err := tx.QueryTable("comment").Descendants("ID", "Parent", root.ID).OrderBy("ID").All(&comments)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 4))
+	throwFail(t, AssertIs(comments[0].Content, "root"))
+	throwFail(t, AssertIs(comments[3].Content, "grandchild"))
+
+	num, err = tx.QueryTable("comment").Descendants("ID", "Parent", child.ID).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = tx.QueryTable("comment").Descendants("ID", "Parent", root.ID).Exclude("Content__startswith", "child").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	_, err = tx.QueryTable("comment").Descendants("ID", "Missing", root.ID).Count()
+	throwFail(t, AssertIs(err != nil, true))
+}
+
+func TestValuesCase(t *testing.T) {
+	var list ParamsList
+	cases := []CaseWhen{
+		{Cond: NewCondition().And("user_name", "slene"), Then: "me"},
+		{Cond: NewCondition().And("user_name__in", "astaxie", "nobody"), Then: "them"},
+	}
+	qs := dORM.QueryTable("user").ValuesCase("who", cases, "other")
+	num, err := qs.Filter("user_name__in", "slene", "astaxie").OrderBy("id").ValuesFlat(&list, "who")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFail(t, AssertIs(list[0], "me"))
+	throwFail(t, AssertIs(list[1], "them"))
+
+	var maps []Params
+	qs = dORM.QueryTable("user").ValuesCase("who", cases[:1], nil)
+	num, err = qs.OrderBy("id").Values(&maps, "UserName", "who")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	throwFail(t, AssertIs(maps[0]["who"], "me"))
+	throwFail(t, AssertIs(maps[2]["UserName"], "nobody"))
+	throwFail(t, AssertIs(maps[2]["who"], nil))
+}
+
+func TestValuesBool(t *testing.T) {
+	var maps []Params
+	qs := dORM.QueryTable("user").ValuesBool("is_me", NewCondition().And("user_name", "slene"))
+	num, err := qs.OrderBy("id").Values(&maps, "UserName", "is_me")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	throwFail(t, AssertIs(maps[0]["is_me"], true))
+	throwFail(t, AssertIs(maps[1]["is_me"], false))
+	throwFail(t, AssertIs(maps[2]["is_me"], false))
+
+	// the params of the condition come before the ones of the filter
+	var list ParamsList
+	qs = dORM.QueryTable("user").ValuesBool("active", NewCondition().And("is_active", true))
+	num, err = qs.Filter("user_name__in", "slene", "nobody").OrderBy("id").ValuesFlat(&list, "active")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFail(t, AssertIs(list[0], true))
+	throwFail(t, AssertIs(list[1], false))
+}
+
+func TestTimeBucket(t *testing.T) {
+	post := &Post{ID: 1}
+	throwFailNow(t, dORM.Read(post))
+
+	var maps []Params
+	qs := dORM.QueryTable("post").TimeBucket("Created", "hour", "hour")
+	num, err := qs.Filter("Id", 1).Values(&maps, "Id", "hour")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFailNow(t, AssertIs(maps[0]["hour"].(time.Time).Equal(post.Created.Truncate(time.Hour)), true))
+
+	// the posts are created together
+	var list ParamsList
+	qs = dORM.QueryTable("post").TimeBucket("Created", "year", "year")
+	num, err = qs.GroupBy("year").ValuesFlat(&list, "year")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFailNow(t, AssertIs(list[0].(time.Time).Year(), post.Created.Year()))
+	throwFailNow(t, AssertIs(list[0].(time.Time).YearDay(), 1))
+
+	assert.Panics(t, func() {
+		dORM.QueryTable("post").TimeBucket("Created", "week", "week")
+	})
+	assert.Panics(t, func() {
+		_, _ = dORM.QueryTable("post").TimeBucket("Title", "day", "day").Values(&maps, "day")
+	})
+}
+
+// dbBaser converting the times to the fixed +09:00 of the tests, which sqlite cannot by the timezone names.
+type tzDbBaser struct {
+	dbBaser
+}
+
+func (tzDbBaser) TimezoneConvertSQL(col string, from string, tz string) string {
+	return fmt.Sprintf("datetime(%s, '+9 hours')", col)
+}
+
+func TestTimeBucketTZ(t *testing.T) {
+	if !IsSqlite {
+		t.Skip("the timezone conversion is faked on sqlite")
+	}
+	base := newdbBaseSqlite()
+	al := *getDbAlias("default")
+	al.DbBaser = tzDbBaser{base}
+	al.TZ = time.UTC
+	base.(*dbBaseSqlite).ins = al.DbBaser
+	o := &ormBase{alias: &al, db: al.DB}
+
+	// the first two are on 2024-03-01 of UTC, the second is on 2024-03-02 of +09:00 like the last
+	var ids []interface{}
+	for _, tm := range []time.Time{
+		time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 15, 30, 0, 0, time.UTC),
+		time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC),
+	} {
+		id, err := o.Insert(&TM{TMPrecision1: tm})
+		throwFailNow(t, err)
+		ids = append(ids, id)
+	}
+
+	var maps []Params
+	num, err := o.QueryTable("tm").Filter("id__in", ids...).TimeBucketTZ("TMPrecision1", "day", "day", "+09:00").
+		GroupBy("day").Values(&maps, "day")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+
+	loc := time.FixedZone("+09:00", 9*60*60)
+	first, second := maps[0]["day"].(time.Time), maps[1]["day"].(time.Time)
+	if second.Before(first) {
+		first, second = second, first
+	}
+	throwFail(t, AssertIs(first.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, loc)), true))
+	throwFail(t, AssertIs(second.Equal(time.Date(2024, 3, 2, 0, 0, 0, 0, loc)), true))
+	// labeled in the timezone
+	throwFail(t, AssertIs(second.Format("2006-01-02 15:04:05 -07:00"), "2024-03-02 00:00:00 +09:00"))
+
+	num, err = o.QueryTable("tm").Filter("id__in", ids...).Delete()
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(num, 3))
+}
+
+func TestNoPrepare(t *testing.T) {
+	al := getDbAlias("default")
+	cache, err := newStmtDecoratorLruWithEvict(10)
+	throwFailNow(t, err)
+	db := &DB{RWMutex: new(sync.RWMutex), DB: al.DB.DB, stmtDecorators: cache}
+	o := &ormBase{alias: al, db: db}
+
+	num, err := o.QueryTable("user").NoPrepare().Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(cache.Len(), 0))
+
+	var ids []int
+	Q := al.DbBaser.TableQuote()
+	num, err = o.Raw(fmt.Sprintf("SELECT %sid%s FROM %suser%s", Q, Q, Q, Q)).NoPrepare().QueryRows(&ids)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(cache.Len(), 0))
+
+	// without NoPrepare the query is prepared and cached
+	num, err = o.QueryTable("user").Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(cache.Len(), 1))
+}
+
+func TestExcludeManyRel(t *testing.T) {
+	// post 2 has the tags golang and example
+	var posts []*Post
+	num, err := dORM.QueryTable("post").Exclude("Tags__Tag__Name", "example").OrderBy("Id").All(&posts)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(posts[0].Title, "Introduction"))
+	throwFailNow(t, AssertIs(posts[1].Title, "Formatting"))
+	throwFailNow(t, AssertIs(posts[2].Title, "Commentary"))
+
+	// astaxie has another post
+	var users []*User
+	num, err = dORM.QueryTable("user").Exclude("Posts__Title", "Examples").OrderBy("Id").All(&users)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFailNow(t, AssertIs(users[0].UserName, "slene"))
+	throwFailNow(t, AssertIs(users[1].UserName, "nobody"))
+
+	num, err = dORM.QueryTable("tag").Exclude("Posts__Post__User__UserName", "astaxie").Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+
+	mi := (&ormBase{}).getMi(&Post{})
+	tables := newDbTables(mi, newdbBaseMysql())
+	where, args := tables.getCondSQL(NewCondition().And("Title", "Examples").AndNot("Tags__Tag__Name", "example"), false, nil)
+	throwFailNow(t, AssertIs(where, "WHERE T0.`title` = ? AND NOT T0.`id` IN (SELECT T0.`id` FROM `post` T0 "+
+		"INNER JOIN `prefix_post_tags` T1 ON T1.`post_id` = T0.`id` INNER JOIN `tag` T2 ON T2.`id` = T1.`tag_id` WHERE T2.`name` = ? ) "))
+	throwFailNow(t, AssertIs(len(args), 2))
+	throwFailNow(t, AssertIs(len(tables.tables), 0))
+}
+
+func TestFilterDBTime(t *testing.T) {
+	qs := dORM.QueryTable("post")
+	num, err := qs.Filter("Created__after", Now().Minus(1, Day)).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 4))
+
+	num, err = qs.Filter("Created__before", Now().Minus(1, Day)).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 0))
+
+	num, err = qs.Filter("Created__lte", Now().Plus(1, Hour).Minus(30, Minute)).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 4))
+
+	num, err = qs.FilterRecent("Created", 24*time.Hour).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 4))
+}
+
+func TestCompositeField(t *testing.T) {
+	mi, ok := defaultModelCache.Get("product")
+	throwFailNow(t, AssertIs(ok, true))
+	for name, column := range map[string]string{
+		"PriceAmount":   "price_amount",
+		"PriceCurrency": "price_currency",
+		"CostAmount":    "unit_cost_amount",
+		"CostCurrency":  "unit_cost_currency",
+	} {
+		fi := mi.Fields.GetByName(name)
+		throwFailNow(t, AssertNot(fi, nil))
+		throwFailNow(t, AssertIs(fi.Column, column))
+	}
+
+	product := &Product{Name: "book", Price: Money{Amount: 1250, Currency: "EUR"}, Cost: Money{Amount: 800, Currency: "USD"}}
+	id, err := dORM.Insert(product)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(id, 1))
+
+	read := &Product{ID: product.ID}
+	err = dORM.Read(read)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(read.Price, product.Price))
+	throwFailNow(t, AssertIs(read.Cost, product.Cost))
+
+	read.Price.Amount = 1100
+	num, err := dORM.Update(read, "PriceAmount")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+
+	var products []*Product
+	num, err = dORM.QueryTable("product").Filter("PriceAmount__lt", 1200).Filter("price_currency", "EUR").All(&products)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFailNow(t, AssertIs(products[0].Price, Money{Amount: 1100, Currency: "EUR"}))
+	throwFailNow(t, AssertIs(products[0].Cost, product.Cost))
+}
+
+// record the queries
+type recordQuerier struct {
+	dbQuerier
+	queries []string
+}
+
+func (q *recordQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	q.queries = append(q.queries, query)
+	return q.dbQuerier.QueryContext(ctx, query, args...)
+}
+
+// record the exec queries too
+type execRecordQuerier struct {
+	recordQuerier
+}
+
+func (q *execRecordQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	q.queries = append(q.queries, query)
+	return q.dbQuerier.ExecContext(ctx, query, args...)
+}
+
+func TestUsingSchema(t *testing.T) {
+	// the schema of the test tables
+	var schema string
+	switch {
+	case IsSqlite:
+		schema = "main"
+	case IsPostgres:
+		schema = "public"
+	case IsMysql:
+		throwFailNow(t, dORM.Raw("SELECT DATABASE()").QueryRow(&schema))
+	default:
+		t.Skip("schema of the test tables is unknown")
+	}
+
+	al := getDbAlias("default")
+	q := &recordQuerier{dbQuerier: al.DB}
+	so := (&orm{ormBase: ormBase{alias: al, db: q}}).UsingSchema(schema)
+	Q := al.DbBaser.TableQuote()
+	table := fmt.Sprintf("%s%s%s.%suser%s", Q, schema, Q, Q, Q)
+
+	var user User
+	throwFailNow(t, so.QueryTable("user").Filter("UserName", "slene").One(&user))
+	var users []*User
+	num, err := so.QueryTable("user").Filter("Profile__Age__gt", 0).RelatedSel().All(&users)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(num, 2))
+	num, err = so.QueryTable("user").Exclude("Posts__Title", "Introduction").All(&users)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	throwFailNow(t, AssertIs(len(q.queries), 3))
+	throwFail(t, AssertIs(strings.Contains(q.queries[0], "FROM "+table+" T0"), true))
+	throwFail(t, AssertIs(strings.Contains(q.queries[1], "FROM "+table+" T0"), true))
+	throwFail(t, AssertIs(strings.Contains(q.queries[1], fmt.Sprintf("JOIN %s%s%s.%suser_profile%s T1", Q, schema, Q, Q, Q)), true))
+	throwFail(t, AssertIs(strings.Contains(q.queries[2], fmt.Sprintf("JOIN %s%s%s.%spost%s", Q, schema, Q, Q, Q)), true))
+
+	// writes and transactions
+	so = dORM.UsingSchema(schema)
+	tag := &Tag{Name: "schema"}
+	_, err = so.Insert(tag)
+	throwFailNow(t, err)
+	tx, err := so.Begin()
+	throwFailNow(t, err)
+	tag.Name = "schema tx"
+	_, err = tx.Update(tag)
+	throwFail(t, err)
+	throwFailNow(t, tx.Commit())
+	throwFailNow(t, dORM.Read(tag))
+	throwFail(t, AssertIs(tag.Name, "schema tx"))
+	_, err = so.Delete(tag)
+	throwFail(t, err)
+
+	assert.Panics(t, func() {
+		dORM.UsingSchema(`a"; DROP TABLE x`)
+	})
+}
+
+func TestReadOnly(t *testing.T) {
+	ro := dORM.ReadOnly()
+	qs := ro.QueryTable("user")
+
+	// reads work
+	user := &User{UserName: "slene"}
+	throwFailNow(t, ro.Read(user, "UserName"))
+	num, err := qs.Count()
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(num > 0, true))
+	var maps []Params
+	_, err = ro.Raw("SELECT id FROM user_profile").Values(&maps)
+	throwFail(t, err)
+	var name string
+	throwFail(t, ro.Raw("SELECT user_name FROM user WHERE id = ?", user.ID).QueryRow(&name))
+	throwFail(t, AssertIs(name, "slene"))
+
+	// every write is rejected
+	tag := &Tag{Name: "read only"}
+	_, err = ro.Insert(tag)
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, err = ro.InsertMulti(2, []*Tag{{Name: "ro 1"}, {Name: "ro 2"}})
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, err = ro.InsertOrUpdate(tag)
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, _, err = ro.ReadOrCreate(tag, "Name")
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	user.Status = 99
+	_, err = ro.Update(user, "Status")
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, err = ro.Delete(user)
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, err = qs.Filter("UserName", "slene").Update(Params{"status": 99})
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, err = qs.Filter("UserName", "slene").Delete()
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, err = ro.QueryTable("tag").PrepareInsert()
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, err = ro.Raw("UPDATE user SET status = 99").Exec()
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, err = ro.Raw("SELECT id FROM user").Exec()
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, err = ro.Raw("DELETE FROM user RETURNING id").Values(&maps)
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	throwFail(t, AssertIs(ro.Raw("DELETE FROM user RETURNING id").QueryRow(&name), ErrReadOnlyOrmer))
+
+	// the transactions are read-only too
+	tx, err := ro.Begin()
+	throwFailNow(t, err)
+	throwFail(t, tx.Read(user))
+	_, err = tx.Insert(tag)
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, err = tx.Raw("UPDATE user SET status = 99").Exec()
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	_, err = tx.WithReadCache().Update(user, "Status")
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+	throwFailNow(t, tx.Commit())
+
+	_, err = ro.UsingSchema("main").Insert(tag)
+	throwFail(t, AssertIs(err, ErrReadOnlyOrmer))
+
+	num, err = dORM.QueryTable("user").Filter("Status", 99).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+	num, err = dORM.QueryTable("tag").Filter("Name__startswith", "r").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 0))
+	throwFail(t, AssertIs(ro.ReadOnly(), ro))
+}
+
+func TestIsReadQuery(t *testing.T) {
+	for _, query := range []string{"SELECT 1", " select * FROM user", "WITH RECURSIVE t AS (SELECT 1) SELECT * FROM t", "(SELECT 1) UNION (SELECT 2)", "EXPLAIN SELECT 1", "SHOW TABLES"} {
+		assert.True(t, isReadQuery(query), query)
+	}
+	for _, query := range []string{"INSERT INTO user VALUES (1)", "UPDATE user SET id = 1", "DELETE FROM user", "SELECTED", "DROP TABLE user", ""} {
+		assert.False(t, isReadQuery(query), query)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	names := []string{"slene", "astaxie", "nobody"}
+	started := make(chan struct{}, len(names))
+	p := dORM.Pipeline()
+	for _, name := range names {
+		name := name
+		p.Add(func(ctx context.Context, o Ormer) (interface{}, error) {
+			started <- struct{}{}
+			// every query waits for the others to start, only met if they run concurrently
+			for len(started) < len(names) {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(time.Millisecond):
+				}
+			}
+			var user User
+			err := o.QueryTable("user").Filter("UserName", name).OneWithCtx(ctx, &user)
+			return user.UserName, err
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results := p.Exec(ctx)
+	throwFailNow(t, AssertIs(len(results), len(names)))
+	for i, res := range results {
+		throwFail(t, res.Err)
+		throwFail(t, AssertIs(res.Value, names[i]))
+	}
+
+	// at most MaxOpenConns at a time
+	db, err := sql.Open("sqlite3", ":memory:")
+	throwFailNow(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(2)
+	var mu sync.Mutex
+	running, most := 0, 0
+	p = &Pipeline{o: dORM, db: db}
+	for i := 0; i < 6; i++ {
+		i := i
+		p.Add(func(ctx context.Context, o Ormer) (interface{}, error) {
+			mu.Lock()
+			running++
+			if running > most {
+				most = running
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return i, nil
+		})
+	}
+	results = p.Exec(context.Background())
+	throwFail(t, AssertIs(most, 2))
+	for i, res := range results {
+		throwFail(t, AssertIs(res.Value, i))
+	}
+}
+
+func TestQuerySetErr(t *testing.T) {
+	qs := dORM.QueryTable("user")
+	throwFail(t, AssertIs(qs.Filter("profile__age__gt", 1).Filter("Posts__Title__lower", "go").Err(), nil))
+
+	typo := qs.Filter("UserName", "slene").Filter("profile__agee__gt", 1).Filter("unknown", 1)
+	err := typo.Err()
+	throwFailNow(t, AssertNot(err, nil))
+	throwFail(t, AssertIs(err.Error(), "<QuerySeter.Filter> unknown field/column name `profile__agee` of model `User`"))
+
+	var users []*User
+	assert.NotPanics(t, func() {
+		num, err := typo.OrderBy("-id").All(&users)
+		throwFail(t, AssertIs(num, 0))
+		throwFail(t, AssertIs(err, typo.Err()))
+	})
+	_, err = typo.Count()
+	throwFail(t, AssertIs(err, typo.Err()))
+	throwFail(t, AssertIs(typo.Exist(), false))
+	_, err = typo.Update(Params{"status": 1})
+	throwFail(t, AssertIs(err, typo.Err()))
+
+	num, err := qs.Exclude("names", "slene").Delete()
+	throwFail(t, AssertIs(num, 0))
+	throwFail(t, AssertIs(err.Error(), "<QuerySeter.Exclude> unknown field/column name `names` of model `User`"))
+
+	assert.NotPanics(t, func() {
+		_, err = qs.FilterCoalesce("typo", 0, "gt", 1).Count()
+		throwFail(t, AssertIs(err.Error(), "<QuerySeter.FilterCoalesce> unknown field/column name `typo` of model `User`"))
+		_, err = qs.Search("x", "user_name", "typo").Count()
+		throwFail(t, AssertIs(err.Error(), "<QuerySeter.Search> unknown field/column name `typo` of model `User`"))
+		_, err = qs.SetCond(NewCondition().And("user_name", "slene").OrCond(NewCondition().And("typo", 1))).Count()
+		throwFail(t, AssertIs(err.Error(), "<QuerySeter.SetCond> unknown field/column name `typo` of model `User`"))
+		_, err = qs.FilterRound("typo", 1, "", 1).Count()
+		throwFail(t, AssertIs(err.Error(), "<QuerySeter.FilterRound> unknown field/column name `typo` of model `User`"))
+		_, err = qs.OrderBy("-typo").All(&users)
+		throwFail(t, AssertIs(err.Error(), "<QuerySeter.OrderBy> unknown field/column name `typo` of model `User`"))
+		_, err = qs.OrderByCoalesce("id", "typo").All(&users)
+		throwFail(t, AssertIs(err.Error(), "<QuerySeter.OrderByCoalesce> unknown field/column name `typo` of model `User`"))
+
+		sub := NewSubQuery(dORM.QueryTable("user").Filter("no_such_field", 1), "id")
+		_, err = qs.Filter("id__in", sub).Count()
+		throwFail(t, AssertIs(err, sub.qs.err))
+		_, err = qs.Exclude("id__in", sub).Count()
+		throwFail(t, AssertIs(err, sub.qs.err))
+		_, err = qs.SetCond(NewCondition().And("id__in", sub)).Count()
+		throwFail(t, AssertIs(errors.Is(err, sub.qs.err), true))
+	})
+}
+
+func TestFilterLikeEscape(t *testing.T) {
+	names := []string{"like50%off", "like500off", "like5_0"}
+	for _, name := range names {
+		_, err := dORM.Insert(&Tag{Name: name})
+		throwFailNow(t, err)
+	}
+	defer func() {
+		_, err := dORM.QueryTable("tag").Filter("name__in", names).Delete()
+		throwFail(t, err)
+	}()
+	qs := dORM.QueryTable("tag")
+
+	num, err := qs.Filter("name__contains", "50%").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("name__startswith", "like50%").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("name__endswith", "5_0").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("name__icontains", "LIKE5_").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("name__startswith", "like5").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+}
+
+func TestGenerateTableSQLOfAlias(t *testing.T) {
+	sql, err := GenerateTableSQL(new(Tag), "default")
+	throwFailNow(t, err)
+	Q := dDbBaser.TableQuote()
+	throwFail(t, AssertIs(strings.Contains(sql, "CREATE TABLE IF NOT EXISTS "+Q+"tag"+Q+" ("), true))
+
+	_, err = GenerateTableSQL(new(Tag), "unknown")
+	throwFail(t, AssertNot(err, nil))
+	_, err = GenerateTableSQL(new(ModelWithIndexSpec), "default")
+	throwFail(t, AssertNot(err, nil))
+}
+
+func TestRefresh(t *testing.T) {
+	tag := &Tag{Name: "refresh"}
+	_, err := dORM.Insert(tag)
+	throwFailNow(t, err)
+	defer func() {
+		_, err := dORM.Delete(&Tag{ID: tag.ID})
+		throwFail(t, err)
+	}()
+
+	if IsSqlite {
+		_, err = dORM.Raw("CREATE TRIGGER tag_refresh AFTER UPDATE OF name ON tag BEGIN UPDATE tag SET name = UPPER(NEW.name) WHERE id = NEW.id; END").Exec()
+		throwFailNow(t, err)
+		defer dORM.Raw("DROP TRIGGER tag_refresh").Exec()
+		tag.Name = "refreshed"
+		_, err = dORM.Update(tag, "Name")
+		throwFailNow(t, err)
+	} else {
+		// changed by the db like a trigger
+		_, err = dORM.Raw("UPDATE tag SET name = ? WHERE id = ?", "REFRESHED", tag.ID).Exec()
+		throwFailNow(t, err)
+	}
+	throwFail(t, AssertIs(tag.Name != "REFRESHED", true))
+
+	throwFailNow(t, dORM.Refresh(tag))
+	throwFail(t, AssertIs(tag.Name, "REFRESHED"))
+
+	err = dORM.Refresh(&Tag{ID: tag.ID + 1000})
+	throwFail(t, AssertIs(err, ErrNoRows))
+}
+
+func TestDiff(t *testing.T) {
+	var users []*User
+	_, err := dORM.QueryTable("user").OrderBy("id").All(&users)
+	throwFailNow(t, err)
+
+	var incoming []*User
+	ids := make(map[string]int, len(users))
+	for _, u := range users {
+		ids[u.UserName] = u.ID
+		switch u.UserName {
+		case "nobody":
+			continue
+		case "astaxie":
+			u.Status = u.Status + 1
+		}
+		u.ID = 0
+		incoming = append(incoming, u)
+	}
+	incoming = append(incoming, &User{UserName: "diff", Email: "diff@gmail.com"})
+
+	toInsert, toUpdate, toDelete, err := dORM.Diff(incoming, "user_name")
+	throwFailNow(t, err)
+
+	inserts := toInsert.([]*User)
+	throwFailNow(t, AssertIs(len(inserts), 1))
+	throwFail(t, AssertIs(inserts[0].UserName, "diff"))
+
+	updates := toUpdate.([]*User)
+	throwFailNow(t, AssertIs(len(updates), 1))
+	throwFail(t, AssertIs(updates[0].UserName, "astaxie"))
+	throwFail(t, AssertIs(updates[0].ID, ids["astaxie"]))
+
+	deletes := toDelete.([]*User)
+	throwFailNow(t, AssertIs(len(deletes), 1))
+	throwFail(t, AssertIs(deletes[0].UserName, "nobody"))
+	throwFail(t, AssertIs(deletes[0].ID, ids["nobody"]))
+
+	_, _, _, err = dORM.Diff(incoming, "unknown")
+	throwFail(t, AssertNot(err, nil))
+
+	_, _, _, err = dORM.Diff(append(incoming, &User{UserName: "diff"}), "user_name")
+	throwFail(t, AssertNot(err, nil))
+}
+
+func TestFilterBareBool(t *testing.T) {
+	qs := dORM.QueryTable("user")
+	staff, err := qs.Filter("is_staff", true).Count()
+	throwFailNow(t, err)
+	others, err := qs.Filter("is_staff", false).Count()
+	throwFailNow(t, err)
+
+	num, err := qs.Filter("is_staff").Count()
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(num, staff))
+
+	num, err = qs.Exclude("is_staff").Count()
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(num, others))
+
+	num, err = qs.Filter("is_active").Exclude("IsStaff").Count()
+	throwFailNow(t, err)
+	active, err := qs.Filter("is_active", true).Filter("is_staff", false).Count()
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(num, active))
+}
+
+func TestQuerySetMap(t *testing.T) {
+	qs := dORM.QueryTable("user").OrderBy("id")
+	var ids []int
+	redact := func(m interface{}) interface{} {
+		user := m.(*User)
+		ids = append(ids, user.ID)
+		user.Password = ""
+		return user
+	}
+	upper := func(m interface{}) interface{} {
+		user := m.(*User)
+		user.UserName = strings.ToUpper(user.UserName)
+		return user
+	}
+
+	var users []*User
+	num, err := qs.Map(redact).Map(upper).All(&users)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(len(users), num))
+	throwFail(t, AssertIs(len(ids), num))
+	for i, user := range users {
+		throwFail(t, AssertIs(user.ID, ids[i]))
+		throwFail(t, AssertIs(user.Password, ""))
+		throwFail(t, AssertIs(user.UserName, strings.ToUpper(user.UserName)))
+	}
+
+	var values []User
+	num, err = qs.Filter("user_name", "slene").Map(redact).All(&values)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(values[0].UserName, "slene"))
+	throwFail(t, AssertIs(values[0].Password, ""))
+
+	var user User
+	err = qs.Filter("user_name", "slene").Map(upper).One(&user)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(user.UserName, "SLENE"))
+	throwFail(t, AssertNot(user.Password, ""))
+
+	_, err = qs.Map(func(m interface{}) interface{} { return *m.(*User) }).All(&users)
+	throwFail(t, AssertNot(err, nil))
+}
+
+func TestTimeFormat(t *testing.T) {
+	tz := getDbAlias("default").TZ
+	logged := time.Date(2023, 4, 5, 6, 7, 8, 9, tz)
+	day := time.Date(2023, 4, 5, 0, 0, 0, 0, tz)
+	log := &LegacyLog{Logged: logged, Day: &day}
+	id, err := dORM.Insert(log)
+	throwFailNow(t, err)
+
+	var s string
+	err = dORM.Raw("SELECT logged FROM legacy_log WHERE id = ?", id).QueryRow(&s)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(s, "2023/04/05 06:07:08"))
+	err = dORM.Raw("SELECT day FROM legacy_log WHERE id = ?", id).QueryRow(&s)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(s, "05.04.2023"))
+
+	read := &LegacyLog{ID: int(id)}
+	err = dORM.Read(read)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(read.Logged.Equal(logged.Truncate(time.Second)), true))
+	throwFailNow(t, AssertNot(read.Day, nil))
+	throwFail(t, AssertIs(read.Day.Equal(day), true))
+
+	num, err := dORM.QueryTable("legacy_log").Filter("id", id).Filter("logged", logged).Count()
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	read.Day = nil
+	_, err = dORM.Update(read, "Day")
+	throwFailNow(t, err)
+	err = dORM.Read(read)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(read.Day == nil, true))
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	changeHooksMu.Lock()
+	hooks := changeHooks
+	changeHooksMu.Unlock()
+	defer func() {
+		changeHooksMu.Lock()
+		changeHooks = hooks
+		changeHooksMu.Unlock()
+	}()
+
+	tags := []*Tag{{Name: "cas"}, {Name: "cas"}, {Name: "cas"}}
+	pks := make([]interface{}, 0, len(tags))
+	for _, tag := range tags {
+		id, err := dORM.Insert(tag)
+		throwFailNow(t, err)
+		pks = append(pks, id)
+	}
+
+	// the second row is changed meanwhile
+	tags[1].Name = "cas changed"
+	_, err := dORM.Update(tags[1], "name")
+	throwFailNow(t, err)
+
+	var events []ChangeEvent
+	OnChange(func(ev ChangeEvent) {
+		events = append(events, ev)
+	})
+	swapped, err := dORM.CompareAndSwap(&Tag{}, map[string]interface{}{"name": "cas"}, map[string]interface{}{"name": "cas swapped"}, pks)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(len(swapped), 2))
+	throwFail(t, AssertIs(swapped[0], pks[0]))
+	throwFail(t, AssertIs(swapped[1], pks[2]))
+	// the swapped rows are reported by one update
+	throwFailNow(t, AssertIs(len(events), 1))
+	throwFail(t, AssertIs(events[0].Op, ChangeUpdate))
+	throwFail(t, AssertIs(strings.Join(events[0].Columns, ","), "name"))
+	assert.Equal(t, swapped, events[0].Pks)
+
+	for i, name := range []string{"cas swapped", "cas changed", "cas swapped"} {
+		tag := &Tag{ID: tags[i].ID}
+		throwFailNow(t, dORM.Read(tag))
+		throwFail(t, AssertIs(tag.Name, name))
+	}
+
+	// swapped rows no longer match
+	swapped, err = dORM.CompareAndSwap(&Tag{}, map[string]interface{}{"name": "cas"}, map[string]interface{}{"name": "cas swapped"}, pks)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(len(swapped), 0))
+	throwFail(t, AssertIs(len(events), 1))
+
+	_, err = dORM.CompareAndSwap(&Tag{}, map[string]interface{}{"unknown": "cas"}, map[string]interface{}{"name": "cas swapped"}, pks)
+	throwFail(t, AssertNot(err, nil))
+	_, err = dORM.CompareAndSwap(&Tag{}, map[string]interface{}{"name": "cas"}, nil, pks)
+	throwFail(t, AssertNot(err, nil))
+
+	num, err := dORM.QueryTable("tag").Filter("name__startswith", "cas ").Delete()
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(num, 3))
+}
+
+func TestReflectTable(t *testing.T) {
+	table, err := ReflectTable("default", "user")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(table.Name, "user"))
+	col, ok := table.Column("user_name")
+	throwFailNow(t, AssertIs(ok, true))
+	throwFailNow(t, AssertIs(col.Nullable, false))
+	_, ok = table.Column("nums")
+	throwFailNow(t, AssertIs(ok, true))
+	for i := 1; i < len(table.Columns); i++ {
+		throwFailNow(t, AssertIs(table.Columns[i-1].Name < table.Columns[i].Name, true))
+	}
+
+	var maps []Params
+	num, err := table.Select(dORM, "").Values(&maps)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+
+	num, err = table.Select(dORM, "user_name = ?", "slene").Values(&maps)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFailNow(t, AssertIs(maps[0]["email"], "vslene@gmail.com"))
+
+	_, err = ReflectTable("default", "no_such_table")
+	throwFailNow(t, AssertNot(err, nil))
+	_, err = ReflectTable("default", "user; DROP TABLE user")
+	throwFailNow(t, AssertNot(err, nil))
+	_, err = ReflectTable("no_such_alias", "user")
+	throwFailNow(t, AssertNot(err, nil))
+}
+
+func TestAddNotNullColumn(t *testing.T) {
+	al := getDbAlias("default")
+	Q := al.DbBaser.TableQuote()
+	_, err := dORM.Raw(fmt.Sprintf("CREATE TABLE %sadd_column_test%s (%sid%s integer NOT NULL PRIMARY KEY)", Q, Q, Q, Q)).Exec()
+	throwFailNow(t, err)
+	defer dORM.Raw(fmt.Sprintf("DROP TABLE %sadd_column_test%s", Q, Q)).Exec()
+	_, err = dORM.Raw(fmt.Sprintf("INSERT INTO %sadd_column_test%s (%sid%s) VALUES (1), (2)", Q, Q, Q, Q)).Exec()
+	throwFailNow(t, err)
+
+	mi := &models.ModelInfo{Table: "add_column_test"}
+	lang := &models.FieldInfo{Mi: mi, FieldType: TypeVarCharField, Column: "lang", Size: 10, ColDefault: true}
+	lang.Initial.Set("en")
+	for _, fi := range []*models.FieldInfo{
+		{Mi: mi, FieldType: TypeIntegerField, Column: "nums"},
+		{Mi: mi, FieldType: TypeBooleanField, Column: "active"},
+		lang,
+	} {
+		query, err := getColumnAddQuery(al, fi)
+		throwFailNow(t, err)
+		_, err = dORM.Raw(query).Exec()
+		throwFailNow(t, err)
+	}
+
+	var maps []Params
+	num, err := dORM.Raw(fmt.Sprintf("SELECT %snums%s, %slang%s FROM %sadd_column_test%s", Q, Q, Q, Q, Q, Q)).Values(&maps)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFailNow(t, AssertIs(maps[0]["nums"], "0"))
+	throwFailNow(t, AssertIs(maps[1]["lang"], "en"))
+
+	_, err = getColumnAddQuery(al, &models.FieldInfo{Mi: mi, FieldType: TypeDateTimeField, Column: "seen"})
+	throwFailNow(t, AssertNot(err, nil))
+}
+
+func TestNextSequence(t *testing.T) {
+	al := getDbAlias("default")
+	defer func() {
+		Q := al.DbBaser.TableQuote()
+		_, err := dORM.Raw(fmt.Sprintf("DROP TABLE %s%s%s", Q, SequenceTable, Q)).Exec()
+		throwFail(t, err)
+		forgetSequence(al, "invoice")
+		forgetSequence(al, "order")
+	}()
+
+	name := "invoice"
+	for i := int64(1); i <= 3; i++ {
+		value, err := dORM.NextSequence(name)
+		throwFailNow(t, err)
+		throwFail(t, AssertIs(value, i))
+	}
+
+	// the value of a rollback is allocated again
+	to, err := dORM.Begin()
+	throwFailNow(t, err)
+	value, err := to.NextSequence(name)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(value, 4))
+	throwFailNow(t, to.Rollback())
+
+	value, err = dORM.NextSequence(name)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(value, 4))
+
+	// the sequences are independent
+	value, err = dORM.NextSequence("order")
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(value, 1))
+
+	// a row deleted by another is created again
+	Q := al.DbBaser.TableQuote()
+	_, err = dORM.Raw(fmt.Sprintf("DELETE FROM %s%s%s WHERE %sname%s = ?", Q, SequenceTable, Q, Q, Q), "order").Exec()
+	throwFailNow(t, err)
+	value, err = dORM.NextSequence("order")
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(value, 1))
+
+	if IsSqlite {
+		// the table of a schema is qualified, and the copies of the alias by UsingSchema share their keys
+		keys := func() (n int) {
+			ensuredSequences.Range(func(interface{}, interface{}) bool {
+				n++
+				return true
+			})
+			return
+		}
+		so := dORM.UsingSchema("main")
+		defer forgetSequence(so.(*orm).alias, name)
+		value, err = so.NextSequence(name)
+		throwFailNow(t, err)
+		throwFail(t, AssertIs(value, 5))
+		n := keys()
+		value, err = dORM.UsingSchema("main").NextSequence(name)
+		throwFailNow(t, err)
+		throwFail(t, AssertIs(value, 6))
+		throwFail(t, AssertIs(keys(), n))
+	}
+
+	_, err = dORM.NextSequence("")
+	throwFail(t, AssertNot(err, nil))
+
+	if IsSqlite {
+		// sqlite does not allow concurrent writes
+		return
+	}
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		values = make(map[int64]bool)
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := dORM.NextSequence(name)
+			throwFail(t, err)
+			mu.Lock()
+			values[value] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	for i := int64(5); i < 15; i++ {
+		throwFail(t, AssertIs(values[i], true))
+	}
+}
+
+func TestComputedField(t *testing.T) {
+	mi, _ := defaultModelCache.GetByMd(new(Person))
+	throwFail(t, AssertIs(mi.Fields.GetByName("FullName") == nil, true))
+
+	person := &Person{FirstName: "Ada", LastName: "Lovelace", FullName: "not stored"}
+	id, err := dORM.Insert(person)
+	throwFailNow(t, err)
+
+	person = &Person{ID: int(id)}
+	throwFailNow(t, dORM.Read(person))
+	throwFail(t, AssertIs(person.FullName, "Ada LOVELACE"))
+
+	person.LastName = "Byron"
+	person.FullName = "not stored"
+	_, err = dORM.Update(person)
+	throwFailNow(t, err)
+
+	var persons []*Person
+	num, err := dORM.QueryTable("person").Filter("ID", id).All(&persons)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(persons[0].FullName, "Ada BYRON"))
+
+	into := make([]Person, 0, 1)
+	num, err = dORM.QueryTable("person").Filter("ID", id).AllInto(&into)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(into[0].FullName, "Ada BYRON"))
+
+	// not read with the columns given
+	num, err = dORM.QueryTable("person").Filter("ID", id).All(&persons, "FirstName")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(persons[0].FullName, ""))
+
+	_, err = dORM.Delete(&Person{ID: int(id)})
+	throwFail(t, err)
+}
+
+func TestDefaultOrderBy(t *testing.T) {
+	for _, task := range []*Task{{Title: "a", Priority: 1}, {Title: "b", Priority: 3}, {Title: "c", Priority: 1}} {
+		_, err := dORM.Insert(task)
+		throwFailNow(t, err)
+	}
+
+	var tasks []*Task
+	num, err := dORM.QueryTable("task").All(&tasks)
 	throwFailNow(t, err)
-	throwFailNow(t, AssertIs(num, 0))
+	throwFailNow(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(tasks[0].Title, "b"))
+	throwFailNow(t, AssertIs(tasks[1].Title, "a"))
+	throwFailNow(t, AssertIs(tasks[2].Title, "c"))
 
-	var users3 []*User
-	qs = dORM.QueryTable("user")
-	num, err = qs.Filter("user_name", "nothing").All(&users3)
+	num, err = dORM.QueryTable("task").OrderBy("-Title").All(&tasks)
 	throwFailNow(t, err)
-	throwFailNow(t, AssertIs(num, 0))
-	throwFailNow(t, AssertIs(users3 == nil, false))
-}
+	throwFailNow(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(tasks[0].Title, "c"))
+	throwFailNow(t, AssertIs(tasks[2].Title, "a"))
 
-func TestOne(t *testing.T) {
-	var user User
-	qs := dORM.QueryTable("user")
-	err := qs.One(&user)
-	throwFail(t, err)
+	q := &recordQuerier{dbQuerier: getDbAlias("default").DB}
+	o := &ormBase{alias: getDbAlias("default"), db: q}
+	_, err = o.QueryTable("task").NoDefaultOrder().All(&tasks)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(strings.Contains(q.queries[0], "ORDER BY"), false))
 
-	user = User{}
-	err = qs.OrderBy("Id").Limit(1).One(&user)
+	// no default order with group by
+	var maps []Params
+	_, err = o.QueryTable("task").GroupBy("Priority").Values(&maps, "Priority")
 	throwFailNow(t, err)
-	throwFail(t, AssertIs(user.UserName, "slene"))
-	throwFail(t, AssertNot(err, ErrMultiRows))
+	throwFailNow(t, AssertIs(strings.Contains(q.queries[1], "ORDER BY"), false))
 
-	user = User{}
-	err = qs.OrderBy("-Id").Limit(100).One(&user)
+	// no default order with distinct, whose columns may not be the order ones
+	var priorities ParamsList
+	num, err = o.QueryTable("task").Distinct().ValuesFlat(&priorities, "Priority")
 	throwFailNow(t, err)
-	throwFail(t, AssertIs(user.UserName, "nobody"))
-	throwFail(t, AssertNot(err, ErrMultiRows))
+	throwFailNow(t, AssertIs(num, 2))
+	throwFailNow(t, AssertIs(strings.Contains(q.queries[2], "ORDER BY"), false))
 
-	err = qs.Filter("user_name", "nothing").One(&user)
-	throwFail(t, AssertIs(err, ErrNoRows))
+	// no default order in a sub query
+	num, err = o.QueryTable("task").Filter("ID__in", NewSubQuery(o.QueryTable("task").Filter("Priority", 1), "ID")).NoDefaultOrder().All(&tasks)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFailNow(t, AssertIs(strings.Contains(q.queries[3], "ORDER BY"), false))
 }
 
-func TestValues(t *testing.T) {
-	var maps []Params
-	qs := dORM.QueryTable("user")
+// serialize the queries like the lock of a sqlite writer
+type lockQuerier struct {
+	dbQuerier
+	mu sync.Mutex
+}
 
-	num, err := qs.OrderBy("Id").Values(&maps)
-	throwFail(t, err)
-	throwFail(t, AssertIs(num, 3))
-	if num == 3 {
-		throwFail(t, AssertIs(maps[0]["UserName"], "slene"))
-		throwFail(t, AssertIs(maps[2]["Profile"], nil))
-	}
+func (q *lockQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dbQuerier.QueryContext(ctx, query, args...)
+}
 
-	num, err = qs.OrderClauses(
-		order_clause.Clause(
-			order_clause.Column("Id"),
-			order_clause.SortAscending(),
-		),
-	).Values(&maps)
-	throwFail(t, err)
-	throwFail(t, AssertIs(num, 3))
-	if num == 3 {
-		throwFail(t, AssertIs(maps[0]["UserName"], "slene"))
-		throwFail(t, AssertIs(maps[2]["Profile"], nil))
+func TestDeleteReturning(t *testing.T) {
+	if !dDbBaser.SupportReturning() {
+		t.Skip("DELETE RETURNING is not supported by the driver")
+	}
+	for i := 0; i < 10; i++ {
+		_, err := dORM.Insert(&Task{Title: fmt.Sprintf("job%d", i), Priority: 10})
+		throwFailNow(t, err)
 	}
 
-	num, err = qs.OrderBy("Id").Values(&maps, "UserName", "Profile__Age")
-	throwFail(t, err)
-	throwFail(t, AssertIs(num, 3))
-	if num == 3 {
-		throwFail(t, AssertIs(maps[0]["UserName"], "slene"))
-		throwFail(t, AssertIs(maps[0]["Profile__Age"], 28))
-		throwFail(t, AssertIs(maps[2]["Profile__Age"], nil))
+	qs := dORM.QueryTable("task").Filter("Priority", 10).OrderBy("ID")
+	var jobs []*Task
+	num, err := qs.Limit(2).DeleteReturning(&jobs)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFailNow(t, AssertIs(jobs[0].Title, "job0"))
+	throwFailNow(t, AssertIs(jobs[1].Title, "job1"))
+
+	var next []Task
+	num, err = qs.Limit(1).DeleteReturning(&next)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFailNow(t, AssertIs(next[0].Title, "job2"))
+
+	// concurrent consumers dequeue different jobs
+	al := getDbAlias("default")
+	o := &ormBase{alias: al, db: &lockQuerier{dbQuerier: al.DB}}
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var jobs []*Task
+			_, err := o.QueryTable("task").Filter("Priority", 10).OrderBy("ID").Limit(2).DeleteReturning(&jobs)
+			assert.Nil(t, err)
+			mu.Lock()
+			defer mu.Unlock()
+			for _, job := range jobs {
+				assert.False(t, seen[job.Title])
+				seen[job.Title] = true
+			}
+		}()
 	}
+	wg.Wait()
+	throwFailNow(t, AssertIs(len(seen), 7))
 
-	num, err = qs.Filter("UserName", "slene").Values(&maps)
-	throwFail(t, err)
-	throwFail(t, AssertIs(num, 1))
+	num, err = qs.Limit(1).DeleteReturning(&next)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 0))
+
+	assert.Panics(t, func() { _, _ = qs.DeleteReturning(&[]*User{}) })
 }
 
-func TestValuesList(t *testing.T) {
-	var list []ParamsList
-	qs := dORM.QueryTable("user")
+func TestExplain(t *testing.T) {
+	al := getDbAlias("default")
+	q := &recordQuerier{dbQuerier: al.DB}
+	o := &ormBase{alias: al, db: q}
+	qs := o.QueryTable("user").Filter("UserName", "slene")
 
-	num, err := qs.OrderBy("Id").ValuesList(&list)
-	throwFail(t, err)
-	throwFail(t, AssertIs(num, 3))
-	if num == 3 {
-		throwFail(t, AssertIs(list[0][1], "slene")) // username
-		throwFail(t, AssertIs(list[2][10], nil))    // profile
-	}
+	plan, err := qs.Explain(false)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(plan != "", true))
+	throwFailNow(t, AssertIs(strings.HasPrefix(q.queries[0], dDbBaser.ExplainSQL(false)+" SELECT "), true))
 
-	num, err = qs.OrderBy("Id").ValuesList(&list, "UserName", "Profile__Age")
-	throwFail(t, err)
-	throwFail(t, AssertIs(num, 3))
-	if num == 3 {
-		throwFail(t, AssertIs(list[0][0], "slene"))
-		throwFail(t, AssertIs(list[0][1], 28))
-		throwFail(t, AssertIs(list[2][1], nil))
+	if IsSqlite {
+		_, err = qs.Explain(true)
+		throwFailNow(t, AssertIs(err, ErrNotImplement))
+		throwFailNow(t, AssertIs(len(q.queries), 1))
 	}
 }
 
-func TestValuesFlat(t *testing.T) {
-	var list ParamsList
-	qs := dORM.QueryTable("user")
+func TestExcludeInSubQuery(t *testing.T) {
+	// the profile of nobody is NULL
+	num, err := dORM.QueryTable("user").Filter("Profile__isnull", true).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num > 0, true))
 
-	num, err := qs.OrderBy("id").ValuesFlat(&list, "UserName")
-	throwFail(t, err)
-	throwFail(t, AssertIs(num, 3))
-	if num == 3 {
-		throwFail(t, AssertIs(list[0], "slene"))
-		throwFail(t, AssertIs(list[1], "astaxie"))
-		throwFail(t, AssertIs(list[2], "nobody"))
+	unused := NewProfile()
+	unused.Age = 99
+	_, err = dORM.Insert(unused)
+	throwFailNow(t, err)
+	defer func() {
+		_, err := dORM.Delete(unused)
+		throwFail(t, err)
+	}()
+
+	qs := dORM.QueryTable("user_profile")
+	sub := NewSubQuery(dORM.QueryTable("user"), "Profile")
+	total, err := qs.Count()
+	throwFailNow(t, err)
+	used, err := qs.Filter("id__in", sub).Count()
+	throwFailNow(t, err)
+
+	// NOT IN would match no rows at all
+	var profiles []*Profile
+	num, err = qs.Exclude("id__in", sub).All(&profiles)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, total-used))
+	found := false
+	for _, p := range profiles {
+		found = found || p.ID == unused.ID
 	}
+	throwFailNow(t, AssertIs(found, true))
+}
+
+func TestFilterDateEq(t *testing.T) {
+	qs := dORM.QueryTable("post")
+	num, err := qs.FilterDateEq("created", time.Now()).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 4))
+
+	num, err = qs.FilterDateEq("created", time.Now().AddDate(0, 0, -1)).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 0))
+
+	// the day is in the timezone of the alias
+	al := *getDbAlias("default")
+	al.TZ = time.FixedZone("UTC+9", 9*3600)
+	o := &ormBase{alias: &al, db: al.DB}
+	sqs := o.QueryTable("post").FilterDateEq("created", time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)).(*querySet)
+	params := sqs.cond.params
+	throwFailNow(t, AssertIs(len(params), 2))
+	throwFailNow(t, AssertIs(params[0].exprs[1], "gte"))
+	throwFailNow(t, AssertIs(params[0].args[0].(time.Time).Equal(time.Date(2024, 3, 2, 0, 0, 0, 0, al.TZ)), true))
+	throwFailNow(t, AssertIs(params[1].exprs[1], "lt"))
+	throwFailNow(t, AssertIs(params[1].args[0].(time.Time).Equal(time.Date(2024, 3, 3, 0, 0, 0, 0, al.TZ)), true))
 }
 
 func TestRelatedSel(t *testing.T) {
@@ -1632,25 +4108,407 @@ func TestLoadRelated(t *testing.T) {
 	throwFailNow(t, AssertIs(post.Tags[0].BestPost == nil, false))
 	throwFailNow(t, AssertIs(post.Tags[0].BestPost.User.UserName, "astaxie"))
 
-	// load reverse m2m
-	tag := Tag{ID: 1}
+	// load reverse m2m
+	tag := Tag{ID: 1}
+
+	err = dORM.Read(&tag)
+	throwFailNow(t, err)
+
+	num, err = dORM.LoadRelated(&tag, "Posts")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(tag.Posts[0].Title, "Introduction"))
+	throwFailNow(t, AssertIs(tag.Posts[0].User.ID, 2))
+	throwFailNow(t, AssertIs(tag.Posts[0].User.Profile == nil, true))
+
+	num, err = dORM.LoadRelated(&tag, "Posts", hints.DefaultRelDepth())
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+	throwFailNow(t, AssertIs(tag.Posts[0].Title, "Introduction"))
+	throwFailNow(t, AssertIs(tag.Posts[0].User.ID, 2))
+	throwFailNow(t, AssertIs(tag.Posts[0].User.UserName, "slene"))
+}
+
+// run the query instead of the generated ones
+type fixedQuerier struct {
+	dbQuerier
+	query string
+}
+
+func (q *fixedQuerier) QueryContext(ctx context.Context, _ string, _ ...interface{}) (*sql.Rows, error) {
+	return q.dbQuerier.QueryContext(ctx, q.query)
+}
+
+func TestLenientScan(t *testing.T) {
+	al := getDbAlias("default")
+	q := &fixedQuerier{dbQuerier: al.DB, query: "SELECT 1, 'many', 'ok' UNION ALL SELECT 2, '4', 'fine'"}
+	o := &ormBase{alias: al, db: q}
+
+	var ratings []*Rating
+	assert.Panics(t, func() {
+		o.QueryTable("rating").All(&ratings)
+	})
+
+	var warnings []ScanWarning
+	qs := o.QueryTable("rating").LenientScan(&warnings)
+	num, err := qs.OrderBy("ID").All(&ratings)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFail(t, AssertIs(ratings[0].ID, 1))
+	throwFail(t, AssertIs(ratings[0].Stars, 0))
+	throwFail(t, AssertIs(ratings[0].Comment, "ok"))
+	throwFail(t, AssertIs(ratings[1].Stars, 4))
+	throwFail(t, AssertIs(ratings[1].Comment, "fine"))
+
+	throwFailNow(t, AssertIs(len(warnings), 1))
+	throwFail(t, AssertIs(warnings[0].Row, 0))
+	throwFail(t, AssertIs(warnings[0].Column, "stars"))
+	throwFail(t, AssertIs(strings.Contains(warnings[0].Error(), "many"), true))
+
+	into := make([]Rating, 1)
+	num, err = qs.AllInto(&into)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFail(t, AssertIs(into[0].Stars, 0))
+	throwFail(t, AssertIs(len(warnings), 1))
+
+	// the warnings are of the last read
+	q.query = "SELECT 2, '4', 'fine'"
+	var rating Rating
+	throwFailNow(t, qs.One(&rating))
+	throwFail(t, AssertIs(rating.Stars, 4))
+	throwFail(t, AssertIs(len(warnings), 0))
+
+	// the reads running at the same time have their own warnings
+	q.query = "SELECT 1, 'many', 'ok' UNION ALL SELECT 2, '4', 'fine'"
+	var wg sync.WaitGroup
+	counts := make([]int, 4)
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var warnings []ScanWarning
+			var ratings []Rating
+			if _, err := o.QueryTable("rating").LenientScan(&warnings).All(&ratings); err == nil {
+				counts[i] = len(warnings)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, count := range counts {
+		throwFail(t, AssertIs(count, 1))
+	}
+
+	assert.Panics(t, func() {
+		o.QueryTable("rating").LenientScan(nil)
+	})
+}
+
+type countQuerier struct {
+	dbQuerier
+	count int
+}
+
+func (q *countQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	q.count++
+	return q.dbQuerier.QueryContext(ctx, query, args...)
+}
+
+func TestPreload(t *testing.T) {
+	var users []*User
+	num, err := dORM.QueryTable("user").OrderBy("Id").All(&users)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+
+	al := getDbAlias("default")
+	q := &countQuerier{dbQuerier: al.DB}
+	o := &ormBase{alias: al, db: q}
+
+	// one query per relation level
+	err = o.Preload(users, "Posts.User", "Profile")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(q.count, 3))
+	throwFailNow(t, AssertIs(len(users[0].Posts), 1))
+	throwFailNow(t, AssertIs(len(users[1].Posts), 2))
+	throwFailNow(t, AssertIs(len(users[2].Posts), 1))
+	throwFailNow(t, AssertIs(users[1].Posts[1].Title, "Formatting"))
+	throwFailNow(t, AssertIs(users[1].Posts[1].User.UserName, "astaxie"))
+	throwFailNow(t, AssertIs(users[0].Profile.Age, 28))
+	throwFailNow(t, AssertIs(users[2].Profile == nil, true))
+
+	// the number of queries does not depend on the number of models
+	q.count = 0
+	err = o.Preload(users[0], "Posts")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(q.count, 1))
+	throwFailNow(t, AssertIs(len(users[0].Posts), 1))
+
+	// m2m needs one more query for the rel table
+	q.count = 0
+	err = o.Preload(&users, "Posts", "Posts.Tags")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(q.count, 3))
+	throwFailNow(t, AssertIs(len(users[1].Posts[1].Tags), 2))
+	throwFailNow(t, AssertIs(users[1].Posts[1].Tags[0].Name, "golang"))
+	throwFailNow(t, AssertIs(users[1].Posts[1].Tags[1].Name, "format"))
+	throwFailNow(t, AssertIs(users[2].Posts[0].Tags[0].Name, "c++"))
+
+	// reverse m2m
+	var tags []*Tag
+	_, err = dORM.QueryTable("tag").OrderBy("Id").All(&tags)
+	throwFailNow(t, err)
+	q.count = 0
+	err = o.Preload(tags, "Posts")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(q.count, 2))
+	throwFailNow(t, AssertIs(len(tags[0].Posts), 3))
+	throwFailNow(t, AssertIs(len(tags[1].Posts), 1))
+	throwFailNow(t, AssertIs(tags[1].Posts[0].Title, "Examples"))
+
+	// reverse one to one
+	var profiles []Profile
+	_, err = dORM.QueryTable("user_profile").OrderBy("Id").All(&profiles)
+	throwFailNow(t, err)
+	err = dORM.Preload(&profiles, "User")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(profiles[0].User.UserName, "slene"))
+	throwFailNow(t, AssertIs(profiles[1].User.UserName, "astaxie"))
+
+	assert.Panics(t, func() {
+		_ = dORM.Preload(&users, "UserName")
+	})
+}
+
+func TestStream(t *testing.T) {
+	ch, err := dORM.QueryTable("post").OrderBy("id").RelatedSel("user").Stream(context.Background())
+	throwFailNow(t, err)
+	var titles []string
+	for res := range ch {
+		throwFailNow(t, res.Err)
+		post := res.Model.(*Post)
+		throwFailNow(t, AssertNot(post.User, nil))
+		titles = append(titles, post.Title)
+	}
+	throwFailNow(t, AssertIs(strings.Join(titles, ","), "Introduction,Examples,Formatting,Commentary"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err = dORM.QueryTable("post").Stream(ctx)
+	throwFailNow(t, err)
+	res := <-ch
+	throwFailNow(t, res.Err)
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		for range ch {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream is not closed after the cancellation")
+	}
+
+	_, err = dORM.QueryTable("post").Stream(ctx)
+	throwFailNow(t, AssertIs(errors.Is(err, context.Canceled), true))
+}
+
+func TestStreamConsumerTimeout(t *testing.T) {
+	ch, err := dORM.QueryTable("post").OrderBy("id").StreamBuffer(1, 50*time.Millisecond).Stream(context.Background())
+	throwFailNow(t, err)
+	res := <-ch
+	throwFailNow(t, res.Err)
+	throwFailNow(t, AssertIs(res.Model.(*Post).Title, "Introduction"))
+
+	// the stalled consumer aborts the stream, releasing the connection
+	time.Sleep(300 * time.Millisecond)
+	throwFail(t, AssertIs(dORM.DBStats().InUse, 0))
+
+	var titles []string
+	for res = range ch {
+		if res.Err != nil {
+			break
+		}
+		titles = append(titles, res.Model.(*Post).Title)
+	}
+	throwFail(t, AssertIs(res.Err, ErrConsumerTimeout))
+	// the buffered row is received before the error
+	throwFail(t, AssertIs(strings.Join(titles, ","), "Examples"))
+
+	// a consumer in time reads all the rows
+	ch, err = dORM.QueryTable("post").OrderBy("id").StreamBuffer(2, time.Second).Stream(context.Background())
+	throwFailNow(t, err)
+	titles = titles[:0]
+	for res := range ch {
+		throwFailNow(t, res.Err)
+		titles = append(titles, res.Model.(*Post).Title)
+	}
+	throwFailNow(t, AssertIs(strings.Join(titles, ","), "Introduction,Examples,Formatting,Commentary"))
+
+	assert.Panics(t, func() {
+		dORM.QueryTable("post").StreamBuffer(-1, 0)
+	})
+}
+
+// dbBaser declaring cursors, which cursorQuerier runs as pages of the query.
+type cursorDbBaser struct {
+	dbBaser
+}
+
+func (cursorDbBaser) CursorSQL(name string, size int) (string, string, string) {
+	return "DECLARE " + name, fmt.Sprintf("FETCH %d", size), "CLOSE " + name
+}
+
+// transaction querier fetching the rows of the declared query by LIMIT and OFFSET.
+type cursorQuerier struct {
+	dbQuerier
+	query   string
+	args    []interface{}
+	fetches int
+	closed  bool
+}
+
+func (q *cursorQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if strings.HasPrefix(query, "DECLARE ") {
+		q.query, q.args = query[strings.Index(query, " SELECT ")+1:], args
+		return nil, nil
+	}
+	if strings.HasPrefix(query, "CLOSE ") {
+		q.closed = true
+		return nil, nil
+	}
+	return q.dbQuerier.ExecContext(ctx, query, args...)
+}
+
+func (q *cursorQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	var size int
+	if _, err := fmt.Sscanf(query, "FETCH %d", &size); err != nil {
+		return q.dbQuerier.QueryContext(ctx, query, args...)
+	}
+	page := fmt.Sprintf("SELECT * FROM (%s) T LIMIT %d OFFSET %d", q.query, size, q.fetches*size)
+	q.fetches++
+	return q.dbQuerier.QueryContext(ctx, page, q.args...)
+}
+
+func (q *cursorQuerier) Commit() error { return nil }
+
+func (q *cursorQuerier) Rollback() error { return nil }
+
+func (q *cursorQuerier) RollbackUnlessCommit() error { return nil }
+
+func TestStreamFetchSize(t *testing.T) {
+	if !IsSqlite {
+		t.Skip("the cursors are faked on sqlite")
+	}
+	base := newdbBaseSqlite()
+	al := *getDbAlias("default")
+	al.DbBaser = cursorDbBaser{base}
+	base.(*dbBaseSqlite).ins = al.DbBaser
+	q := &cursorQuerier{dbQuerier: al.DB}
+	o := &ormBase{alias: &al, db: q}
+
+	ch, err := o.QueryTable("post").OrderBy("id").FetchSize(3).Stream(context.Background())
+	throwFailNow(t, err)
+	var titles []string
+	for res := range ch {
+		throwFailNow(t, res.Err)
+		titles = append(titles, res.Model.(*Post).Title)
+	}
+	throwFailNow(t, AssertIs(strings.Join(titles, ","), "Introduction,Examples,Formatting,Commentary"))
+	throwFailNow(t, AssertIs(q.fetches, 2))
+	throwFailNow(t, AssertIs(q.closed, true))
+
+	// the rows are read by one query out of a transaction
+	o.db = &recordQuerier{dbQuerier: al.DB}
+	ch, err = o.QueryTable("post").FetchSize(3).Stream(context.Background())
+	throwFailNow(t, err)
+	for res := range ch {
+		throwFailNow(t, res.Err)
+	}
+	throwFailNow(t, AssertIs(len(o.db.(*recordQuerier).queries), 1))
+}
+
+func TestMaxResultBytes(t *testing.T) {
+	to, err := dORM.Begin()
+	throwFailNow(t, err)
+	defer func() {
+		throwFail(t, to.Rollback())
+	}()
+
+	user := &User{ID: 1}
+	for i := 0; i < 3; i++ {
+		_, err = to.Insert(&Post{User: user, Title: "huge", Content: strings.Repeat("x", 4096)})
+		throwFailNow(t, err)
+	}
+	qs := to.QueryTable("post").Filter("Title", "huge")
+
+	var posts []*Post
+	num, err := qs.MaxResultBytes(16 << 10).All(&posts)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 3))
+
+	// aborted by the content of the second row
+	num, err = qs.MaxResultBytes(6 << 10).All(&posts)
+	throwFailNow(t, AssertIs(err, ErrResultTooLarge))
+	throwFailNow(t, AssertIs(num, 0))
+
+	var values []Post
+	_, err = qs.MaxResultBytes(6 << 10).AllInto(&values)
+	throwFailNow(t, AssertIs(err, ErrResultTooLarge))
+
+	ch, err := qs.MaxResultBytes(6 << 10).Stream(context.Background())
+	throwFailNow(t, err)
+	var rows int
+	var last error
+	for res := range ch {
+		if res.Err != nil {
+			last = res.Err
+			continue
+		}
+		rows++
+	}
+	throwFailNow(t, AssertIs(rows, 1))
+	throwFailNow(t, AssertIs(last, ErrResultTooLarge))
+
+	assert.Panics(t, func() {
+		qs.MaxResultBytes(-1)
+	})
+}
 
-	err = dORM.Read(&tag)
+func TestLazy(t *testing.T) {
+	al := getDbAlias("default")
+	q := &countQuerier{dbQuerier: al.DB}
+	o := &ormBase{alias: al, db: q}
+
+	comment := &Comment{ID: 2}
+	err := o.Read(comment)
 	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(comment.Post.Title, ""))
 
-	num, err = dORM.LoadRelated(&tag, "Posts")
+	// loads on the first access only
+	q.count = 0
+	post, err := comment.LazyPost.Get()
 	throwFailNow(t, err)
-	throwFailNow(t, AssertIs(num, 3))
-	throwFailNow(t, AssertIs(tag.Posts[0].Title, "Introduction"))
-	throwFailNow(t, AssertIs(tag.Posts[0].User.ID, 2))
-	throwFailNow(t, AssertIs(tag.Posts[0].User.Profile == nil, true))
+	throwFailNow(t, AssertIs(q.count, 1))
+	throwFailNow(t, AssertIs(post.(*Post).Title, "Examples"))
+	throwFailNow(t, AssertIs(comment.Post.Title, "Examples"))
+	post, err = comment.LazyPost.Get()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(q.count, 1))
+	throwFailNow(t, AssertIs(post.(*Post).Title, "Examples"))
 
-	num, err = dORM.LoadRelated(&tag, "Posts", hints.DefaultRelDepth())
+	var comments []Comment
+	num, err := o.QueryTable("comment").OrderBy("Id").All(&comments)
 	throwFailNow(t, err)
-	throwFailNow(t, AssertIs(num, 3))
-	throwFailNow(t, AssertIs(tag.Posts[0].Title, "Introduction"))
-	throwFailNow(t, AssertIs(tag.Posts[0].User.ID, 2))
-	throwFailNow(t, AssertIs(tag.Posts[0].User.UserName, "slene"))
+	throwFailNow(t, AssertIs(num, 6))
+	q.count = 0
+	post, err = comments[0].LazyPost.Get()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(q.count, 1))
+	throwFailNow(t, AssertIs(post.(*Post).Title, "Introduction"))
+
+	_, err = new(Comment).LazyPost.Get()
+	throwFailNow(t, AssertIs(err, ErrLazyNotBound))
 }
 
 func TestQueryM2M(t *testing.T) {
@@ -2121,6 +4979,122 @@ func TestRawValues(t *testing.T) {
 	}
 }
 
+func TestRawQueryRowsFunc(t *testing.T) {
+	Q := dDbBaser.TableQuote()
+
+	query := fmt.Sprintf("SELECT %sid%s, %suser_name%s, %sprofile_id%s FROM %suser%s ORDER BY %sid%s ASC", Q, Q, Q, Q, Q, Q, Q, Q, Q, Q)
+	var names []string
+	var profiles []interface{}
+	err := dORM.Raw(query).QueryRowsFunc(func(cols []string, vals []interface{}) error {
+		throwFail(t, AssertIs(strings.Join(cols, ","), "id,user_name,profile_id"))
+		names = append(names, fmt.Sprintf("%s", vals[1]))
+		profiles = append(profiles, vals[2])
+		return nil
+	})
+	throwFail(t, err)
+	throwFail(t, AssertIs(strings.Join(names, ","), "slene,astaxie,nobody"))
+	throwFailNow(t, AssertIs(len(profiles), 3))
+	throwFail(t, AssertIs(fmt.Sprint(profiles[0]), "2"))
+	throwFail(t, AssertIs(profiles[2] == nil, true))
+
+	errStop := errors.New("stop")
+	calls := 0
+	err = dORM.Raw(query).QueryRowsFunc(func(cols []string, vals []interface{}) error {
+		calls++
+		if calls == 2 {
+			return errStop
+		}
+		return nil
+	})
+	throwFail(t, AssertIs(err, errStop))
+	throwFail(t, AssertIs(calls, 2))
+}
+
+func TestRawQueryRowsWith(t *testing.T) {
+	Q := dDbBaser.TableQuote()
+
+	type userTags struct {
+		ID   int    `orm:"column(id)"`
+		Name string `orm:"column(user_name)"`
+		Tags []string
+	}
+	scanners := map[string]func([]byte) (interface{}, error){
+		"tags": func(b []byte) (interface{}, error) {
+			if b == nil {
+				return nil, nil
+			}
+			return strings.Split(string(b), ","), nil
+		},
+	}
+
+	query := fmt.Sprintf("SELECT %sid%s, %suser_name%s, 'go,orm,sql' AS %stags%s FROM %suser%s WHERE %suser_name%s = ?", Q, Q, Q, Q, Q, Q, Q, Q, Q, Q)
+	var users []*userTags
+	num, err := dORM.Raw(query, "slene").QueryRowsWith(scanners, &users)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(users[0].Name, "slene"))
+	throwFail(t, AssertIs(users[0].ID > 0, true))
+	throwFailNow(t, AssertIs(len(users[0].Tags), 3))
+	throwFail(t, AssertIs(users[0].Tags[1], "orm"))
+
+	query = fmt.Sprintf("SELECT %sid%s, NULL AS %stags%s FROM %suser%s WHERE %suser_name%s = ?", Q, Q, Q, Q, Q, Q, Q, Q)
+	var rows []userTags
+	num, err = dORM.Raw(query, "slene").QueryRowsWith(scanners, &rows)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(rows[0].Tags == nil, true))
+
+	errBad := errors.New("bad tags")
+	_, err = dORM.Raw(query, "slene").QueryRowsWith(map[string]func([]byte) (interface{}, error){
+		"tags": func(b []byte) (interface{}, error) { return nil, errBad },
+	}, &rows)
+	throwFail(t, AssertIs(errors.Is(err, errBad), true))
+
+	_, err = dORM.Raw(query, "slene").QueryRowsWith(map[string]func([]byte) (interface{}, error){
+		"tags": func(b []byte) (interface{}, error) { return 1, nil },
+	}, &rows)
+	throwFail(t, AssertIs(err != nil, true))
+
+	_, err = dORM.Raw(query, "slene").QueryRowsWith(map[string]func([]byte) (interface{}, error){
+		"missing": func(b []byte) (interface{}, error) { return nil, nil },
+	}, &rows)
+	throwFail(t, AssertIs(err != nil, true))
+}
+
+func TestRawQueryRowsNested(t *testing.T) {
+	Q := dDbBaser.TableQuote()
+
+	type postAuthor struct {
+		ID     int `orm:"column(id)"`
+		Title  string
+		Author *User
+	}
+	query := fmt.Sprintf("SELECT p.%sid%s, p.%stitle%s, u.%sid%s AS %sauthor__id%s, u.%suser_name%s AS %sauthor__user_name%s "+
+		"FROM %spost%s p LEFT JOIN %suser%s u ON u.%sid%s = p.%suser_id%s WHERE p.%stitle%s = ?",
+		Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q)
+	var posts []*postAuthor
+	num, err := dORM.Raw(query, "Introduction").QueryRowsNested(&posts)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(posts[0].Title, "Introduction"))
+	throwFail(t, AssertIs(posts[0].ID > 0, true))
+	throwFailNow(t, AssertIs(posts[0].Author != nil, true))
+	throwFail(t, AssertIs(posts[0].Author.UserName, "slene"))
+	throwFail(t, AssertIs(posts[0].Author.ID > 0, true))
+
+	query = fmt.Sprintf("SELECT %sid%s, %stitle%s, NULL AS %sauthor__id%s, NULL AS %sauthor__user_name%s FROM %spost%s WHERE %stitle%s = ?",
+		Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q, Q)
+	var rows []postAuthor
+	num, err = dORM.Raw(query, "Introduction").QueryRowsNested(&rows)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFail(t, AssertIs(rows[0].Author == nil, true))
+
+	query = fmt.Sprintf("SELECT %sid%s AS %seditor__id%s FROM %spost%s", Q, Q, Q, Q, Q, Q)
+	_, err = dORM.Raw(query).QueryRowsNested(&rows)
+	throwFail(t, AssertIs(err != nil, true))
+}
+
 func TestForIssue4709(t *testing.T) {
 	pre, err := dORM.Raw("INSERT into null_value (value) VALUES (?)").Prepare()
 	assert.Nil(t, err)
@@ -2368,6 +5342,630 @@ func TestTxOrmRollbackUnlessCommit(t *testing.T) {
 	assert.Equal(t, int64(1), num)
 }
 
+func TestDoTxWithTimeout(t *testing.T) {
+	o := NewOrm()
+	ctx := context.Background()
+	countTag := func(name string) int64 {
+		num, err := o.QueryTable("tag").Filter("name", name).Count()
+		assert.Nil(t, err)
+		return num
+	}
+
+	// exceeding the max duration rolls back
+	err := o.DoTxWithTimeout(ctx, &TxOptions{MaxDuration: 50 * time.Millisecond}, func(ctx context.Context, txOrm TxOrmer) error {
+		_, err := txOrm.Insert(&Tag{Name: "timeout slow"})
+		assert.Nil(t, err)
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+	assert.Equal(t, ErrTxTimeout, err)
+	assert.Equal(t, int64(0), countTag("timeout slow"))
+
+	// within the max duration commits
+	err = o.DoTxWithTimeout(ctx, &TxOptions{MaxDuration: 5 * time.Second}, func(ctx context.Context, txOrm TxOrmer) error {
+		_, err := txOrm.Insert(&Tag{Name: "timeout fast"})
+		return err
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), countTag("timeout fast"))
+
+	num, err := o.QueryTable("tag").Filter("name", "timeout fast").Delete()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), num)
+}
+
+func TestDoTxWithPropagation(t *testing.T) {
+	o := NewOrm()
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+	countTag := func(name string) int64 {
+		num, err := o.QueryTable("tag").Filter("name", name).Count()
+		assert.Nil(t, err)
+		return num
+	}
+
+	// required joins the outer transaction, an inner error rolls back both
+	err := o.DoTxWithPropagation(ctx, PropagationRequired, func(ctx context.Context, outer TxOrmer) error {
+		_, err := outer.Insert(&Tag{Name: "propagation outer"})
+		assert.Nil(t, err)
+		return o.DoTxWithPropagation(ctx, PropagationRequired, func(ctx context.Context, inner TxOrmer) error {
+			assert.Equal(t, outer, inner)
+			txOrm, ok := TxOrmerFromContext(ctx, "default")
+			assert.True(t, ok)
+			assert.Equal(t, outer, txOrm)
+			_, err := inner.Insert(&Tag{Name: "propagation inner"})
+			assert.Nil(t, err)
+			return errRollback
+		})
+	})
+	assert.Equal(t, errRollback, err)
+	assert.Equal(t, int64(0), countTag("propagation outer"))
+	assert.Equal(t, int64(0), countTag("propagation inner"))
+
+	_, ok := TxOrmerFromContext(ctx, "default")
+	assert.False(t, ok)
+
+	// nested rolls back to the savepoint only
+	err = o.DoTxWithPropagation(ctx, PropagationNested, func(ctx context.Context, outer TxOrmer) error {
+		_, err := outer.Insert(&Tag{Name: "propagation outer"})
+		assert.Nil(t, err)
+		err = o.DoTxWithPropagation(ctx, PropagationNested, func(ctx context.Context, inner TxOrmer) error {
+			assert.Equal(t, outer, inner)
+			_, err := inner.Insert(&Tag{Name: "propagation inner"})
+			assert.Nil(t, err)
+			return errRollback
+		})
+		assert.Equal(t, errRollback, err)
+		return o.DoTxWithPropagation(ctx, PropagationNested, func(ctx context.Context, inner TxOrmer) error {
+			_, err := inner.Insert(&Tag{Name: "propagation nested"})
+			return err
+		})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), countTag("propagation outer"))
+	assert.Equal(t, int64(0), countTag("propagation inner"))
+	assert.Equal(t, int64(1), countTag("propagation nested"))
+
+	// requires new commits on its own, whatever the outer transaction does
+	err = o.DoTxWithPropagation(ctx, PropagationRequired, func(ctx context.Context, outer TxOrmer) error {
+		err := o.DoTxWithPropagation(ctx, PropagationRequiresNew, func(ctx context.Context, inner TxOrmer) error {
+			assert.NotEqual(t, outer, inner)
+			_, err := inner.Insert(&Tag{Name: "propagation new"})
+			return err
+		})
+		assert.Nil(t, err)
+		_, err = outer.QueryTable("tag").Filter("name", "propagation outer").Delete()
+		assert.Nil(t, err)
+		return errRollback
+	})
+	assert.Equal(t, errRollback, err)
+	assert.Equal(t, int64(1), countTag("propagation outer"))
+	assert.Equal(t, int64(1), countTag("propagation new"))
+
+	num, err := o.QueryTable("tag").Filter("name__startswith", "propagation").Delete()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), num)
+}
+
+func TestNewOrmFromContext(t *testing.T) {
+	ctx := context.Background()
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+	_, ok = NewOrmFromContext(ctx).(Ormer)
+	assert.True(t, ok)
+
+	o := NewOrm()
+	errRollback := errors.New("rollback")
+	// a service function joining the transaction of its caller
+	insertTag := func(ctx context.Context, name string) error {
+		_, err := NewOrmFromContext(ctx).Insert(&Tag{Name: name})
+		return err
+	}
+	err := o.DoTxWithPropagation(ctx, PropagationRequired, func(ctx context.Context, txOrm TxOrmer) error {
+		ambient, ok := FromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, txOrm, ambient)
+		assert.Equal(t, txOrm, NewOrmFromContextUsingDB(ctx, "default"))
+		assert.Nil(t, insertTag(ctx, "ambient tx"))
+		return errRollback
+	})
+	assert.Equal(t, errRollback, err)
+	num, err := o.QueryTable("tag").Filter("name", "ambient tx").Count()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), num)
+
+	assert.Nil(t, insertTag(ctx, "ambient tx"))
+	num, err = o.QueryTable("tag").Filter("name", "ambient tx").Delete()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), num)
+}
+
+func TestTxWithReadCache(t *testing.T) {
+	to, err := dORM.Begin()
+	throwFailNow(t, err)
+	defer func() {
+		throwFail(t, to.Rollback())
+	}()
+
+	q := &countQuerier{dbQuerier: to.(*txOrm).db}
+	counted := &txOrm{ormBase: ormBase{alias: to.(*txOrm).alias, db: q}}
+	o := counted.WithReadCache()
+
+	user := &User{ID: 2}
+	throwFailNow(t, o.Read(user))
+	throwFailNow(t, AssertIs(q.count, 1))
+	user = &User{ID: 2}
+	throwFailNow(t, o.Read(user))
+	throwFailNow(t, AssertIs(q.count, 1))
+	throwFailNow(t, AssertIs(user.UserName, "slene"))
+
+	var maps []Params
+	num, err := o.QueryTable("user").Filter("Status__gt", 1).Values(&maps, "UserName")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	num, err = o.QueryTable("user").Filter("Status__gt", 1).Values(&maps, "UserName")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFailNow(t, AssertIs(q.count, 2))
+
+	// other args are another read
+	num, err = o.QueryTable("user").Filter("Status__gt", 2).Count()
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	throwFailNow(t, AssertIs(q.count, 3))
+
+	// a write clears the cache
+	user.UserName = "slene_cached"
+	_, err = o.Update(user, "UserName")
+	throwFailNow(t, err)
+	user = &User{ID: 2}
+	throwFailNow(t, o.Read(user))
+	throwFailNow(t, AssertIs(q.count, 4))
+	throwFailNow(t, AssertIs(user.UserName, "slene_cached"))
+
+	// a query which is not a SELECT is run every time and clears the cache
+	if IsSqlite || IsPostgres {
+		var name string
+		query := `UPDATE "user" SET "user_name" = ? WHERE "id" = ? RETURNING "user_name"`
+		for i := 1; i <= 2; i++ {
+			throwFailNow(t, o.Raw(query, fmt.Sprintf("slene_returning%d", i), 2).QueryRow(&name))
+			throwFailNow(t, AssertIs(name, fmt.Sprintf("slene_returning%d", i)))
+			throwFailNow(t, AssertIs(q.count, 4+i))
+		}
+		user = &User{ID: 2}
+		throwFailNow(t, o.Read(user))
+		throwFailNow(t, AssertIs(q.count, 7))
+		throwFailNow(t, AssertIs(user.UserName, "slene_returning2"))
+	}
+
+	err = o.Raw("SELECT * FROM not_exist_table").QueryRow(&user)
+	throwFailNow(t, AssertIs(err != nil, true))
+}
+
+// count the rows queries too
+type countRowQuerier struct {
+	countQuerier
+}
+
+func (q *countRowQuerier) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	q.count++
+	return q.dbQuerier.QueryRowContext(ctx, query, args...)
+}
+
+func TestOnChange(t *testing.T) {
+	changeHooksMu.Lock()
+	hooks := changeHooks
+	changeHooksMu.Unlock()
+	defer func() {
+		changeHooksMu.Lock()
+		changeHooks = hooks
+		changeHooksMu.Unlock()
+	}()
+
+	var events []ChangeEvent
+	OnChange(func(ev ChangeEvent) {
+		events = append(events, ev)
+	})
+	last := func() ChangeEvent {
+		throwFailNow(t, AssertIs(len(events) > 0, true))
+		return events[len(events)-1]
+	}
+
+	task := &Task{Title: "cdc", Priority: 1}
+	_, err := dORM.Insert(task)
+	throwFailNow(t, err)
+	ev := last()
+	throwFailNow(t, AssertIs(ev.Op, ChangeInsert))
+	throwFailNow(t, AssertIs(ev.Table, "task"))
+	throwFailNow(t, AssertIs(ev.Model, models.GetFullName(reflect.TypeOf(*task))))
+	throwFailNow(t, AssertIs(len(ev.Pks), 1))
+	throwFailNow(t, AssertIs(ev.Pks[0], task.ID))
+
+	task.Priority = 2
+	_, err = dORM.Update(task, "Priority")
+	throwFailNow(t, err)
+	ev = last()
+	throwFailNow(t, AssertIs(ev.Op, ChangeUpdate))
+	throwFailNow(t, AssertIs(strings.Join(ev.Columns, ","), "Priority"))
+	throwFailNow(t, AssertIs(ev.Pks[0], task.ID))
+
+	_, err = dORM.Delete(task)
+	throwFailNow(t, err)
+	ev = last()
+	throwFailNow(t, AssertIs(ev.Op, ChangeDelete))
+	throwFailNow(t, AssertIs(ev.Pks[0], task.ID))
+	throwFailNow(t, AssertIs(len(events), 3))
+
+	// reported after the commit only
+	txOrm, err := dORM.Begin()
+	throwFailNow(t, err)
+	committed := &Task{Title: "cdc_tx", Priority: 1}
+	_, err = txOrm.Insert(committed)
+	throwFailNow(t, err)
+	_, err = txOrm.Delete(committed)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(len(events), 3))
+	throwFailNow(t, txOrm.Commit())
+	throwFailNow(t, AssertIs(len(events), 5))
+	throwFailNow(t, AssertIs(events[3].Op, ChangeInsert))
+	throwFailNow(t, AssertIs(events[4].Op, ChangeDelete))
+	throwFailNow(t, AssertIs(events[4].Pks[0], committed.ID))
+
+	txOrm, err = dORM.Begin()
+	throwFailNow(t, err)
+	_, err = txOrm.Insert(&Task{Title: "cdc_rollback", Priority: 1})
+	throwFailNow(t, err)
+	throwFailNow(t, txOrm.Rollback())
+	throwFailNow(t, AssertIs(len(events), 5))
+}
+
+func TestTxOnCommitOnRollback(t *testing.T) {
+	var calls []string
+	register := func(txOrm TxOrmer, name string) {
+		txOrm.OnCommit(func() { calls = append(calls, name+"_commit") })
+		txOrm.OnRollback(func() { calls = append(calls, name+"_rollback") })
+	}
+
+	txOrm, err := dORM.Begin()
+	throwFailNow(t, err)
+	register(txOrm, "a")
+	register(txOrm.WithReadCache(), "b")
+	throwFailNow(t, AssertIs(len(calls), 0))
+	throwFailNow(t, txOrm.Commit())
+	throwFailNow(t, AssertIs(strings.Join(calls, ","), "a_commit,b_commit"))
+	// ended, the later rollback runs nothing
+	_ = txOrm.RollbackUnlessCommit()
+	throwFailNow(t, AssertIs(len(calls), 2))
+
+	calls = nil
+	txOrm, err = dORM.Begin()
+	throwFailNow(t, err)
+	register(txOrm, "a")
+	throwFailNow(t, txOrm.Rollback())
+	throwFailNow(t, AssertIs(strings.Join(calls, ","), "a_rollback"))
+
+	calls = nil
+	err = dORM.DoTx(func(ctx context.Context, txOrm TxOrmer) error {
+		register(txOrm, "ok")
+		return nil
+	})
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(strings.Join(calls, ","), "ok_commit"))
+
+	calls = nil
+	err = dORM.DoTx(func(ctx context.Context, txOrm TxOrmer) error {
+		register(txOrm, "err")
+		return errors.New("task failed")
+	})
+	throwFailNow(t, AssertIs(err.Error(), "task failed"))
+	throwFailNow(t, AssertIs(strings.Join(calls, ","), "err_rollback"))
+
+	calls = nil
+	func() {
+		defer func() {
+			throwFailNow(t, AssertIs(recover(), "task panicked"))
+		}()
+		_ = dORM.DoTx(func(ctx context.Context, txOrm TxOrmer) error {
+			register(txOrm, "panic")
+			panic("task panicked")
+		})
+	}()
+	throwFailNow(t, AssertIs(strings.Join(calls, ","), "panic_rollback"))
+}
+
+func TestEntityCache(t *testing.T) {
+	cache, err := NewLRUEntityCache(16)
+	throwFailNow(t, err)
+	al := *getDbAlias("default")
+	al.EntityCache = cache
+	q := &countRowQuerier{countQuerier{dbQuerier: al.DB}}
+	o := &orm{ormBase: ormBase{alias: &al, db: q}}
+
+	user := &User{ID: 2}
+	throwFailNow(t, o.Read(user))
+	throwFailNow(t, AssertIs(q.count, 1))
+	throwFailNow(t, AssertIs(user.UserName, "slene"))
+
+	// read by pk again is a hit
+	user = &User{ID: 2}
+	throwFailNow(t, o.Read(user, "ID"))
+	throwFailNow(t, AssertIs(q.count, 1))
+	throwFailNow(t, AssertIs(user.UserName, "slene"))
+
+	// other columns bypass the cache
+	user = &User{UserName: "slene"}
+	throwFailNow(t, o.Read(user, "UserName"))
+	throwFailNow(t, AssertIs(q.count, 2))
+
+	// update deletes the entry
+	email := user.Email
+	user.Email = "slene-cache@gmail.com"
+	num, err := o.Update(user, "Email")
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+	user = &User{ID: 2}
+	throwFailNow(t, o.Read(user))
+	throwFailNow(t, AssertIs(q.count, 3))
+	throwFailNow(t, AssertIs(user.Email, "slene-cache@gmail.com"))
+	user = &User{ID: 2}
+	throwFailNow(t, o.Read(user))
+	throwFailNow(t, AssertIs(q.count, 3))
+
+	// a miss is not cached
+	err = o.Read(&User{ID: 100})
+	throwFailNow(t, AssertIs(err, ErrNoRows))
+	err = o.Read(&User{ID: 100})
+	throwFailNow(t, AssertIs(err, ErrNoRows))
+	throwFailNow(t, AssertIs(q.count, 5))
+
+	user.Email = email
+	_, err = o.Update(user, "Email")
+	throwFailNow(t, err)
+	key := fmt.Sprintf("%s:%v", o.getMi(user).FullName, 2)
+	_, ok := cache.Get(key)
+	throwFailNow(t, AssertIs(ok, false))
+
+	// in a transaction the entry is deleted by the commit only
+	for _, commit := range []bool{false, true} {
+		throwFailNow(t, o.Read(&User{ID: 2}))
+		tx, err := dORM.Begin()
+		throwFailNow(t, err)
+		to := &txOrm{ormBase: ormBase{alias: &al, db: tx.(*txOrm).db, changes: tx.(*txOrm).changes}}
+		_, err = to.Update(user, "Email")
+		throwFailNow(t, err)
+		_, ok = cache.Get(key)
+		throwFailNow(t, AssertIs(ok, true))
+		if commit {
+			throwFailNow(t, to.Commit())
+		} else {
+			throwFailNow(t, to.Rollback())
+		}
+		_, ok = cache.Get(key)
+		throwFailNow(t, AssertIs(ok, !commit))
+	}
+
+	// so is the entry of a row swapped by CompareAndSwap
+	for _, commit := range []bool{false, true} {
+		throwFailNow(t, o.Read(&User{ID: 2}))
+		tx, err := dORM.Begin()
+		throwFailNow(t, err)
+		to := &txOrm{ormBase: ormBase{alias: &al, db: tx.(*txOrm).db, changes: tx.(*txOrm).changes}}
+		swapped, err := to.CompareAndSwap(&User{}, map[string]interface{}{"email": email}, map[string]interface{}{"email": email}, []interface{}{2})
+		throwFailNow(t, err)
+		throwFailNow(t, AssertIs(len(swapped), 1))
+		_, ok = cache.Get(key)
+		throwFailNow(t, AssertIs(ok, true))
+		if commit {
+			throwFailNow(t, to.Commit())
+		} else {
+			throwFailNow(t, to.Rollback())
+		}
+		_, ok = cache.Get(key)
+		throwFailNow(t, AssertIs(ok, !commit))
+	}
+}
+
+func TestLRUEntityCache(t *testing.T) {
+	cache, err := NewLRUEntityCache(2)
+	throwFailNow(t, err)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	// a is used, so b is the least recently used
+	v, ok := cache.Get("a")
+	throwFail(t, AssertIs(ok, true))
+	throwFail(t, AssertIs(v, 1))
+	cache.Set("c", 3)
+	_, ok = cache.Get("b")
+	throwFail(t, AssertIs(ok, false))
+	_, ok = cache.Get("a")
+	throwFail(t, AssertIs(ok, true))
+	_, ok = cache.Get("c")
+	throwFail(t, AssertIs(ok, true))
+	cache.Delete("c")
+	_, ok = cache.Get("c")
+	throwFail(t, AssertIs(ok, false))
+
+	cache, err = NewLRUEntityCacheWithTTL(2, 50*time.Millisecond)
+	throwFailNow(t, err)
+	cache.Set("a", 1)
+	_, ok = cache.Get("a")
+	throwFail(t, AssertIs(ok, true))
+	time.Sleep(100 * time.Millisecond)
+	_, ok = cache.Get("a")
+	throwFail(t, AssertIs(ok, false))
+	// set again lives for another ttl
+	cache.Set("a", 1)
+	_, ok = cache.Get("a")
+	throwFail(t, AssertIs(ok, true))
+
+	_, err = NewLRUEntityCacheWithTTL(2, -time.Second)
+	throwFail(t, AssertNot(err, nil))
+	_, err = NewLRUEntityCache(0)
+	throwFail(t, AssertNot(err, nil))
+}
+
+// sqliteLocker takes advisory locks by the rows of a table for sqlite.
+type sqliteLocker struct {
+	dbBaser
+}
+
+func (d *sqliteLocker) AdvisoryLockSQL() (string, string) {
+	return "INSERT INTO advisory_locks (name) VALUES (?) ON CONFLICT DO NOTHING RETURNING 1",
+		"DELETE FROM advisory_locks WHERE name = ? RETURNING 1"
+}
+
+func TestAdvisoryLock(t *testing.T) {
+	o := dORM
+	if IsSqlite {
+		_, _, err := dORM.AdvisoryLock(context.Background(), "cron", 0)
+		throwFailNow(t, AssertIs(err, ErrNotImplement))
+
+		_, err = dORM.Raw("CREATE TABLE advisory_locks (name varchar(64) NOT NULL PRIMARY KEY)").Exec()
+		throwFailNow(t, err)
+		defer dORM.Raw("DROP TABLE advisory_locks").Exec()
+		al := *getDbAlias("default")
+		al.DbBaser = &sqliteLocker{al.DbBaser}
+		o = &orm{ormBase: ormBase{alias: &al, db: al.DB}}
+	} else if !IsMysql && !IsPostgres && !IsTidb {
+		return
+	}
+	ctx := context.Background()
+
+	unlock, acquired, err := o.AdvisoryLock(ctx, "cron", time.Second)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(acquired, true))
+
+	start := time.Now()
+	other, acquired, err := o.AdvisoryLock(ctx, "cron", 200*time.Millisecond)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(acquired, false))
+	throwFailNow(t, AssertIs(other == nil, true))
+	throwFailNow(t, AssertIs(time.Since(start) >= 200*time.Millisecond, true))
+
+	// another key is not locked
+	other, acquired, err = o.AdvisoryLock(ctx, "mail", 0)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(acquired, true))
+	throwFailNow(t, other())
+
+	// the waiting lock is taken once released
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = unlock()
+	}()
+	other, acquired, err = o.AdvisoryLock(ctx, "cron", 5*time.Second)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(acquired, true))
+	throwFailNow(t, other())
+
+	cctx, cancel := context.WithCancel(ctx)
+	unlock, _, err = o.AdvisoryLock(ctx, "cron", 0)
+	throwFailNow(t, err)
+	cancel()
+	_, acquired, err = o.AdvisoryLock(cctx, "cron", 5*time.Second)
+	throwFailNow(t, AssertIs(acquired, false))
+	throwFailNow(t, AssertIs(errors.Is(err, context.Canceled), true))
+	throwFailNow(t, unlock())
+}
+
+func TestMigrate(t *testing.T) {
+	Q := getDbAlias("default").DbBaser.TableQuote()
+	var ups, downs []string
+	migrations := []Migration{
+		{
+			ID: "001_create_migrate_note",
+			Up: func(txOrm TxOrmer) error {
+				ups = append(ups, "001")
+				_, err := txOrm.Raw(fmt.Sprintf("CREATE TABLE %smigrate_note%s (%sid%s integer NOT NULL PRIMARY KEY)", Q, Q, Q, Q)).Exec()
+				return err
+			},
+			Down: func(txOrm TxOrmer) error {
+				downs = append(downs, "001")
+				_, err := txOrm.Raw(fmt.Sprintf("DROP TABLE %smigrate_note%s", Q, Q)).Exec()
+				return err
+			},
+		},
+		{
+			ID: "002_insert_migrate_note",
+			Up: func(txOrm TxOrmer) error {
+				ups = append(ups, "002")
+				_, err := txOrm.Raw(fmt.Sprintf("INSERT INTO %smigrate_note%s (%sid%s) VALUES (1)", Q, Q, Q, Q)).Exec()
+				return err
+			},
+			Down: func(txOrm TxOrmer) error {
+				downs = append(downs, "002")
+				_, err := txOrm.Raw(fmt.Sprintf("DELETE FROM %smigrate_note%s", Q, Q)).Exec()
+				return err
+			},
+		},
+	}
+
+	err := Migrate(migrations)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(strings.Join(ups, ","), "001,002"))
+
+	// applied migrations are skipped
+	err = Migrate(migrations)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(len(ups), 2))
+
+	var num int
+	err = dORM.Raw(fmt.Sprintf("SELECT COUNT(*) FROM %smigrate_note%s", Q, Q)).QueryRow(&num)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 1))
+
+	// a failed migration is not recorded
+	failed := append(migrations, Migration{ID: "003_fail", Up: func(txOrm TxOrmer) error {
+		return errors.New("fail")
+	}})
+	err = Migrate(failed)
+	throwFailNow(t, AssertIs(err != nil, true))
+	var ids []string
+	_, err = dORM.Raw(fmt.Sprintf("SELECT %sid%s FROM %s%s%s ORDER BY %sid%s", Q, Q, Q, MigrationTable, Q, Q, Q)).QueryRows(&ids)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(strings.Join(ids, ","), "001_create_migrate_note,002_insert_migrate_note"))
+
+	err = Migrate(append(migrations, migrations[0]))
+	throwFailNow(t, AssertIs(err != nil, true))
+
+	err = RollbackMigrationsWithCtx(context.Background(), "default", migrations, 2)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(strings.Join(downs, ","), "002,001"))
+	ids = nil
+	_, err = dORM.Raw(fmt.Sprintf("SELECT %sid%s FROM %s%s%s", Q, Q, Q, MigrationTable, Q)).QueryRows(&ids)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(len(ids), 0))
+}
+
+func TestMigrationGuard(t *testing.T) {
+	errVeto := errors.New("drop is not allowed")
+	var ops []MigrationOp
+	SetMigrationGuard(func(op MigrationOp) error {
+		ops = append(ops, op)
+		return errVeto
+	})
+	defer SetMigrationGuard(nil)
+
+	// the first drop is vetoed, no table is dropped
+	err := RunSyncdb("default", true, false)
+	assert.Equal(t, errVeto, err)
+	assert.Equal(t, 1, len(ops))
+	assert.Equal(t, MigrationDropTable, ops[0].Type)
+	assert.Equal(t, "default", ops[0].Alias)
+	assert.Equal(t, defaultModelCache.AllOrdered()[0].Table, ops[0].Table)
+	assert.True(t, strings.HasPrefix(ops[0].SQL, "DROP TABLE"))
+
+	num, err := dORM.QueryTable("user").Count()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), num)
+
+	// no drop without force
+	ops = nil
+	err = RunSyncdb("default", false, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ops))
+}
+
 func TestTransactionIsolationLevel(t *testing.T) {
 	// this test worked when database support transaction isolation level
 	if IsSqlite {
@@ -2528,6 +6126,56 @@ func TestInLineOneToOne(t *testing.T) {
 	throwFail(t, AssertIs(rinline.Email, email))
 }
 
+type prepareCountQuerier struct {
+	dbQuerier
+	prepares int
+}
+
+func (q *prepareCountQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	q.prepares++
+	return q.dbQuerier.PrepareContext(ctx, query)
+}
+
+func TestPrepareUpsert(t *testing.T) {
+	al := getDbAlias("default")
+	q := &prepareCountQuerier{dbQuerier: al.DB}
+	o := &orm{ormBase: ormBase{alias: al, db: q}}
+
+	stmt, err := o.PrepareUpsert(NewInLine(), "Name")
+	if err == ErrNotImplement {
+		t.Skip("upsert is not supported by the driver")
+	}
+	throwFailNow(t, err)
+	for _, email := range []string{"first@go.com", "second@go.com"} {
+		for i := 0; i < 10; i++ {
+			inline := NewInLine()
+			inline.Name = fmt.Sprintf("upsert-%d", i)
+			inline.Email = email
+			_, err = stmt.Exec(inline)
+			throwFailNow(t, err)
+		}
+	}
+	throwFail(t, stmt.Close())
+	throwFail(t, AssertIs(q.prepares, 1))
+	_, err = stmt.Exec(NewInLine())
+	throwFail(t, AssertIs(err, ErrStmtClosed))
+
+	qs := dORM.QueryTable("in_line").Filter("Name__startswith", "upsert-")
+	num, err := qs.Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 10))
+	num, err = qs.Filter("Email", "second@go.com").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 10))
+	_, err = qs.Delete()
+	throwFail(t, err)
+
+	_, err = o.PrepareUpsert(NewInLine())
+	throwFail(t, AssertIs(err != nil, true))
+	_, err = o.PrepareUpsert(NewInLine(), "Unknown")
+	throwFail(t, AssertIs(err != nil, true))
+}
+
 func TestIntegerPk(t *testing.T) {
 	its := []IntegerPk{
 		{ID: math.MinInt64, Value: "-"},
@@ -0,0 +1,157 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/core/logs"
+)
+
+// Propagation decides how DoTxWithPropagation works with the transaction in the context.
+type Propagation int
+
+const (
+	// PropagationRequired joins the transaction in the context, or begins a new one.
+	PropagationRequired Propagation = iota
+	// PropagationRequiresNew always begins a new transaction,
+	// the one in the context is suspended until the new one ends.
+	PropagationRequiresNew
+	// PropagationNested runs in a savepoint of the transaction in the context,
+	// rolling back only to the savepoint on error, or begins a new one.
+	PropagationNested
+)
+
+// TxOptions is the sql.TxOptions of a transaction of DoTxWithTimeout
+// and the MaxDuration it can run before it is rolled back.
+type TxOptions struct {
+	sql.TxOptions
+	MaxDuration time.Duration
+}
+
+// the transaction is begun with a ctx canceled by the timer of opts.MaxDuration,
+// database/sql rolls back the transaction of a canceled ctx.
+// the timer is stopped before the commit, so a committed transaction never times out.
+func doTxWithTimeout(ctx context.Context, o TxBeginner, opts *TxOptions,
+	task func(ctx context.Context, txOrm TxOrmer) error) error {
+	if opts == nil {
+		return doTxTemplate(ctx, o, nil, task)
+	}
+	if opts.MaxDuration <= 0 {
+		return doTxTemplate(ctx, o, &opts.TxOptions, task)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	timer := time.AfterFunc(opts.MaxDuration, cancel)
+	defer timer.Stop()
+	return doTxTemplate(ctx, o, &opts.TxOptions, func(ctx context.Context, txOrm TxOrmer) error {
+		err := task(ctx, txOrm)
+		if !timer.Stop() {
+			return ErrTxTimeout
+		}
+		return err
+	})
+}
+
+// the transaction of a db alias stored in the context
+type txCtxKey struct {
+	name string
+}
+
+// the savepoint depth of a db alias stored in the context
+type txSavepointCtxKey struct {
+	name string
+}
+
+// TxOrmerFromContext return the transaction of the db alias aliasName
+// begun by DoTxWithPropagation, ok is false if ctx is not inside one.
+func TxOrmerFromContext(ctx context.Context, aliasName string) (txOrm TxOrmer, ok bool) {
+	txOrm, ok = ctx.Value(txCtxKey{name: aliasName}).(TxOrmer)
+	return
+}
+
+// FromContext return the transaction of the default db begun by DoTxWithPropagation, see TxOrmerFromContext.
+func FromContext(ctx context.Context) (QueryExecutor, bool) {
+	return TxOrmerFromContext(ctx, "default")
+}
+
+// NewOrmFromContext return the transaction of the default db in ctx, or a new Ormer of it outside a transaction,
+// so that the functions taking ctx join the transaction of their caller.
+func NewOrmFromContext(ctx context.Context) QueryExecutor {
+	return NewOrmFromContextUsingDB(ctx, "default")
+}
+
+// NewOrmFromContextUsingDB return the transaction of the db alias aliasName in ctx, or a new Ormer of it.
+func NewOrmFromContextUsingDB(ctx context.Context, aliasName string) QueryExecutor {
+	if txOrm, ok := TxOrmerFromContext(ctx, aliasName); ok {
+		return txOrm
+	}
+	return NewOrmUsingDB(aliasName)
+}
+
+func driverName(d Driver) string {
+	if d == nil {
+		return ""
+	}
+	return d.Name()
+}
+
+func doTxWithPropagation(ctx context.Context, o TxBeginner, aliasName string, propagation Propagation,
+	task func(ctx context.Context, txOrm TxOrmer) error) error {
+	if txOrm, ok := TxOrmerFromContext(ctx, aliasName); ok {
+		switch propagation {
+		case PropagationRequired:
+			return task(ctx, txOrm)
+		case PropagationNested:
+			return doSavepointTemplate(ctx, txOrm, aliasName, task)
+		}
+	}
+	return doTxTemplate(ctx, o, nil, func(ctx context.Context, txOrm TxOrmer) error {
+		ctx = context.WithValue(ctx, txCtxKey{name: aliasName}, txOrm)
+		ctx = context.WithValue(ctx, txSavepointCtxKey{name: aliasName}, 0)
+		return task(ctx, txOrm)
+	})
+}
+
+func doSavepointTemplate(ctx context.Context, txOrm TxOrmer, aliasName string,
+	task func(ctx context.Context, txOrm TxOrmer) error) (err error) {
+	depth, _ := ctx.Value(txSavepointCtxKey{name: aliasName}).(int)
+	depth++
+	savepoint := fmt.Sprintf("beego_sp_%d", depth)
+	if _, err = txOrm.Raw("SAVEPOINT " + savepoint).Exec(); err != nil {
+		return err
+	}
+	panicked := true
+	defer func() {
+		if panicked || err != nil {
+			if _, e := txOrm.Raw("ROLLBACK TO SAVEPOINT " + savepoint).Exec(); e != nil {
+				logs.Error("rollback to savepoint failed: %v,%v", e, panicked)
+			}
+		} else if d := txOrm.Driver(); d == nil || d.Type() != DROracle {
+			// oracle releases savepoints when the transaction ends
+			if _, e := txOrm.Raw("RELEASE SAVEPOINT " + savepoint).Exec(); e != nil {
+				logs.Error("release savepoint failed: %v", e)
+				err = e
+			}
+		}
+	}()
+	err = task(context.WithValue(ctx, txSavepointCtxKey{name: aliasName}, depth), txOrm)
+	panicked = false
+	return err
+}
@@ -0,0 +1,267 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// transaction query with read cache struct.
+// the rows of a SELECT are read once and replayed for the same sql and args,
+// Exec, Prepare, the other queries and the end of the transaction clear the cache.
+type dbReadCache struct {
+	db      dbQuerier
+	mu      sync.Mutex
+	results map[string]*cachedRows
+}
+
+var (
+	_ dbQuerier = new(dbReadCache)
+	_ txEnder   = new(dbReadCache)
+)
+
+// the columns and values of the rows of a query.
+type cachedRows struct {
+	columns []string
+	rows    [][]sqldriver.Value
+}
+
+func newDbReadCache(db dbQuerier) dbQuerier {
+	return &dbReadCache{db: db, results: make(map[string]*cachedRows)}
+}
+
+func (d *dbReadCache) clear() {
+	d.mu.Lock()
+	d.results = make(map[string]*cachedRows)
+	d.mu.Unlock()
+}
+
+// check the query is a SELECT, the other queries such as INSERT ... RETURNING may write.
+func isSelectQuery(query string) bool {
+	query = strings.TrimLeft(query, " \t\r\n(")
+	const kw = "SELECT"
+	return len(query) >= len(kw) && strings.EqualFold(query[:len(kw)], kw) &&
+		(len(query) == len(kw) || !isIdentByte(query[len(kw)]))
+}
+
+// get the rows of query from the cache, or read them from db.
+func (d *dbReadCache) read(ctx context.Context, query string, args []interface{}) (*cachedRows, error) {
+	key := fmt.Sprintf("%s %#v", query, args)
+	d.mu.Lock()
+	res, ok := d.results[key]
+	d.mu.Unlock()
+	if ok {
+		return res, nil
+	}
+
+	rs, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	res = new(cachedRows)
+	if res.columns, err = rs.Columns(); err != nil {
+		return nil, err
+	}
+	for rs.Next() {
+		vals := make([]interface{}, len(res.columns))
+		refs := make([]interface{}, len(vals))
+		for i := range vals {
+			refs[i] = &vals[i]
+		}
+		if err = rs.Scan(refs...); err != nil {
+			return nil, err
+		}
+		row := make([]sqldriver.Value, len(vals))
+		for i, v := range vals {
+			row[i] = v
+		}
+		res.rows = append(res.rows, row)
+	}
+	if err = rs.Err(); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	d.results[key] = res
+	d.mu.Unlock()
+	return res, nil
+}
+
+func (d *dbReadCache) Prepare(query string) (*sql.Stmt, error) {
+	return d.PrepareContext(context.Background(), query)
+}
+
+func (d *dbReadCache) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	d.clear()
+	return d.db.PrepareContext(ctx, query)
+}
+
+func (d *dbReadCache) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return d.ExecContext(context.Background(), query, args...)
+}
+
+func (d *dbReadCache) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	d.clear()
+	return d.db.ExecContext(ctx, query, args...)
+}
+
+func (d *dbReadCache) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.QueryContext(context.Background(), query, args...)
+}
+
+func (d *dbReadCache) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if !isSelectQuery(query) {
+		d.clear()
+		return d.db.QueryContext(ctx, query, args...)
+	}
+	res, err := d.read(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return getReplayDB().QueryContext(ctx, "", res)
+}
+
+func (d *dbReadCache) QueryRow(query string, args ...interface{}) *sql.Row {
+	return d.QueryRowContext(context.Background(), query, args...)
+}
+
+func (d *dbReadCache) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if !isSelectQuery(query) {
+		d.clear()
+		return d.db.QueryRowContext(ctx, query, args...)
+	}
+	res, err := d.read(ctx, query, args)
+	if err != nil {
+		// the replayed error is returned by Scan of the row
+		return getReplayDB().QueryRowContext(ctx, "", err)
+	}
+	return getReplayDB().QueryRowContext(ctx, "", res)
+}
+
+func (d *dbReadCache) Commit() error {
+	d.clear()
+	return d.db.(txEnder).Commit()
+}
+
+func (d *dbReadCache) Rollback() error {
+	d.clear()
+	return d.db.(txEnder).Rollback()
+}
+
+func (d *dbReadCache) RollbackUnlessCommit() error {
+	d.clear()
+	return d.db.(txEnder).RollbackUnlessCommit()
+}
+
+var (
+	replayDB     *sql.DB
+	replayDBOnce sync.Once
+)
+
+// get the db turning cached rows back into *sql.Rows,
+// the query is ignored, the only arg is the *cachedRows or the error to return.
+func getReplayDB() *sql.DB {
+	replayDBOnce.Do(func() {
+		replayDB = sql.OpenDB(replayConnector{})
+	})
+	return replayDB
+}
+
+var errReplayUnsupported = errors.New("<orm.WithReadCache> replay connection only supports query")
+
+type replayConnector struct{}
+
+func (replayConnector) Connect(context.Context) (sqldriver.Conn, error) {
+	return replayConn{}, nil
+}
+
+func (replayConnector) Driver() sqldriver.Driver {
+	return replayDriver{}
+}
+
+type replayDriver struct{}
+
+func (replayDriver) Open(string) (sqldriver.Conn, error) {
+	return replayConn{}, nil
+}
+
+type replayConn struct{}
+
+func (replayConn) Prepare(string) (sqldriver.Stmt, error) {
+	return nil, errReplayUnsupported
+}
+
+func (replayConn) Close() error {
+	return nil
+}
+
+func (replayConn) Begin() (sqldriver.Tx, error) {
+	return nil, errReplayUnsupported
+}
+
+// pass the arg to QueryContext as is.
+func (replayConn) CheckNamedValue(*sqldriver.NamedValue) error {
+	return nil
+}
+
+func (replayConn) QueryContext(_ context.Context, _ string, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+	if len(args) == 1 {
+		switch v := args[0].Value.(type) {
+		case *cachedRows:
+			return &replayRows{cached: v}, nil
+		case error:
+			return nil, v
+		}
+	}
+	return nil, errReplayUnsupported
+}
+
+type replayRows struct {
+	cached *cachedRows
+	next   int
+}
+
+func (r *replayRows) Columns() []string {
+	return r.cached.columns
+}
+
+func (r *replayRows) Close() error {
+	return nil
+}
+
+func (r *replayRows) Next(dest []sqldriver.Value) error {
+	if r.next >= len(r.cached.rows) {
+		return io.EOF
+	}
+	for i, v := range r.cached.rows[r.next] {
+		if b, ok := v.([]byte); ok {
+			// the cached bytes are shared by every replay
+			v = bytes.Clone(b)
+		}
+		dest[i] = v
+	}
+	r.next++
+	return nil
+}
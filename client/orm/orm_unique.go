@@ -0,0 +1,63 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/beego/beego/v2/client/orm/internal/models"
+)
+
+// ErrDuplicate is returned by Insert when CheckUnique of the db alias is on
+// and a row already has the value of a unique column of the model.
+type ErrDuplicate struct {
+	Model  string
+	Column string
+	Value  interface{}
+}
+
+func (e *ErrDuplicate) Error() string {
+	return fmt.Sprintf("duplicated value `%v` of unique column `%s` of model `%s`", e.Value, e.Column, e.Model)
+}
+
+// check that no row has the value of a unique column of the model ind, before inserting it.
+// It is only best-effort: a row inserted by another at the same time is not seen,
+// and the insert then still fails by the unique constraint.
+func (o *ormBase) checkUnique(ctx context.Context, mi *models.ModelInfo, ind reflect.Value) error {
+	for _, fi := range mi.Fields.FieldsDB {
+		if !fi.Unique || fi.Auto || fi.Rel {
+			continue
+		}
+		field := ind.FieldByIndex(fi.FieldIndex)
+		if fi.Null && field.IsZero() {
+			// inserted as NULL, which is never a duplicate
+			continue
+		}
+		value := field.Interface()
+		if field.Kind() == reflect.Ptr {
+			value = field.Elem().Interface()
+		}
+		num, err := newQuerySet(o, mi).Filter(fi.Name, value).CountWithCtx(ctx)
+		if err != nil {
+			return err
+		}
+		if num > 0 {
+			return &ErrDuplicate{Model: mi.Name, Column: fi.Column, Value: value}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/beego/beego/v2/client/orm/internal/models"
+)
+
+// UpsertStmt is an upsert of the rows of a model prepared once by Ormer.PrepareUpsert,
+// executed for every row with its values.
+type UpsertStmt struct {
+	mi     *models.ModelInfo
+	orm    *ormBase
+	stmt   stmtQuerier
+	closed bool
+}
+
+// PrepareUpsert see Ormer.PrepareUpsert.
+func (o *orm) PrepareUpsert(md interface{}, conflictCols ...string) (*UpsertStmt, error) {
+	return o.PrepareUpsertWithCtx(context.Background(), md, conflictCols...)
+}
+
+// PrepareUpsertWithCtx see Ormer.PrepareUpsert.
+func (o *orm) PrepareUpsertWithCtx(ctx context.Context, md interface{}, conflictCols ...string) (*UpsertStmt, error) {
+	mi, _ := o.getPtrMiInd(md)
+	if len(conflictCols) == 0 {
+		return nil, fmt.Errorf("<Ormer.PrepareUpsert> need at least one conflict column")
+	}
+	conflicts := make([]string, len(conflictCols))
+	for i, col := range conflictCols {
+		fi, ok := mi.Fields.GetByAny(col)
+		if !ok || !fi.DBcol {
+			return nil, fmt.Errorf("<Ormer.PrepareUpsert> wrong field/column name `%s`", col)
+		}
+		conflicts[i] = fi.Column
+	}
+	st, query, err := o.alias.DbBaser.PrepareUpsert(ctx, o.db, mi, conflicts)
+	if err != nil {
+		return nil, err
+	}
+	u := &UpsertStmt{mi: mi, orm: &o.ormBase, stmt: st}
+	if Debug {
+		u.stmt = newStmtQueryLog(o.alias, st, query)
+	}
+	return u, nil
+}
+
+// Exec upsert the model md, a ptr of the model of the statement.
+func (u *UpsertStmt) Exec(md interface{}) (sql.Result, error) {
+	return u.ExecWithCtx(context.Background(), md)
+}
+
+// ExecWithCtx upsert the model md, a ptr of the model of the statement.
+func (u *UpsertStmt) ExecWithCtx(ctx context.Context, md interface{}) (sql.Result, error) {
+	if u.closed {
+		return nil, ErrStmtClosed
+	}
+	val := reflect.ValueOf(md)
+	ind := reflect.Indirect(val)
+	name := models.GetFullName(ind.Type())
+	if val.Kind() != reflect.Ptr {
+		panic(fmt.Errorf("<UpsertStmt.Exec> cannot use non-ptr model struct `%s`", name))
+	}
+	if name != u.mi.FullName {
+		panic(fmt.Errorf("<UpsertStmt.Exec> need model `%s` but found `%s`", u.mi.FullName, name))
+	}
+	res, err := u.orm.alias.DbBaser.UpsertStmtExec(ctx, u.stmt, u.mi, ind, u.orm.alias.TZ)
+	if err != nil {
+		return nil, err
+	}
+	u.orm.notifyChange(u.mi, ChangeUpsert, nil, ind)
+	return res, nil
+}
+
+// Close close the prepared statement.
+func (u *UpsertStmt) Close() error {
+	if u.closed {
+		return ErrStmtClosed
+	}
+	u.closed = true
+	return u.stmt.Close()
+}
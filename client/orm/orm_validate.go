@@ -0,0 +1,90 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Validator is the model validating itself before InsertMulti inserts it.
+type Validator interface {
+	Validate() error
+}
+
+// RowError is the error of the row Index of the models of InsertMulti.
+type RowError struct {
+	Index int
+	Err   error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Index, e.Err)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError is returned by InsertMulti with the errors of Validate of all its invalid rows.
+type MultiError []RowError
+
+func (e MultiError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d invalid rows: %s", len(e), strings.Join(msgs, "; "))
+}
+
+func (e MultiError) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// validate the models of sind implementing Validator, return the errors of the invalid ones.
+func validateRows(sind reflect.Value) MultiError {
+	var errs MultiError
+	for i := 0; i < sind.Len(); i++ {
+		row := sind.Index(i)
+		v, ok := row.Interface().(Validator)
+		if !ok && row.CanAddr() {
+			v, ok = row.Addr().Interface().(Validator)
+		}
+		if ok {
+			if err := v.Validate(); err != nil {
+				errs = append(errs, RowError{Index: i, Err: err})
+			}
+		}
+	}
+	return errs
+}
+
+// the slice of the models of sind without the rows of errs.
+func withoutRows(sind reflect.Value, errs MultiError) reflect.Value {
+	rows := reflect.MakeSlice(reflect.SliceOf(sind.Type().Elem()), 0, sind.Len()-len(errs))
+	for i, j := 0, 0; i < sind.Len(); i++ {
+		if j < len(errs) && errs[j].Index == i {
+			j++
+			continue
+		}
+		rows = reflect.Append(rows, sind.Index(i))
+	}
+	return rows
+}
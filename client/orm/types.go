@@ -41,6 +41,22 @@ type TableNameI interface {
 	TableName() string
 }
 
+// TableNoPrefixI is usually used by model
+// when the table of the model is shared and its name must not get the prefix of SetTablePrefix,
+// you can implement this interface
+// for example:
+//
+//	type Setting struct {
+//	  ...
+//	}
+//
+//	func (s *Setting) TableNoPrefix() bool {
+//	   return true
+//	}
+type TableNoPrefixI interface {
+	TableNoPrefix() bool
+}
+
 // TableEngineI is usually used by model
 // when you want to use specific engine, like myisam, you can implement this interface
 // for example:
@@ -71,6 +87,28 @@ type TableIndexI interface {
 	TableIndex() [][]string
 }
 
+// IndexSpec is a named index of TableIndexSpecI
+type IndexSpec = models.IndexSpec
+
+// IndexColumn is a column of IndexSpec
+type IndexColumn = models.IndexColumn
+
+// TableIndexSpecI is usually used by model
+// when you want to create indexes with names or descending columns, you can implement this interface
+// the name is table_column1_column2 if empty
+// for example:
+//
+//	type Post struct {
+//	  ...
+//	}
+//
+//	func (p *Post) TableIndexSpec() []orm.IndexSpec {
+//	   return []orm.IndexSpec{{Name: "idx_post_recent", Columns: []orm.IndexColumn{{Name: "User"}, {Name: "Created", Desc: true}}}}
+//	}
+type TableIndexSpecI interface {
+	TableIndexSpec() []IndexSpec
+}
+
 // TableUniqueI is usually used by model
 // when you want to create unique indexes, you can implement this interface
 // for example:
@@ -86,6 +124,22 @@ type TableUniqueI interface {
 	TableUnique() [][]string
 }
 
+// DefaultOrderByI is usually used by model
+// when you want the rows of a QuerySeter ordered by default, you can implement this interface
+// the default is applied without OrderBy, OrderClauses, Distinct, group by or aggregation, and dropped by NoDefaultOrder
+// for example:
+//
+//	type Post struct {
+//	  ...
+//	}
+//
+//	func (p *Post) DefaultOrderBy() []string {
+//	   return []string{"-Created", "Id"}
+//	}
+type DefaultOrderByI interface {
+	DefaultOrderBy() []string
+}
+
 // IsApplicableTableForDB if return false, we won't create table to this db
 type IsApplicableTableForDB interface {
 	IsApplicableTableForDB(db string) bool
@@ -111,6 +165,26 @@ type TxBeginner interface {
 	DoTxWithCtx(ctx context.Context, task func(ctx context.Context, txOrm TxOrmer) error) error
 	DoTxWithOpts(opts *sql.TxOptions, task func(ctx context.Context, txOrm TxOrmer) error) error
 	DoTxWithCtxAndOpts(ctx context.Context, opts *sql.TxOptions, task func(ctx context.Context, txOrm TxOrmer) error) error
+	// DoTxWithPropagation closure control transaction with the transaction stored in ctx.
+	// The ctx passed to task carries its transaction, so nested calls with that ctx can join it.
+	// for example:
+	//	err := o.DoTxWithPropagation(ctx, orm.PropagationRequired, func(ctx context.Context, txOrm orm.TxOrmer) error {
+	//		// joins the outer transaction
+	//		return o.DoTxWithPropagation(ctx, orm.PropagationNested, func(ctx context.Context, txOrm orm.TxOrmer) error {
+	//			// rolls back to a savepoint on error
+	//			return nil
+	//		})
+	//	})
+	DoTxWithPropagation(ctx context.Context, propagation Propagation, task func(ctx context.Context, txOrm TxOrmer) error) error
+	// DoTxWithTimeout closure control transaction rolled back once it runs longer than opts.MaxDuration,
+	// returning ErrTxTimeout. The statements of task with its ctx are canceled at the timeout,
+	// the later ones fail as the transaction is done. A zero MaxDuration has no timeout.
+	// for example:
+	//	err := o.DoTxWithTimeout(ctx, &orm.TxOptions{MaxDuration: 5 * time.Second}, func(ctx context.Context, txOrm orm.TxOrmer) error {
+	//		_, err := txOrm.QueryTable("user").Filter("id", 1).UpdateWithCtx(ctx, orm.Params{"status": 2})
+	//		return err
+	//	})
+	DoTxWithTimeout(ctx context.Context, opts *TxOptions, task func(ctx context.Context, txOrm TxOrmer) error) error
 }
 
 type TxCommitter interface {
@@ -149,6 +223,7 @@ type DML interface {
 	//  user := new(User)
 	//  id, err = Ormer.Insert(user)
 	//  user must be a pointer and Insert will Set user's pk field
+	// With CheckUnique on the db alias, it returns an *ErrDuplicate when a row has the value of a unique column.
 	Insert(md interface{}) (int64, error)
 	InsertWithCtx(ctx context.Context, md interface{}) (int64, error)
 	// InsertOrUpdate mysql:InsertOrUpdate(model) or InsertOrUpdate(model,"colu=colu+value")
@@ -158,8 +233,23 @@ type DML interface {
 	InsertOrUpdate(md interface{}, colConflitAndArgs ...string) (int64, error)
 	InsertOrUpdateWithCtx(ctx context.Context, md interface{}, colConflitAndArgs ...string) (int64, error)
 	// InsertMulti inserts some models to database
+	// The consecutive models setting the same columns are inserted together by bulk, so the models with an auto pk value
+	// and the ones without it are inserted by different statements.
+	// The models implementing Validator are validated first, and a MultiError of the invalid rows is returned
+	// without inserting any of them, or with SkipInvalid on the db alias after inserting the valid ones.
 	InsertMulti(bulk int, mds interface{}) (int64, error)
 	InsertMultiWithCtx(ctx context.Context, bulk int, mds interface{}) (int64, error)
+	// InsertMultiReturning inserts some models to database like InsertMulti,
+	// and appends the inserted rows returned by the database to container,
+	// which must be a ptr to slice of the model or of ptr to the model.
+	// The rows of every bulk are sorted by the auto pk, which is generated in the order of mds,
+	// as the database does not guarantee the order of the returned rows.
+	// Only the drivers supporting RETURNING, postgres and sqlite, are supported.
+	// for example:
+	//	var inserted []*User
+	//	err := o.InsertMultiReturning(100, users, &inserted)
+	InsertMultiReturning(bulk int, mds interface{}, container interface{}) error
+	InsertMultiReturningWithCtx(ctx context.Context, bulk int, mds interface{}, container interface{}) error
 	// Update updates model to database.
 	// cols Set the Columns those want to update.
 	// find model by Id(pk) field and update Columns specified by Fields, if cols is null then update All Columns
@@ -175,6 +265,21 @@ type DML interface {
 	Delete(md interface{}, cols ...string) (int64, error)
 	DeleteWithCtx(ctx context.Context, md interface{}, cols ...string) (int64, error)
 
+	// NextSequence return the next value of the gap-free sequence name, 1 at first, stored in SequenceTable,
+	// of the schema of UsingSchema if any, which is created with the row of the sequence when needed.
+	// On a TxOrmer the row is locked until the end of the transaction,
+	// so the concurrent ones get the next values in turn and a rollback gives the value back.
+	// On an Ormer it is allocated in a transaction of its own.
+	// for example:
+	//	err := o.DoTx(func(ctx context.Context, txOrm orm.TxOrmer) error {
+	//		no, err := txOrm.NextSequence("invoice")
+	//		...
+	//		_, err = txOrm.Insert(&Invoice{No: no})
+	//		return err
+	//	})
+	NextSequence(name string) (int64, error)
+	NextSequenceWithCtx(ctx context.Context, name string) (int64, error)
+
 	// Raw return a raw query seter for raw sql string.
 	// for example:
 	//	 ormer.Raw("UPDATE `user` SET `user_name` = ? WHERE `user_name` = ?", "slene", "testing").Exec()
@@ -220,6 +325,18 @@ type DQL interface {
 	LoadRelated(md interface{}, name string, args ...utils.KV) (int64, error)
 	LoadRelatedWithCtx(ctx context.Context, md interface{}, name string, args ...utils.KV) (int64, error)
 
+	// Preload load related models of md along the relation paths, avoiding N+1 queries.
+	// md can be a ptr to model struct, a slice of models or a ptr to it.
+	// every relation level is loaded by one batched query for all models of that level,
+	// many to many relation needs one more query for the rel table.
+	//
+	// example:
+	// 	Ormer.Preload(&users, "Posts.Comments", "Profile")
+	// 	for _, post := range users[0].Posts{...}
+	// paths sharing a prefix, such as "Posts" and "Posts.Tags", load the prefix only once.
+	Preload(md interface{}, paths ...string) error
+	PreloadWithCtx(ctx context.Context, md interface{}, paths ...string) error
+
 	// QueryM2M create a models to models queryer
 	// for example:
 	// 	post := Post{Id: 4}
@@ -258,11 +375,126 @@ type QueryExecutor interface {
 type Ormer interface {
 	QueryExecutor
 	TxBeginner
+	AdvisoryLocker
+
+	// UsingSchema return an Ormer of the same db qualifying the tables of the models by schema,
+	// for the schema per tenant, the models are the same for all the schemas.
+	// The statements generated by it and its transactions are qualified, such as SELECT ... FROM "tenant_1"."user" T0,
+	// the sql of Raw is not rewritten, but on postgres its transactions set the search_path to schema,
+	// so the unqualified tables of Raw in them are of the schema.
+	// It does not use the EntityCache. schema can only have letters, digits and underscores.
+	// for example:
+	//	o := orm.NewOrm().UsingSchema("tenant_1")
+	//	o.QueryTable("user").Filter("id", 1).One(&user)
+	UsingSchema(schema string) Ormer
+
+	// ReadOnly return an Ormer of the same db rejecting all the writes with ErrReadOnlyOrmer, without running them,
+	// for the code paths such as the reports which must not write. Its transactions are read-only too.
+	// The statements of QuerySeter, Raw and QueryM2M run only when they start with SELECT, WITH, SHOW, EXPLAIN
+	// or DESCRIBE, it is a guard against the writes by mistake, not a permission of the db.
+	// for example:
+	//	ro := orm.NewOrm().ReadOnly()
+	//	num, err := ro.QueryTable("user").Count()
+	//	_, err = ro.Insert(user) // err is ErrReadOnlyOrmer
+	ReadOnly() Ormer
+
+	// Refresh re-read all the fields of the model md by its pk, including the computed fields,
+	// to get the columns changed by the db, like by triggers or defaults, after a write.
+	// It bypasses the EntityCache and deletes the entry of md, ErrNoRows if the row does not exist.
+	// for example:
+	//	o.Update(user)
+	//	err := o.Refresh(user)
+	Refresh(md interface{}) error
+	RefreshWithCtx(ctx context.Context, md interface{}) error
+
+	// Diff partitions the slice of models incoming, matched with all the rows of their table by the column keyCol,
+	// into the slices of the type of incoming of the models to insert, of the changed ones to update, with the pks of their rows,
+	// and of the rows without a model in incoming to delete. The pk and the auto time fields are not compared.
+	// for example:
+	//	toInsert, toUpdate, toDelete, err := o.Diff(incomingUsers, "email")
+	Diff(incoming interface{}, keyCol string) (toInsert, toUpdate, toDelete interface{}, err error)
+	DiffWithCtx(ctx context.Context, incoming interface{}, keyCol string) (toInsert, toUpdate, toDelete interface{}, err error)
+
+	// CompareAndSwap sets the columns of new on the rows of the table of md with the pks whose columns still have
+	// the values of expected, and return the pks of the swapped rows, the other rows were changed meanwhile.
+	// Each row is swapped atomically by its own update, the batch is not, run it in a transaction for that.
+	// The swapped rows are reported by one ChangeUpdate event.
+	// for example:
+	//	swapped, err := o.CompareAndSwap(&Item{}, map[string]interface{}{"stock": 5}, map[string]interface{}{"stock": 4}, ids)
+	//	// sql-> UPDATE `item` SET `stock` = ? WHERE `id` = ? AND `stock` = ?, for each of the ids
+	CompareAndSwap(md interface{}, expected, new map[string]interface{}, pks []interface{}) (swapped []interface{}, err error)
+	CompareAndSwapWithCtx(ctx context.Context, md interface{}, expected, new map[string]interface{}, pks []interface{}) (swapped []interface{}, err error)
+
+	// Pipeline return a Pipeline issuing the independent queries added to it concurrently over the connection pool,
+	// at most MaxOpenConns at a time, with the results in the order they are added.
+	// for example:
+	//	results := o.Pipeline().Add(countUsers).Add(readPosts).Exec(ctx)
+	Pipeline() *Pipeline
+
+	// PrepareUpsert return an upsert of the rows of the model of md prepared once and executed for every row,
+	// inserting the row or updating the row conflicting on the conflictCols, a pk or unique index,
+	// with all the columns but the conflictCols and the auto_now_add ones.
+	// mysql and tidb update the row of any duplicate key, oracle merges, postgres and sqlite use ON CONFLICT.
+	// for example:
+	//	stmt, err := o.PrepareUpsert(&Metric{}, "Name")
+	//	defer stmt.Close()
+	//	for _, m := range metrics {
+	//		_, err = stmt.Exec(m)
+	//	}
+	PrepareUpsert(md interface{}, conflictCols ...string) (*UpsertStmt, error)
+	PrepareUpsertWithCtx(ctx context.Context, md interface{}, conflictCols ...string) (*UpsertStmt, error)
+
+	// HealthCheck ping all the registered db aliases and report, per alias, if it is reachable, the ping latency
+	// and the connections of its pool, for a readiness probe. The report is healthy if all the aliases are.
+	// for example:
+	//	report := o.HealthCheck(ctx)
+	//	if !report.Healthy {
+	//		w.WriteHeader(http.StatusServiceUnavailable)
+	//	}
+	//	json.NewEncoder(w).Encode(report)
+	HealthCheck(ctx context.Context) HealthReport
+}
+
+type AdvisoryLocker interface {
+	// AdvisoryLock take the advisory lock of key in the db, shared by all the processes using it,
+	// waiting up to timeout for it to be released by another holder.
+	// It keeps a connection until unlock is called, unlock is nil if the lock is not acquired.
+	// Supported by mysql, tidb (GET_LOCK, key up to 64 characters) and postgres (pg_try_advisory_lock of the key hash),
+	// others return ErrNotImplement.
+	// for example:
+	//	unlock, acquired, err := o.AdvisoryLock(ctx, "cron:report", 5*time.Second)
+	//	if err == nil && acquired {
+	//		defer unlock()
+	//		...
+	//	}
+	AdvisoryLock(ctx context.Context, key string, timeout time.Duration) (unlock func() error, acquired bool, err error)
 }
 
 type TxOrmer interface {
 	QueryExecutor
 	TxCommitter
+
+	// WithReadCache return TxOrmer of the same transaction caching the rows of its reads by sql and args,
+	// so repeating a read returns the same rows without querying again.
+	// Exec and Prepare through it clear the cache, writes through the original TxOrmer
+	// or statements prepared before are not tracked.
+	// for example:
+	//	txOrm = txOrm.WithReadCache()
+	//	_ = txOrm.Read(&user) // query
+	//	_ = txOrm.Read(&user) // cached
+	//	_, _ = txOrm.Update(&user) // clear
+	WithReadCache() TxOrmer
+	// OnCommit register fn to run after the transaction is committed, in the order of registration.
+	// fn is dropped if the transaction is rolled back, or the commit fails.
+	// for example:
+	//	txOrm.OnCommit(func() { sendMail(user) })
+	OnCommit(fn func())
+	// OnRollback register fn to run after the transaction is rolled back, in the order of registration,
+	// also by DoTx when the task panics or returns an error, and when the commit fails.
+	// fn is dropped if the transaction is committed.
+	// for example:
+	//	txOrm.OnRollback(func() { releaseSeat(seat) })
+	OnRollback(fn func())
 }
 
 // Inserter insert prepared statement
@@ -280,16 +512,221 @@ type QuerySeter interface {
 	//	qs.Filter("UserName", "slene")
 	//	sql : left outer join profile on t0.id1==t1.id2 where t1.age == 28
 	//	Filter("profile__Age", 28)
+	// 	 // the % and _ of the value of contains, startswith and endswith are matched literally, sql : name LIKE '50\%%'
+	//	qs.Filter("Name__startswith", "50%")
 	// 	 // time compare
 	//	qs.Filter("created", time.Now())
+	// 	 // []byte is bound as bytes, mysql then compares binary-safe whatever the collation of the column is
+	//	qs.Filter("Hash", sum[:])
+	// 	 // IN over related models, pk are extracted from the models
+	//	qs.Filter("User__in", []*User{u1, u2})
+	// 	 // IN with nil also matches NULL, sql : (status IN (?, ?) OR status IS NULL)
+	//	qs.Filter("Status__in", 1, 2, nil)
+	// 	 // compare the character length of column
+	//	qs.Filter("UserName__len__gt", 10)
+	// 	 // compare the quarter or the ISO-8601 week of the date column
+	//	qs.Filter("Created__isoweek", 53)
+	// 	 // compare the whole years from the date column to the current date
+	//	qs.Filter("Birthday__age__gte", 18)
+	// 	 // compare the number of elements of the array or json array column, not supported by oracle
+	//	qs.Filter("Tags__size__gt", 3)
+	// 	 // compare the element at the 0-based index of the array or json array column,
+	// 	 // sql : tags[1] = ? on postgres whose arrays are 1-based, json_extract(tags, '$[0]') = ? on sqlite
+	//	qs.Filter("Tags__0", "go")
+	// 	 // apply lower, upper, trim, ltrim, rtrim or abs to the column, and to the value by Fn
+	//	qs.Filter("Email__lower", orm.Fn("LOWER", email))
+	// 	 // the value sent as a parameter cast to the sql type, sql : name = CAST(? AS NVARCHAR(50))
+	//	qs.Filter("Name", orm.Typed(name, "nvarchar(50)"))
+	// 	 // compare with every or any row of a sub query, not supported by sqlite
+	//	qs.Filter("Age__gt_all", orm.NewSubQuery(o.QueryTable("profile").Filter("Money__lt", 10), "Age"))
+	// 	 // in the rows of a sub query, excluded by NOT EXISTS which is not affected by NULLs
+	//	qs.Exclude("ID__in", orm.NewSubQuery(o.QueryTable("post"), "User"))
+	// 	 // the json path exists in the json column
+	//	qs.Filter("Data__json_exists", "$.user.id")
+	// 	 // the column holds a valid json document, sql : JSON_VALID(data) = 1 on mysql, data IS JSON on oracle and postgres 16+
+	//	qs.Filter("Data__is_json", true)
+	// 	 // equal regardless of the accents, sql : unaccent(name) = unaccent(?) on postgres, not supported by sqlite
+	//	qs.Filter("Name__unaccent", "cafe")
+	// 	 // the json document equals the marshaled value, the keys may be in any order, not supported by sqlite,
+	// 	 // numbers are compared as json numbers, 1 and 1.0 are equal on postgres and mysql
+	//	qs.Filter("Data__json_eq", map[string]interface{}{"id": 1, "tags": []string{"a"}})
+	// 	 // compare with the current time of the database plus intervals
+	//	qs.Filter("Created__before", orm.Now().Minus(7, orm.Day))
+	// 	 // all or any bits of the mask are set in the column
+	//	qs.Filter("Roles__hasall", 6)
+	// 	 // case-insensitive LIKE of any of the patterns, joined by OR
+	//	qs.Filter("UserName__ilike_any", []string{"a%", "b%"})
+	// 	 // the column sounds like the value, sql : SOUNDEX(name) = SOUNDEX(?), not supported by sqlite and tidb,
+	// 	 // postgres needs the fuzzystrmatch extension
+	//	qs.Filter("Name__soundex", "smith")
+	// 	 // the substring occurs at or after the 1-based position
+	//	qs.Filter("Notes__contains_at", orm.SubstrPos("foo", 10))
+	// 	 // the remainder of the column divided by the divisor, sql : (id % ?) = ?, MOD(id, ?) = ? on oracle
+	//	qs.Filter("ID__mod", orm.Mod(10, 3))
+	// 	 // equal with the spaces around both sides trimmed, sql : TRIM(code) = TRIM(?), TRIM(code::text) on postgres,
+	// 	 // oracle trims a value of only spaces to NULL which matches nothing
+	//	qs.Filter("Code__trimeq", " A01 ")
+	// 	 // the comma-separated values of the column include the value, sql : FIND_IN_SET(?, perms) > 0 on mysql,
+	// 	 // the others match the column wrapped by commas with LIKE
+	//	qs.Filter("Perms__includes", "write")
+	// 	 // the polymorphic relation declared by poly(commentable) is the table name and the pk of post
+	//	qs.Filter("commentable", post)
+	// 	 // a boolean field without args is its being true, sql : T0.`is_active` = TRUE, = 1 on sqlite and oracle
+	//	qs.Filter("IsActive")
 	Filter(string, ...interface{}) QuerySeter
+	// FilterInOrdered add the condition of the column in values and order the rows by the position of their value in values,
+	// before the orders already set.
+	// for example:
+	//	qs.FilterInOrdered("id", []interface{}{3, 1, 2})
+	//	//mysql sql-> WHERE T0.`id` IN (?, ?, ?) ORDER BY FIELD(T0.`id`, ?, ?, ?) ASC
+	//	//postgres sql-> WHERE T0."id" IN ($1, $2, $3) ORDER BY CASE T0."id" WHEN $4 THEN 0 WHEN $5 THEN 1 WHEN $6 THEN 2 END ASC
+	FilterInOrdered(column string, values []interface{}) QuerySeter
+	// FilterRecent add the condition of the time column at or after the current time of the database minus within,
+	// computed by the database so the clock of the client is not used. within is a positive whole number of seconds.
+	// for example:
+	//	qs.FilterRecent("LastSeen", time.Hour)
+	//	//mysql sql-> WHERE T0.`last_seen` >= NOW() - INTERVAL 1 HOUR
+	//	//postgres sql-> WHERE T0."last_seen" >= CURRENT_TIMESTAMP - INTERVAL '1 hour'
+	FilterRecent(column string, within time.Duration) QuerySeter
+	// FilterDateEq add the condition of the column within the day of date,
+	// the day is in the timezone of the db alias. It compares with the bounds of the day
+	// so that an index of the column can be used.
+	// for example:
+	//	qs.FilterDateEq("created", time.Now())
+	//	// sql-> WHERE T0.`created` >= ? AND T0.`created` < ?, the start of today and of tomorrow
+	FilterDateEq(column string, date time.Time) QuerySeter
+	// FilterBBox add the conditions of the point of the latitude and longitude columns within the bounding box,
+	// four comparisons of the plain columns, which can use their indexes.
+	// minLng greater than maxLng means the box crosses the antimeridian, the longitude is then compared by OR.
+	// for example:
+	//	qs.FilterBBox("Lat", "Lng", 48.8, 2.2, 48.9, 2.4)
+	//	// sql-> WHERE T0.`lat` >= ? AND T0.`lat` <= ? AND T0.`lng` >= ? AND T0.`lng` <= ?
+	FilterBBox(latCol, lngCol string, minLat, minLng, maxLat, maxLng float64) QuerySeter
+	// FilterOpen add the conditions of the column within the exclusive bounds lo and hi,
+	// unlike the inclusive between operator. A nil bound is skipped, it adds nothing when both are nil.
+	// for example:
+	//	qs.FilterOpen("Age", 18, 65)
+	//	// sql-> WHERE T0.`age` > ? AND T0.`age` < ?
+	FilterOpen(column string, lo interface{}, hi interface{}) QuerySeter
+	// FilterBetween add the condition of the column within the inclusive bounds lo and hi by BETWEEN,
+	// the column can be a field of a related model, joined like by Filter.
+	// A nil bound is the other compared alone, by <= or >=, it adds nothing when both are nil.
+	// for example:
+	//	qs.FilterBetween("Customer__JoinedAt", lo, hi)
+	//	// sql-> INNER JOIN `customer` T1 ON T1.`id` = T0.`customer_id` WHERE T1.`joined_at` BETWEEN ? AND ?
+	FilterBetween(column string, lo interface{}, hi interface{}) QuerySeter
+	// TopNPerGroup keep the first n rows ordered by orderBy of each group of partitionCols,
+	// ranked among the rows matching the conditions added before it, the conditions added after
+	// filter the ranked rows. It needs window functions, sqlite 3.25 or mysql 8 at least,
+	// on an older sqlite the terminal calls return an error without running the query.
+	// for example:
+	//	qs.Filter("status", 1).TopNPerGroup([]string{"user"}, "-created", 3).All(&posts)
+	//	// sql-> WHERE T0.`id` IN (SELECT T.`id` FROM (SELECT T0.`id`, ROW_NUMBER() OVER
+	//	//	(PARTITION BY T0.`user_id` ORDER BY T0.`created` DESC) `row_num` FROM `post` T0 WHERE T0.`status` = ?) T
+	//	//	WHERE T.`row_num` <= 3)
+	TopNPerGroup(partitionCols []string, orderBy string, n int) QuerySeter
+	// FilterWindow keep the rows whose window aggregate compared by operator with value is true,
+	// computed among the rows matching the conditions added before it like TopNPerGroup.
+	// The operator is one of exact, eq, ne, gt, gte, lt and lte. It needs window functions, sqlite 3.25 or mysql 8 at least.
+	// for example:
+	//	// the days of the accounts whose 7-day rolling sum exceeds 1000
+	//	qs.FilterWindow(orm.WindowAggr{Func: "SUM", Column: "amount", Partition: []string{"acct"}, OrderBy: "day", Preceding: 6}, "gt", 1000)
+	//	// sql-> WHERE T0.`id` IN (SELECT T.`id` FROM (SELECT T0.`id`, SUM(T0.`amount`) OVER
+	//	//	(PARTITION BY T0.`acct` ORDER BY T0.`day` ASC ROWS 6 PRECEDING) `window_aggr` FROM `balance` T0) T
+	//	//	WHERE T.`window_aggr` > ?)
+	FilterWindow(aggr WindowAggr, operator string, value interface{}) QuerySeter
 	// FilterRaw add raw sql to querySeter.
 	// for example:
 	// qs.FilterRaw("user_id IN (SELECT id FROM profile WHERE age>=18)")
 	// //sql-> WHERE user_id IN (SELECT id FROM profile WHERE age>=18)
 	FilterRaw(string, string) QuerySeter
+	// FilterByExample add equality conditions for every non-zero field of example,
+	// which must be the model of this QuerySeter. Conditions are joined by AND.
+	// Related fields are matched by primary key, pointer fields are skipped when nil
+	// and, unless hints.IncludeZeroPtr() is given, when they point to a zero value.
+	// for example:
+	//	qs.FilterByExample(&User{UserName: "slene", Status: 1})
+	//	//sql-> WHERE T0.`user_name` = ? AND T0.`Status` = ?
+	FilterByExample(example interface{}, args ...utils.KV) QuerySeter
+	// FilterCoalesce add condition comparing the column, or def when the column is NULL, with value.
+	// operator is one of the Filter operators, empty means exact.
+	// for example:
+	//	qs.FilterCoalesce("Status", 1, "gt", 0)
+	//	//sql-> WHERE COALESCE(T0.`Status`, ?) > ?
+	FilterCoalesce(column string, def interface{}, operator string, value interface{}) QuerySeter
+	// FilterHasRelated add condition of the rows having at least one related row by the relation,
+	// or having none if has is false, by EXISTS or NOT EXISTS of the related rows.
+	// the relation is a fk, one, m2m or reverse field of the model.
+	// for example:
+	//	qs.FilterHasRelated("Posts", false)
+	//	// sql-> WHERE NOT EXISTS (SELECT 1 FROM `post` R WHERE R.`user_id` = T0.`id`)
+	FilterHasRelated(relation string, has bool) QuerySeter
+	// Descendants add condition of the rows in the tree under the row whose pkColumn is rootPK, the root included,
+	// each row linked to its parent row by parentColumn, such as the categories under a category.
+	// The rows are collected by a recursive common table expression, WITH without RECURSIVE on oracle,
+	// the tree must not have cycles. mysql needs 8.0 and tidb 5.1.
+	// for example:
+	//	o.QueryTable("category").Descendants("ID", "Parent", 1)
+	//	// sql-> WHERE T0.`id` IN (WITH RECURSIVE `tree` (`id`) AS (SELECT `id` FROM `category` WHERE `id` = ?
+	//	//	UNION ALL SELECT C.`id` FROM `category` C INNER JOIN `tree` P ON C.`parent_id` = P.`id`) SELECT `id` FROM `tree`)
+	Descendants(pkColumn, parentColumn string, rootPK interface{}) QuerySeter
+	// FilterCollate add condition comparing the column with the collation, whatever the column's default one is.
+	// operator is one of the Filter operators, empty means exact.
+	// The collation is written to sql as is, oracle is not supported.
+	// for example:
+	//	qs.FilterCollate("UserName", "utf8mb4_general_ci", "", "SLENE")
+	//	//sql-> WHERE T0.`user_name` COLLATE utf8mb4_general_ci = ?
+	FilterCollate(column string, collation string, operator string, value interface{}) QuerySeter
+	// FilterCast add condition comparing the column cast to castType with value.
+	// castType is one of int, bigint, decimal, float, string, date and datetime,
+	// operator is one of the Filter operators, empty means exact.
+	// for example:
+	//	qs.FilterCast("Code", "int", "gt", 10)
+	//	//sql-> WHERE CAST(T0.`code` AS SIGNED) > ?
+	FilterCast(column string, castType string, operator string, value interface{}) QuerySeter
+	// FilterConcat add condition comparing the columns concatenated with separator between each with value.
+	// The value is compared by operator with the type of the first column,
+	// operator is one of the Filter operators, empty means exact.
+	// for example:
+	//	qs.FilterConcat([]string{"FirstName", "LastName"}, " ", "", "Ada Lovelace")
+	//	//mysql sql-> WHERE CONCAT(T0.`first_name`, ?, T0.`last_name`) = ?
+	//	//postgres sql-> WHERE (T0."first_name" || $1 || T0."last_name") = $2
+	FilterConcat(cols []string, separator string, operator string, value interface{}) QuerySeter
+	// FilterRound add condition comparing the column rounded to decimals with value,
+	// so that the float columns are compared without their representation errors.
+	// decimals cannot be negative, operator is one of the Filter operators, empty means exact.
+	// for example:
+	//	qs.FilterRound("Price", 2, "", 9.99)
+	//	//mysql sql-> WHERE ROUND(T0.`price`, 2) = ?
+	//	//postgres sql-> WHERE ROUND(T0."price"::numeric, 2) = $1
+	FilterRound(column string, decimals int, operator string, value interface{}) QuerySeter
+	// FilterApprox add condition of the numeric column within tolerance of target, both bounds included.
+	// tolerance cannot be negative.
+	// for example:
+	//	qs.FilterApprox("Temperature", 21.5, 0.5)
+	//	//mysql sql-> WHERE ABS(T0.`temperature` - ?) <= ?
+	FilterApprox(column string, target float64, tolerance float64) QuerySeter
+	// FilterTZ add condition comparing the time column converted to the timezone tz with value, the wall time of tz,
+	// the time of the column is taken in the timezone of the db alias like ValuesTZ. operator is one of the Filter operators, empty means exact.
+	// for example:
+	//	qs.FilterTZ("Created", "America/New_York", "gte", "2024-03-01 00:00:00")
+	//	//postgres sql-> WHERE (T0."created" AT TIME ZONE 'America/New_York') >= $1
+	FilterTZ(column string, tz string, operator string, value interface{}) QuerySeter
+	// Search add an OR of icontains conditions over columns, matching term in any of them.
+	// Repeated columns are used once, no column means no condition.
+	// for example:
+	//	qs.Search("sle", "UserName", "Email")
+	//	//sql-> WHERE ( T0.`user_name` LIKE ? OR T0.`email` LIKE ? )
+	Search(term string, columns ...string) QuerySeter
 	// Exclude add NOT condition to querySeter.
 	// have the same usage as Filter
+	// through a m2m or reverse many relation it excludes the rows having any related row matched,
+	// for example, the posts without the tag:
+	//	qs.Exclude("Tags__Tag__Name", "example")
+	//	//sql-> WHERE NOT T0.`id` IN (SELECT T0.`id` FROM `post` T0 INNER JOIN ... WHERE T2.`name` = ? )
+	// a boolean field without args excludes its being true:
+	//	qs.Exclude("IsActive")
+	//	//sql-> WHERE NOT T0.`is_active` = TRUE
 	Exclude(string, ...interface{}) QuerySeter
 	// SetCond Set condition to QuerySeter.
 	// sql's where condition
@@ -308,6 +745,13 @@ type QuerySeter interface {
 	//  //sql-> WHERE T0.`profile_id` IS NOT NULL AND NOT T0.`Status` IN (?) OR T1.`age` >  2000
 	//  num, err := qs.SetCond(cond).Count()
 	GetCond() *Condition
+	// Err return the first error of the chained calls, such as an unknown field of a Filter, Exclude, SetCond or OrderBy,
+	// the terminal calls, like All, One, Count, Update and Delete, return it without running the query.
+	// for example:
+	//	qs := o.QueryTable("user").Filter("UserNmae", "slene")
+	//	err := qs.Err() // unknown field/column name `UserNmae` of model `User`
+	//	_, err = qs.Count() // the same error
+	Err() error
 	// Limit add LIMIT value.
 	// args[0] means offset, e.g. LIMIT num,offset.
 	// if Limit <= 0 then Limit will be Set to default limit ,eg 1000
@@ -323,11 +767,73 @@ type QuerySeter interface {
 	// for example:
 	//	qs.GroupBy("id")
 	GroupBy(exprs ...string) QuerySeter
+	// TimeBucket add the time column truncated to the unit as alias,
+	// which can be used in Values, ValuesList, ValuesFlat and GroupBy.
+	// unit is one of minute, hour, day, month and year.
+	// for example:
+	//	qs.TimeBucket("Created", "hour", "bucket").GroupBy("bucket").Values(&maps, "bucket")
+	//	// postgres sql-> SELECT date_trunc('hour', T0."created") "bucket" ... GROUP BY date_trunc('hour', T0."created")
+	TimeBucket(column string, unit string, alias string) QuerySeter
+	// TimeBucketTZ add the time column converted to the timezone tz then truncated to the unit as alias,
+	// so the buckets are the local days, or other units, of tz rather than of the db alias.
+	// The time of the column is taken in the timezone of the db alias like ValuesTZ, tz is a timezone name or offset, like America/New_York or +08:00,
+	// the buckets are read as the times of tz. It needs the timezone conversion of ValuesTZ, sqlite is not supported.
+	// for example:
+	//	qs.TimeBucketTZ("Created", "day", "day", "Asia/Tokyo").GroupBy("day").Values(&maps, "day")
+	//	// postgres sql-> SELECT date_trunc('day', (T0."created" AT TIME ZONE 'Asia/Tokyo')) "day" ...
+	TimeBucketTZ(column string, unit string, alias string, tz string) QuerySeter
+	// ValuesCase add the CASE expression of the cases as alias, which can be used in Values, ValuesList and ValuesFlat.
+	// Then of the first case whose condition is true is selected, else elseVal, a nil elseVal selects NULL.
+	// The values are read as they are returned by the driver, with []byte as string.
+	// for example:
+	//	qs.ValuesCase("level", []CaseWhen{{Cond: NewCondition().And("age__lt", 18), Then: "minor"}}, "adult").Values(&maps, "name", "level")
+	//	// postgres sql-> SELECT T0."name" "name", CASE WHEN T0."age" < $1 THEN $2 ELSE $3 END "level" ...
+	ValuesCase(alias string, cases []CaseWhen, elseVal interface{}) QuerySeter
+	// ValuesBool add the condition as alias, which can be used in Values, ValuesList and ValuesFlat.
+	// It is selected by CASE WHEN cond THEN 1 ELSE 0 END and read as a bool, false when cond is NULL.
+	// for example:
+	//	qs.ValuesBool("is_overdue", NewCondition().And("due__lt", time.Now())).Values(&maps, "id", "is_overdue")
+	//	// postgres sql-> SELECT T0."id" "id", CASE WHEN T0."due" < $1 THEN 1 ELSE 0 END "is_overdue" ...
+	ValuesBool(alias string, cond *Condition) QuerySeter
+	// ValuesTZ add the time column converted to the timezone tz as alias, which can be used in Values, ValuesList and ValuesFlat.
+	// The time of the column is taken in the timezone of the db alias, by its current utc offset for mysql and oracle,
+	// tz is a timezone name or offset, like America/New_York or +08:00,
+	// the named timezones of mysql need its timezone tables. sqlite is not supported.
+	// The values are the wall time of tz read as they are returned by the driver, with []byte as string.
+	// for example:
+	//	qs.ValuesTZ("Created", "created_ny", "America/New_York").Values(&maps, "id", "created_ny")
+	//	// mysql sql-> SELECT ..., CONVERT_TZ(T0.`created`, '+00:00', 'America/New_York') `created_ny` ... with the UTC db alias
+	//	// postgres sql-> SELECT ..., (T0."created" AT TIME ZONE 'America/New_York') "created_ny" ...
+	ValuesTZ(column string, alias string, tz string) QuerySeter
 	// OrderBy add ORDER expression.
 	// "column" means ASC, "-column" means DESC.
 	// for example:
 	//	qs.OrderBy("-status")
 	OrderBy(exprs ...string) QuerySeter
+	// OrderByCoalesce add ORDER by the first non null value of the columns after the current orders,
+	// "-column" as the first column means DESC.
+	// for example:
+	//	qs.OrderBy("-status").OrderByCoalesce("display_name", "user_name")
+	//	// sql-> ORDER BY T0.`status` DESC, COALESCE(T0.`display_name`, T0.`user_name`) ASC
+	OrderByCoalesce(cols ...string) QuerySeter
+	// OrderByCollate add ORDER by the column with the collation after the current orders, whatever the column's default one is,
+	// "-column" means DESC. not supported by oracle, which orders by the NLS_SORT setting.
+	// for example:
+	//	qs.OrderByCollate("-UserName", "utf8mb4_general_ci")
+	//	// sql-> ORDER BY T0.`user_name` COLLATE utf8mb4_general_ci DESC
+	OrderByCollate(col string, collation string) QuerySeter
+	// OrderByDistance add ORDER by the distance of the point of the latitude and longitude columns to the point lat, lng
+	// after the current orders, the nearest first.
+	// The distance is the equirectangular approximation of the plain columns, which needs no spatial types or functions
+	// and sorts like the great circle distance except across the antimeridian or near the poles.
+	// for example:
+	//	qs.OrderByDistance("Lat", "Lng", 48.85, 2.35)
+	//	// sql-> ORDER BY ((T0.`lat` - ?) * (T0.`lat` - ?) + (T0.`lng` - ?) * (T0.`lng` - ?) * ?) ASC
+	OrderByDistance(latCol, lngCol string, lat, lng float64) QuerySeter
+	// NoDefaultOrder do not apply the DefaultOrderBy of the model when there is no OrderBy.
+	// for example:
+	//	qs.NoDefaultOrder()
+	NoDefaultOrder() QuerySeter
 	// OrderClauses add ORDER expression by order clauses
 	// for example:
 	//	OrderClauses(
@@ -380,15 +886,68 @@ type QuerySeter interface {
 	//    Distinct().
 	//    All(&permissions)
 	Distinct() QuerySeter
+	// DedupByPK keep only the first row of every primary key in the result of All,
+	// for joins over one-to-many relations which repeat the parent row.
+	// Unlike Distinct the rows are compared by primary key after scanning, not by the database.
+	// Other loaders work on the deduplicated result, so call Preload or LoadRelated after All.
+	// for example:
+	//  o.QueryTable("user").Filter("Posts__Title__contains", "go").DedupByPK().All(&users)
+	DedupByPK() QuerySeter
+	// LenientScan keep reading when a column of a row cannot be converted to its field by All, AllInto or One,
+	// such as a text in an integer column of dirty legacy data. The field is left at its zero value
+	// and the error is collected instead of the panic of the read.
+	// Every All, AllInto and One sets warnings to the warnings of its read, in the order of the rows,
+	// so use a warnings of its own for every goroutine running the query.
+	// for example:
+	//  var warnings []orm.ScanWarning
+	//  num, err := o.QueryTable("user").LenientScan(&warnings).All(&users)
+	LenientScan(warnings *[]ScanWarning) QuerySeter
+	// Map set fn to transform every model scanned by All and One before it is put into the container,
+	// such as to compute the derived fields or to redact the secret ones. fn runs in the scan order of the rows,
+	// it gets the pointer to the model and must return a non nil pointer to a model of the same type,
+	// which is put into the container. The fns of the chained Maps run in the order they are set.
+	// for example:
+	//  qs.Map(func(m interface{}) interface{} {
+	//  	user := m.(*User)
+	//  	user.Password = ""
+	//  	return user
+	//  }).All(&users)
+	Map(fn func(interface{}) interface{}) QuerySeter
 	// ForUpdate Set FOR UPDATE to query.
 	// for example:
 	//  o.QueryTable("user").Filter("uid", uid).ForUpdate().All(&users)
 	ForUpdate() QuerySeter
+	// NoPrepare execute the query directly, not by a prepared statement of the MaxStmtCacheSize cache,
+	// so that one-off queries do not take the place of the frequent ones.
+	// for example:
+	//  o.QueryTable("user").Filter("uid", uid).NoPrepare().All(&users)
+	NoPrepare() QuerySeter
 	// Count returns QuerySeter execution result number
 	// for example:
 	//	num, err = qs.Filter("profile__age__gt", 28).Count()
 	Count() (int64, error)
 	CountWithCtx(context.Context) (int64, error)
+	// CountColumn returns the number of rows whose col is not NULL, by COUNT(col)
+	// for example:
+	//	num, err = qs.CountColumn("email")
+	//	// sql-> SELECT COUNT(T0.`email`) FROM `user` T0
+	CountColumn(col string) (int64, error)
+	CountColumnWithCtx(ctx context.Context, col string) (int64, error)
+	// GroupCount returns the number of rows for each distinct value of groupCol
+	// rows whose group value is NULL are counted under the nil key, apart from the rows of the zero value,
+	// and the binary values are keyed by their string
+	// for example:
+	//	counts, err := qs.GroupCount("status")
+	//	// sql-> SELECT T0.`status`, COUNT(*) FROM `user` T0 GROUP BY T0.`status`
+	//	// counts[int64(1)] == 2
+	GroupCount(groupCol string) (map[interface{}]int64, error)
+	GroupCountWithCtx(ctx context.Context, groupCol string) (map[interface{}]int64, error)
+	// GroupCountColumn returns the number of rows whose col is not NULL for each distinct value of groupCol
+	// for example:
+	//	counts, err := qs.GroupCountColumn("status", "email")
+	//	// sql-> SELECT T0.`status`, COUNT(T0.`email`) FROM `user` T0 GROUP BY T0.`status`
+	GroupCountColumn(groupCol string, col string) (map[interface{}]int64, error)
+	GroupCountColumnWithCtx(ctx context.Context, groupCol string, col string) (map[interface{}]int64, error)
 	// Exist check result empty or not after QuerySeter executed
 	// the same as QuerySeter.Count > 0
 	Exist() bool
@@ -409,6 +968,17 @@ type QuerySeter interface {
 	// 	//delete two user  who's name is testing1 or testing2
 	Delete() (int64, error)
 	DeleteWithCtx(context.Context) (int64, error)
+	// DeleteReturning delete the rows like Delete, up to the Limit in the order of OrderBy,
+	// and scan the deleted rows to container, a ptr to a slice of the model, the number of them is returned.
+	// Postgres locks the rows by FOR UPDATE SKIP LOCKED, so that concurrent calls, such as the consumers of
+	// a job queue, delete and return different rows. Only postgres and sqlite are supported.
+	// for example:
+	//	var jobs []*Job
+	//	num, err := qs.Filter("Status", "ready").OrderBy("Id").Limit(10).DeleteReturning(&jobs)
+	//	//sql-> DELETE FROM "job" WHERE "id" IN (SELECT T0."id" FROM "job" T0 WHERE T0."status" = $1
+	//	//	ORDER BY T0."id" ASC LIMIT 10 FOR UPDATE OF T0 SKIP LOCKED) RETURNING "id", "status", ...
+	DeleteReturning(container interface{}) (int64, error)
+	DeleteReturningWithCtx(ctx context.Context, container interface{}) (int64, error)
 	// PrepareInsert return an insert queryer.
 	// it can be used in times.
 	// example:
@@ -425,6 +995,16 @@ type QuerySeter interface {
 	//	qs.All(&users) // users[0],users[1],users[2] ...
 	All(container interface{}, cols ...string) (int64, error)
 	AllWithCtx(ctx context.Context, container interface{}, cols ...string) (int64, error)
+	// AllInto query all data into the elements of the ptr slice of the models container,
+	// which is preallocated, such as by the result of Count, to avoid growing it row by row.
+	// The elements are reset and reused, the slice grows only when there are more rows than its length,
+	// then it is cut to the number of rows.
+	// for example:
+	//	num, _ := qs.Count()
+	//	users := make([]User, num)
+	//	qs.AllInto(&users)
+	AllInto(container interface{}, cols ...string) (int64, error)
+	AllIntoWithCtx(ctx context.Context, container interface{}, cols ...string) (int64, error)
 	// One query one row data and map to containers.
 	// cols means the Columns when querying.
 	// for example:
@@ -432,6 +1012,53 @@ type QuerySeter interface {
 	//	qs.One(&user) //user.UserName == "slene"
 	One(container interface{}, cols ...string) error
 	OneWithCtx(ctx context.Context, container interface{}, cols ...string) error
+	// Cursor return the Cursor of the rows ordered by orderCols, "-column" means DESC, read by pages of the limit
+	// after the position of the last row, so its Position can be saved to resume the iteration by Seek.
+	// the pk is added as the last column when it is not in orderCols so the order is unique.
+	// for example:
+	//	cursor := qs.Limit(500).Cursor([]string{"-created"})
+	//	err := cursor.Seek(saved)
+	//	for ok, err := cursor.Next(&user); ok; ok, err = cursor.Next(&user) {
+	//		saved = cursor.Position()
+	//	}
+	Cursor(orderCols []string) *Cursor
+	// Stream query data and send the models to the channel while reading the rows,
+	// the channel is closed after the last model or the error, or when ctx is done.
+	// Stop reading before the end by canceling ctx, the connection is held until then.
+	// for example:
+	//	ch, err := qs.Stream(ctx)
+	//	for res := range ch {
+	//		if res.Err != nil { ... }
+	//		user := res.Model.(*User)
+	//	}
+	Stream(ctx context.Context) (<-chan RowResult, error)
+	// FetchSize set the number of rows fetched from the db at a time by Stream.
+	// postgres fetches the rows by a cursor in a transaction, by the whole result set out of it,
+	// mysql, tidb and sqlite drivers always read the rows from the connection while streaming, so it does nothing.
+	// for example:
+	//	ch, err := txOrm.QueryTable("user").FetchSize(500).Stream(ctx)
+	FetchSize(n int) QuerySeter
+	// StreamBuffer set the number of the rows Stream reads ahead into the buffer of its channel,
+	// and the time the consumer can take to receive a row once the buffer is full.
+	// A stalled consumer aborts the stream, its connection is released at once and the channel gets ErrConsumerTimeout
+	// after the buffered rows, instead of holding the connection until the consumer resumes. A zero timeout waits forever.
+	// for example:
+	//	ch, err := qs.StreamBuffer(100, 30*time.Second).Stream(ctx)
+	StreamBuffer(size int, consumerTimeout time.Duration) QuerySeter
+	// MaxResultBytes abort All, AllInto and Stream with ErrResultTooLarge once the sum of the lengths
+	// of the strings and bytes scanned from the rows exceeds n, the other values are not counted.
+	// It bounds the memory of the rows having large TEXT or BLOB columns, 0 means unlimited.
+	// for example:
+	//	num, err := qs.MaxResultBytes(64 << 20).All(&posts)
+	MaxResultBytes(n int64) QuerySeter
+	// Explain return the plan of the query of All, by EXPLAIN of mysql, tidb and postgres
+	// and EXPLAIN QUERY PLAN of sqlite. analyze runs the query to report the actual costs,
+	// by EXPLAIN ANALYZE of mysql 8.0.18+, tidb and postgres, sqlite and oracle are not supported.
+	// the columns of a row of the plan are joined by tab and the rows by newline.
+	// for example:
+	//	plan, err := qs.Filter("Status", 1).Explain(false)
+	Explain(analyze bool) (string, error)
+	ExplainWithCtx(ctx context.Context, analyze bool) (string, error)
 	// Values query All data and map to []map[string]interface.
 	// expres means condition expression.
 	// it converts data to []map[column]value.
@@ -485,6 +1112,28 @@ type QuerySeter interface {
 	// var res []result
 	//  o.QueryTable("dept_info").Aggregate("dept_name,sum(salary) as total").GroupBy("dept_name").All(&res)
 	Aggregate(s string) QuerySeter
+	// AggregateBy select the group columns and the aggregations, grouped by the group columns.
+	// for example:
+	// type result struct {
+	//	ProductId int
+	//	AvgRating float64
+	// }
+	// var res []result
+	//  o.QueryTable("review").AggregateBy([]string{"product_id"}, orm.Avg("rating", orm.CoalesceZero())).All(&res)
+	//  // sql-> SELECT T0.`product_id`, AVG(COALESCE(T0.`rating`, 0)) `avg_rating` FROM `review` T0 GROUP BY T0.`product_id`
+	AggregateBy(groups []string, aggrs ...Aggregation) QuerySeter
+	// Having filter the groups by the conditions of their aggregations or of the ratios of them, joined by AND.
+	// for example:
+	//  o.QueryTable("signup").AggregateBy([]string{"campaign"}, orm.Count("*")).
+	//  	Having(orm.Ratio(orm.Sum("converted"), orm.Count("*")).Gt(0.5)).All(&res)
+	//  // sql-> ... GROUP BY T0.`campaign` HAVING 1.0 * SUM(T0.`converted`) / NULLIF(COUNT(*), 0) > ?
+	Having(conds ...HavingCond) QuerySeter
+	// MarshalSpec return the json spec of the conditions, orders, limit and offset of the query,
+	// which QuerySeterFromSpec turns back into a QuerySeter.
+	// for example:
+	//  spec, err := o.QueryTable("user").Filter("age__gt", 18).OrderBy("-id").Limit(10).MarshalSpec()
+	//  // spec-> {"cond":[{"expr":"age__gt","args":[18]}],"orders":["-id"],"limit":10}
+	MarshalSpec() ([]byte, error)
 }
 
 // QueryM2Mer model to model query struct
@@ -550,6 +1199,10 @@ type RawSeter interface {
 	//	num, err = dORM.Raw(query).QueryRows(&ids,&names) // ids=>{1,2},names=>{"nobody","slene"}
 	QueryRows(containers ...interface{}) (int64, error)
 	SetArgs(...interface{}) RawSeter
+	// NoPrepare execute the query directly, not by a prepared statement of the MaxStmtCacheSize cache.
+	// for example:
+	//	num, err = dORM.Raw("SELECT * FROM user WHERE id = ?", 1).NoPrepare().QueryRows(&users)
+	NoPrepare() RawSeter
 	// Values query data to []map[string]interface
 	// see QuerySeter's Values
 	Values(container *[]Params, cols ...string) (int64, error)
@@ -582,6 +1235,40 @@ type RawSeter interface {
 	// }
 	RowsToStruct(ptrStruct interface{}, keyCol, valueCol string) (int64, error)
 
+	// QueryRowsFunc query rows one by one without buffering them,
+	// fn is called for every row with the column names and the values scanned by the driver.
+	// vals is reused for the next row, copy it to keep the values.
+	// An error returned by fn stops the iteration and is returned.
+	// for example:
+	//	err := dORM.Raw("SELECT id, name FROM user").QueryRowsFunc(func(cols []string, vals []interface{}) error {
+	//		fmt.Println(cols, vals) // [id name] [2 [115 108 101 110 101]]
+	//		return nil
+	//	})
+	QueryRowsFunc(fn func(cols []string, vals []interface{}) error) error
+	// QueryRowsWith query rows to the ptr slice of structs container like QueryRows,
+	// the columns of scanners are decoded by them from the raw bytes, nil for NULL, instead of by the field types.
+	// The values they return must be assignable or convertible to the fields of the columns.
+	// for example:
+	//	var posts []*PostTags
+	//	num, err := dORM.Raw("SELECT id, tags FROM post").QueryRowsWith(map[string]func([]byte) (interface{}, error){
+	//		"tags": func(b []byte) (interface{}, error) { return strings.Split(string(b), ","), nil },
+	//	}, &posts)
+	QueryRowsWith(scanners map[string]func([]byte) (interface{}, error), container interface{}) (int64, error)
+	// QueryRowsNested query rows to the ptr slice of structs container like QueryRows,
+	// the columns aliased by the path of the nested struct fields and a column joined by __ are set to them,
+	// the fields are matched by their snake name or name. A nested ptr struct stays nil when all its columns are NULL,
+	// such as the unmatched rows of a LEFT JOIN.
+	// for example:
+	//	type postRow struct {
+	//		ID     int    `orm:"column(id)"`
+	//		Title  string
+	//		Author *User
+	//	}
+	//	var rows []postRow
+	//	num, err := dORM.Raw("SELECT p.id, p.title, u.id AS author__id, u.user_name AS author__user_name " +
+	//		"FROM post p LEFT JOIN user u ON u.id = p.user_id").QueryRowsNested(&rows)
+	QueryRowsNested(container interface{}) (int64, error)
+
 	// Prepare return prepared raw statement for used in times.
 	// for example:
 	// 	pre, err := dORM.Raw("INSERT INTO tag (name) VALUES (?)").Prepare()
@@ -624,30 +1311,72 @@ type dbQuerier interface {
 type dbBaser interface {
 	Read(context.Context, dbQuerier, *models.ModelInfo, reflect.Value, *time.Location, []string, bool) error
 	ReadBatch(context.Context, dbQuerier, querySet, *models.ModelInfo, *Condition, interface{}, *time.Location, []string) (int64, error)
+	ReadInto(context.Context, dbQuerier, querySet, *models.ModelInfo, *Condition, interface{}, *time.Location, []string) (int64, error)
+	StreamBatch(context.Context, dbQuerier, querySet, *models.ModelInfo, *Condition, *time.Location) (streamRows, func() (reflect.Value, error), error)
+	Explain(context.Context, dbQuerier, querySet, *models.ModelInfo, *Condition, bool, *time.Location) (string, error)
 	Count(context.Context, dbQuerier, querySet, *models.ModelInfo, *Condition, *time.Location) (int64, error)
+	GroupCount(context.Context, dbQuerier, querySet, *models.ModelInfo, *Condition, string, *time.Location) (map[interface{}]int64, error)
 	ReadValues(context.Context, dbQuerier, querySet, *models.ModelInfo, *Condition, []string, interface{}, *time.Location) (int64, error)
 
 	Insert(context.Context, dbQuerier, *models.ModelInfo, reflect.Value, *time.Location) (int64, error)
 	InsertOrUpdate(context.Context, dbQuerier, *models.ModelInfo, reflect.Value, *alias, ...string) (int64, error)
 	InsertMulti(context.Context, dbQuerier, *models.ModelInfo, reflect.Value, int, *time.Location) (int64, error)
+	InsertMultiReturning(context.Context, dbQuerier, *models.ModelInfo, reflect.Value, int, interface{}, *time.Location) error
 	InsertValue(context.Context, dbQuerier, *models.ModelInfo, bool, []string, []interface{}) (int64, error)
 	InsertStmt(context.Context, stmtQuerier, *models.ModelInfo, reflect.Value, *time.Location) (int64, error)
+	UpsertStmtExec(context.Context, stmtQuerier, *models.ModelInfo, reflect.Value, *time.Location) (sql.Result, error)
 
 	Update(context.Context, dbQuerier, *models.ModelInfo, reflect.Value, *time.Location, []string) (int64, error)
 	UpdateBatch(context.Context, dbQuerier, *querySet, *models.ModelInfo, *Condition, Params, *time.Location) (int64, error)
 
 	Delete(context.Context, dbQuerier, *models.ModelInfo, reflect.Value, *time.Location, []string) (int64, error)
 	DeleteBatch(context.Context, dbQuerier, *querySet, *models.ModelInfo, *Condition, *time.Location) (int64, error)
+	DeleteBatchReturning(context.Context, dbQuerier, *querySet, *models.ModelInfo, *Condition, interface{}, *time.Location) (int64, error)
 
 	SupportUpdateJoin() bool
+	SupportsInlineCollate() bool
 	OperatorSQL(string) string
 	GenerateOperatorSQL(*models.ModelInfo, *models.FieldInfo, string, []interface{}, *time.Location) (string, []interface{})
 	GenerateOperatorLeftCol(*models.FieldInfo, string, *string)
+	SubQuerySQL(*SubQuery, *time.Location) (string, []interface{}, *models.FieldInfo)
+	LengthSQL(string) string
+	ArrayLengthSQL(*models.FieldInfo, string) string
+	ArrayElementSQL(*models.FieldInfo, string, int) string
+	DatePartSQL(string, string) string
+	TimezoneConvertSQL(string, string, string) string
+	BitAndSQL(string) string
+	ModuloSQL(string) string
+	TrimSQL(string) string
+	RecursiveWithSQL() string
+	CastSQL(string, string) string
+	ConcatSQL([]string) string
+	RoundSQL(string, int) string
+	TableSQL(string) string
+	SearchPathSQL() string
+	TimeBucketSQL(string, string) string
+	PercentileSQL(string, float64) string
+	GroupConcatSQL(string, string, string) string
+	PositionSQL(string, int) string
+	JSONExistsSQL(string, string) (string, []interface{})
+	JSONEqualSQL(string, string) (string, []interface{})
+	JSONValidSQL(*models.FieldInfo, string) string
+	UnaccentSQL(string) string
+	SetIncludesSQL(string, string) (string, []interface{})
+	SubstringIndexSQL(string, int) string
+	UpsertSQL(*models.ModelInfo, []string, []string) string
+	IntervalSQL(string, int, IntervalUnit) string
+	AdvisoryLockSQL() (string, string)
 	PrepareInsert(context.Context, dbQuerier, *models.ModelInfo) (stmtQuerier, string, error)
+	PrepareUpsert(context.Context, dbQuerier, *models.ModelInfo, []string) (stmtQuerier, string, error)
 	MaxLimit() uint64
 	TableQuote() string
+	BoolLiteral(bool) string
 	ReplaceMarks(*string)
 	HasReturningID(*models.ModelInfo, *string) bool
+	SupportReturning() bool
+	SkipLockedSQL(string) string
+	ExplainSQL(bool) string
+	CursorSQL(string, int) (string, string, string)
 	TimeFromDB(*time.Time, *time.Location)
 	TimeToDB(*time.Time, *time.Location)
 	DbTypes() map[string]string